@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// diffGeneratedOutput compares each object in the generated output against the file for that
+// object in diffDir and writes a unified diff of the differences to w. Both sides are normalized
+// before comparing so that differences in key ordering and formatting are ignored. The .yaml files
+// in diffDir that don't correspond to a generated object are reported as removed. It returns
+// whether there are differences.
+func diffGeneratedOutput(output []byte, diffDir string, w io.Writer) (bool, error) {
+	info, err := os.Stat(diffDir)
+	if err != nil {
+		return false, fmt.Errorf("failed to read the diff directory '%s': %w", diffDir, err)
+	}
+
+	if !info.IsDir() {
+		return false, fmt.Errorf("the diff directory '%s' is not a directory", diffDir)
+	}
+
+	objects, err := splitGeneratedOutput(output)
+	if err != nil {
+		return false, err
+	}
+
+	changed := false
+	seenFiles := map[string]bool{}
+
+	for _, obj := range objects {
+		fileName := getObjectFileName(obj)
+		if seenFiles[fileName] {
+			return false, fmt.Errorf("multiple generated objects map to the diff file '%s'", fileName)
+		}
+
+		seenFiles[fileName] = true
+
+		generated, err := normalizeYAML(obj)
+		if err != nil {
+			return false, err
+		}
+
+		filePath := filepath.Join(diffDir, fileName)
+		fromFile := filePath
+
+		var existing string
+
+		// #nosec G304
+		existingData, err := os.ReadFile(filePath)
+		if err == nil {
+			existing = normalizeYAMLFile(existingData)
+		} else if errors.Is(err, fs.ErrNotExist) {
+			fromFile = "/dev/null"
+		} else {
+			return false, fmt.Errorf("failed to read the diff file '%s': %w", filePath, err)
+		}
+
+		if existing == generated {
+			continue
+		}
+
+		changed = true
+
+		err = writeDiff(w, existing, generated, fromFile, filePath)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	entries, err := os.ReadDir(diffDir)
+	if err != nil {
+		return false, fmt.Errorf("failed to read the diff directory '%s': %w", diffDir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".yaml" || seenFiles[entry.Name()] {
+			continue
+		}
+
+		changed = true
+		filePath := filepath.Join(diffDir, entry.Name())
+
+		// #nosec G304
+		existingData, err := os.ReadFile(filePath)
+		if err != nil {
+			return false, fmt.Errorf("failed to read the diff file '%s': %w", filePath, err)
+		}
+
+		err = writeDiff(w, normalizeYAMLFile(existingData), "", filePath, "/dev/null")
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return changed, nil
+}
+
+// writeDiff writes the unified diff of the input file contents to w.
+func writeDiff(w io.Writer, from string, to string, fromFile string, toFile string) error {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(from),
+		B:        difflib.SplitLines(to),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to diff the file '%s': %w", fromFile, err)
+	}
+
+	_, err = io.WriteString(w, diff)
+
+	return err
+}
+
+// splitGeneratedOutput splits the multi-document YAML output of the generator into its objects.
+func splitGeneratedOutput(output []byte) ([]map[string]interface{}, error) {
+	objects := []map[string]interface{}{}
+	dec := yaml.NewDecoder(bytes.NewReader(output))
+
+	for {
+		var obj map[string]interface{}
+
+		err := dec.Decode(&obj)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to split the generated output: %w", err)
+		}
+
+		if obj == nil {
+			continue
+		}
+
+		objects = append(objects, obj)
+	}
+
+	return objects, nil
+}
+
+// getObjectFileName returns the file name of the input object in the diff directory in the format
+// of <kind>-<namespace>-<name>.yaml, where the kind is lowercase.
+func getObjectFileName(obj map[string]interface{}) string {
+	kind, _ := obj["kind"].(string)
+	nameParts := []string{strings.ToLower(kind)}
+
+	if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+		if namespace, ok := metadata["namespace"].(string); ok && namespace != "" {
+			nameParts = append(nameParts, namespace)
+		}
+
+		if name, ok := metadata["name"].(string); ok {
+			nameParts = append(nameParts, name)
+		}
+	}
+
+	return strings.Join(nameParts, "-") + ".yaml"
+}
+
+// normalizeYAML marshals the input object as YAML with the same formatting as the generated output.
+func normalizeYAML(obj interface{}) (string, error) {
+	normalized, err := yaml.Marshal(obj)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal the object to YAML: %w", err)
+	}
+
+	return string(normalized), nil
+}
+
+// normalizeYAMLFile normalizes the YAML object in the input file contents. If the contents can't be
+// parsed as a single YAML object, they are returned unmodified so that the diff shows them as is.
+func normalizeYAMLFile(data []byte) string {
+	var obj map[string]interface{}
+
+	err := yaml.Unmarshal(data, &obj)
+	if err != nil || obj == nil {
+		return string(data)
+	}
+
+	normalized, err := normalizeYAML(obj)
+	if err != nil {
+		return string(data)
+	}
+
+	return normalized
+}
@@ -0,0 +1,100 @@
+// Copyright Contributors to the Open Cluster Management project
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffGeneratedOutput(t *testing.T) {
+	t.Parallel()
+
+	output := []byte(`---
+apiVersion: policy.open-cluster-management.io/v1
+kind: Policy
+metadata:
+    name: my-policy
+    namespace: my-policies
+spec:
+    disabled: false
+`)
+
+	tests := map[string]struct {
+		files           map[string]string
+		expectedChanged bool
+		expectedDiff    []string
+	}{
+		"unchanged": {
+			files: map[string]string{
+				// The key ordering and formatting differences are ignored
+				"policy-my-policies-my-policy.yaml": "kind: Policy\napiVersion: policy.open-cluster-management.io/v1\n" +
+					"spec: {disabled: false}\nmetadata: {name: my-policy, namespace: my-policies}\n",
+			},
+			expectedChanged: false,
+		},
+		"added": {
+			files:           map[string]string{},
+			expectedChanged: true,
+			expectedDiff:    []string{"--- /dev/null", "+kind: Policy"},
+		},
+		"changed": {
+			files: map[string]string{
+				"policy-my-policies-my-policy.yaml": "apiVersion: policy.open-cluster-management.io/v1\n" +
+					"kind: Policy\nmetadata: {name: my-policy, namespace: my-policies}\nspec: {disabled: true}\n",
+			},
+			expectedChanged: true,
+			expectedDiff:    []string{"-    disabled: true", "+    disabled: false"},
+		},
+		"removed": {
+			files: map[string]string{
+				"policy-my-policies-my-policy.yaml": string(output),
+				"policy-my-policies-old-policy.yaml": "apiVersion: policy.open-cluster-management.io/v1\n" +
+					"kind: Policy\nmetadata: {name: old-policy, namespace: my-policies}\n",
+				// Files that aren't YAML files aren't reported
+				"README.md": "The generated policies\n",
+			},
+			expectedChanged: true,
+			expectedDiff:    []string{"+++ /dev/null", "-    name: old-policy"},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir := t.TempDir()
+
+			for fileName, contents := range test.files {
+				err := os.WriteFile(filepath.Join(tmpDir, fileName), []byte(contents), 0o600)
+				if err != nil {
+					t.Fatal(err.Error())
+				}
+			}
+
+			var diff bytes.Buffer
+
+			changed, err := diffGeneratedOutput(output, tmpDir, &diff)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			if changed != test.expectedChanged {
+				t.Fatalf("Expected changed to be %v but got %v with the diff:\n%s", test.expectedChanged, changed, diff.String())
+			}
+
+			if !test.expectedChanged && diff.Len() != 0 {
+				t.Fatalf("Expected no diff but got:\n%s", diff.String())
+			}
+
+			for _, expected := range test.expectedDiff {
+				if !strings.Contains(diff.String(), expected) {
+					t.Fatalf("Expected the diff to contain %q but got:\n%s", expected, diff.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// helmNamespacePlaceholder is set as the namespace of the objects before they are marshaled so that
+// it can be replaced with the Helm template of the namespace value after the braces are escaped.
+const helmNamespacePlaceholder = "POLICY_GENERATOR_HELM_NAMESPACE"
+
+// helmBraceEscaper escapes the braces of the OCM templates in the generated objects so that Helm
+// renders them as is rather than interpreting them.
+var helmBraceEscaper = strings.NewReplacer("{{", `{{ "{{" }}`, "}}", `{{ "}}" }}`)
+
+const helmValues = `# The namespace of the generated objects. This defaults to the namespace each object was generated in.
+namespace: ""
+`
+
+// writeHelmChart writes each object in the generated output to its own file in the templates
+// directory of the Helm chart in helmDir, along with a minimal Chart.yaml and values.yaml. The file
+// names are in the format of <kind>-<namespace>-<name>.yaml, where the kind is lowercase. The
+// namespace of each object is set from the namespace value, which defaults to the generated
+// namespace, so the chart renders the generated output as is with the default values.
+func writeHelmChart(output []byte, helmDir string) error {
+	objects, err := splitGeneratedOutput(output)
+	if err != nil {
+		return err
+	}
+
+	templatesDir := filepath.Join(helmDir, "templates")
+
+	err = os.MkdirAll(templatesDir, 0o755)
+	if err != nil {
+		return fmt.Errorf("failed to create the Helm templates directory '%s': %w", templatesDir, err)
+	}
+
+	absHelmDir, err := filepath.Abs(helmDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve the Helm directory '%s': %w", helmDir, err)
+	}
+
+	chart := fmt.Sprintf(
+		"apiVersion: v2\nname: %s\ndescription: Policies generated by the Policy Generator\ntype: application\n"+
+			"version: 0.1.0\n",
+		filepath.Base(absHelmDir),
+	)
+
+	for fileName, contents := range map[string]string{"Chart.yaml": chart, "values.yaml": helmValues} {
+		err = os.WriteFile(filepath.Join(helmDir, fileName), []byte(contents), 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to write the Helm file '%s': %w", filepath.Join(helmDir, fileName), err)
+		}
+	}
+
+	seenFiles := map[string]bool{}
+
+	for _, obj := range objects {
+		fileName := getObjectFileName(obj)
+		if seenFiles[fileName] {
+			return fmt.Errorf("multiple generated objects map to the Helm template file '%s'", fileName)
+		}
+
+		seenFiles[fileName] = true
+
+		template, err := getHelmTemplate(obj)
+		if err != nil {
+			return err
+		}
+
+		filePath := filepath.Join(templatesDir, fileName)
+
+		err = os.WriteFile(filePath, []byte(template), 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to write the Helm template file '%s': %w", filePath, err)
+		}
+	}
+
+	return nil
+}
+
+// getHelmTemplate returns the Helm template of the input object with the OCM template braces
+// escaped and the namespace set from the namespace value.
+func getHelmTemplate(obj map[string]interface{}) (string, error) {
+	var namespace string
+
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	if metadata != nil {
+		namespace, _ = metadata["namespace"].(string)
+	}
+
+	if namespace != "" {
+		metadata["namespace"] = helmNamespacePlaceholder
+		defer func() { metadata["namespace"] = namespace }()
+	}
+
+	objYAML, err := yaml.Marshal(obj)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal the object to YAML: %w", err)
+	}
+
+	template := helmBraceEscaper.Replace(string(objYAML))
+
+	if namespace != "" {
+		template = strings.Replace(
+			template,
+			"namespace: "+helmNamespacePlaceholder,
+			fmt.Sprintf("namespace: {{ .Values.namespace | default %q | quote }}", namespace),
+			1,
+		)
+	}
+
+	return template, nil
+}
@@ -0,0 +1,146 @@
+// Copyright Contributors to the Open Cluster Management project
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"text/template"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// renderHelmTemplate renders the input Helm template with the subset of the Helm template functions
+// used in the generated templates.
+func renderHelmTemplate(t *testing.T, helmTemplate []byte, namespace string) map[string]interface{} {
+	t.Helper()
+
+	funcs := template.FuncMap{
+		"default": func(defaultValue string, value string) string {
+			if value == "" {
+				return defaultValue
+			}
+
+			return value
+		},
+		"quote": strconv.Quote,
+	}
+
+	tmpl, err := template.New("helm").Funcs(funcs).Parse(string(helmTemplate))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var rendered bytes.Buffer
+
+	err = tmpl.Execute(&rendered, map[string]interface{}{"Values": map[string]interface{}{"namespace": namespace}})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var obj map[string]interface{}
+
+	err = yaml.Unmarshal(rendered.Bytes(), &obj)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return obj
+}
+
+func TestWriteHelmChart(t *testing.T) {
+	t.Parallel()
+
+	output := []byte(`---
+apiVersion: policy.open-cluster-management.io/v1
+kind: Policy
+metadata:
+    name: my-policy
+    namespace: my-policies
+spec:
+    policy-templates:
+        - objectDefinition:
+            apiVersion: policy.open-cluster-management.io/v1
+            kind: ConfigurationPolicy
+            metadata:
+                name: my-policy
+            spec:
+                object-templates-raw: |
+                    {{- range (lookup "v1" "ConfigMap" "default" "").items }}
+                    - complianceType: musthave
+                      objectDefinition:
+                        data:
+                          password: '{{hub fromSecret "hub-ns" "my-secret" "password" hub}}'
+                    {{- end }}
+---
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: ManagedClusterSetBinding
+metadata:
+    name: global
+    namespace: my-policies
+spec:
+    clusterSet: global
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+    name: my-role
+`)
+
+	helmDir := filepath.Join(t.TempDir(), "my-chart")
+
+	err := writeHelmChart(output, helmDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	chart, err := os.ReadFile(filepath.Join(helmDir, "Chart.yaml"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var chartObj map[string]interface{}
+
+	err = yaml.Unmarshal(chart, &chartObj)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if chartObj["apiVersion"] != "v2" || chartObj["name"] != "my-chart" {
+		t.Fatalf("Unexpected Chart.yaml: %s", chart)
+	}
+
+	objects, err := splitGeneratedOutput(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for _, obj := range objects {
+		helmTemplate, err := os.ReadFile(filepath.Join(helmDir, "templates", getObjectFileName(obj)))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		// The chart renders the generated object as is with the default values
+		rendered := renderHelmTemplate(t, helmTemplate, "")
+		if !reflect.DeepEqual(rendered, obj) {
+			t.Fatalf("Expected the template to render the generated object:\n%s", helmTemplate)
+		}
+
+		// The namespace value overrides the namespace of namespaced objects
+		rendered = renderHelmTemplate(t, helmTemplate, "other-policies")
+		metadata, _ := rendered["metadata"].(map[string]interface{})
+		originalMetadata, _ := obj["metadata"].(map[string]interface{})
+
+		if originalMetadata["namespace"] == nil {
+			if metadata["namespace"] != nil {
+				t.Fatalf("Expected no namespace on the cluster scoped object:\n%s", helmTemplate)
+			}
+		} else if metadata["namespace"] != "other-policies" {
+			t.Fatalf("Expected the namespace to be set from the values:\n%s", helmTemplate)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+// wrapResourceList returns the objects in the generated output wrapped in the items of a
+// config.kubernetes.io/v1 ResourceList, which is the format that KRM functions and tools read from
+// stdin.
+func wrapResourceList(output []byte) ([]byte, error) {
+	nodes, err := (&kio.ByteReader{Reader: bytes.NewReader(output), OmitReaderAnnotations: true}).Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the generated output: %w", err)
+	}
+
+	var wrapped bytes.Buffer
+
+	err = kio.ByteWriter{
+		Writer:             &wrapped,
+		WrappingKind:       kio.ResourceListKind,
+		WrappingAPIVersion: kio.ResourceListAPIVersion,
+	}.Write(nodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap the generated output in a ResourceList: %w", err)
+	}
+
+	return wrapped.Bytes(), nil
+}
@@ -0,0 +1,61 @@
+// Copyright Contributors to the Open Cluster Management project
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+func TestWrapResourceList(t *testing.T) {
+	t.Parallel()
+
+	output := []byte(`---
+apiVersion: policy.open-cluster-management.io/v1
+kind: Policy
+metadata:
+    name: my-policy
+    namespace: my-policies
+spec:
+    disabled: false
+---
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
+metadata:
+    name: placement-my-policy
+    namespace: my-policies
+`)
+
+	wrapped, err := wrapResourceList(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var resourceList map[string]interface{}
+
+	err = yaml.Unmarshal(wrapped, &resourceList)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if resourceList["apiVersion"] != "config.kubernetes.io/v1" || resourceList["kind"] != "ResourceList" {
+		t.Fatalf("Expected a config.kubernetes.io/v1 ResourceList but got:\n%s", wrapped)
+	}
+
+	objects, err := splitGeneratedOutput(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	items, _ := resourceList["items"].([]interface{})
+	if len(items) != len(objects) {
+		t.Fatalf("Expected %d items in the ResourceList but got:\n%s", len(objects), wrapped)
+	}
+
+	for i, item := range items {
+		if !reflect.DeepEqual(item, objects[i]) {
+			t.Fatalf("Expected the ResourceList item %d to be the generated object:\n%s", i, wrapped)
+		}
+	}
+}
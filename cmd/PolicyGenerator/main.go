@@ -2,24 +2,104 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"os/exec"
+	"path/filepath"
 	runtimeDebug "runtime/debug"
+	"slices"
 	"strings"
 
 	"github.com/spf13/pflag"
+	yaml "gopkg.in/yaml.v3"
 
 	"open-cluster-management.io/policy-generator-plugin/internal"
 )
 
 var Version string
 
+// The exit codes of the generator. Automation can use these to determine the type of failure.
+const (
+	// exitCodeError is the exit code for errors that don't fit in a more specific exit code.
+	exitCodeError = 1
+	// exitCodeInvalidConfig is the exit code for an invalid PolicyGenerator file.
+	exitCodeInvalidConfig = 2
+	// exitCodeReadError is the exit code for an input or output file that can't be read or written.
+	exitCodeReadError = 3
+	// exitCodeGenerationError is the exit code for a failure to generate the policies from a valid
+	// PolicyGenerator file.
+	exitCodeGenerationError = 4
+	// exitCodeDiff is the exit code for generated output that differs from the files in the --diff
+	// directory.
+	exitCodeDiff = 5
+)
+
 var debug = false
 
 func main() {
 	// Parse command input
 	debugFlag := pflag.Bool("debug", false, "Print the stack trace with error messages")
 	versionFlag := pflag.Bool("version", false, "Print the version of the generator")
+	outputFlag := pflag.String("output", "", "Write the generated output to this file instead of stdout")
+	baseDirFlag := pflag.String(
+		"base-dir", "", "Restrict the manifest paths to this directory instead of the current working directory",
+	)
+	valuesFlag := pflag.String(
+		"values", "", "Substitute the ${NAME} variables in the PolicyGenerator files with the values in this YAML file",
+	)
+	valuesFromEnvFlag := pflag.Bool(
+		"values-from-env", false, "Substitute ${NAME} variables that aren't in the values file with environment variables",
+	)
+	allowUndefinedValuesFlag := pflag.Bool(
+		"allow-undefined-values", false, "Substitute undefined ${NAME} variables with an empty string instead of failing",
+	)
+	watchFlag := pflag.Bool(
+		"watch", false, "Regenerate the output whenever the PolicyGenerator files or their input files change",
+	)
+	diffFlag := pflag.String(
+		"diff", "", "Print a diff of the generated output against the files in this directory instead of writing it",
+	)
+	pathPrefixMapFlag := pflag.StringArray(
+		"path-prefix-map", nil,
+		"Rewrite the paths in the configuration that start with OLD to start with NEW in the format of OLD=NEW "+
+			"(can be repeated)",
+	)
+	emitIndexFlag := pflag.String(
+		"emit-index", "", "Append a ConfigMap with this name listing the generated policies and policy sets",
+	)
+	policyFlag := pflag.StringArray(
+		"policy", nil, "Only generate the policy with this name and its placement and binding (can be repeated)",
+	)
+	validateSchemaFlag := pflag.Bool(
+		"validate-schema", false,
+		"Validate the generated Policy, ConfigurationPolicy, PolicySet, Placement, and PlacementBinding objects "+
+			"against their schemas",
+	)
+	keepGoingFlag := pflag.Bool(
+		"keep-going", false,
+		"Continue processing the remaining PolicyGenerator files after one fails and report all the failures",
+	)
+	postHookFlag := pflag.String(
+		"post-hook", "", "Pipe the generated output to this shell command and use its output as the final output",
+	)
+	helmDirFlag := pflag.String(
+		"helm-dir", "", "Write the generated objects as the templates of a Helm chart in this directory instead of stdout",
+	)
+	krmOutputFlag := pflag.Bool(
+		"krm-output", false,
+		"Wrap the generated objects in a config.kubernetes.io/v1 ResourceList for KRM tools instead of YAML documents",
+	)
+	scaffoldOnlyFlag := pflag.Bool(
+		"scaffold-only", false,
+		"Only output the placements and placement bindings and not the policies and policy sets they bind",
+	)
+	checkNamesFlag := pflag.Bool(
+		"check-names", false,
+		"Only report every policy, policy set, placement, and placement binding name that isn't valid without "+
+			"generating any output",
+	)
 	pflag.Parse()
 
 	if *versionFlag {
@@ -40,24 +120,190 @@ func main() {
 
 	debug = *debugFlag
 
+	baseDirectory, err := getBaseDirectory(*baseDirFlag)
+	if err != nil {
+		errorAndExit(getExitCode(err), "%s", err)
+	}
+
+	pathPrefixMappings, err := parsePathPrefixMappings(*pathPrefixMapFlag)
+	if err != nil {
+		errorAndExit(exitCodeError, "%s", err)
+	}
+
+	// Kustomize sets this environment variable when it runs the generator as a plugin
+	_, isKustomizePlugin := os.LookupEnv("KUSTOMIZE_PLUGIN_CONFIG_ROOT")
+
+	opts := generatorOptions{
+		baseDirectory:        baseDirectory,
+		standalone:           *baseDirFlag != "" || !isKustomizePlugin,
+		valuesPath:           *valuesFlag,
+		valuesFromEnv:        *valuesFromEnvFlag,
+		allowUndefinedValues: *allowUndefinedValuesFlag,
+		pathPrefixMappings:   pathPrefixMappings,
+		indexName:            *emitIndexFlag,
+		validateSchema:       *validateSchemaFlag,
+		policyFilter:         *policyFlag,
+		filteredPolicies:     map[string]bool{},
+		postHook:             *postHookFlag,
+		scaffoldOnly:         *scaffoldOnlyFlag,
+	}
+
 	// Collect and parse PolicyGeneratorConfig file paths
 	generators := pflag.Args()
+
+	if *diffFlag != "" && (*watchFlag || *outputFlag != "") {
+		errorAndExit(exitCodeError, "the --diff flag can't be combined with the --watch or --output flags")
+	}
+
+	if *helmDirFlag != "" && (*watchFlag || *outputFlag != "" || *diffFlag != "") {
+		errorAndExit(exitCodeError, "the --helm-dir flag can't be combined with the --watch, --output, or --diff flags")
+	}
+
+	if *krmOutputFlag && (*watchFlag || *diffFlag != "" || *helmDirFlag != "") {
+		errorAndExit(exitCodeError, "the --krm-output flag can't be combined with the --watch, --diff, or --helm-dir flags")
+	}
+
+	if *checkNamesFlag {
+		if *watchFlag || *diffFlag != "" || *outputFlag != "" || *helmDirFlag != "" {
+			errorAndExit(
+				exitCodeError,
+				"the --check-names flag can't be combined with the --watch, --diff, --output, or --helm-dir flags",
+			)
+		}
+
+		checkGeneratorNames(generators, opts)
+
+		return
+	}
+
+	if *watchFlag {
+		err := watchGeneratorConfigs(generators, opts, *outputFlag)
+		if err != nil {
+			errorAndExit(getExitCode(err), "%s", err)
+		}
+
+		return
+	}
+
 	var outputBuffer bytes.Buffer
 
+	failures := []error{}
+
 	for _, gen := range generators {
-		outputBuffer.Write(processGeneratorConfig(gen))
+		generatedOutput, _, err := processGeneratorConfig(gen, opts)
+		if err != nil {
+			if !*keepGoingFlag {
+				errorAndExit(getExitCode(err), "%s", err)
+			}
+
+			failures = append(failures, err)
+
+			continue
+		}
+
+		outputBuffer.Write(generatedOutput)
+	}
+
+	// A policy in the --policy flag may be defined in a PolicyGenerator file that failed
+	if len(failures) == 0 {
+		err = assertPolicyFilterMatched(opts)
+		if err != nil {
+			errorAndExit(getExitCode(err), "%s", err)
+		}
+	}
+
+	err = appendIndex(&outputBuffer, opts.indexName)
+	if err != nil {
+		errorAndExit(getExitCode(err), "%s", err)
 	}
 
-	// Output results to stdout for Kustomize to handle
-	//nolint:forbidigo
-	fmt.Print(outputBuffer.String())
+	output, err := runPostHook(outputBuffer.Bytes(), opts.postHook)
+	if err != nil {
+		errorAndExit(getExitCode(err), "%s", err)
+	}
+
+	if *diffFlag != "" {
+		changed, err := diffGeneratedOutput(output, *diffFlag, os.Stdout)
+		if err != nil {
+			errorAndExit(getExitCode(err), "%s", err)
+		}
+
+		exitOnFailures(failures, len(generators))
+
+		if changed {
+			os.Exit(exitCodeDiff)
+		}
+
+		return
+	}
+
+	if *helmDirFlag != "" {
+		err = writeHelmChart(output, *helmDirFlag)
+		if err != nil {
+			errorAndExit(getExitCode(err), "%s", err)
+		}
+
+		exitOnFailures(failures, len(generators))
+
+		return
+	}
+
+	if *krmOutputFlag {
+		output, err = wrapResourceList(output)
+		if err != nil {
+			errorAndExit(getExitCode(err), "%s", err)
+		}
+	}
+
+	err = writeOutput(output, *outputFlag)
+	if err != nil {
+		errorAndExit(getExitCode(err), "%s", err)
+	}
+
+	exitOnFailures(failures, len(generators))
+}
+
+// exitOnFailures prints a summary of the errors of the PolicyGenerator files that failed to process
+// with the --keep-going flag and exits with the exit code of the first failure. If there are no
+// failures, it returns.
+func exitOnFailures(failures []error, total int) {
+	if len(failures) == 0 {
+		return
+	}
+
+	msgs := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		msgs = append(msgs, failure.Error())
+	}
+
+	errorAndExit(
+		getExitCode(failures[0]),
+		"failed to process %d of %d PolicyGenerator files:\n%s",
+		len(failures), total, strings.Join(msgs, "\n"),
+	)
+}
+
+// getExitCode returns the exit code for the input error based on its error class.
+func getExitCode(err error) int {
+	var pathErr *fs.PathError
+
+	switch {
+	case errors.Is(err, internal.ErrManifestRead) || errors.As(err, &pathErr):
+		return exitCodeReadError
+	case errors.Is(err, internal.ErrInvalidConfig):
+		return exitCodeInvalidConfig
+	case errors.Is(err, internal.ErrGeneration):
+		return exitCodeGenerationError
+	default:
+		return exitCodeError
+	}
 }
 
-// errorAndExit takes a message string with formatting verbs and associated formatting
-// arguments similar to fmt.Errorf(). If `debug` is set or it is given an empty message
-// string, it throws a panic to print the message along with the trace. Otherwise
-// it prints the formatted message to stderr and exits with error code 1.
-func errorAndExit(msg string, formatArgs ...interface{}) {
+// errorAndExit takes an exit code, a message string with formatting verbs, and associated
+// formatting arguments similar to fmt.Errorf(). If `debug` is set or it is given an empty
+// message string, it throws a panic to print the message along with the trace. Otherwise
+// it prints the formatted message to stderr and exits with the exit code.
+func errorAndExit(exitCode int, msg string, formatArgs ...interface{}) {
 	printArgs := make([]interface{}, len(formatArgs))
 	copy(printArgs, formatArgs)
 	// Show trace if the debug flag is set
@@ -67,35 +313,307 @@ func errorAndExit(msg string, formatArgs ...interface{}) {
 
 	fmt.Fprintf(os.Stderr, msg, printArgs...)
 	fmt.Fprint(os.Stderr, "\n")
-	os.Exit(1)
+	os.Exit(exitCode)
+}
+
+// writeOutput writes the generated output to the file at outputPath. If outputPath is empty, the
+// output is written to stdout for Kustomize to handle.
+func writeOutput(output []byte, outputPath string) error {
+	if outputPath == "" {
+		//nolint:forbidigo
+		fmt.Print(string(output))
+
+		return nil
+	}
+
+	err := os.WriteFile(outputPath, output, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write the output file '%s': %w", outputPath, err)
+	}
+
+	return nil
 }
 
-// processGeneratorConfig takes a string file path to a PolicyGenerator YAML file.
-// It reads the file, processes and validates the contents, uses the contents to
-// generate policies, and returns the generated policies as a byte array.
-func processGeneratorConfig(filePath string) []byte {
-	cwd, err := os.Getwd()
+// getBaseDirectory returns the directory that manifest paths are restricted to. This is the
+// input directory with symlinks resolved or, if the input directory is empty, the current
+// working directory.
+func getBaseDirectory(baseDir string) (string, error) {
+	if baseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("failed to determine the current directory: %w", err)
+		}
+
+		return cwd, nil
+	}
+
+	absBaseDir, err := filepath.Abs(baseDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the base directory '%s': %w", baseDir, err)
+	}
+
+	resolvedBaseDir, err := filepath.EvalSymlinks(absBaseDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the base directory '%s': %w", baseDir, err)
+	}
+
+	info, err := os.Stat(resolvedBaseDir)
 	if err != nil {
-		errorAndExit("failed to determine the current directory: %v", err)
+		return "", fmt.Errorf("failed to resolve the base directory '%s': %w", baseDir, err)
 	}
 
+	if !info.IsDir() {
+		return "", fmt.Errorf("the base directory '%s' is not a directory", baseDir)
+	}
+
+	return resolvedBaseDir, nil
+}
+
+// generatorOptions are the command line options that apply to processing the PolicyGenerator files.
+type generatorOptions struct {
+	// The directory that manifest paths are restricted to
+	baseDirectory string
+	// Whether the generator is run standalone rather than as a Kustomize plugin
+	standalone bool
+	// The path to the YAML file of values to substitute in the PolicyGenerator files, if any
+	valuesPath           string
+	valuesFromEnv        bool
+	allowUndefinedValues bool
+	// The path prefixes to rewrite in the manifest and placement paths, if any
+	pathPrefixMappings []internal.PathPrefixMapping
+	// The name of the ConfigMap index of the generated policies to append to the output, if any
+	indexName string
+	// Whether to validate the generated objects against their schemas
+	validateSchema bool
+	// The names of the policies to generate, if only some should be generated
+	policyFilter []string
+	// The names in policyFilter that matched a policy in the processed PolicyGenerator files
+	filteredPolicies map[string]bool
+	// The shell command to pipe the generated output to, if any
+	postHook string
+	// Whether to only output the placements and placement bindings
+	scaffoldOnly bool
+}
+
+// assertPolicyFilterMatched returns an error if a name in the --policy flag didn't match a policy in
+// any of the processed PolicyGenerator files.
+func assertPolicyFilterMatched(opts generatorOptions) error {
+	for _, name := range opts.policyFilter {
+		if !opts.filteredPolicies[name] {
+			return fmt.Errorf("the policy %s passed to --policy is not defined in the PolicyGenerator files", name)
+		}
+	}
+
+	return nil
+}
+
+// appendIndex appends the ConfigMap index of the policies and policy sets in the generated output
+// to the output. If indexName is empty, the output is left unmodified.
+func appendIndex(outputBuffer *bytes.Buffer, indexName string) error {
+	if indexName == "" {
+		return nil
+	}
+
+	index, err := internal.GenerateIndex(outputBuffer.Bytes(), indexName)
+	if err != nil {
+		return err
+	}
+
+	outputBuffer.Write(index)
+
+	return nil
+}
+
+// runPostHook runs the input shell command with the generated output as its stdin and returns its
+// stdout as the final output. If postHook is empty, the output is returned unmodified. An error with
+// the stderr of the command is returned if the command exits with a non-zero exit code.
+func runPostHook(output []byte, postHook string) ([]byte, error) {
+	if postHook == "" {
+		return output, nil
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	// #nosec G204
+	cmd := exec.Command("sh", "-c", postHook)
+	cmd.Stdin = bytes.NewReader(output)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		hookErr := fmt.Errorf("the --post-hook command '%s' failed: %w", postHook, err)
+
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			hookErr = fmt.Errorf("%w: %s", hookErr, msg)
+		}
+
+		return nil, hookErr
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// parsePathPrefixMappings parses the values of the --path-prefix-map flag in the format of OLD=NEW.
+func parsePathPrefixMappings(values []string) ([]internal.PathPrefixMapping, error) {
+	mappings := make([]internal.PathPrefixMapping, 0, len(values))
+
+	for _, value := range values {
+		oldPrefix, newPrefix, found := strings.Cut(value, "=")
+		if !found || oldPrefix == "" || newPrefix == "" {
+			return nil, fmt.Errorf("the --path-prefix-map value '%s' must be in the format of OLD=NEW", value)
+		}
+
+		mappings = append(mappings, internal.PathPrefixMapping{Old: oldPrefix, New: newPrefix})
+	}
+
+	return mappings, nil
+}
+
+// readValuesFile reads the YAML file of key-value pairs to substitute in the PolicyGenerator files.
+func readValuesFile(valuesPath string) (map[string]string, error) {
+	// #nosec G304
+	valuesData, err := os.ReadFile(valuesPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the values file '%s': %w", valuesPath, err)
+	}
+
+	values := map[string]string{}
+
+	err = yaml.Unmarshal(valuesData, &values)
+	if err != nil {
+		return nil, fmt.Errorf("the values file '%s' must contain a map of key-value pairs: %w", valuesPath, err)
+	}
+
+	return values, nil
+}
+
+// newPlugin returns a plugin configured with the command line options that apply before the
+// PolicyGenerator file is processed.
+func newPlugin(opts generatorOptions) (*internal.Plugin, error) {
 	p := internal.Plugin{}
+	p.SetStandalone(opts.standalone)
+	p.SetPathPrefixMappings(opts.pathPrefixMappings)
+	p.SetScaffoldOnly(opts.scaffoldOnly)
+
+	if opts.valuesPath != "" || opts.valuesFromEnv {
+		values := map[string]string{}
+
+		if opts.valuesPath != "" {
+			var err error
+
+			values, err = readValuesFile(opts.valuesPath)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		p.SetSubstitutionOptions(internal.SubstitutionOptions{
+			Values:         values,
+			UseEnv:         opts.valuesFromEnv,
+			AllowUndefined: opts.allowUndefinedValues,
+			ValuesPath:     opts.valuesPath,
+		})
+	}
+
+	return &p, nil
+}
+
+// checkGeneratorNames prints every naming violation in the input PolicyGenerator files with the
+// file and location of the name. It exits with the invalid configuration exit code if there are any
+// violations or a file can't be decoded.
+func checkGeneratorNames(filePaths []string, opts generatorOptions) {
+	total := 0
+
+	for _, filePath := range filePaths {
+		p, err := newPlugin(opts)
+		if err != nil {
+			errorAndExit(getExitCode(err), "%s", err)
+		}
+
+		// #nosec G304
+		fileData, err := os.ReadFile(filePath)
+		if err != nil {
+			errorAndExit(exitCodeReadError, "failed to read file '%s': %s", filePath, err)
+		}
+
+		violations, err := p.CheckNames(fileData)
+		if err != nil {
+			errorAndExit(getExitCode(err), "error processing the PolicyGenerator file '%s': %s", filePath, err)
+		}
+
+		for _, violation := range violations {
+			//nolint:forbidigo
+			fmt.Printf("%s: %s\n", filePath, violation)
+		}
+
+		total += len(violations)
+	}
+
+	if total != 0 {
+		errorAndExit(exitCodeInvalidConfig, "found %d invalid names in the PolicyGenerator files", total)
+	}
+}
+
+// processGeneratorConfig takes a string file path to a PolicyGenerator YAML file and the
+// command line options. It reads the file, processes and validates the contents, uses the
+// contents to generate policies, and returns the generated policies as a byte array along
+// with the processed plugin. An error is returned if any of these steps fail.
+func processGeneratorConfig(filePath string, opts generatorOptions) ([]byte, *internal.Plugin, error) {
+	p, err := newPlugin(opts)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// #nosec G304
 	fileData, err := os.ReadFile(filePath)
 	if err != nil {
-		errorAndExit("failed to read file '%s': %s", filePath, err)
+		return nil, nil, fmt.Errorf("failed to read file '%s': %w", filePath, err)
 	}
 
-	err = p.Config(fileData, cwd)
+	err = p.Config(fileData, opts.baseDirectory)
 	if err != nil {
-		errorAndExit("error processing the PolicyGenerator file '%s': %s", filePath, err)
+		return nil, nil, fmt.Errorf("error processing the PolicyGenerator file '%s': %w", filePath, err)
+	}
+
+	if len(opts.policyFilter) != 0 {
+		// The filter applies across all the PolicyGenerator files, so only filter by the names defined in this file
+		names := []string{}
+
+		for _, policy := range p.Policies {
+			if slices.Contains(opts.policyFilter, policy.Name) {
+				names = append(names, policy.Name)
+				opts.filteredPolicies[policy.Name] = true
+			}
+		}
+
+		if len(names) == 0 {
+			return nil, p, nil
+		}
+
+		err = p.FilterPolicies(names)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error processing the PolicyGenerator file '%s': %w", filePath, err)
+		}
 	}
 
 	generatedOutput, err := p.Generate()
 	if err != nil {
-		errorAndExit("error generating policies from the PolicyGenerator file '%s': %s", filePath, err)
+		return nil, nil, fmt.Errorf("error generating policies from the PolicyGenerator file '%s': %w", filePath, err)
+	}
+
+	for _, warning := range p.Warnings() {
+		fmt.Fprintf(os.Stderr, "warning: %s in the PolicyGenerator file '%s'\n", warning, filePath)
+	}
+
+	if opts.validateSchema {
+		err = p.ValidateOutput(generatedOutput)
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"the policies generated from the PolicyGenerator file '%s' are invalid:\n%w", filePath, err,
+			)
+		}
 	}
 
-	return generatedOutput
+	return generatedOutput, p, nil
 }
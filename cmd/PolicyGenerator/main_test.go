@@ -0,0 +1,235 @@
+// Copyright Contributors to the Open Cluster Management project
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// mainArgsEnv is the environment variable with the newline-separated arguments that TestMainProcess
+// runs the main function with.
+const mainArgsEnv = "POLICY_GENERATOR_TEST_MAIN_ARGS"
+
+// writeGeneratorFile writes a PolicyGenerator file with a single policy named policyName of a
+// ConfigMap manifest to the input directory and returns its path. The extra YAML is added to the
+// policyDefaults.
+func writeGeneratorFile(t *testing.T, dir string, policyName string, policyDefaults string) string {
+	t.Helper()
+
+	manifestPath := filepath.Join(dir, policyName+"-configmap.yaml")
+
+	err := os.WriteFile(
+		manifestPath, []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: my-configmap\n"), 0o644,
+	)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	generatorPath := filepath.Join(dir, policyName+"-generator.yaml")
+	generator := fmt.Sprintf(`apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+%s
+policies:
+- name: %s
+  manifests:
+    - path: %s
+`,
+		policyDefaults, policyName, manifestPath,
+	)
+
+	err = os.WriteFile(generatorPath, []byte(generator), 0o644)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return generatorPath
+}
+
+// getTestGeneratorOptions returns the generator options for the PolicyGenerator files in the input
+// base directory.
+func getTestGeneratorOptions(t *testing.T, baseDirectory string) generatorOptions {
+	t.Helper()
+
+	baseDirectory, err := filepath.EvalSymlinks(baseDirectory)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return generatorOptions{
+		baseDirectory:    baseDirectory,
+		standalone:       true,
+		filteredPolicies: map[string]bool{},
+	}
+}
+
+func TestRunPostHook(t *testing.T) {
+	t.Parallel()
+
+	output := []byte("apiVersion: v1\nkind: ConfigMap\n")
+
+	hookOutput, err := runPostHook(output, "tr a-z A-Z")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if string(hookOutput) != "APIVERSION: V1\nKIND: CONFIGMAP\n" {
+		t.Fatalf("Unexpected output from the post hook: %s", hookOutput)
+	}
+
+	hookOutput, err = runPostHook(output, "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if string(hookOutput) != string(output) {
+		t.Fatalf("Expected the output to be unmodified without a post hook: %s", hookOutput)
+	}
+}
+
+func TestRunPostHookFailure(t *testing.T) {
+	t.Parallel()
+
+	_, err := runPostHook([]byte("kind: ConfigMap\n"), "cat > /dev/null; echo 'failed to sign' >&2; exit 3")
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := "the --post-hook command 'cat > /dev/null; echo 'failed to sign' >&2; exit 3' failed: exit status 3: " +
+		"failed to sign"
+	if err.Error() != expected {
+		t.Fatalf("Expected the error %q but got %q", expected, err.Error())
+	}
+}
+
+func TestProcessGeneratorConfigValidateSchemaObjectSelector(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	generatorPath := writeGeneratorFile(t, tmpDir, "my-policy", "  objectSelector:\n    matchLabels:\n      app: my-app")
+
+	opts := getTestGeneratorOptions(t, tmpDir)
+	opts.validateSchema = true
+
+	// The generated objectSelector must pass --validate-schema
+	_, _, err := processGeneratorConfig(generatorPath, opts)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+}
+
+// runMain runs the main function with the input arguments in a subprocess, since it exits the
+// process, and returns the exit code and the stderr output.
+func runMain(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+
+	// #nosec G204
+	cmd := exec.Command(os.Args[0], "-test.run=^TestMainProcess$")
+	cmd.Env = append(os.Environ(), mainArgsEnv+"="+strings.Join(args, "\n"))
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), stderr.String()
+	}
+
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return 0, stderr.String()
+}
+
+// TestMainProcess runs the main function with the arguments set by runMain. It is skipped otherwise.
+func TestMainProcess(t *testing.T) {
+	args, ok := os.LookupEnv(mainArgsEnv)
+	if !ok {
+		t.Skip("This only runs as a subprocess of runMain")
+	}
+
+	os.Args = append([]string{"PolicyGenerator"}, strings.Split(args, "\n")...)
+
+	main()
+}
+
+func TestMainKeepGoing(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		keepGoing bool
+	}{
+		"keep going":    {keepGoing: true},
+		"stop on error": {keepGoing: false},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir := t.TempDir()
+
+			goodPath := writeGeneratorFile(t, tmpDir, "good-policy", "")
+			badPath := writeGeneratorFile(t, tmpDir, "bad-policy", "")
+
+			// The bad PolicyGenerator file fails since its manifest doesn't exist
+			err := os.Remove(filepath.Join(tmpDir, "bad-policy-configmap.yaml"))
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			outputPath := filepath.Join(tmpDir, "output.yaml")
+			args := []string{"--base-dir", tmpDir, "--output", outputPath, badPath, goodPath}
+
+			if test.keepGoing {
+				args = append([]string{"--keep-going"}, args...)
+			}
+
+			exitCode, stderr := runMain(t, args...)
+
+			if exitCode != exitCodeReadError {
+				t.Fatalf("Expected the exit code %d but got %d with the error: %s", exitCodeReadError, exitCode, stderr)
+			}
+
+			if !strings.Contains(stderr, badPath) {
+				t.Fatalf("Expected the error to contain the failing file %s but got: %s", badPath, stderr)
+			}
+
+			// #nosec G304
+			output, err := os.ReadFile(outputPath)
+
+			if !test.keepGoing {
+				if !errors.Is(err, os.ErrNotExist) {
+					t.Fatalf("Expected no output to be written but got: %s", output)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			if !strings.Contains(stderr, "failed to process 1 of 2 PolicyGenerator files") {
+				t.Fatalf("Expected the error to summarize the failures but got: %s", stderr)
+			}
+
+			if !strings.Contains(string(output), "name: good-policy") || strings.Contains(string(output), "bad-policy") {
+				t.Fatalf("Expected the output to only have the good policy but got:\n%s", output)
+			}
+		})
+	}
+}
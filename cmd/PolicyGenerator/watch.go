@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long to wait for further file changes before regenerating, since editors
+// commonly emit several events for a single save.
+const watchDebounce = 100 * time.Millisecond
+
+// generatorWatcher regenerates the output of a set of PolicyGenerator files whenever the files or
+// any of the paths they reference change.
+type generatorWatcher struct {
+	generators []string
+	opts       generatorOptions
+	outputPath string
+	watcher    *fsnotify.Watcher
+	// The absolute paths of the input files and directories that trigger a regeneration
+	inputPaths map[string]bool
+	// The absolute paths of the directories added to the watcher
+	watchedDirs map[string]bool
+}
+
+// watchGeneratorConfigs generates the output of the input PolicyGenerator files and then regenerates
+// it whenever one of the files or the paths they reference change. Errors from generating the output
+// are printed to stderr rather than stopping the watch. This only returns if the watcher fails.
+func watchGeneratorConfigs(generators []string, opts generatorOptions, outputPath string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create the file watcher: %w", err)
+	}
+
+	defer watcher.Close()
+
+	w := generatorWatcher{
+		generators:  generators,
+		opts:        opts,
+		outputPath:  outputPath,
+		watcher:     watcher,
+		inputPaths:  map[string]bool{},
+		watchedDirs: map[string]bool{},
+	}
+
+	return w.run(nil)
+}
+
+// run generates the output and then regenerates it whenever an input path changes until the stop
+// channel is closed or the watcher fails.
+func (w *generatorWatcher) run(stop <-chan struct{}) error {
+	w.regenerate()
+
+	var debounce <-chan time.Time
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			if event.Op == fsnotify.Chmod || !w.isInputPath(event.Name) {
+				continue
+			}
+
+			debounce = time.After(watchDebounce)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return nil
+			}
+
+			fmt.Fprintf(os.Stderr, "error watching the input files: %s\n", err)
+		case <-debounce:
+			debounce = nil
+
+			w.regenerate()
+		}
+	}
+}
+
+// regenerate processes all the PolicyGenerator files, writes the output, and updates the watched
+// paths based on the processed configurations. Errors are printed to stderr.
+func (w *generatorWatcher) regenerate() {
+	var outputBuffer bytes.Buffer
+
+	inputPaths := append([]string{}, w.generators...)
+	if w.opts.valuesPath != "" {
+		inputPaths = append(inputPaths, w.opts.valuesPath)
+	}
+
+	failed := false
+	w.opts.filteredPolicies = map[string]bool{}
+
+	for _, gen := range w.generators {
+		generatedOutput, p, err := processGeneratorConfig(gen, w.opts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+
+			failed = true
+
+			continue
+		}
+
+		outputBuffer.Write(generatedOutput)
+
+		inputPaths = append(inputPaths, p.InputPaths()...)
+	}
+
+	// Keep watching the previous paths on failure since a config file that failed to process doesn't
+	// report the paths it references.
+	w.watchPaths(inputPaths)
+
+	if failed {
+		return
+	}
+
+	err := assertPolicyFilterMatched(w.opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+
+		return
+	}
+
+	err = appendIndex(&outputBuffer, w.opts.indexName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+
+		return
+	}
+
+	output, err := runPostHook(outputBuffer.Bytes(), w.opts.postHook)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+
+		return
+	}
+
+	err = writeOutput(output, w.outputPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+// watchPaths adds the input paths to the watcher. Files are watched through their parent
+// directory so that editors that replace files on save are handled. Directories are watched
+// recursively, and subdirectories created since the last call are added.
+func (w *generatorWatcher) watchPaths(paths []string) {
+	for _, path := range paths {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to resolve the path '%s' to watch: %s\n", path, err)
+
+			continue
+		}
+
+		w.inputPaths[absPath] = true
+
+		if info, err := os.Stat(absPath); err != nil || !info.IsDir() {
+			w.watchDir(filepath.Dir(absPath))
+
+			continue
+		}
+
+		err = filepath.WalkDir(absPath, func(subPath string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if entry.IsDir() {
+				w.watchDir(subPath)
+			}
+
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to watch the path '%s': %s\n", absPath, err)
+		}
+	}
+}
+
+// watchDir adds the input directory to the watcher if it isn't already watched.
+func (w *generatorWatcher) watchDir(dir string) {
+	if w.watchedDirs[dir] {
+		return
+	}
+
+	err := w.watcher.Add(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to watch the path '%s': %s\n", dir, err)
+
+		return
+	}
+
+	w.watchedDirs[dir] = true
+}
+
+// isInputPath determines if the changed path is an input path or is in an input directory at any
+// depth. The output file is never considered an input path so that writing it doesn't trigger
+// another regeneration.
+func (w *generatorWatcher) isInputPath(path string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+
+	if w.outputPath != "" {
+		if absOutputPath, err := filepath.Abs(w.outputPath); err == nil && absOutputPath == absPath {
+			return false
+		}
+	}
+
+	for {
+		if w.inputPaths[absPath] {
+			return true
+		}
+
+		parent := filepath.Dir(absPath)
+		if parent == absPath {
+			return false
+		}
+
+		absPath = parent
+	}
+}
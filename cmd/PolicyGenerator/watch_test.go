@@ -0,0 +1,114 @@
+// Copyright Contributors to the Open Cluster Management project
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// waitForOutput waits for the file at outputPath to contain the expected string.
+func waitForOutput(t *testing.T, outputPath string, expected string) {
+	t.Helper()
+
+	var output []byte
+
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); {
+		// #nosec G304
+		output, _ = os.ReadFile(outputPath)
+		if strings.Contains(string(output), expected) {
+			return
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	t.Fatalf("Expected the output to contain %q but got:\n%s", expected, output)
+}
+
+func TestWatchGeneratorConfigs(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	// The ConfigMap is in a subdirectory of the Kustomize manifest path, so the watch must be recursive
+	baseDir := filepath.Join(tmpDir, "manifests", "base")
+
+	err := os.MkdirAll(baseDir, 0o755)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	writeFile := func(filePath string, contents string) {
+		t.Helper()
+
+		err := os.WriteFile(filePath, []byte(contents), 0o644)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	configMapPath := filepath.Join(baseDir, "configmap.yaml")
+	configMap := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: my-configmap\ndata:\n  game: %s\n"
+
+	writeFile(configMapPath, fmt.Sprintf(configMap, "potato"))
+	writeFile(filepath.Join(tmpDir, "manifests", "kustomization.yaml"), "resources:\n- base/configmap.yaml\n")
+
+	generatorPath := filepath.Join(tmpDir, "generator.yaml")
+	writeFile(generatorPath, fmt.Sprintf(`apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: my-policy
+  manifests:
+    - path: %s
+`,
+		filepath.Join(tmpDir, "manifests"),
+	))
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	defer watcher.Close()
+
+	outputPath := filepath.Join(tmpDir, "output.yaml")
+	w := generatorWatcher{
+		generators:  []string{generatorPath},
+		opts:        getTestGeneratorOptions(t, tmpDir),
+		outputPath:  outputPath,
+		watcher:     watcher,
+		inputPaths:  map[string]bool{},
+		watchedDirs: map[string]bool{},
+	}
+
+	stop := make(chan struct{})
+	done := make(chan error)
+
+	go func() {
+		done <- w.run(stop)
+	}()
+
+	defer func() {
+		close(stop)
+
+		err := <-done
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	}()
+
+	waitForOutput(t, outputPath, "game: potato")
+
+	writeFile(configMapPath, fmt.Sprintf(configMap, "tomato"))
+
+	waitForOutput(t, outputPath, "game: tomato")
+}
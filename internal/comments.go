@@ -0,0 +1,255 @@
+// Copyright Contributors to the Open Cluster Management project
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"sigs.k8s.io/kustomize/kyaml/comments"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"open-cluster-management.io/policy-generator-plugin/internal/types"
+)
+
+// commentSource is an object of a source manifest with its comments and the kind and name that
+// identify it in the generated policy.
+type commentSource struct {
+	kind string
+	name string
+	node *kyaml.RNode
+}
+
+// marshalPolicyNode marshals the input generated policy to YAML through a kyaml node tree so that the
+// embedded objectDefinition values of the object-templates can be changed based on the input policy
+// configuration. When preserveComments is set, the comments from the source manifests are copied onto
+// the embedded objects. The embedded objects are matched to the source manifest objects by their kind
+// and name, where the name of a single object renamed by the metadata of the single patch of its
+// manifest is the patched name, as in getManifests. Only YAML files that aren't processed by Kustomize
+// or another renderer have comments since the comments are lost otherwise. When canonicalKeyOrder is
+// set, the top-level keys of the embedded objects are reordered with orderCanonicalKeys. An error is
+// returned if a source manifest can't be read. See marshalYAML for the indent argument.
+func marshalPolicyNode(obj map[string]interface{}, policyConf *types.PolicyConfig, indent int) ([]byte, error) {
+	node := kyaml.Node{}
+
+	err := node.Encode(obj)
+	if err != nil {
+		return nil, fmt.Errorf("an unexpected error occurred when converting the object to YAML: %w", err)
+	}
+
+	if policyConf.PreserveComments {
+		sources := []commentSource{}
+
+		for _, manifest := range policyConf.Manifests {
+			manifestSources, err := readManifestCommentNodes(manifest)
+			if err != nil {
+				return nil, err
+			}
+
+			sources = append(sources, manifestSources...)
+		}
+
+		for _, objDef := range findObjectDefinitionNodes(&node) {
+			objDefNode := kyaml.NewRNode(objDef)
+
+			source := findSourceNode(objDefNode, sources)
+			if source == nil {
+				continue
+			}
+
+			err := comments.CopyComments(source, objDefNode)
+			if err != nil {
+				return nil, fmt.Errorf("failed to copy the manifest comments: %w", err)
+			}
+		}
+	}
+
+	if policyConf.CanonicalKeyOrder {
+		for _, objDef := range findObjectDefinitionNodes(&node) {
+			orderCanonicalKeys(objDef)
+		}
+	}
+
+	// Match the formatting of marshalYAML, including its default indent of 4 spaces, rather than the
+	// compact sequences of kyaml
+	if indent == 0 {
+		indent = 4
+	}
+
+	var buf bytes.Buffer
+
+	encoder := kyaml.NewEncoder(&buf)
+	encoder.DefaultSeqIndent()
+	encoder.SetIndent(indent)
+
+	err = encoder.Encode(&node)
+	if err == nil {
+		err = encoder.Close()
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("an unexpected error occurred when converting the object to YAML: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// readManifestCommentNodes returns the objects, which retain the comments, in the YAML files of the
+// input manifest. Manifests that are processed by Kustomize or another renderer, that are pulled from
+// an OCI artifact, or that only have library templates don't have any objects returned.
+func readManifestCommentNodes(manifest types.Manifest) ([]commentSource, error) {
+	if manifest.Path == "" || manifest.OCIRef != "" || (manifest.Renderer != "" && manifest.Renderer != rawRenderer) {
+		return nil, nil
+	}
+
+	readErr := withErrorClass(fmt.Errorf("failed to read the manifest path %s", manifest.Path), ErrManifestRead)
+
+	manifestPathInfo, err := os.Stat(manifest.Path)
+	if err != nil {
+		return nil, readErr
+	}
+
+	filePaths := []string{manifest.Path}
+
+	if manifestPathInfo.IsDir() {
+		files, err := os.ReadDir(manifest.Path)
+		if err != nil {
+			return nil, readErr
+		}
+
+		filePaths = []string{}
+
+		for _, f := range files {
+			if f.IsDir() {
+				continue
+			}
+
+			ext := path.Ext(f.Name())
+			if ext != ".yaml" && ext != ".yml" {
+				continue
+			}
+
+			// A Kustomize directory loses the comments when it is processed
+			if manifest.Renderer == "" && (f.Name() == "kustomization.yml" || f.Name() == "kustomization.yaml") {
+				return nil, nil
+			}
+
+			filePaths = append(filePaths, path.Join(manifest.Path, f.Name()))
+		}
+	}
+
+	sources := []commentSource{}
+
+	for _, filePath := range filePaths {
+		manifestBytes, err := os.ReadFile(filepath.Clean(filePath))
+		if err != nil {
+			return nil, readErr
+		}
+
+		d := kyaml.NewDecoder(bytes.NewReader(manifestBytes))
+
+		for {
+			doc := kyaml.Node{}
+
+			err := d.Decode(&doc)
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+
+				return nil, fmt.Errorf("failed to read the manifest comments in %s: %w", filePath, err)
+			}
+
+			if len(doc.Content) == 0 || doc.Content[0].Kind != kyaml.MappingNode {
+				continue
+			}
+
+			root := kyaml.NewRNode(doc.Content[0])
+
+			// Keep the comments at the top of the document on the object
+			if root.YNode().HeadComment == "" {
+				root.YNode().HeadComment = doc.HeadComment
+			}
+
+			sources = append(sources, commentSource{kind: root.GetKind(), name: root.GetName(), node: root})
+		}
+	}
+
+	// getManifests replaces the name of the single object of a manifest file with the name in the
+	// metadata of its single patch
+	if !manifestPathInfo.IsDir() && len(sources) == 1 && len(manifest.Patches) == 1 {
+		if patchMetadata, ok := manifest.Patches[0]["metadata"].(map[string]interface{}); ok {
+			if name, ok := patchMetadata["name"].(string); ok && name != "" {
+				sources[0].name = name
+			}
+		}
+	}
+
+	return sources, nil
+}
+
+// findObjectDefinitionNodes returns the objectDefinition mapping nodes of the object-templates entries
+// in the input YAML node tree.
+func findObjectDefinitionNodes(node *kyaml.Node) []*kyaml.Node {
+	objDefs := []*kyaml.Node{}
+
+	switch node.Kind {
+	case kyaml.DocumentNode, kyaml.SequenceNode:
+		for _, child := range node.Content {
+			objDefs = append(objDefs, findObjectDefinitionNodes(child)...)
+		}
+	case kyaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+
+			if key.Value == "object-templates" && value.Kind == kyaml.SequenceNode {
+				for _, objTemplate := range value.Content {
+					if objDef := getMappingValue(objTemplate, "objectDefinition"); objDef != nil {
+						objDefs = append(objDefs, objDef)
+					}
+				}
+
+				continue
+			}
+
+			objDefs = append(objDefs, findObjectDefinitionNodes(value)...)
+		}
+	}
+
+	return objDefs
+}
+
+// findSourceNode returns the first source manifest object with the same kind and name as the input
+// objectDefinition node. If there isn't one, nil is returned.
+func findSourceNode(objDef *kyaml.RNode, sources []commentSource) *kyaml.RNode {
+	kind := objDef.GetKind()
+	name := objDef.GetName()
+
+	for _, source := range sources {
+		if source.kind == kind && source.name == name {
+			return source.node
+		}
+	}
+
+	return nil
+}
+
+// getMappingValue returns the value node of the key in the input mapping node. If the input node
+// isn't a mapping node or the key isn't present, nil is returned.
+func getMappingValue(node *kyaml.Node, key string) *kyaml.Node {
+	if node == nil || node.Kind != kyaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+// Copyright Contributors to the Open Cluster Management project
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestGeneratePreserveComments(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	manifestPath := path.Join(tmpDir, "configmap.yaml")
+	manifestYAML := `# The configuration of the app
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-configmap
+  namespace: my-app # The app namespace
+data:
+  # The container image of the app
+  image: quay.io/potatos1
+  # The log level of the app
+  logLevel: info
+`
+
+	err := os.WriteFile(manifestPath, []byte(manifestYAML), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write %s", manifestPath)
+	}
+
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  preserveComments: true
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+      patches:
+        - metadata:
+            name: patched-configmap
+          data:
+            logLevel: debug
+`,
+		manifestPath,
+	)
+
+	p := Plugin{}
+
+	err = p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := `
+---
+apiVersion: policy.open-cluster-management.io/v1
+kind: Policy
+metadata:
+    annotations:
+        policy.open-cluster-management.io/categories: CM Configuration Management
+        policy.open-cluster-management.io/controls: CM-2 Baseline Configuration
+        policy.open-cluster-management.io/description: ""
+        policy.open-cluster-management.io/standards: NIST SP 800-53
+    name: policy-app-config
+    namespace: my-policies
+spec:
+    disabled: false
+    policy-templates:
+        - objectDefinition:
+            apiVersion: policy.open-cluster-management.io/v1
+            kind: ConfigurationPolicy
+            metadata:
+                name: policy-app-config
+            spec:
+                object-templates:
+                    - complianceType: musthave
+                      objectDefinition:
+                        # The configuration of the app
+                        apiVersion: v1
+                        data:
+                            # The container image of the app
+                            image: quay.io/potatos1
+                            # The log level of the app
+                            logLevel: debug
+                        kind: ConfigMap
+                        metadata:
+                            name: patched-configmap
+                            namespace: my-app # The app namespace
+                remediationAction: inform
+                severity: low
+    remediationAction: inform
+`
+	policyYAML, _, _ := strings.Cut(string(output), "---\napiVersion: cluster.open-cluster-management.io/v1beta1")
+	assertEqual(t, "\n"+policyYAML, expected)
+}
+
+func TestGenerateWithoutPreserveComments(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	manifestPath := path.Join(tmpDir, "configmap.yaml")
+	manifestYAML := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-configmap
+data:
+  image: quay.io/potatos1 # The container image of the app
+`
+
+	err := os.WriteFile(manifestPath, []byte(manifestYAML), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write %s", manifestPath)
+	}
+
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+`,
+		manifestPath,
+	)
+
+	p := Plugin{}
+
+	err = p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, strings.Contains(string(output), "#"), false)
+}
+
+func TestGeneratePreserveCommentsOtherObject(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	manifestPath := path.Join(tmpDir, "configmap.yaml")
+	manifestYAML := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-configmap
+data:
+  image: quay.io/potatos1 # The container image of the app
+`
+
+	err := os.WriteFile(manifestPath, []byte(manifestYAML), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write %s", manifestPath)
+	}
+
+	// The inline ConfigMap is the only other ConfigMap but has a different name, so it doesn't get the
+	// comments of the ConfigMap in the manifest path
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  preserveComments: true
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+    - object:
+        apiVersion: v1
+        kind: ConfigMap
+        metadata:
+          name: other-configmap
+        data:
+          image: quay.io/potatos2
+`,
+		manifestPath,
+	)
+
+	p := Plugin{}
+
+	err = p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, strings.Count(string(output), "# The container image of the app"), 1)
+	assertEqual(t, strings.Contains(string(output), "image: quay.io/potatos1 # The container image of the app"), true)
+}
@@ -0,0 +1,180 @@
+// Copyright Contributors to the Open Cluster Management project
+package internal
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/kustomize/api/hasher"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"open-cluster-management.io/policy-generator-plugin/internal/types"
+)
+
+// parseConfigMapLiteral parses a configMapGenerator literal in the format of KEY=VALUE. As with
+// Kustomize, quotes surrounding the value are removed.
+func parseConfigMapLiteral(literal string) (string, string, error) {
+	key, value, found := strings.Cut(literal, "=")
+	if !found || key == "" {
+		return "", "", fmt.Errorf("the literal %s must be in the format of KEY=VALUE", literal)
+	}
+
+	if len(value) >= 2 && value[0] == value[len(value)-1] && (value[0] == '"' || value[0] == '\'') {
+		value = value[1 : len(value)-1]
+	}
+
+	return key, value, nil
+}
+
+// parseConfigMapFile parses a configMapGenerator file in the format of [KEY=]PATH. As with
+// Kustomize, the key defaults to the base name of the path.
+func parseConfigMapFile(file string) (string, string, error) {
+	key, filePath, found := strings.Cut(file, "=")
+	if !found {
+		return filepath.Base(file), file, nil
+	}
+
+	if key == "" || filePath == "" {
+		return "", "", fmt.Errorf("the file %s must be in the format of [KEY=]PATH", file)
+	}
+
+	return key, filePath, nil
+}
+
+// assertValidConfigMapGenerator verifies that the input configMapGenerator has a valid name and
+// valid, unique keys. The file paths are returned so that they can be verified by the caller.
+func assertValidConfigMapGenerator(generator *types.ConfigMapGeneratorOptions) ([]string, error) {
+	if generator.Name == "" {
+		return nil, fmt.Errorf("the name must be set")
+	}
+
+	if errs := validation.IsDNS1123Subdomain(generator.Name); len(errs) != 0 {
+		return nil, fmt.Errorf("the name %s is invalid: %s", generator.Name, strings.Join(errs, "; "))
+	}
+
+	if len(generator.Literals) == 0 && len(generator.Files) == 0 {
+		return nil, fmt.Errorf("at least one literal or file must be set")
+	}
+
+	keys := map[string]bool{}
+	filePaths := make([]string, 0, len(generator.Files))
+
+	addKey := func(key string) error {
+		if errs := validation.IsConfigMapKey(key); len(errs) != 0 {
+			return fmt.Errorf("the key %s is invalid: %s", key, strings.Join(errs, "; "))
+		}
+
+		if keys[key] {
+			return fmt.Errorf("the key %s is set more than once", key)
+		}
+
+		keys[key] = true
+
+		return nil
+	}
+
+	for _, literal := range generator.Literals {
+		key, _, err := parseConfigMapLiteral(literal)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := addKey(key); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, file := range generator.Files {
+		key, filePath, err := parseConfigMapFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := addKey(key); err != nil {
+			return nil, err
+		}
+
+		filePaths = append(filePaths, filePath)
+	}
+
+	return filePaths, nil
+}
+
+// generateConfigMap returns the ConfigMap of the input configMapGenerator in the same way as the
+// Kustomize configMapGenerator. File contents that aren't valid UTF-8 are set in binaryData. Unless
+// disableNameSuffixHash is set, the name is suffixed with the Kustomize hash of the ConfigMap.
+func generateConfigMap(generator *types.ConfigMapGeneratorOptions) (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+	binaryData := map[string]interface{}{}
+
+	for _, literal := range generator.Literals {
+		key, value, err := parseConfigMapLiteral(literal)
+		if err != nil {
+			return nil, err
+		}
+
+		data[key] = value
+	}
+
+	for _, file := range generator.Files {
+		key, filePath, err := parseConfigMapFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := os.ReadFile(filepath.Clean(filePath))
+		if err != nil {
+			return nil, withErrorClass(
+				fmt.Errorf("failed to read the configMapGenerator file %s", filePath), ErrManifestRead,
+			)
+		}
+
+		if utf8.Valid(content) {
+			data[key] = string(content)
+		} else {
+			binaryData[key] = base64.StdEncoding.EncodeToString(content)
+		}
+	}
+
+	metadata := map[string]interface{}{"name": generator.Name}
+	if generator.Namespace != "" {
+		metadata["namespace"] = generator.Namespace
+	}
+
+	configMap := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   metadata,
+	}
+
+	if len(data) != 0 {
+		configMap["data"] = data
+	}
+
+	if len(binaryData) != 0 {
+		configMap["binaryData"] = binaryData
+	}
+
+	if generator.DisableNameSuffixHash {
+		return configMap, nil
+	}
+
+	node, err := kyaml.FromMap(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash the generated ConfigMap %s: %w", generator.Name, err)
+	}
+
+	hash, err := (&hasher.Hasher{}).Hash(node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash the generated ConfigMap %s: %w", generator.Name, err)
+	}
+
+	metadata["name"] = generator.Name + "-" + hash
+
+	return configMap, nil
+}
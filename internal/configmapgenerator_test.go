@@ -0,0 +1,219 @@
+// Copyright Contributors to the Open Cluster Management project
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"open-cluster-management.io/policy-generator-plugin/internal/types"
+)
+
+func TestGenerateConfigMap(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	propertiesPath := path.Join(tmpDir, "game.properties")
+
+	err := os.WriteFile(propertiesPath, []byte("enemies=potato\n"), 0o666)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = os.WriteFile(path.Join(tmpDir, "logo.png"), []byte{0xff, 0xfe}, 0o666)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	tests := map[string]struct {
+		generator types.ConfigMapGeneratorOptions
+		expected  map[string]interface{}
+	}{
+		"literals and files": {
+			generator: types.ConfigMapGeneratorOptions{
+				Name:     "my-config",
+				Literals: []string{"mode=hard", `greeting="hello world"`},
+				Files:    []string{propertiesPath, "lives=" + propertiesPath},
+			},
+			// The name suffix is the same as the one generated by Kustomize
+			expected: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "my-config-f5547922m4"},
+				"data": map[string]interface{}{
+					"game.properties": "enemies=potato\n",
+					"greeting":        "hello world",
+					"lives":           "enemies=potato\n",
+					"mode":            "hard",
+				},
+			},
+		},
+		"binary file without hash": {
+			generator: types.ConfigMapGeneratorOptions{
+				Name:                  "my-config",
+				Namespace:             "my-namespace",
+				Files:                 []string{path.Join(tmpDir, "logo.png")},
+				DisableNameSuffixHash: true,
+			},
+			expected: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "my-config", "namespace": "my-namespace"},
+				"binaryData": map[string]interface{}{"logo.png": "//4="},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			configMap, err := generateConfigMap(&test.generator)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			assertEqual(t, configMap, test.expected)
+		})
+	}
+}
+
+func TestGenerateConfigMapManifest(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	err := os.WriteFile(path.Join(tmpDir, "game.properties"), []byte("enemies=potato\n"), 0o666)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  manifests:
+    - configMapGenerator:
+        name: my-config
+        namespace: my-namespace
+        literals:
+          - mode=hard
+        files:
+          - %s
+`,
+		path.Join(tmpDir, "game.properties"),
+	)
+
+	p := Plugin{}
+
+	err = p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	policyTemplates, err := getPolicyTemplates(&p.Policies[0], nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	obj := getFirstEmbeddedObject(policyTemplates)
+	metadata, _ := obj["metadata"].(map[string]interface{})
+
+	assertEqual(t, obj["kind"], "ConfigMap")
+	assertEqual(t, metadata["namespace"], "my-namespace")
+	assertEqual(t, obj["data"], map[string]interface{}{"game.properties": "enemies=potato\n", "mode": "hard"})
+
+	if name, _ := metadata["name"].(string); len(name) != len("my-config-")+10 {
+		t.Fatalf("Expected the name to have a hash suffix but got %s", name)
+	}
+}
+
+func TestConfigInvalidConfigMapGenerator(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		manifest    string
+		expectedErr string
+	}{
+		"path set": {
+			manifest: fmt.Sprintf(
+				"{path: %s, configMapGenerator: {name: my-config, literals: [mode=hard]}}",
+				path.Join(tmpDir, "configmap.yaml"),
+			),
+			expectedErr: "the policy policy-app-config has manifest[0].configMapGenerator set but path, ociRef, and " +
+				"renderer may not be set with configMapGenerator",
+		},
+		"no name": {
+			manifest: "{configMapGenerator: {literals: [mode=hard]}}",
+			expectedErr: "the policy policy-app-config has an invalid manifest[0].configMapGenerator: the name must " +
+				"be set",
+		},
+		"no data": {
+			manifest: "{configMapGenerator: {name: my-config}}",
+			expectedErr: "the policy policy-app-config has an invalid manifest[0].configMapGenerator: at least one " +
+				"literal or file must be set",
+		},
+		"invalid literal": {
+			manifest: "{configMapGenerator: {name: my-config, literals: [mode]}}",
+			expectedErr: "the policy policy-app-config has an invalid manifest[0].configMapGenerator: the literal " +
+				"mode must be in the format of KEY=VALUE",
+		},
+		"duplicate key": {
+			manifest: fmt.Sprintf(
+				"{configMapGenerator: {name: my-config, literals: [configmap.yaml=a], files: [%s]}}",
+				path.Join(tmpDir, "configmap.yaml"),
+			),
+			expectedErr: "the policy policy-app-config has an invalid manifest[0].configMapGenerator: the key " +
+				"configmap.yaml is set more than once",
+		},
+		"missing file": {
+			manifest: fmt.Sprintf(
+				"{configMapGenerator: {name: my-config, files: [%s]}}", path.Join(tmpDir, "missing.properties"),
+			),
+			expectedErr: fmt.Sprintf(
+				"could not read the configMapGenerator file %s in policy policy-app-config",
+				path.Join(tmpDir, "missing.properties"),
+			),
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  manifests:
+    - %s
+`,
+				test.manifest,
+			)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
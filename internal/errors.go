@@ -0,0 +1,74 @@
+// Copyright Contributors to the Open Cluster Management project
+package internal
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The classes of errors returned by the Plugin methods. Use errors.Is to determine the class of an
+// error.
+var (
+	// ErrInvalidConfig is the class of errors for an invalid PolicyGenerator configuration.
+	ErrInvalidConfig = errors.New("invalid PolicyGenerator configuration")
+	// ErrManifestRead is the class of errors for an input file, such as a manifest, that can't be read.
+	ErrManifestRead = errors.New("failed to read an input file")
+	// ErrGeneration is the class of errors for a failure to generate the policies from a valid
+	// configuration.
+	ErrGeneration = errors.New("failed to generate the policies")
+)
+
+// Specific errors returned by the Plugin methods in addition to an error class. Use errors.Is to
+// check for them.
+var (
+	// ErrManifestNotFound is returned when a manifest path doesn't exist. It has the ErrManifestRead
+	// class.
+	ErrManifestNotFound = fmt.Errorf("manifest not found: %w", ErrManifestRead)
+	// ErrEmptyManifest is returned when the manifests of a policy don't contain any objects.
+	ErrEmptyManifest = errors.New("empty manifest")
+	// ErrDuplicatePlacement is returned when a generated placement has the same name as another
+	// placement.
+	ErrDuplicatePlacement = errors.New("duplicate placement")
+	// ErrDuplicatePlacementBinding is returned when the same placement binding name is generated for
+	// more than one placement binding.
+	ErrDuplicatePlacementBinding = errors.New("duplicate placement binding")
+	// ErrInvalidOutput is returned by ValidateOutput when a generated object doesn't match its
+	// schema. It has the ErrGeneration class.
+	ErrInvalidOutput = fmt.Errorf("invalid generated output: %w", ErrGeneration)
+)
+
+// sentinelError associates an error with a sentinel error without changing its message.
+type sentinelError struct {
+	err      error
+	sentinel error
+}
+
+func (e *sentinelError) Error() string {
+	return e.err.Error()
+}
+
+func (e *sentinelError) Unwrap() []error {
+	return []error{e.err, e.sentinel}
+}
+
+// wrapSentinel associates the input error with the input sentinel error so that errors.Is matches
+// the sentinel error while the message is unchanged.
+func wrapSentinel(err error, sentinel error) error {
+	return &sentinelError{err: err, sentinel: sentinel}
+}
+
+// withErrorClass associates the input error with the input error class. If the error is nil or
+// already has an error class, it is returned unchanged.
+func withErrorClass(err error, class error) error {
+	if err == nil {
+		return nil
+	}
+
+	for _, existingClass := range []error{ErrInvalidConfig, ErrManifestRead, ErrGeneration} {
+		if errors.Is(err, existingClass) {
+			return err
+		}
+	}
+
+	return wrapSentinel(err, class)
+}
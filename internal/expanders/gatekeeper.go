@@ -47,7 +47,7 @@ func (g GatekeeperPolicyExpander) Enabled(policyConf *types.PolicyConfig) bool {
 // for auditing purposes through Open Cluster Management. This should be run after the CanHandle
 // method.
 func (g GatekeeperPolicyExpander) Expand(
-	manifest map[string]interface{}, severity string,
+	manifest map[string]interface{}, policyConf *types.PolicyConfig,
 ) []map[string]interface{} {
 	templates := []map[string]interface{}{}
 	// These were previously validated in the CanHandle method.
@@ -66,7 +66,7 @@ func (g GatekeeperPolicyExpander) Expand(
 					"include": []string{"*"},
 				},
 				"remediationAction": "inform",
-				"severity":          severity,
+				"severity":          policyConf.Severity,
 				"object-templates": []map[string]interface{}{
 					{
 						"complianceType": "musthave",
@@ -99,7 +99,7 @@ func (g GatekeeperPolicyExpander) Expand(
 					"include": []string{"*"},
 				},
 				"remediationAction": "inform",
-				"severity":          severity,
+				"severity":          policyConf.Severity,
 				"object-templates": []map[string]interface{}{
 					{
 						"complianceType": "mustnothave",
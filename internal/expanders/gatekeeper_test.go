@@ -155,7 +155,10 @@ func TestGatekeeperExpand(t *testing.T) {
 		},
 	}
 
-	templates := g.Expand(manifest, "medium")
+	policyConf := &types.PolicyConfig{}
+	policyConf.Severity = "medium"
+
+	templates := g.Expand(manifest, policyConf)
 
 	assertReflectEqual(t, templates, expected)
 }
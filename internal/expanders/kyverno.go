@@ -44,11 +44,46 @@ func (k KyvernoPolicyExpander) Enabled(policyConf *types.PolicyConfig) bool {
 // Expand will generate additional policy templates for the Kyverno policy for auditing purposes
 // through Open Cluster Management. This should be run after the CanHandle method.
 func (k KyvernoPolicyExpander) Expand(
-	manifest map[string]interface{}, severity string,
+	manifest map[string]interface{}, policyConf *types.PolicyConfig,
 ) []map[string]interface{} {
 	templates := []map[string]interface{}{}
 	// This was previously validated in the CanHandle method.
 	policyName, _, _ := unstructured.NestedString(manifest, "metadata", "name")
+	options := policyConf.KyvernoExpanderOptions
+
+	reportKinds := []string{}
+	if options.Scope == "" || options.Scope == "All" || options.Scope == "Cluster" {
+		reportKinds = append(reportKinds, kyvernoClusterKind+"Report")
+	}
+
+	if options.Scope == "" || options.Scope == "All" || options.Scope == "Namespaced" {
+		reportKinds = append(reportKinds, kyvernoNamespacedKind+"Report")
+	}
+
+	results := []string{"fail"}
+	if options.IncludeWarn {
+		results = append(results, "warn")
+	}
+
+	objectTemplates := []map[string]interface{}{}
+
+	for _, reportKind := range reportKinds {
+		for _, result := range results {
+			objectTemplates = append(objectTemplates, map[string]interface{}{
+				"complianceType": "mustnothave",
+				"objectDefinition": map[string]interface{}{
+					"apiVersion": kyvernoPolicyReportAPIVersion,
+					"kind":       reportKind,
+					"results": []map[string]interface{}{
+						{
+							"policy": policyName,
+							"result": result,
+						},
+					},
+				},
+			})
+		}
+	}
 
 	configPolicyName := fmt.Sprintf("inform-kyverno-%s", policyName)
 	configurationPolicy := map[string]interface{}{
@@ -62,35 +97,8 @@ func (k KyvernoPolicyExpander) Expand(
 					"include": []string{"*"},
 				},
 				"remediationAction": "inform",
-				"severity":          severity,
-				"object-templates": []map[string]interface{}{
-					{
-						"complianceType": "mustnothave",
-						"objectDefinition": map[string]interface{}{
-							"apiVersion": kyvernoPolicyReportAPIVersion,
-							"kind":       kyvernoClusterKind + "Report",
-							"results": []map[string]interface{}{
-								{
-									"policy": policyName,
-									"result": "fail",
-								},
-							},
-						},
-					},
-					{
-						"complianceType": "mustnothave",
-						"objectDefinition": map[string]interface{}{
-							"apiVersion": kyvernoPolicyReportAPIVersion,
-							"kind":       kyvernoNamespacedKind + "Report",
-							"results": []map[string]interface{}{
-								{
-									"policy": policyName,
-									"result": "fail",
-								},
-							},
-						},
-					},
-				},
+				"severity":          policyConf.Severity,
+				"object-templates":  objectTemplates,
 			},
 		},
 	}
@@ -141,7 +141,94 @@ func TestKyvernoExpand(t *testing.T) {
 		},
 	}
 
-	templates := k.Expand(manifest, "medium")
+	policyConf := &types.PolicyConfig{}
+	policyConf.Severity = "medium"
+
+	templates := k.Expand(manifest, policyConf)
 
 	assertReflectEqual(t, templates, expected)
 }
+
+func TestKyvernoExpandOptions(t *testing.T) {
+	t.Parallel()
+
+	k := KyvernoPolicyExpander{}
+	manifest := map[string]interface{}{
+		"apiVersion": kyvernoAPIVersion,
+		"kind":       kyvernoNamespacedKind,
+		"metadata": map[string]interface{}{
+			"name": "my-awesome-policy",
+		},
+	}
+
+	reportTemplate := func(kind string, result string) map[string]interface{} {
+		return map[string]interface{}{
+			"complianceType": "mustnothave",
+			"objectDefinition": map[string]interface{}{
+				"apiVersion": kyvernoPolicyReportAPIVersion,
+				"kind":       kind,
+				"results": []map[string]interface{}{
+					{
+						"policy": "my-awesome-policy",
+						"result": result,
+					},
+				},
+			},
+		}
+	}
+
+	tests := map[string]struct {
+		options  types.KyvernoExpanderOptions
+		expected []map[string]interface{}
+	}{
+		"includeWarn": {
+			types.KyvernoExpanderOptions{IncludeWarn: true},
+			[]map[string]interface{}{
+				reportTemplate("ClusterPolicyReport", "fail"),
+				reportTemplate("ClusterPolicyReport", "warn"),
+				reportTemplate("PolicyReport", "fail"),
+				reportTemplate("PolicyReport", "warn"),
+			},
+		},
+		"scope All": {
+			types.KyvernoExpanderOptions{Scope: "All"},
+			[]map[string]interface{}{
+				reportTemplate("ClusterPolicyReport", "fail"),
+				reportTemplate("PolicyReport", "fail"),
+			},
+		},
+		"scope Cluster": {
+			types.KyvernoExpanderOptions{Scope: "Cluster"},
+			[]map[string]interface{}{reportTemplate("ClusterPolicyReport", "fail")},
+		},
+		"scope Namespaced with includeWarn": {
+			types.KyvernoExpanderOptions{Scope: "Namespaced", IncludeWarn: true},
+			[]map[string]interface{}{
+				reportTemplate("PolicyReport", "fail"),
+				reportTemplate("PolicyReport", "warn"),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			policyConf := &types.PolicyConfig{}
+			policyConf.KyvernoExpanderOptions = test.options
+
+			templates := k.Expand(manifest, policyConf)
+			if len(templates) != 1 {
+				t.Fatalf("Expected 1 policy template but got %d", len(templates))
+			}
+
+			objDef, _ := templates[0]["objectDefinition"].(map[string]interface{})
+			spec, _ := objDef["spec"].(map[string]interface{})
+			objectTemplates := spec["object-templates"]
+
+			assertReflectEqual(t, objectTemplates, test.expected)
+		})
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright Contributors to the Open Cluster Management project
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"open-cluster-management.io/policy-generator-plugin/internal/types"
+)
+
+const hubTemplateFunctionsMsg = "copyConfigMapData, copySecretData, fromConfigMap, fromSecret, or lookup"
+
+// assertValidHubTemplate verifies that the input hubTemplate has a supported function and the
+// arguments that the function requires. Since the arguments must be valid Kubernetes names, they
+// don't need any escaping beyond quoting in the generated hub template. The namespace must be the
+// input policy namespace since hub templates can only access objects in the namespace of the policy.
+func assertValidHubTemplate(hubTemplate *types.HubTemplateManifest, policyNamespace string) error {
+	switch hubTemplate.Function {
+	case "copyConfigMapData", "copySecretData", "fromConfigMap", "fromSecret", "lookup":
+	case "":
+		return fmt.Errorf("the function must be set to one of %s", hubTemplateFunctionsMsg)
+	default:
+		return fmt.Errorf(
+			"the function %s is not supported; it must be one of %s", hubTemplate.Function, hubTemplateFunctionsMsg,
+		)
+	}
+
+	if hubTemplate.Name == "" {
+		return fmt.Errorf("the name must be set")
+	}
+
+	names := [][2]string{
+		{"name", hubTemplate.Name},
+		{"namespace", hubTemplate.Namespace},
+		{"targetName", hubTemplate.TargetName},
+		{"targetNamespace", hubTemplate.TargetNamespace},
+	}
+
+	for _, name := range names {
+		if name[1] != "" && len(validation.IsDNS1123Subdomain(name[1])) != 0 {
+			return fmt.Errorf("the %s %s is not a valid Kubernetes name", name[0], name[1])
+		}
+	}
+
+	isLookup := hubTemplate.Function == "lookup"
+
+	if hubTemplate.Namespace == "" && !isLookup {
+		return fmt.Errorf("the namespace must be set for the %s function", hubTemplate.Function)
+	}
+
+	if hubTemplate.Namespace != "" && hubTemplate.Namespace != policyNamespace {
+		return fmt.Errorf(
+			"the namespace %s must be the policy namespace %s since hub templates can only access objects in the "+
+				"namespace of the policy",
+			hubTemplate.Namespace, policyNamespace,
+		)
+	}
+
+	requiresKey := hubTemplate.Function == "fromConfigMap" || hubTemplate.Function == "fromSecret"
+
+	if requiresKey && hubTemplate.Key == "" {
+		return fmt.Errorf("the key must be set for the %s function", hubTemplate.Function)
+	}
+
+	if !requiresKey && hubTemplate.Key != "" {
+		return fmt.Errorf("the key may only be set for the fromConfigMap and fromSecret functions")
+	}
+
+	if hubTemplate.Key != "" && len(validation.IsConfigMapKey(hubTemplate.Key)) != 0 {
+		return fmt.Errorf("the key %s is not a valid data key", hubTemplate.Key)
+	}
+
+	if isLookup && (hubTemplate.APIVersion == "" || hubTemplate.Kind == "") {
+		return fmt.Errorf("the apiVersion and kind must be set for the lookup function")
+	}
+
+	if !isLookup && (hubTemplate.APIVersion != "" || hubTemplate.Kind != "") {
+		return fmt.Errorf("the apiVersion and kind may only be set for the lookup function")
+	}
+
+	if isLookup && strings.ContainsAny(hubTemplate.APIVersion+hubTemplate.Kind, `"'\ `) {
+		return fmt.Errorf("the apiVersion and kind may not contain quotes, backslashes, or spaces")
+	}
+
+	return nil
+}
+
+// hubTemplateCall returns the call of the input hub template function with the input arguments, which
+// are quoted as Go template string literals.
+func hubTemplateCall(function string, args ...string) string {
+	quotedArgs := make([]string, 0, len(args))
+
+	for _, arg := range args {
+		quotedArgs = append(quotedArgs, strconv.Quote(arg))
+	}
+
+	return function + " " + strings.Join(quotedArgs, " ")
+}
+
+// generateHubTemplateManifest returns an object-templates-raw manifest with an object template that
+// copies data from the object on the hub with the hub template function of the input hubTemplate.
+// The object on the managed cluster defaults to the same name and namespace as the object on the hub.
+func generateHubTemplateManifest(
+	hubTemplate *types.HubTemplateManifest, complianceType string,
+) (map[string]interface{}, error) {
+	targetName := hubTemplate.TargetName
+	if targetName == "" {
+		targetName = hubTemplate.Name
+	}
+
+	targetNamespace := hubTemplate.TargetNamespace
+	if targetNamespace == "" {
+		targetNamespace = hubTemplate.Namespace
+	}
+
+	metadata := map[string]interface{}{"name": targetName}
+	if targetNamespace != "" {
+		metadata["namespace"] = targetNamespace
+	}
+
+	objectDefinition := map[string]interface{}{"metadata": metadata}
+
+	switch hubTemplate.Function {
+	case "copyConfigMapData", "copySecretData":
+		objectDefinition["data"] = fmt.Sprintf(
+			"{{hub %s hub}}", hubTemplateCall(hubTemplate.Function, hubTemplate.Namespace, hubTemplate.Name),
+		)
+	case "fromConfigMap", "fromSecret":
+		objectDefinition["data"] = map[string]interface{}{
+			hubTemplate.Key: fmt.Sprintf(
+				"{{hub %s hub}}",
+				hubTemplateCall(hubTemplate.Function, hubTemplate.Namespace, hubTemplate.Name, hubTemplate.Key),
+			),
+		}
+	case "lookup":
+		objectDefinition["apiVersion"] = hubTemplate.APIVersion
+		objectDefinition["kind"] = hubTemplate.Kind
+		// The spec of the object on the hub is converted to JSON, which is valid YAML, and used as is rather
+		// than as a string
+		objectDefinition["spec"] = fmt.Sprintf(
+			"{{hub (%s).spec | toRawJson | toLiteral hub}}",
+			hubTemplateCall(
+				"lookup", hubTemplate.APIVersion, hubTemplate.Kind, hubTemplate.Namespace, hubTemplate.Name,
+			),
+		)
+	default:
+		return nil, fmt.Errorf("the hubTemplate function %s is not supported", hubTemplate.Function)
+	}
+
+	switch hubTemplate.Function {
+	case "copyConfigMapData", "fromConfigMap":
+		objectDefinition["apiVersion"] = "v1"
+		objectDefinition["kind"] = "ConfigMap"
+	case "copySecretData", "fromSecret":
+		objectDefinition["apiVersion"] = "v1"
+		objectDefinition["kind"] = "Secret"
+	}
+
+	objectTemplates := []map[string]interface{}{
+		{"complianceType": complianceType, "objectDefinition": objectDefinition},
+	}
+
+	objectTemplatesRaw, err := yaml.Marshal(objectTemplates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate the object-templates-raw for the hubTemplate: %w", err)
+	}
+
+	return map[string]interface{}{"object-templates-raw": string(objectTemplatesRaw)}, nil
+}
@@ -0,0 +1,205 @@
+// Copyright Contributors to the Open Cluster Management project
+package internal
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"testing"
+
+	"open-cluster-management.io/policy-generator-plugin/internal/types"
+)
+
+func TestGenerateHubTemplateManifest(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		hubTemplate types.HubTemplateManifest
+		expected    string
+	}{
+		"copySecretData": {
+			hubTemplate: types.HubTemplateManifest{
+				Function:        "copySecretData",
+				Name:            "my-secret",
+				Namespace:       "my-policies",
+				TargetNamespace: "app",
+			},
+			expected: `
+- complianceType: musthave
+  objectDefinition:
+    apiVersion: v1
+    data: '{{hub copySecretData "my-policies" "my-secret" hub}}'
+    kind: Secret
+    metadata:
+        name: my-secret
+        namespace: app
+`,
+		},
+		"fromConfigMap": {
+			hubTemplate: types.HubTemplateManifest{
+				Function:   "fromConfigMap",
+				Name:       "my-configmap",
+				Namespace:  "my-policies",
+				Key:        "app.conf",
+				TargetName: "app-config",
+			},
+			expected: `
+- complianceType: musthave
+  objectDefinition:
+    apiVersion: v1
+    data:
+        app.conf: '{{hub fromConfigMap "my-policies" "my-configmap" "app.conf" hub}}'
+    kind: ConfigMap
+    metadata:
+        name: app-config
+        namespace: my-policies
+`,
+		},
+		"lookup cluster scoped": {
+			hubTemplate: types.HubTemplateManifest{
+				Function:   "lookup",
+				Name:       "my-issuer",
+				APIVersion: "cert-manager.io/v1",
+				Kind:       "ClusterIssuer",
+			},
+			expected: `
+- complianceType: musthave
+  objectDefinition:
+    apiVersion: cert-manager.io/v1
+    kind: ClusterIssuer
+    metadata:
+        name: my-issuer
+    spec: '{{hub (lookup "cert-manager.io/v1" "ClusterIssuer" "" "my-issuer").spec | toRawJson | toLiteral hub}}'
+`,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := assertValidHubTemplate(&test.hubTemplate, "my-policies")
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			manifest, err := generateHubTemplateManifest(&test.hubTemplate, "musthave")
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			expected := strings.TrimPrefix(test.expected, "\n")
+			assertEqual(t, manifest, map[string]interface{}{"object-templates-raw": expected})
+		})
+	}
+}
+
+func TestConfigInvalidHubTemplate(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		manifest    string
+		expectedErr string
+	}{
+		"path set": {
+			manifest: fmt.Sprintf(
+				"{path: %s, hubTemplate: {function: copySecretData, namespace: my-policies, name: my-secret}}",
+				path.Join(tmpDir, "configmap.yaml"),
+			),
+			expectedErr: "the policy policy-app-config has manifest[0].hubTemplate set but path, ociRef, renderer, " +
+				"and configMapGenerator may not be set with hubTemplate",
+		},
+		"unsupported function": {
+			manifest: "{hubTemplate: {function: fromClusterClaim, name: my-claim}}",
+			expectedErr: "the policy policy-app-config has an invalid manifest[0].hubTemplate: the function " +
+				"fromClusterClaim is not supported; it must be one of copyConfigMapData, copySecretData, " +
+				"fromConfigMap, fromSecret, or lookup",
+		},
+		"other namespace": {
+			manifest: "{hubTemplate: {function: copySecretData, namespace: hub-ns, name: my-secret}}",
+			expectedErr: "the policy policy-app-config has an invalid manifest[0].hubTemplate: the namespace hub-ns " +
+				"must be the policy namespace my-policies since hub templates can only access objects in the " +
+				"namespace of the policy",
+		},
+		"no key": {
+			manifest: "{hubTemplate: {function: fromSecret, namespace: my-policies, name: my-secret}}",
+			expectedErr: "the policy policy-app-config has an invalid manifest[0].hubTemplate: the key must be set " +
+				"for the fromSecret function",
+		},
+		"no kind": {
+			manifest: "{hubTemplate: {function: lookup, apiVersion: v1, name: my-secret}}",
+			expectedErr: "the policy policy-app-config has an invalid manifest[0].hubTemplate: the apiVersion and " +
+				"kind must be set for the lookup function",
+		},
+		"invalid name": {
+			manifest: `{hubTemplate: {function: copySecretData, namespace: my-policies, name: 'my"secret'}}`,
+			expectedErr: "the policy policy-app-config has an invalid manifest[0].hubTemplate: the name my\"secret " +
+				"is not a valid Kubernetes name",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  manifests:
+    - %s
+`,
+				test.manifest,
+			)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+func TestConfigHubTemplateDefaultNamespace(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	config := `
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  manifests:
+    - hubTemplate:
+        function: copySecretData
+        name: my-secret
+`
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// The namespace of the object on the hub defaults to the namespace of the policy
+	assertEqual(t, p.Policies[0].Manifests[0].HubTemplate.Namespace, "my-policies")
+}
@@ -0,0 +1,211 @@
+// Copyright Contributors to the Open Cluster Management project
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// resolveImports merges the PolicyGenerator files in the imports of the input configuration into
+// it, after recursively merging the imports of the imported files. The input configuration is
+// returned as is if it doesn't have imports or can't be decoded, in which case the regular decoding
+// reports the errors.
+func (p *Plugin) resolveImports(config []byte, baseDirectory string) ([]byte, error) {
+	var doc map[string]interface{}
+
+	err := yaml.Unmarshal(config, &doc)
+	if err != nil || doc["imports"] == nil {
+		return config, nil
+	}
+
+	// The import paths are resolved with symlinks, so the base directory must be too
+	baseDirectory, err = filepath.EvalSymlinks(baseDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate symlinks for the base directory: %w", err)
+	}
+
+	mergedPaths := map[string]bool{}
+
+	merged, err := p.mergeImports(doc, baseDirectory, []string{}, []string{}, mergedPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	p.importPaths = make([]string, 0, len(mergedPaths))
+	for importPath := range mergedPaths {
+		p.importPaths = append(p.importPaths, importPath)
+	}
+
+	mergedConfig, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge the PolicyGenerator imports: %w", err)
+	}
+
+	return mergedConfig, nil
+}
+
+// mergeImports returns the input PolicyGenerator document with the policies, policySets, and
+// policyDefaults of the files in its imports merged into it. The policies and policy sets of the
+// imported files come before the ones of the document in the order of the imports. A policyDefaults
+// field set in the document overrides the value of the imported files, and a policyDefaults field
+// set in a later import overrides the value of an earlier import. The importChain and chainPaths
+// are the resolved and input paths of the files that imported the document, which are used to
+// detect a cycle in the imports. The mergedPaths are the resolved paths of the files that were
+// already merged in the configuration, so that a file imported more than once, such as by two
+// imported files, is only merged at the position of its first import.
+func (p *Plugin) mergeImports(
+	doc map[string]interface{},
+	baseDirectory string,
+	importChain []string,
+	chainPaths []string,
+	mergedPaths map[string]bool,
+) (map[string]interface{}, error) {
+	imports, ok := doc["imports"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("the PolicyGenerator imports must be a list of paths")
+	}
+
+	imported := map[string]interface{}{}
+
+	for _, importValue := range imports {
+		importPath, ok := importValue.(string)
+		if !ok || importPath == "" {
+			return nil, fmt.Errorf("the PolicyGenerator imports must be a list of paths")
+		}
+
+		importPath = rewritePathPrefix(importPath, p.pathPrefixMappings)
+
+		err := verifyFilePath(baseDirectory, importPath, "import", p.standalone)
+		if err != nil {
+			return nil, err
+		}
+
+		resolvedPath, err := filepath.EvalSymlinks(importPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the import path %s: %w", importPath, err)
+		}
+
+		resolvedPath, err = filepath.Abs(resolvedPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve the import path %s to an absolute path", importPath)
+		}
+
+		if idx := slices.Index(importChain, resolvedPath); idx != -1 {
+			cycle := append(slices.Clone(chainPaths[idx:]), importPath)
+
+			return nil, fmt.Errorf("the PolicyGenerator imports have a cycle: %s", strings.Join(cycle, " -> "))
+		}
+
+		if mergedPaths[resolvedPath] {
+			continue
+		}
+
+		mergedPaths[resolvedPath] = true
+
+		importDoc, err := p.readImport(importPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if importDoc["imports"] != nil {
+			importDoc, err = p.mergeImports(
+				importDoc,
+				baseDirectory,
+				append(slices.Clone(importChain), resolvedPath),
+				append(slices.Clone(chainPaths), importPath),
+				mergedPaths,
+			)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		mergeImportedDoc(imported, importDoc)
+	}
+
+	mergeImportedDoc(imported, doc)
+
+	for _, key := range []string{"policies", "policySets", "policyDefaults"} {
+		if imported[key] != nil {
+			doc[key] = imported[key]
+		}
+	}
+
+	return doc, nil
+}
+
+// readImport reads and decodes the PolicyGenerator file at the input import path, substituting its
+// variables and merging its documents in the same way as the importing configuration.
+func (p *Plugin) readImport(importPath string) (map[string]interface{}, error) {
+	const errTemplate = "the imported PolicyGenerator file %s is invalid: %w"
+
+	importConfig, err := os.ReadFile(importPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the import path %s: %w", importPath, err)
+	}
+
+	if p.substitution != nil {
+		importConfig, err = substituteVariables(importConfig, *p.substitution)
+		if err != nil {
+			return nil, fmt.Errorf(errTemplate, importPath, err)
+		}
+	}
+
+	importConfig, err = mergeConfigDocuments(importConfig)
+	if err != nil {
+		return nil, fmt.Errorf(errTemplate, importPath, err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(importConfig))
+	dec.KnownFields(true) // emit an error on unknown fields in the input
+
+	err = dec.Decode(&Plugin{})
+	if err != nil {
+		return nil, fmt.Errorf(errTemplate, importPath, addFieldNotFoundHelp(err))
+	}
+
+	var importDoc map[string]interface{}
+
+	err = yaml.Unmarshal(importConfig, &importDoc)
+	if err != nil {
+		return nil, fmt.Errorf(errTemplate, importPath, err)
+	}
+
+	return importDoc, nil
+}
+
+// mergeImportedDoc merges the policies, policySets, and policyDefaults of the input PolicyGenerator
+// document into merged. The policies and policy sets are appended, and the policyDefaults fields of
+// the document override the ones already in merged.
+func mergeImportedDoc(merged map[string]interface{}, doc map[string]interface{}) {
+	for _, key := range []string{"policies", "policySets"} {
+		items, _ := doc[key].([]interface{})
+		if len(items) == 0 {
+			continue
+		}
+
+		existing, _ := merged[key].([]interface{})
+		merged[key] = append(existing, items...)
+	}
+
+	defaults, _ := doc["policyDefaults"].(map[string]interface{})
+	if len(defaults) == 0 {
+		return
+	}
+
+	mergedDefaults, _ := merged["policyDefaults"].(map[string]interface{})
+	if mergedDefaults == nil {
+		mergedDefaults = map[string]interface{}{}
+		merged["policyDefaults"] = mergedDefaults
+	}
+
+	for key, value := range defaults {
+		mergedDefaults[key] = value
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright Contributors to the Open Cluster Management project
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestConfigImports(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	sharedDefaults := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: shared-defaults
+policyDefaults:
+  namespace: my-policies
+  remediationAction: enforce
+  severity: high
+policies:
+- name: policy-shared
+  manifests:
+    - path: %s
+`,
+		path.Join(tmpDir, "configmap.yaml"),
+	)
+
+	err := os.WriteFile(path.Join(tmpDir, "shared.yaml"), []byte(sharedDefaults), 0o666)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+imports:
+- %s
+policyDefaults:
+  severity: low
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+`,
+		path.Join(tmpDir, "shared.yaml"), path.Join(tmpDir, "configmap.yaml"),
+	)
+
+	p := Plugin{}
+
+	err = p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, len(p.Policies), 2)
+	assertEqual(t, p.Policies[0].Name, "policy-shared")
+	assertEqual(t, p.Policies[1].Name, "policy-app-config")
+	assertEqual(t, p.PolicyDefaults.Namespace, "my-policies")
+
+	for _, policy := range p.Policies {
+		// The imported defaults apply unless the importing configuration overrides them
+		assertEqual(t, policy.RemediationAction, "enforce")
+		assertEqual(t, policy.Severity, "low")
+	}
+}
+
+func TestConfigImportsDiamond(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	// Both imported files import the shared file, which is only merged once
+	files := map[string]string{
+		"shared.yaml": "",
+		"a.yaml":      "imports:\n- " + path.Join(tmpDir, "shared.yaml") + "\n",
+		"b.yaml":      "imports:\n- " + path.Join(tmpDir, "shared.yaml") + "\n",
+	}
+
+	for name, imports := range files {
+		importConfig := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: %[1]s
+%[2]spolicies:
+- name: policy-%[1]s
+  manifests:
+    - path: %[3]s
+`,
+			name[:len(name)-len(".yaml")], imports, path.Join(tmpDir, "configmap.yaml"),
+		)
+
+		err := os.WriteFile(path.Join(tmpDir, name), []byte(importConfig), 0o666)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+imports:
+- %s
+- %s
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+`,
+		path.Join(tmpDir, "a.yaml"), path.Join(tmpDir, "b.yaml"), path.Join(tmpDir, "configmap.yaml"),
+	)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	policyNames := make([]string, 0, len(p.Policies))
+	for _, policy := range p.Policies {
+		policyNames = append(policyNames, policy.Name)
+	}
+
+	assertReflectEqual(t, policyNames, []string{"policy-shared", "policy-a", "policy-b", "policy-app-config"})
+}
+
+func TestConfigImportsInvalid(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	importFiles := map[string]string{
+		path.Join(tmpDir, "a.yaml"):                fmt.Sprintf("imports: [%s/b.yaml]\npolicies: []\n", tmpDir),
+		path.Join(tmpDir, "b.yaml"):                fmt.Sprintf("imports: [%s/a.yaml]\npolicies: []\n", tmpDir),
+		path.Join(tmpDir, "unknown.yaml"):          "policies: []\nunknown: true\n",
+		path.Join(path.Dir(tmpDir), "shared.yaml"): "policies: []\n",
+	}
+
+	for filePath, contents := range importFiles {
+		err := os.WriteFile(filePath, []byte(contents), 0o666)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	tests := map[string]struct {
+		imports     string
+		expectedErr string
+	}{
+		"cycle": {
+			imports: path.Join(tmpDir, "a.yaml"),
+			expectedErr: fmt.Sprintf(
+				"the PolicyGenerator imports have a cycle: %[1]s/a.yaml -> %[1]s/b.yaml -> %[1]s/a.yaml", tmpDir,
+			),
+		},
+		"outside of the base directory": {
+			imports: path.Join(path.Dir(tmpDir), "shared.yaml"),
+			expectedErr: fmt.Sprintf(
+				"the import path %s/shared.yaml is not in the same directory tree as the kustomization.yaml file",
+				path.Dir(tmpDir),
+			),
+		},
+		"unknown field": {
+			imports: path.Join(tmpDir, "unknown.yaml"),
+			expectedErr: fmt.Sprintf(
+				"the imported PolicyGenerator file %s/unknown.yaml is invalid: failed to decode the PolicyGenerator "+
+					"document 1: yaml: unmarshal errors:\n  line 2: field unknown found but not defined in type "+
+					"PolicyGenerator",
+				tmpDir,
+			),
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+imports:
+- %s
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+`,
+				test.imports, path.Join(tmpDir, "configmap.yaml"),
+			)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright Contributors to the Open Cluster Management project
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// GenerateIndex returns a ConfigMap named name for each namespace of the policies and policy sets in
+// the input generated output, which lists the names of the policies and policy sets in that
+// namespace so that downstream tooling can determine what was generated. The policy names are in the
+// "policies" key and the policy set names are in the "policySets" key, each sorted and separated by
+// newlines. The ConfigMaps are returned as YAML documents to append to the generated output. An
+// error is returned if the name is invalid or the generated output can't be parsed.
+func GenerateIndex(output []byte, name string) ([]byte, error) {
+	if len(validation.IsDNS1123Subdomain(name)) > 0 {
+		return nil, fmt.Errorf("the index name `%s` is not DNS compliant. See %s", name, dnsReference)
+	}
+
+	manifests, err := unmarshalManifestBytes(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the generated output to create the index: %w", err)
+	}
+
+	// The policy and policy set names of each namespace
+	indexes := map[string]*struct{ policies, policySets []string }{}
+
+	for _, manifest := range manifests {
+		apiVersion, _, _ := unstructured.NestedString(manifest, "apiVersion")
+		if !strings.HasPrefix(apiVersion, policyAPIGroup+"/") {
+			continue
+		}
+
+		kind, _, _ := unstructured.NestedString(manifest, "kind")
+		if kind != policyKind && kind != policySetKind {
+			continue
+		}
+
+		objName, _, _ := unstructured.NestedString(manifest, "metadata", "name")
+		namespace, _, _ := unstructured.NestedString(manifest, "metadata", "namespace")
+
+		if indexes[namespace] == nil {
+			indexes[namespace] = &struct{ policies, policySets []string }{[]string{}, []string{}}
+		}
+
+		if kind == policyKind {
+			indexes[namespace].policies = append(indexes[namespace].policies, objName)
+		} else {
+			indexes[namespace].policySets = append(indexes[namespace].policySets, objName)
+		}
+	}
+
+	namespaces := make([]string, 0, len(indexes))
+	for namespace := range indexes {
+		namespaces = append(namespaces, namespace)
+	}
+
+	sort.Strings(namespaces)
+
+	var indexBuffer bytes.Buffer
+
+	for _, namespace := range namespaces {
+		policies := indexes[namespace].policies
+		policySets := indexes[namespace].policySets
+
+		sort.Strings(policies)
+		sort.Strings(policySets)
+
+		metadata := map[string]interface{}{"name": name}
+		if namespace != "" {
+			metadata["namespace"] = namespace
+		}
+
+		index := map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   metadata,
+			"data": map[string]string{
+				"policies":   strings.Join(policies, "\n"),
+				"policySets": strings.Join(policySets, "\n"),
+			},
+		}
+
+		indexYAML, err := yaml.Marshal(index)
+		if err != nil {
+			return nil, fmt.Errorf("an unexpected error occurred when converting the index to YAML: %w", err)
+		}
+
+		indexBuffer.WriteString("---\n")
+		indexBuffer.Write(indexYAML)
+	}
+
+	return indexBuffer.Bytes(), nil
+}
@@ -0,0 +1,144 @@
+// Copyright Contributors to the Open Cluster Management project
+package internal
+
+import (
+	"fmt"
+	"path"
+	"testing"
+)
+
+func TestGenerateIndex(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	manifestPath := path.Join(tmpDir, "configmap.yaml")
+
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-b
+  manifests:
+    - path: %s
+  policySets:
+    - policyset-a
+- name: policy-a
+  manifests:
+    - path: %s
+`,
+		manifestPath, manifestPath,
+	)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	index, err := GenerateIndex(output, "generated-policies")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := `---
+apiVersion: v1
+data:
+    policies: |-
+        policy-a
+        policy-b
+    policySets: policyset-a
+kind: ConfigMap
+metadata:
+    name: generated-policies
+    namespace: my-policies
+`
+
+	assertEqual(t, string(index), expected)
+}
+
+func TestGenerateIndexNamespaces(t *testing.T) {
+	t.Parallel()
+
+	output := `
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+    name: policy-not-generated
+    namespace: my-policies
+---
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
+metadata:
+    name: placement-policy-a
+    namespace: my-policies
+---
+apiVersion: policy.open-cluster-management.io/v1
+kind: PlacementBinding
+metadata:
+    name: binding-policy-a
+    namespace: my-policies
+---
+apiVersion: policy.open-cluster-management.io/v1
+kind: Policy
+metadata:
+    name: policy-a
+    namespace: my-policies
+---
+apiVersion: policy.open-cluster-management.io/v1
+kind: Policy
+metadata:
+    name: policy-b
+    namespace: other-policies
+`
+
+	index, err := GenerateIndex([]byte(output), "generated-policies")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := `---
+apiVersion: v1
+data:
+    policies: policy-a
+    policySets: ""
+kind: ConfigMap
+metadata:
+    name: generated-policies
+    namespace: my-policies
+---
+apiVersion: v1
+data:
+    policies: policy-b
+    policySets: ""
+kind: ConfigMap
+metadata:
+    name: generated-policies
+    namespace: other-policies
+`
+
+	assertEqual(t, string(index), expected)
+}
+
+func TestGenerateIndexInvalidName(t *testing.T) {
+	t.Parallel()
+
+	_, err := GenerateIndex([]byte{}, "Generated_Policies")
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := "the index name `Generated_Policies` is not DNS compliant. See " +
+		"https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#dns-subdomain-names"
+	assertEqual(t, err.Error(), expected)
+}
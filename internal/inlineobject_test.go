@@ -0,0 +1,127 @@
+// Copyright Contributors to the Open Cluster Management project
+package internal
+
+import (
+	"fmt"
+	"path"
+	"testing"
+)
+
+func TestGetPolicyTemplateInlineObject(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	config := `
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  manifests:
+    - object:
+        apiVersion: v1
+        kind: ConfigMap
+        metadata:
+          name: my-configmap
+          namespace: default
+        data:
+          game.properties: enemies=aliens
+      patches:
+        - data:
+            ui.properties: color=purple
+`
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	policyTemplates, err := getPolicyTemplates(&p.Policies[0], nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, len(policyTemplates), 1)
+
+	objDef, _ := policyTemplates[0]["objectDefinition"].(map[string]interface{})
+	assertEqual(t, objDef["kind"], configPolicyKind)
+
+	obj := getFirstEmbeddedObject(policyTemplates)
+	assertEqual(t, obj["kind"], "ConfigMap")
+	assertEqual(t, obj["metadata"], map[string]interface{}{"name": "my-configmap", "namespace": "default"})
+	assertEqual(t, obj["data"], map[string]interface{}{
+		"game.properties": "enemies=aliens",
+		"ui.properties":   "color=purple",
+	})
+
+	// The patch applies to a copy of the inline object rather than the configuration
+	data, _ := p.Policies[0].Manifests[0].Object["data"].(map[string]interface{})
+	assertEqual(t, len(data), 1)
+}
+
+func TestConfigInvalidInlineObject(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		manifest    string
+		expectedErr string
+	}{
+		"path set": {
+			manifest: fmt.Sprintf(
+				"{path: %s, object: {apiVersion: v1, kind: ConfigMap, metadata: {name: my-configmap}}}",
+				path.Join(tmpDir, "configmap.yaml"),
+			),
+			expectedErr: "the policy policy-app-config has manifest[0].object set but path, ociRef, renderer, " +
+				"configMapGenerator, and hubTemplate may not be set with object",
+		},
+		"configMapGenerator set": {
+			manifest: "{configMapGenerator: {name: my-config, literals: [mode=hard]}, " +
+				"object: {apiVersion: v1, kind: ConfigMap, metadata: {name: my-configmap}}}",
+			expectedErr: "the policy policy-app-config has manifest[0].object set but path, ociRef, renderer, " +
+				"configMapGenerator, and hubTemplate may not be set with object",
+		},
+		"no kind": {
+			manifest: "{object: {apiVersion: v1, metadata: {name: my-configmap}}}",
+			expectedErr: "the policy policy-app-config has manifest[0].object set but it must have an apiVersion " +
+				"and kind",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  manifests:
+    - %s
+`,
+				test.manifest,
+			)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
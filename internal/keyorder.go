@@ -0,0 +1,45 @@
+// Copyright Contributors to the Open Cluster Management project
+package internal
+
+import (
+	"slices"
+
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// canonicalKeys are the top-level keys of a Kubernetes object in the order they are conventionally
+// written in. The other keys are placed after them.
+var canonicalKeys = []string{"apiVersion", "kind", "metadata", "spec", "data"}
+
+// orderCanonicalKeys reorders the key and value pairs of the input mapping node so that the
+// canonicalKeys come first in their conventional order. The other keys keep their relative order,
+// which is alphabetical when the node was encoded from a map.
+func orderCanonicalKeys(node *kyaml.Node) {
+	if node == nil || node.Kind != kyaml.MappingNode {
+		return
+	}
+
+	rank := func(key string) int {
+		if i := slices.Index(canonicalKeys, key); i != -1 {
+			return i
+		}
+
+		return len(canonicalKeys)
+	}
+
+	pairs := make([][]*kyaml.Node, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, node.Content[i:i+2])
+	}
+
+	slices.SortStableFunc(pairs, func(a, b []*kyaml.Node) int {
+		return rank(a[0].Value) - rank(b[0].Value)
+	})
+
+	content := make([]*kyaml.Node, 0, len(node.Content))
+	for _, pair := range pairs {
+		content = append(content, pair...)
+	}
+
+	node.Content = content
+}
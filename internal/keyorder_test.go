@@ -0,0 +1,117 @@
+// Copyright Contributors to the Open Cluster Management project
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestGenerateCanonicalKeyOrder(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	manifestPath := path.Join(tmpDir, "manifests.yaml")
+	manifestYAML := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-configmap
+data:
+  image: quay.io/potatos1
+immutable: true
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: my-service
+spec:
+  ports:
+  - port: 8080
+status:
+  loadBalancer: {}
+`
+
+	err := os.WriteFile(manifestPath, []byte(manifestYAML), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write %s", manifestPath)
+	}
+
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  canonicalKeyOrder: true
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+`,
+		manifestPath,
+	)
+
+	p := Plugin{}
+
+	err = p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := `
+---
+apiVersion: policy.open-cluster-management.io/v1
+kind: Policy
+metadata:
+    annotations:
+        policy.open-cluster-management.io/categories: CM Configuration Management
+        policy.open-cluster-management.io/controls: CM-2 Baseline Configuration
+        policy.open-cluster-management.io/description: ""
+        policy.open-cluster-management.io/standards: NIST SP 800-53
+    name: policy-app-config
+    namespace: my-policies
+spec:
+    disabled: false
+    policy-templates:
+        - objectDefinition:
+            apiVersion: policy.open-cluster-management.io/v1
+            kind: ConfigurationPolicy
+            metadata:
+                name: policy-app-config
+            spec:
+                object-templates:
+                    - complianceType: musthave
+                      objectDefinition:
+                        apiVersion: v1
+                        kind: ConfigMap
+                        metadata:
+                            name: my-configmap
+                        data:
+                            image: quay.io/potatos1
+                        immutable: true
+                    - complianceType: musthave
+                      objectDefinition:
+                        apiVersion: v1
+                        kind: Service
+                        metadata:
+                            name: my-service
+                        spec:
+                            ports:
+                                - port: 8080
+                        status:
+                            loadBalancer: {}
+                remediationAction: inform
+                severity: low
+    remediationAction: inform
+`
+	policyYAML, _, _ := strings.Cut(string(output), "---\napiVersion: cluster.open-cluster-management.io/v1beta1")
+	assertEqual(t, "\n"+policyYAML, expected)
+}
@@ -0,0 +1,67 @@
+// Copyright Contributors to the Open Cluster Management project
+package internal
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"open-cluster-management.io/policy-generator-plugin/internal/types"
+)
+
+// CheckNames decodes the input PolicyGenerator configuration without applying defaults or
+// validating it, and returns a message with the location of every policy, configuration policy,
+// policy set, placement, and placement binding name that isn't DNS compliant, along with every
+// policy whose namespace and name are more than 63 characters. This allows all the naming violations
+// to be reported at once rather than only the first one as with Config. Nothing is generated. The imports aren't
+// merged, so only the names in the input configuration are checked. A returned error has the
+// ErrInvalidConfig class and means that the configuration couldn't be decoded.
+func (p *Plugin) CheckNames(config []byte) ([]string, error) {
+	_, err := p.decodeConfig(config, "")
+	if err != nil {
+		return nil, withErrorClass(err, ErrInvalidConfig)
+	}
+
+	violations := []string{}
+
+	checkName := func(location string, name string) {
+		if name != "" && len(validation.IsDNS1123Subdomain(name)) > 0 {
+			violations = append(violations, fmt.Sprintf("%s `%s` is not DNS compliant", location, name))
+		}
+	}
+
+	checkPlacement := func(location string, placement types.PlacementConfig) {
+		checkName(location+".placement.name", placement.Name)
+		checkName(location+".placement.placementName", placement.PlacementName)
+		checkName(location+".placement.placementRuleName", placement.PlacementRuleName)
+	}
+
+	checkPlacement("policyDefaults", p.PolicyDefaults.Placement)
+	checkPlacement("policySetDefaults", p.PolicySetDefaults.Placement)
+	checkName("placementBindingDefaults.name", p.PlacementBindingDefaults.Name)
+
+	for i, policy := range p.Policies {
+		location := fmt.Sprintf("policies[%d]", i)
+
+		checkName(location+".name", policy.Name)
+
+		if len(p.PolicyDefaults.Namespace+"."+policy.Name) > maxObjectNameLength {
+			violations = append(violations, fmt.Sprintf(
+				"%s.name `%s` is too long since the policy namespace and name cannot be more than 63 characters: %s.%s",
+				location, policy.Name, p.PolicyDefaults.Namespace, policy.Name,
+			))
+		}
+
+		checkName(location+".configurationPolicyName", policy.ConfigurationPolicyName)
+		checkPlacement(location, policy.Placement)
+	}
+
+	for i, plcset := range p.PolicySets {
+		location := fmt.Sprintf("policySets[%d]", i)
+
+		checkName(location+".name", plcset.Name)
+		checkPlacement(location, plcset.Placement)
+	}
+
+	return violations, nil
+}
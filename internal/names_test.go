@@ -0,0 +1,74 @@
+// Copyright Contributors to the Open Cluster Management project
+package internal
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckNames(t *testing.T) {
+	t.Parallel()
+
+	config := `
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+placementBindingDefaults:
+  name: Bad_Binding
+policyDefaults:
+  namespace: my-policies
+  placement:
+    name: Bad_Placement
+policies:
+- name: Bad_Policy
+  manifests:
+    - path: configmap.yaml
+- name: my-policy-with-a-long-name-that-is-more-than-the-limit
+  manifests:
+    - path: configmap.yaml
+- name: my-policy
+  configurationPolicyName: My_ConfigPolicy
+  placement:
+    placementRuleName: my-placementrule!
+  manifests:
+    - path: configmap.yaml
+policySets:
+- name: My.PolicySet
+`
+
+	p := Plugin{}
+
+	violations, err := p.CheckNames([]byte(config))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := []string{
+		"policyDefaults.placement.name `Bad_Placement` is not DNS compliant",
+		"placementBindingDefaults.name `Bad_Binding` is not DNS compliant",
+		"policies[0].name `Bad_Policy` is not DNS compliant",
+		"policies[1].name `my-policy-with-a-long-name-that-is-more-than-the-limit` is too long since the policy " +
+			"namespace and name cannot be more than 63 characters: " +
+			"my-policies.my-policy-with-a-long-name-that-is-more-than-the-limit",
+		"policies[2].configurationPolicyName `My_ConfigPolicy` is not DNS compliant",
+		"policies[2].placement.placementRuleName `my-placementrule!` is not DNS compliant",
+		"policySets[0].name `My.PolicySet` is not DNS compliant",
+	}
+	assertEqual(t, violations, expected)
+}
+
+func TestCheckNamesInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	p := Plugin{}
+
+	_, err := p.CheckNames([]byte("policies: [{name: my-policy, unknown: true}]"))
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected the error to have the ErrInvalidConfig class but got: %v", err)
+	}
+}
@@ -0,0 +1,563 @@
+// Copyright Contributors to the Open Cluster Management project
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	ociScheme = "oci://"
+	// The maximum time to pull an OCI artifact, including authentication
+	ociPullTimeout = 2 * time.Minute
+	// The maximum size of an OCI manifest or layer that is read
+	ociMaxBlobSize = 64 << 20
+	// The annotation with the file name of an OCI layer
+	ociTitleAnnotation = "org.opencontainers.image.title"
+	// The registry host of the Docker Hub API, which docker.io and index.docker.io refer to
+	dockerHubRegistry = "registry-1.docker.io"
+	// The server URL that the Docker CLI stores the Docker Hub credentials under
+	dockerHubServerURL = "https://index.docker.io/v1/"
+)
+
+// The media types of the OCI and Docker image manifests, which are accepted when pulling an artifact.
+var ociManifestMediaTypes = []string{
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// The parameters of a WWW-Authenticate header, such as realm="https://auth.example.com/token".
+var authParamRegexp = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// ociReference is a parsed manifest.ociRef value in the format of oci://registry/repository:tag or
+// oci://registry/repository@digest.
+type ociReference struct {
+	registry   string
+	repository string
+	// The tag or digest of the artifact
+	reference string
+}
+
+// parseOCIRef parses the input manifest.ociRef value. The tag defaults to latest. The Docker Hub
+// registry is mapped to the registry-1.docker.io API host, and its official images, such as nginx,
+// are mapped to the library repository.
+func parseOCIRef(ref string) (ociReference, error) {
+	invalidErr := fmt.Errorf(
+		"the OCI reference %s must be in the format of oci://registry/repository:tag or "+
+			"oci://registry/repository@digest",
+		ref,
+	)
+
+	trimmed, found := strings.CutPrefix(ref, ociScheme)
+	if !found {
+		return ociReference{}, invalidErr
+	}
+
+	registry, repository, found := strings.Cut(trimmed, "/")
+	if !found || registry == "" || repository == "" {
+		return ociReference{}, invalidErr
+	}
+
+	reference := "latest"
+
+	if repo, digest, found := strings.Cut(repository, "@"); found {
+		repository, reference = repo, digest
+	} else if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository, reference = repository[:i], repository[i+1:]
+	}
+
+	if repository == "" || reference == "" {
+		return ociReference{}, invalidErr
+	}
+
+	if getDockerConfigHost(registry) == dockerHubRegistry {
+		registry = dockerHubRegistry
+
+		if !strings.Contains(repository, "/") {
+			repository = "library/" + repository
+		}
+	}
+
+	return ociReference{registry: registry, repository: repository, reference: reference}, nil
+}
+
+// ociManifestRenderer pulls an OCI artifact and returns the objects in its YAML layers. The registry
+// credentials are read from the standard Docker configuration file, if present.
+type ociManifestRenderer struct {
+	client *http.Client
+}
+
+// Render pulls the OCI artifact at the input manifest.ociRef value and unmarshals the YAML
+// documents of its YAML layers, in the order of the layers. A layer is a YAML layer if its media
+// type contains yaml or its title annotation has a .yaml or .yml extension. An error naming the
+// reference is returned if the artifact can't be pulled or doesn't have any YAML layers.
+func (r ociManifestRenderer) Render(ref string) ([]map[string]interface{}, error) {
+	manifests, err := r.pull(ref)
+	if err != nil {
+		return nil, withErrorClass(fmt.Errorf("failed to pull the OCI artifact %s: %w", ref, err), ErrManifestRead)
+	}
+
+	return manifests, nil
+}
+
+// pull implements Render.
+func (r ociManifestRenderer) pull(ref string) ([]map[string]interface{}, error) {
+	ociRef, err := parseOCIRef(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ociPullTimeout)
+	defer cancel()
+
+	client := r.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	session := ociSession{client: client, ref: ociRef}
+
+	manifestBytes, err := session.get(
+		ctx, "manifests/"+ociRef.reference, strings.Join(ociManifestMediaTypes, ", "),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	artifact := struct {
+		MediaType string `json:"mediaType"`
+		Layers    []struct {
+			MediaType   string            `json:"mediaType"`
+			Digest      string            `json:"digest"`
+			Annotations map[string]string `json:"annotations"`
+		} `json:"layers"`
+	}{}
+
+	err = json.Unmarshal(manifestBytes, &artifact)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the OCI manifest: %w", err)
+	}
+
+	if strings.Contains(artifact.MediaType, "index") || strings.Contains(artifact.MediaType, "manifest.list") {
+		return nil, fmt.Errorf("the artifact has the unsupported media type %s", artifact.MediaType)
+	}
+
+	manifests := []map[string]interface{}{}
+	yamlLayers := 0
+
+	for _, layer := range artifact.Layers {
+		title := layer.Annotations[ociTitleAnnotation]
+		ext := filepath.Ext(title)
+
+		if !strings.Contains(layer.MediaType, "yaml") && ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+
+		yamlLayers++
+
+		layerBytes, err := session.get(ctx, "blobs/"+layer.Digest, "")
+		if err != nil {
+			return nil, err
+		}
+
+		err = verifyOCIDigest(layerBytes, layer.Digest)
+		if err != nil {
+			return nil, err
+		}
+
+		layerManifests, err := unmarshalManifestBytes(layerBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the layer %s: %w", layer.Digest, err)
+		}
+
+		manifests = append(manifests, layerManifests...)
+	}
+
+	if yamlLayers == 0 {
+		return nil, errors.New("the artifact doesn't have any YAML layers")
+	}
+
+	return manifests, nil
+}
+
+// verifyOCIDigest verifies that the input content matches the input sha256 digest.
+func verifyOCIDigest(content []byte, digest string) error {
+	algorithm, expected, _ := strings.Cut(digest, ":")
+	if algorithm != "sha256" {
+		return fmt.Errorf("the layer digest %s is not a supported sha256 digest", digest)
+	}
+
+	sum := sha256.Sum256(content)
+	if hex.EncodeToString(sum[:]) != expected {
+		return fmt.Errorf("the layer content doesn't match its digest %s", digest)
+	}
+
+	return nil
+}
+
+// ociSession sends the requests to pull an artifact from an OCI registry and tracks the
+// authorization to use for the requests.
+type ociSession struct {
+	client *http.Client
+	ref    ociReference
+	// The value of the Authorization header to send, if any
+	authorization string
+}
+
+// get returns the content of the input path, such as manifests/v1, relative to the repository in
+// the registry. If the registry requires authentication, the credentials for the registry in the
+// Docker configuration file are used to authenticate and the request is retried.
+func (s *ociSession) get(ctx context.Context, apiPath string, accept string) ([]byte, error) {
+	scheme := "https"
+	if isLocalRegistry(s.ref.registry) {
+		scheme = "http"
+	}
+
+	reqURL := fmt.Sprintf("%s://%s/v2/%s/%s", scheme, s.ref.registry, s.ref.repository, apiPath)
+
+	resp, err := s.do(ctx, reqURL, accept, s.authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized && s.authorization == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		_ = resp.Body.Close()
+
+		s.authorization, err = s.authenticate(ctx, challenge)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err = s.do(ctx, reqURL, accept, s.authorization)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("the registry returned the status %s for %s", resp.Status, reqURL)
+	}
+
+	return readOCIBody(resp.Body)
+}
+
+// do sends a GET request to the input URL with the input Accept and Authorization headers, if set.
+func (s *ociSession) do(ctx context.Context, reqURL, accept, authorization string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the request to %s: %w", reqURL, err)
+	}
+
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send the request to %s: %w", reqURL, err)
+	}
+
+	return resp, nil
+}
+
+// authenticate returns the Authorization header value for the input WWW-Authenticate challenge of
+// the registry. Basic authentication uses the Docker configuration credentials directly, and
+// bearer authentication exchanges them, if any, for a token from the challenge realm.
+func (s *ociSession) authenticate(ctx context.Context, challenge string) (string, error) {
+	username, password, err := getDockerCredentials(s.ref.registry)
+	if err != nil {
+		return "", err
+	}
+
+	scheme, params, _ := strings.Cut(challenge, " ")
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if username == "" && password == "" {
+			return "", fmt.Errorf("the registry %s requires credentials but none were found", s.ref.registry)
+		}
+
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password)), nil
+	case "bearer":
+	default:
+		return "", fmt.Errorf("the registry %s returned an unsupported authentication challenge", s.ref.registry)
+	}
+
+	authParams := map[string]string{}
+	for _, match := range authParamRegexp.FindAllStringSubmatch(params, -1) {
+		authParams[match[1]] = match[2]
+	}
+
+	tokenURL, err := url.Parse(authParams["realm"])
+	if err != nil || authParams["realm"] == "" {
+		return "", fmt.Errorf("the registry %s returned an invalid authentication realm", s.ref.registry)
+	}
+
+	query := tokenURL.Query()
+
+	if authParams["service"] != "" {
+		query.Set("service", authParams["service"])
+	}
+
+	scope := authParams["scope"]
+	if scope == "" {
+		scope = "repository:" + s.ref.repository + ":pull"
+	}
+
+	query.Set("scope", scope)
+	tokenURL.RawQuery = query.Encode()
+
+	basicAuth := ""
+	if username != "" || password != "" {
+		basicAuth = "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+	}
+
+	resp, err := s.do(ctx, tokenURL.String(), "", basicAuth)
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("the registry token endpoint returned the status %s", resp.Status)
+	}
+
+	tokenBytes, err := readOCIBody(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+
+	err = json.Unmarshal(tokenBytes, &token)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode the registry token: %w", err)
+	}
+
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+
+	if token.Token == "" {
+		return "", errors.New("the registry token endpoint didn't return a token")
+	}
+
+	return "Bearer " + token.Token, nil
+}
+
+// readOCIBody reads the input response body up to the maximum blob size.
+func readOCIBody(body io.Reader) ([]byte, error) {
+	content, err := io.ReadAll(io.LimitReader(body, ociMaxBlobSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the registry response: %w", err)
+	}
+
+	if len(content) > ociMaxBlobSize {
+		return nil, fmt.Errorf("the registry response exceeds the maximum size of %d bytes", ociMaxBlobSize)
+	}
+
+	return content, nil
+}
+
+// isLocalRegistry returns whether the input registry host is on the local machine, in which case
+// plain HTTP is used rather than HTTPS.
+func isLocalRegistry(registry string) bool {
+	host := registry
+	if h, _, err := net.SplitHostPort(registry); err == nil {
+		host = h
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
+
+// getDockerCredentials returns the username and password for the input registry from the Docker
+// configuration file at $DOCKER_CONFIG/config.json or ~/.docker/config.json. Like the Docker CLI,
+// the credential helper of the registry in credHelpers is used first, then the credsStore helper,
+// and then the auths entries, whose keys may have a scheme and path such as
+// https://index.docker.io/v1/. Empty strings are returned if the file or the registry credentials
+// don't exist.
+func getDockerCredentials(registry string) (string, string, error) {
+	configDir := os.Getenv("DOCKER_CONFIG")
+	if configDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", nil
+		}
+
+		configDir = filepath.Join(homeDir, ".docker")
+	}
+
+	configPath := filepath.Join(configDir, "config.json")
+
+	configBytes, err := os.ReadFile(filepath.Clean(configPath))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", "", nil
+		}
+
+		return "", "", fmt.Errorf("failed to read the Docker configuration file %s: %w", configPath, err)
+	}
+
+	dockerConfig := struct {
+		Auths map[string]struct {
+			Auth     string `json:"auth"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"auths"`
+		CredHelpers map[string]string `json:"credHelpers"`
+		CredsStore  string            `json:"credsStore"`
+	}{}
+
+	err = json.Unmarshal(configBytes, &dockerConfig)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decode the Docker configuration file %s: %w", configPath, err)
+	}
+
+	registryHost := getDockerConfigHost(registry)
+
+	helper := dockerConfig.CredsStore
+	if key, found := findDockerConfigKey(dockerConfig.CredHelpers, registryHost); found {
+		helper = dockerConfig.CredHelpers[key]
+	}
+
+	if helper != "" {
+		// Docker Hub credentials are stored under the server URL of the Docker CLI
+		serverURL := registry
+		if registryHost == dockerHubRegistry {
+			serverURL = dockerHubServerURL
+		}
+
+		username, password, err := runDockerCredentialHelper(helper, serverURL)
+		if err != nil || username != "" || password != "" {
+			return username, password, err
+		}
+	}
+
+	if key, found := findDockerConfigKey(dockerConfig.Auths, registryHost); found {
+		auth := dockerConfig.Auths[key]
+
+		if auth.Auth == "" {
+			return auth.Username, auth.Password, nil
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return "", "", fmt.Errorf("the Docker configuration auth for %s is not valid base64", registry)
+		}
+
+		username, password, _ := bytes.Cut(decoded, []byte(":"))
+
+		return string(username), string(password), nil
+	}
+
+	return "", "", nil
+}
+
+// findDockerConfigKey returns the first key in sorted order of the input Docker configuration
+// entries whose registry host is the input registry host.
+func findDockerConfigKey[V any](entries map[string]V, registryHost string) (string, bool) {
+	keys := []string{}
+
+	for key := range entries {
+		if getDockerConfigHost(key) == registryHost {
+			keys = append(keys, key)
+		}
+	}
+
+	if len(keys) == 0 {
+		return "", false
+	}
+
+	sort.Strings(keys)
+
+	return keys[0], true
+}
+
+// getDockerConfigHost returns the registry host of the input Docker configuration key, such as
+// quay.io for https://quay.io/v1/. The Docker Hub hosts are returned as registry-1.docker.io.
+func getDockerConfigHost(key string) string {
+	host := key
+
+	for _, scheme := range []string{"https://", "http://"} {
+		host = strings.TrimPrefix(host, scheme)
+	}
+
+	host, _, _ = strings.Cut(host, "/")
+
+	if host == "docker.io" || host == "index.docker.io" {
+		return dockerHubRegistry
+	}
+
+	return host
+}
+
+// runDockerCredentialHelper returns the username and password for the input server URL from the
+// docker-credential-<helper> program. Empty strings are returned if the helper doesn't have
+// credentials for the server URL.
+func runDockerCredentialHelper(helper string, serverURL string) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+
+	// #nosec G204 -- the helper is set in the Docker configuration file of the user
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(serverURL)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		if strings.Contains(stdout.String()+stderr.String(), "credentials not found") {
+			return "", "", nil
+		}
+
+		return "", "", fmt.Errorf(
+			"the Docker credential helper %s failed for %s: %w: %s",
+			helper, serverURL, err, strings.TrimSpace(stderr.String()+stdout.String()),
+		)
+	}
+
+	creds := struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}{}
+
+	err = json.Unmarshal(stdout.Bytes(), &creds)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decode the output of the Docker credential helper %s: %w", helper, err)
+	}
+
+	return creds.Username, creds.Secret, nil
+}
@@ -0,0 +1,412 @@
+// Copyright Contributors to the Open Cluster Management project
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"open-cluster-management.io/policy-generator-plugin/internal/types"
+)
+
+const ociTestConfigMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-configmap
+data:
+  game.properties: enemies=potato
+`
+
+// ociTestLayer is a layer of the artifact served by the mock registry.
+type ociTestLayer struct {
+	mediaType string
+	title     string
+	content   string
+}
+
+// newOCITestRegistry returns a mock OCI registry that serves an artifact with the input layers at
+// the my-org/my-policies repository with the v1 tag. If authorization is set, the requests must set it
+// as the Authorization header.
+func newOCITestRegistry(t *testing.T, layers []ociTestLayer, authorization string) *httptest.Server {
+	t.Helper()
+
+	blobs := map[string]string{}
+	manifestLayers := []map[string]interface{}{}
+
+	for _, layer := range layers {
+		sum := sha256.Sum256([]byte(layer.content))
+		digest := "sha256:" + hex.EncodeToString(sum[:])
+		blobs[digest] = layer.content
+
+		manifestLayer := map[string]interface{}{
+			"mediaType": layer.mediaType,
+			"digest":    digest,
+			"size":      len(layer.content),
+		}
+
+		if layer.title != "" {
+			manifestLayer["annotations"] = map[string]string{ociTitleAnnotation: layer.title}
+		}
+
+		manifestLayers = append(manifestLayers, manifestLayer)
+	}
+
+	manifest, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+		"layers":        manifestLayers,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if authorization != "" && r.Header.Get("Authorization") != authorization {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		switch {
+		case r.URL.Path == "/v2/my-org/my-policies/manifests/v1":
+			w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+			_, _ = w.Write(manifest)
+		case strings.HasPrefix(r.URL.Path, "/v2/my-org/my-policies/blobs/"):
+			blob, ok := blobs[strings.TrimPrefix(r.URL.Path, "/v2/my-org/my-policies/blobs/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			_, _ = w.Write([]byte(blob))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestParseOCIRef(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		ref      string
+		expected ociReference
+	}{
+		"tag": {
+			"oci://quay.io/my-org/my-policies:v1",
+			ociReference{registry: "quay.io", repository: "my-org/my-policies", reference: "v1"},
+		},
+		"default tag": {
+			"oci://quay.io/my-org/my-policies",
+			ociReference{registry: "quay.io", repository: "my-org/my-policies", reference: "latest"},
+		},
+		"registry port": {
+			"oci://localhost:5000/my-policies:v1",
+			ociReference{registry: "localhost:5000", repository: "my-policies", reference: "v1"},
+		},
+		"digest": {
+			"oci://quay.io/my-policies@sha256:abc",
+			ociReference{registry: "quay.io", repository: "my-policies", reference: "sha256:abc"},
+		},
+		"docker hub official image": {
+			"oci://docker.io/my-policies:v1",
+			ociReference{registry: "registry-1.docker.io", repository: "library/my-policies", reference: "v1"},
+		},
+		"docker hub index": {
+			"oci://index.docker.io/my-org/my-policies",
+			ociReference{registry: "registry-1.docker.io", repository: "my-org/my-policies", reference: "latest"},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ociRef, err := parseOCIRef(test.ref)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			assertReflectEqual(t, ociRef, test.expected)
+		})
+	}
+
+	for _, ref := range []string{"quay.io/my-policies:v1", "oci://quay.io", "oci://quay.io/:v1"} {
+		_, err := parseOCIRef(ref)
+		if err == nil {
+			t.Fatalf("Expected an error for %s but did not get one", ref)
+		}
+	}
+}
+
+func TestOCIManifestRendererRender(t *testing.T) {
+	t.Parallel()
+
+	server := newOCITestRegistry(t, []ociTestLayer{
+		{mediaType: "application/vnd.oci.image.layer.v1.tar", title: "configmap.yaml", content: ociTestConfigMap},
+		{mediaType: "application/octet-stream", title: "README.md", content: "# My policies"},
+		{
+			mediaType: "application/yaml",
+			content:   "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: my-namespace\n",
+		},
+	}, "")
+
+	ref := "oci://" + strings.TrimPrefix(server.URL, "http://") + "/my-org/my-policies:v1"
+
+	manifests, err := ociManifestRenderer{}.Render(ref)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, len(manifests), 2)
+	assertEqual(t, manifests[0]["kind"], "ConfigMap")
+	assertEqual(t, manifests[1]["kind"], "Namespace")
+}
+
+func TestOCIManifestRendererBearerAuth(t *testing.T) {
+	dockerConfigDir := t.TempDir()
+	auth := base64.StdEncoding.EncodeToString([]byte("my-user:my-password"))
+	var registryHost string
+
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Basic "+auth ||
+			r.URL.Query().Get("scope") != "repository:my-org/my-policies:pull" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"token": "my-token"}`))
+	}))
+	t.Cleanup(tokenServer.Close)
+
+	server := newOCITestRegistry(t, []ociTestLayer{
+		{mediaType: "application/yaml", content: ociTestConfigMap},
+	}, "Bearer my-token")
+
+	// Wrap the registry so that the unauthorized responses have the bearer challenge
+	challengeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.Header().Set(
+				"WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="%s"`, tokenServer.URL, registryHost),
+			)
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(challengeServer.Close)
+
+	registryHost = strings.TrimPrefix(challengeServer.URL, "http://")
+
+	dockerConfig := fmt.Sprintf(`{"auths": {"%s": {"auth": "%s"}}}`, registryHost, auth)
+
+	err := os.WriteFile(path.Join(dockerConfigDir, "config.json"), []byte(dockerConfig), 0o666)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	t.Setenv("DOCKER_CONFIG", dockerConfigDir)
+
+	manifests, err := ociManifestRenderer{}.Render("oci://" + registryHost + "/my-org/my-policies:v1")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, len(manifests), 1)
+	assertEqual(t, manifests[0]["kind"], "ConfigMap")
+}
+
+func TestGetDockerCredentials(t *testing.T) {
+	dockerConfigDir := t.TempDir()
+	helperDir := t.TempDir()
+
+	// The credential helpers only have credentials for quay.io
+	helperScript := `#!/bin/sh
+read -r server
+if [ "$server" = "quay.io" ]; then
+  echo '{"ServerURL": "quay.io", "Username": "%[1]s-user", "Secret": "%[1]s-secret"}'
+  exit 0
+fi
+echo "credentials not found in native keychain"
+exit 1
+`
+
+	for _, helper := range []string{"store", "quay"} {
+		err := os.WriteFile(
+			path.Join(helperDir, "docker-credential-"+helper), []byte(fmt.Sprintf(helperScript, helper)), 0o755,
+		)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	t.Setenv("PATH", helperDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("DOCKER_CONFIG", dockerConfigDir)
+
+	auth := func(username string) string {
+		return base64.StdEncoding.EncodeToString([]byte(username + ":" + username + "-password"))
+	}
+
+	tests := map[string]struct {
+		config           string
+		registry         string
+		expectedUsername string
+	}{
+		"docker hub server URL": {
+			config:           fmt.Sprintf(`{"auths": {"https://index.docker.io/v1/": {"auth": "%s"}}}`, auth("hub")),
+			registry:         "registry-1.docker.io",
+			expectedUsername: "hub",
+		},
+		"auths key with scheme and path": {
+			config:           fmt.Sprintf(`{"auths": {"https://quay.io/v2/": {"auth": "%s"}}}`, auth("quay")),
+			registry:         "quay.io",
+			expectedUsername: "quay",
+		},
+		"credsStore": {
+			config:           fmt.Sprintf(`{"credsStore": "store", "auths": {"quay.io": {"auth": "%s"}}}`, auth("auths")),
+			registry:         "quay.io",
+			expectedUsername: "store-user",
+		},
+		"credHelpers before credsStore": {
+			config:           `{"credsStore": "store", "credHelpers": {"quay.io": "quay"}}`,
+			registry:         "quay.io",
+			expectedUsername: "quay-user",
+		},
+		"credsStore without credentials": {
+			config:           fmt.Sprintf(`{"credsStore": "store", "auths": {"ghcr.io": {"auth": "%s"}}}`, auth("ghcr")),
+			registry:         "ghcr.io",
+			expectedUsername: "ghcr",
+		},
+		"no credentials": {
+			config:           `{"credsStore": "store"}`,
+			registry:         "ghcr.io",
+			expectedUsername: "",
+		},
+	}
+
+	for name, test := range tests {
+		err := os.WriteFile(path.Join(dockerConfigDir, "config.json"), []byte(test.config), 0o666)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		username, _, err := getDockerCredentials(test.registry)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if username != test.expectedUsername {
+			t.Fatalf("%s: expected the username %s but got %s", name, test.expectedUsername, username)
+		}
+	}
+}
+
+func TestOCIManifestRendererErrors(t *testing.T) {
+	t.Parallel()
+
+	server := newOCITestRegistry(t, []ociTestLayer{
+		{mediaType: "application/octet-stream", title: "README.md", content: "# My policies"},
+	}, "")
+	registryHost := strings.TrimPrefix(server.URL, "http://")
+
+	tests := map[string]struct {
+		ref         string
+		expectedErr string
+	}{
+		"not found": {
+			ref: "oci://" + registryHost + "/my-org/my-policies:v2",
+			expectedErr: fmt.Sprintf(
+				"failed to pull the OCI artifact oci://%s/my-org/my-policies:v2: the registry returned the status "+
+					"404 Not Found for http://%s/v2/my-org/my-policies/manifests/v2",
+				registryHost, registryHost,
+			),
+		},
+		"no YAML layers": {
+			ref: "oci://" + registryHost + "/my-org/my-policies:v1",
+			expectedErr: fmt.Sprintf(
+				"failed to pull the OCI artifact oci://%s/my-org/my-policies:v1: the artifact doesn't have any YAML "+
+					"layers",
+				registryHost,
+			),
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ociManifestRenderer{}.Render(test.ref)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+func TestGetPolicyTemplateOCIRef(t *testing.T) {
+	t.Parallel()
+
+	server := newOCITestRegistry(t, []ociTestLayer{
+		{mediaType: "application/yaml", content: ociTestConfigMap},
+	}, "")
+
+	policyConf := types.PolicyConfig{
+		PolicyOptions: types.PolicyOptions{ConsolidateManifests: true},
+		Manifests: []types.Manifest{{
+			OCIRef:  "oci://" + strings.TrimPrefix(server.URL, "http://") + "/my-org/my-policies:v1",
+			Patches: []map[string]interface{}{{"data": map[string]interface{}{"game.properties": "enemies=tomato"}}},
+		}},
+		Name: "policy-app-config",
+	}
+
+	policyTemplates, err := getPolicyTemplates(&policyConf, nil)
+	if err != nil {
+		t.Fatalf("Failed to get the policy templates: %v ", err)
+	}
+
+	assertEqual(t, len(policyTemplates), 1)
+
+	objdef := policyTemplates[0]["objectDefinition"].(map[string]interface{})
+
+	spec, ok := objdef["spec"].(map[string]interface{})
+	if !ok {
+		t.Fatal("The spec field is an invalid format")
+	}
+
+	objTemplates, ok := spec["object-templates"].([]map[string]interface{})
+	if !ok {
+		t.Fatal("The object-templates field is an invalid format")
+	}
+
+	expected := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "my-configmap"},
+		"data":       map[string]interface{}{"game.properties": "enemies=tomato"},
+	}
+
+	assertEqual(t, len(objTemplates), 1)
+	assertReflectEqual(t, objTemplates[0]["objectDefinition"], expected)
+}
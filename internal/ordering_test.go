@@ -3,6 +3,7 @@ package internal
 import (
 	"bytes"
 	"embed"
+	"fmt"
 	"os"
 	"path"
 	"testing"
@@ -159,6 +160,30 @@ policies:
 			wantFile: "",
 			wantErr:  "dependencies may not be set in policy one when policyDefaults.orderPolicies is true",
 		},
+		"inherited policyDefaults dependencies and orderPolicies": {
+			tmpDir: tmpDir,
+			generator: `
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: test
+policyDefaults:
+  orderPolicies: true
+  dependencies:
+  - name: foo
+  namespace: my-policies
+policies:
+- name: one
+  dependencies: []
+  manifests:
+  - path: {{printf "%v/%v" .Dir "configmap.yaml"}}
+- name: two
+  manifests:
+  - path: {{printf "%v/%v" .Dir "configmap.yaml"}}
+`,
+			wantFile: "",
+			wantErr:  "policyDefaults must specify only one of dependencies or orderPolicies",
+		},
 	}
 
 	for name := range tests {
@@ -166,6 +191,58 @@ policies:
 	}
 }
 
+func TestOrderPoliciesGenerateTwice(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	generator := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: test
+policyDefaults:
+  orderPolicies: true
+  namespace: my-policies
+policies:
+- name: one
+  manifests:
+  - path: %[1]s
+- name: two
+  manifests:
+  - path: %[1]s
+`,
+		path.Join(tmpDir, "configmap.yaml"),
+	)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(generator), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	firstOutput, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// The first policy must not depend on the last policy of the previous run
+	secondOutput, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	want, err := wantedOutputs.ReadFile("testdata/ordering/two-ordered-policies.yaml")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqualYaml(t, want, firstOutput)
+	assertEqualYaml(t, want, secondOutput)
+	assertEqual(t, p.Policies[0].Dependencies == nil, true)
+}
+
 func TestSetNameManifestLevel(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
@@ -315,6 +392,7 @@ metadata:
 policyDefaults:
   orderPolicies: true
   namespace: my-policies
+  allowDuplicateObjects: true
   consolidateManifests: true
 policies:
 - name: one
@@ -338,6 +416,7 @@ metadata:
 policyDefaults:
   orderPolicies: true
   namespace: my-policies
+  allowDuplicateObjects: true
   consolidateManifests: true
 policies:
 - name: one
@@ -454,6 +533,50 @@ policies:
 			wantFile: "testdata/ordering/policy-level-dependencies.yaml",
 			wantErr:  "",
 		},
+		"only policy kinds default to the policy namespace": {
+			tmpDir: tmpDir,
+			generator: `
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: test
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: one
+  dependencies:
+  - name: prerequisite
+    namespace: other-team
+  - name: prerequisite-local
+  extraDependencies:
+  - apiVersion: policy.open-cluster-management.io/v1
+    kind: ConfigurationPolicy
+    name: other-config
+  manifests:
+  - path: {{printf "%v/%v" .Dir "configmap.yaml"}}
+`,
+			wantFile: "testdata/ordering/cross-namespace-dependencies.yaml",
+			wantErr:  "",
+		},
+		"dependency namespaces must be valid": {
+			tmpDir: tmpDir,
+			generator: `
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: test
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: one
+  dependencies:
+  - name: prerequisite
+    namespace: Other_Team
+  manifests:
+  - path: {{printf "%v/%v" .Dir "configmap.yaml"}}
+`,
+			wantErr: "dependency namespace `Other_Team` is not a valid namespace name in policy one dependency 0",
+		},
 	}
 
 	for name := range tests {
@@ -499,6 +622,7 @@ kind: PolicyGenerator
 metadata:
   name: test
 policyDefaults:
+  allowDuplicateObjects: true
   consolidateManifests: true
   ignorePending: true
   namespace: my-policies
@@ -798,6 +922,7 @@ kind: PolicyGenerator
 metadata:
   name: test
 policyDefaults:
+  allowDuplicateObjects: true
   consolidateManifests: true
   namespace: my-policies
   extraDependencies:
@@ -862,6 +987,30 @@ policies:
 			wantFile: "testdata/ordering/manifest-extradeps.yaml",
 			wantErr:  "",
 		},
+		"distinct manifest extraDependencies are handled": {
+			tmpDir: tmpDir,
+			generator: `
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: test
+policyDefaults:
+  consolidateManifests: false
+  namespace: my-policies
+policies:
+- name: one
+  manifests:
+  - path: {{printf "%v/%v" .Dir "configmap.yaml"}}
+    extraDependencies:
+    - name: manifestextra
+  - path: {{printf "%v/%v" .Dir "configmap.yaml"}}
+    extraDependencies:
+    - name: otherextra
+      compliance: NonCompliant
+`,
+			wantFile: "testdata/ordering/manifest-extradeps-distinct.yaml",
+			wantErr:  "",
+		},
 		"manifest extraDependencies are handled with ConfigurationPolicy manifests": {
 			tmpDir: tmpDir,
 			generator: `
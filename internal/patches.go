@@ -7,6 +7,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"reflect"
 
 	yaml "gopkg.in/yaml.v3"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -29,6 +30,9 @@ type manifestPatcher struct {
 	// to the input maps. If this is an issue, provide a deep copy of the patches.
 	patches []map[string]interface{}
 	openAPI types.Filepath
+	// When strict is true, a patch must target one of the manifests by name and must change the
+	// manifest it is applied to.
+	strict bool
 }
 
 // validateManifestInfo verifies that the apiVersion, kind, metadata.name fields from a manifest
@@ -84,7 +88,7 @@ func (m *manifestPatcher) Validate() error {
 
 		// At this point, there is a reasonable chance that the patch is valid. Kustomize can handle
 		// further validation.
-		return nil
+		return m.validatePatchTargets()
 	}
 
 	// At this point, we know we are only dealing with a single manifest, so we can assume all
@@ -107,6 +111,44 @@ func (m *manifestPatcher) Validate() error {
 		}
 	}
 
+	return m.validatePatchTargets()
+}
+
+// validatePatchTargets verifies that each patch targets one of the manifests by its kind, name, and
+// namespace when strict patching is enabled. An error is returned if a patch targets a manifest that
+// isn't present.
+func (m *manifestPatcher) validatePatchTargets() error {
+	if !m.strict {
+		return nil
+	}
+
+	for i, patch := range m.patches {
+		kind, _, _ := unstructured.NestedString(patch, "kind")
+		name, _, _ := unstructured.NestedString(patch, "metadata", "name")
+		namespace, _, _ := unstructured.NestedString(patch, "metadata", "namespace")
+
+		found := false
+
+		for _, manifest := range m.manifests {
+			manifestKind, _, _ := unstructured.NestedString(manifest, "kind")
+			manifestName, _, _ := unstructured.NestedString(manifest, "metadata", "name")
+			manifestNamespace, _, _ := unstructured.NestedString(manifest, "metadata", "namespace")
+
+			if kind == manifestKind && name == manifestName &&
+				(namespace == "" || namespace == manifestNamespace) {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf(
+				`the patch at index %d targets the %s "%s" which is not in the manifests`, i, kind, name,
+			)
+		}
+	}
+
 	return nil
 }
 
@@ -172,9 +214,43 @@ func setPatchDefaults(
 }
 
 // ApplyPatches applies the Kustomize patches on the input manifests using Kustomize and returns
-// the patched manifests. An error is returned if the patches can't be applied. This should be
-// run after the Validate method.
+// the patched manifests. An error is returned if the patches can't be applied or if strict patching
+// is enabled and a patch doesn't change any manifest. This should be run after the Validate method.
 func (m *manifestPatcher) ApplyPatches() ([]map[string]interface{}, error) {
+	if !m.strict {
+		return m.kustomizePatches(m.manifests, m.patches)
+	}
+
+	// Render the manifests without patches first so that the formatting changes made by Kustomize
+	// aren't mistaken for changes made by the first patch
+	manifests, err := m.kustomizePatches(m.manifests, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply the patches one at a time so that a patch that doesn't change anything can be detected
+
+	for i, patch := range m.patches {
+		patchedManifests, err := m.kustomizePatches(manifests, []map[string]interface{}{patch})
+		if err != nil {
+			return nil, err
+		}
+
+		if reflect.DeepEqual(manifests, patchedManifests) {
+			return nil, fmt.Errorf("the patch at index %d did not change any manifest", i)
+		}
+
+		manifests = patchedManifests
+	}
+
+	return manifests, nil
+}
+
+// kustomizePatches applies the input Kustomize patches on the input manifests using Kustomize and
+// returns the patched manifests.
+func (m *manifestPatcher) kustomizePatches(
+	manifests []map[string]interface{}, patches []map[string]interface{},
+) ([]map[string]interface{}, error) {
 	const (
 		localSchemaFileName = "schema.json"
 		kustomizeDir        = "kustomize"
@@ -198,8 +274,8 @@ func (m *manifestPatcher) ApplyPatches() ([]map[string]interface{}, error) {
 		kustomizeKey string
 		objects      []map[string]interface{}
 	}{
-		{"manifest", "resources", m.manifests},
-		{"patch", "patches", m.patches},
+		{"manifest", "resources", manifests},
+		{"patch", "patches", patches},
 	}
 	for _, option := range options {
 		for i, object := range option.objects {
@@ -257,12 +333,12 @@ func (m *manifestPatcher) ApplyPatches() ([]map[string]interface{}, error) {
 		return nil, fmt.Errorf("failed to convert the patched manifest(s) back to YAML: %w", err)
 	}
 
-	manifests, err := unmarshalManifestBytes(manifestsYAML)
+	patchedManifests, err := unmarshalManifestBytes(manifestsYAML)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read the patched manifest(s): %w", err)
 	}
 
-	return manifests, nil
+	return patchedManifests, nil
 }
 
 // Initializes the in-memory file system with base directory and open API schema
@@ -279,7 +355,7 @@ func initializeInMemoryKustomizeDir(fSys filesys.FileSystem, schema,
 
 		schemaJSON, err := os.ReadFile(schema)
 		if err != nil {
-			return fmt.Errorf("error reading file %s: %w ", schema, err)
+			return withErrorClass(fmt.Errorf("error reading file %s: %w ", schema, err), ErrManifestRead)
 		}
 
 		err = fSys.WriteFile(path.Join(kustomizeDir, localSchemaFileName), schemaJSON)
@@ -302,6 +302,114 @@ func TestApplyPatchesInvalidPatch(t *testing.T) {
 	assertEqual(t, err.Error(), expected)
 }
 
+func TestValidateStrictPatchTarget(t *testing.T) {
+	t.Parallel()
+
+	manifests := []map[string]interface{}{}
+	manifests = append(
+		manifests, *createExConfigMap("configmap1"), *createExConfigMap("configmap2"),
+	)
+	patches := []map[string]interface{}{
+		{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      "configmap3",
+				"namespace": "default",
+				"labels": map[string]string{
+					"chandler": "bing",
+				},
+			},
+		},
+	}
+
+	patcher := manifestPatcher{manifests: manifests, patches: patches, strict: true}
+	err := patcher.Validate()
+
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := `the patch at index 0 targets the ConfigMap "configmap3" which is not in the manifests`
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestApplyPatchesStrict(t *testing.T) {
+	t.Parallel()
+
+	manifests := []map[string]interface{}{}
+	manifests = append(
+		manifests, *createExConfigMap("configmap1"), *createExConfigMap("configmap2"),
+	)
+	patches := []map[string]interface{}{
+		{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      "configmap2",
+				"namespace": "default",
+				"labels": map[string]string{
+					"chandler": "bing",
+				},
+			},
+		},
+	}
+
+	patcher := manifestPatcher{manifests: manifests, patches: patches, strict: true}
+	err := patcher.Validate()
+	assertEqual(t, err, nil)
+
+	patchedManifests, err := patcher.ApplyPatches()
+	assertEqual(t, err, nil)
+
+	labels, _, _ := unstructured.NestedStringMap(patchedManifests[1], "metadata", "labels")
+	assertReflectEqual(t, labels, map[string]string{"chandler": "bing"})
+}
+
+func TestApplyPatchesStrictNoOp(t *testing.T) {
+	t.Parallel()
+
+	manifests := []map[string]interface{}{}
+	manifests = append(
+		manifests, *createExConfigMap("configmap1"), *createExConfigMap("configmap2"),
+	)
+	patches := []map[string]interface{}{
+		{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      "configmap1",
+				"namespace": "default",
+				"labels": map[string]string{
+					"chandler": "bing",
+				},
+			},
+		},
+		{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      "configmap2",
+				"namespace": "default",
+			},
+			"data": map[string]string{
+				"game.properties": "enemies=goldfish",
+			},
+		},
+	}
+
+	patcher := manifestPatcher{manifests: manifests, patches: patches, strict: true}
+	err := patcher.Validate()
+	assertEqual(t, err, nil)
+
+	_, err = patcher.ApplyPatches()
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	assertEqual(t, err.Error(), "the patch at index 1 did not change any manifest")
+}
+
 func TestInitializeInMemoryKustomizeDir(t *testing.T) {
 	const (
 		localSchemaFileName = "schema.json"
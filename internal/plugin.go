@@ -6,10 +6,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -27,6 +30,9 @@ const (
 	policyAPIGroup             = "policy.open-cluster-management.io"
 	policyAPIVersion           = policyAPIGroup + "/v1"
 	policyKind                 = "Policy"
+	policyAutomationAPIVersion = policyAPIGroup + "/v1beta1"
+	policyAutomationKind       = "PolicyAutomation"
+	policyV1beta1APIVersion    = policyAPIGroup + "/v1beta1"
 	policySetAPIVersion        = policyAPIGroup + "/v1beta1"
 	policySetKind              = "PolicySet"
 	placementBindingAPIVersion = policyAPIGroup + "/v1"
@@ -38,7 +44,17 @@ const (
 	maxObjectNameLength        = 63
 	dnsReference               = "https://kubernetes.io/docs/concepts/overview/working-with-objects/names/" +
 		"#dns-subdomain-names"
-	severityAnnotation = "policy.open-cluster-management.io/severity"
+	severityAnnotation    = "policy.open-cluster-management.io/severity"
+	contentHashAnnotation = "policy.open-cluster-management.io/content-hash"
+	generatedAtAnnotation = "policy.open-cluster-management.io/generated-at"
+	// maxFieldManagerLength is the maximum length of a field manager name accepted by the API server.
+	maxFieldManagerLength = 128
+	recordDiffValuesMsg   = "Log, InStatus, or None"
+	severityValuesMsg     = "low, medium, high, or critical"
+	kyvernoScopeValuesMsg = "All, Cluster, or Namespaced"
+	// defaultYAMLIndent is the default number of spaces to indent the generated YAML with, which matches
+	// yaml.Marshal.
+	defaultYAMLIndent = 4
 )
 
 // Plugin is used to store the PolicyGenerator configuration and the methods to generate the
@@ -50,12 +66,28 @@ type Plugin struct {
 		Name string `json:"name,omitempty" yaml:"name,omitempty"`
 	} `json:"metadata,omitempty" yaml:"metadata,omitempty"`
 	PlacementBindingDefaults struct {
-		Name string `json:"name,omitempty" yaml:"name,omitempty"`
+		Name      string `json:"name,omitempty" yaml:"name,omitempty"`
+		Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+		// The Go template of the placement binding names, which overrides the name above
+		NameTemplate string `json:"nameTemplate,omitempty" yaml:"nameTemplate,omitempty"`
+		// The maximum number of policies and policy sets in a placement binding before the subjects are
+		// split across multiple placement bindings. A value of 0 means there is no maximum.
+		MaxSubjectsPerBinding int `json:"maxSubjectsPerBinding,omitempty" yaml:"maxSubjectsPerBinding,omitempty"`
+		// The bindingOverrides and subFilter to set on the placement bindings, optionally only on those of
+		// policies with a label
+		BindingOverrides *types.BindingOverrides `json:"bindingOverrides,omitempty" yaml:"bindingOverrides,omitempty"`
 	} `json:"placementBindingDefaults,omitempty" yaml:"placementBindingDefaults,omitempty"`
-	PolicyDefaults    types.PolicyDefaults    `json:"policyDefaults,omitempty" yaml:"policyDefaults,omitempty"`
-	PolicySetDefaults types.PolicySetDefaults `json:"policySetDefaults,omitempty" yaml:"policySetDefaults,omitempty"`
-	Policies          []types.PolicyConfig    `json:"policies" yaml:"policies"`
-	PolicySets        []types.PolicySetConfig `json:"policySets" yaml:"policySets"`
+	PolicyDefaults    types.PolicyDefaults             `json:"policyDefaults,omitempty" yaml:"policyDefaults,omitempty"`
+	PolicySetDefaults types.PolicySetDefaults          `json:"policySetDefaults,omitempty" yaml:"policySetDefaults,omitempty"`
+	Policies          []types.PolicyConfig             `json:"policies" yaml:"policies"`
+	PolicySets        []types.PolicySetConfig          `json:"policySets" yaml:"policySets"`
+	Placements        map[string]types.PlacementConfig `json:"placements,omitempty" yaml:"placements,omitempty"`
+	Values            map[string]string                `json:"values,omitempty" yaml:"values,omitempty"`
+	// Paths of PolicyGenerator files whose policies, policySets, and policyDefaults are merged into the
+	// configuration
+	Imports []string `json:"imports,omitempty" yaml:"imports,omitempty"`
+	// Named lists of object templates that manifests can include with manifest.useTemplates
+	TemplateLibrary map[string][]map[string]interface{} `json:"templateLibrary,omitempty" yaml:"templateLibrary,omitempty"`
 	// A set of all placement names that have been processed or generated
 	allPlcs map[string]bool
 	// The base of the directory tree to restrict all manifest files to be within
@@ -72,6 +104,51 @@ type Plugin struct {
 	processedPlcs map[string]bool
 	// Track previous policy name for use if policies are being ordered
 	previousPolicyName string
+	// The time of the current Generate call, which is shared by all generated policies
+	generatedAt time.Time
+	// A mapping of placement names to the explicit placementRef to use in their PlacementBinding
+	bindingPlcRefs map[string]*types.PlacementRef
+	// A set of the placement names generated from named placements referenced with placement.ref
+	refPlcs map[string]bool
+	// The warnings about likely mistakes in the configuration found by the last Generate call
+	warnings []string
+	// The variable substitution to perform on the configuration before it is parsed, if any
+	substitution *SubstitutionOptions
+	// Whether the generator is run standalone rather than as a Kustomize plugin, which determines
+	// how the base directory is referred to in error messages
+	standalone bool
+	// The path prefixes to rewrite in the manifest and placement paths, in order of precedence
+	pathPrefixMappings []PathPrefixMapping
+	// The paths of the placement manifests that were selected with placement.placementSelector
+	selectedPlcPaths []string
+	// Whether only the placements and placement bindings are output
+	scaffoldOnly bool
+	// The resolved paths of the imported PolicyGenerator files, including the nested imports
+	importPaths []string
+	// The policy templates of the policies with deriveLabelsFrom, which are generated before the
+	// placements so that the placement bindings are grouped by the final policy labels
+	policyTemplates map[string][]map[string]interface{}
+}
+
+// SubstitutionOptions configures the substitution of ${NAME} variables in the PolicyGenerator
+// configuration before it is parsed.
+type SubstitutionOptions struct {
+	// The values of the variables to substitute
+	Values map[string]string
+	// Determines whether variables not in Values are substituted with environment variables
+	UseEnv bool
+	// Determines whether undefined variables are substituted with an empty string rather than
+	// returning an error
+	AllowUndefined bool
+	// The path of the file that Values was read from, if any, which is included in InputPaths
+	ValuesPath string
+}
+
+// PathPrefixMapping rewrites the manifest and placement paths in the PolicyGenerator configuration
+// whose leading path segments are Old to start with New instead.
+type PathPrefixMapping struct {
+	Old string
+	New string
 }
 
 var defaults = types.PolicyDefaults{
@@ -87,24 +164,62 @@ var defaults = types.PolicyDefaults{
 	},
 }
 
+// SetSubstitutionOptions enables the substitution of ${NAME} variables in the PolicyGenerator
+// configuration passed to Config. This must be run before Config.
+func (p *Plugin) SetSubstitutionOptions(options SubstitutionOptions) {
+	p.substitution = &options
+}
+
+// SetStandalone sets whether the generator is run standalone rather than as a Kustomize plugin. When
+// set, error messages about manifest paths outside of the base directory refer to the base directory
+// rather than the kustomization.yaml file. This must be run before Config.
+func (p *Plugin) SetStandalone(standalone bool) {
+	p.standalone = standalone
+}
+
+// SetPathPrefixMappings sets the path prefixes to rewrite in the manifest and placement paths of the
+// PolicyGenerator configuration, such as when the manifests are checked out under a different
+// directory in CI. The first mapping that matches a path is used. This must be run before Config.
+func (p *Plugin) SetPathPrefixMappings(mappings []PathPrefixMapping) {
+	p.pathPrefixMappings = mappings
+}
+
+// SetScaffoldOnly sets whether Generate only outputs the placements and placement bindings, such as to
+// create them before the policies they bind are applied separately. The policies and policy sets are
+// still generated so that the configuration is validated, but they aren't part of the output.
+func (p *Plugin) SetScaffoldOnly(scaffoldOnly bool) {
+	p.scaffoldOnly = scaffoldOnly
+}
+
 // Config validates the input PolicyGenerator configuration, applies any missing defaults, and
-// configures the Policy object.
+// configures the Policy object. If the input has multiple PolicyGenerator documents, their policies
+// and policy sets are merged. A returned error has the ErrInvalidConfig or ErrManifestRead class.
 func (p *Plugin) Config(config []byte, baseDirectory string) error {
-	dec := yaml.NewDecoder(bytes.NewReader(config))
-	dec.KnownFields(true) // emit an error on unknown fields in the input
+	return withErrorClass(p.config(config, baseDirectory), ErrInvalidConfig)
+}
 
-	err := dec.Decode(p)
-	const errTemplate = "the PolicyGenerator configuration file is invalid: %w"
+// config implements Config.
+func (p *Plugin) config(config []byte, baseDirectory string) error {
+	unmarshaledConfig, err := p.decodeConfig(config, baseDirectory)
+	if err != nil {
+		return err
+	}
 
+	err = p.resolvePlacementSelectors()
 	if err != nil {
-		return fmt.Errorf(errTemplate, addFieldNotFoundHelp(err))
+		return err
 	}
 
-	var unmarshaledConfig map[string]interface{}
+	err = p.resolvePlacementRefs()
+	if err != nil {
+		return err
+	}
 
-	err = yaml.Unmarshal(config, &unmarshaledConfig)
+	p.rewritePathPrefixes()
+
+	err = p.resolveExtractedPlacements()
 	if err != nil {
-		return fmt.Errorf(errTemplate, err)
+		return err
 	}
 
 	p.applyDefaults(unmarshaledConfig)
@@ -116,19 +231,190 @@ func (p *Plugin) Config(config []byte, baseDirectory string) error {
 
 	p.baseDirectory = baseDirectory
 
+	err = p.expandPerClusterPolicies()
+	if err != nil {
+		return err
+	}
+
+	err = p.resolveTemplateLibrary()
+	if err != nil {
+		return err
+	}
+
 	return p.assertValidConfig()
 }
 
+// decodeConfig substitutes the variables in the input PolicyGenerator configuration, merges its
+// documents and imports, and decodes it into the plugin without applying defaults or validating it.
+// The imports are only merged when baseDirectory is set. The configuration is also returned as a map
+// to determine which fields are explicitly set.
+func (p *Plugin) decodeConfig(config []byte, baseDirectory string) (map[string]interface{}, error) {
+	const errTemplate = "the PolicyGenerator configuration file is invalid: %w"
+
+	if p.substitution != nil {
+		var err error
+
+		config, err = substituteVariables(config, *p.substitution)
+		if err != nil {
+			return nil, fmt.Errorf(errTemplate, err)
+		}
+	}
+
+	config, err := mergeConfigDocuments(config)
+	if err != nil {
+		return nil, fmt.Errorf(errTemplate, err)
+	}
+
+	if baseDirectory != "" {
+		config, err = p.resolveImports(config, baseDirectory)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(config))
+	dec.KnownFields(true) // emit an error on unknown fields in the input
+
+	err = dec.Decode(p)
+	if err != nil {
+		return nil, fmt.Errorf(errTemplate, addFieldNotFoundHelp(err))
+	}
+
+	var unmarshaledConfig map[string]interface{}
+
+	err = yaml.Unmarshal(config, &unmarshaledConfig)
+	if err != nil {
+		return nil, fmt.Errorf(errTemplate, err)
+	}
+
+	return unmarshaledConfig, nil
+}
+
+// FilterPolicies limits the policies to generate to the policies with the input names, such as to
+// regenerate a single policy during development. The policies are removed from the policy sets of
+// the removed policies, and policy sets that no longer have any policies aren't generated. The after
+// entries that reference removed policies are dropped. This must
+// be run after Config. An error with the ErrInvalidConfig class is returned if a name doesn't match
+// a policy in the configuration.
+func (p *Plugin) FilterPolicies(names []string) error {
+	selected := make(map[string]bool, len(names))
+
+	for _, name := range names {
+		selected[name] = false
+	}
+
+	policies := make([]types.PolicyConfig, 0, len(names))
+
+	for _, policy := range p.Policies {
+		if _, ok := selected[policy.Name]; ok {
+			selected[policy.Name] = true
+
+			policies = append(policies, policy)
+		}
+	}
+
+	// The after policies were validated against the full configuration, so the ones that were filtered
+	// out are skipped for ordering and aren't emitted as dependencies.
+	for i := range policies {
+		if len(policies[i].After) == 0 {
+			continue
+		}
+
+		policies[i].After = slices.DeleteFunc(slices.Clone(policies[i].After), func(name string) bool {
+			return !selected[name]
+		})
+	}
+
+	for _, name := range names {
+		if !selected[name] {
+			return withErrorClass(
+				fmt.Errorf("the policy %s in the policy filter is not defined in the configuration", name),
+				ErrInvalidConfig,
+			)
+		}
+	}
+
+	p.Policies = policies
+
+	policySets := make([]types.PolicySetConfig, 0, len(p.PolicySets))
+
+	for _, policySet := range p.PolicySets {
+		if len(policySet.Policies) == 0 {
+			policySets = append(policySets, policySet)
+
+			continue
+		}
+
+		setPolicies := make([]string, 0, len(policySet.Policies))
+
+		for _, policyName := range policySet.Policies {
+			if selected[policyName] {
+				setPolicies = append(setPolicies, policyName)
+			}
+		}
+
+		if len(setPolicies) == 0 {
+			continue
+		}
+
+		policySet.Policies = setPolicies
+		policySets = append(policySets, policySet)
+	}
+
+	p.PolicySets = policySets
+
+	return nil
+}
+
 // Generate generates the policies, placements, and placement bindings and returns them as
-// a single YAML file as a byte array. An error is returned if they cannot be created.
+// a single YAML file as a byte array. An error is returned if they cannot be created, which has
+// the ErrGeneration or ErrManifestRead class.
 func (p *Plugin) Generate() ([]byte, error) {
+	output, err := p.generate()
+	if err != nil {
+		return nil, withErrorClass(err, ErrGeneration)
+	}
+
+	return output, nil
+}
+
+// generate implements Generate.
+func (p *Plugin) generate() ([]byte, error) {
 	// Set the default empty values to the fields that track state
 	p.allPlcs = map[string]bool{}
 	p.csToPlc = map[string]string{}
 	p.outputBuffer = bytes.Buffer{}
 	p.processedPlcs = map[string]bool{}
+	p.bindingPlcRefs = map[string]*types.PlacementRef{}
+	p.refPlcs = map[string]bool{}
+	p.warnings = nil
+	p.previousPolicyName = ""
+	p.generatedAt = time.Now().UTC()
+	p.policyTemplates = map[string][]map[string]interface{}{}
+
+	// The derived labels are needed to determine which placement bindings get the binding overrides
+	err := p.setDerivedLabels()
+	if err != nil {
+		return nil, err
+	}
 
-	for i := range p.Policies {
+	// The placements and placement bindings are generated before the policies so that their names are
+	// validated before the policies are generated, but they are output after the policies and policy sets
+	err = p.generatePlacements()
+	if err != nil {
+		return nil, err
+	}
+
+	placementOutput := bytes.Clone(p.outputBuffer.Bytes())
+	p.outputBuffer.Reset()
+
+	// The policies are generated after the policies in their after lists
+	policyOrder, err := sortPoliciesByAfter(p.Policies)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, i := range policyOrder {
 		err := p.createPolicy(&p.Policies[i])
 		if err != nil {
 			return nil, err
@@ -142,6 +428,19 @@ func (p *Plugin) Generate() ([]byte, error) {
 		}
 	}
 
+	if p.scaffoldOnly {
+		p.outputBuffer.Reset()
+	}
+
+	p.outputBuffer.Write(placementOutput)
+
+	return p.outputBuffer.Bytes(), nil
+}
+
+// generatePlacements writes the placements of the policies and policy sets and the placement bindings
+// that bind them to the output buffer. The names of all the placement bindings are determined before
+// any is written so that an error is returned if a placement binding name is generated more than once.
+func (p *Plugin) generatePlacements() error {
 	// Keep track of which placement maps to which policy and policySet. This will be used to determine
 	// how many placement bindings are required since one binding per placement is required.
 	// plcNameToPolicyAndSetIdxs[plcName]["policy"] stores the index of policy
@@ -155,7 +454,12 @@ func (p *Plugin) Generate() ([]byte, error) {
 			(p.Policies[i].GeneratePolicyPlacement && len(p.Policies[i].PolicySets) == 0) {
 			plcName, err := p.createPolicyPlacement(p.Policies[i].Placement, p.Policies[i].Name)
 			if err != nil {
-				return nil, err
+				return err
+			}
+
+			err = p.setBindingPlcRef(plcName, p.Policies[i].Placement.BindingPlacementRef)
+			if err != nil {
+				return err
 			}
 
 			if plcNameToPolicyAndSetIdxs[plcName] == nil {
@@ -171,7 +475,12 @@ func (p *Plugin) Generate() ([]byte, error) {
 		if p.PolicySets[i].GeneratePolicySetPlacement {
 			plcName, err := p.createPolicySetPlacement(p.PolicySets[i].Placement, p.PolicySets[i].Name)
 			if err != nil {
-				return nil, err
+				return err
+			}
+
+			err = p.setBindingPlcRef(plcName, p.PolicySets[i].Placement.BindingPlacementRef)
+			if err != nil {
+				return err
 			}
 
 			if plcNameToPolicyAndSetIdxs[plcName] == nil {
@@ -195,6 +504,11 @@ func (p *Plugin) Generate() ([]byte, error) {
 	sort.Strings(plcNames)
 
 	plcBindingCount := 0
+	bindings := []plannedBinding{}
+	// The placement name of each planned placement binding name
+	bindingPlcNames := map[string]string{}
+	// The number of placement bindings with each name rendered from the name template
+	renderedBindingNames := map[string]int{}
 
 	for _, plcName := range plcNames {
 		// Determine which policies and policy sets to be included in the placement binding.
@@ -208,47 +522,304 @@ func (p *Plugin) Generate() ([]byte, error) {
 			policySetConfs = append(policySetConfs, &p.PolicySets[i])
 		}
 
+		// Split the subjects across multiple placement bindings when there are more than the maximum or
+		// when only some of the policies get the binding overrides
+		subjectGroups := p.groupBindingSubjects(policyConfs, policySetConfs)
+
 		// If there is more than one policy associated with a placement but no default binding name
 		// specified, throw an error
-		if (len(policyConfs) > 1 || len(policySetConfs) > 1) && p.PlacementBindingDefaults.Name == "" {
-			return nil, fmt.Errorf(
+		if (len(policyConfs) > 1 || len(policySetConfs) > 1 || len(subjectGroups) > 1) &&
+			p.PlacementBindingDefaults.Name == "" && p.PlacementBindingDefaults.NameTemplate == "" {
+			return fmt.Errorf(
 				"placementBindingDefaults.name must be set but is empty (multiple policies or policy sets were found "+
 					"for the PlacementBinding to placement %s)",
 				plcName,
 			)
 		}
 
-		var bindingName string
+		for _, subjects := range subjectGroups {
+			var bindingName string
 
-		existMultiple := false
+			existMultiple := len(subjectGroups) > 1
 
-		// If there is only one policy or one policy set, use the policy or policy set name if there is no default
-		// binding name specified
-		if len(policyConfs) == 1 && len(policySetConfs) == 0 {
-			bindingName = "binding-" + policyConfs[0].Name
-		} else if len(policyConfs) == 0 && len(policySetConfs) == 0 {
-			bindingName = "binding-" + policySetConfs[0].Name
-		} else {
-			existMultiple = true
-		}
-		// If there are multiple policies or policy sets, use the default placement binding name
-		// but append a number to it so it's a unique name.
-		if p.PlacementBindingDefaults.Name != "" && existMultiple {
-			plcBindingCount++
-			if plcBindingCount == 1 {
-				bindingName = p.PlacementBindingDefaults.Name
-			} else {
-				bindingName = fmt.Sprintf("%s%d", p.PlacementBindingDefaults.Name, plcBindingCount)
+			// If there is only one policy, use the policy name. If there is only one policy set, use the policy
+			// set name if there is no default binding name specified since the default binding name has
+			// historically been used for policy sets.
+			switch {
+			case p.PlacementBindingDefaults.NameTemplate != "":
+				var err error
+
+				bindingName, err = renderBindingName(p.PlacementBindingDefaults.NameTemplate, plcName, subjects)
+				if err != nil {
+					return err
+				}
+
+				// Append a number to the rendered name when it's already used so it's a unique name
+				renderedBindingNames[bindingName]++
+				if count := renderedBindingNames[bindingName]; count > 1 {
+					bindingName = fmt.Sprintf("%s%d", bindingName, count)
+				}
+
+				existMultiple = false
+			case existMultiple:
+				// The subjects were split across placement bindings, so they all use the default binding name
+			case len(subjects.policyConfs) == 1 && len(subjects.policySetConfs) == 0:
+				bindingName = "binding-" + subjects.policyConfs[0].Name
+			case len(subjects.policyConfs) == 0 && len(subjects.policySetConfs) == 1 &&
+				p.PlacementBindingDefaults.Name == "":
+				bindingName = "binding-" + subjects.policySetConfs[0].Name
+			default:
+				existMultiple = true
+			}
+			// If there are multiple policies or policy sets, use the default placement binding name
+			// but append a number to it so it's a unique name.
+			if p.PlacementBindingDefaults.Name != "" && existMultiple {
+				plcBindingCount++
+				if plcBindingCount == 1 {
+					bindingName = p.PlacementBindingDefaults.Name
+				} else {
+					bindingName = fmt.Sprintf("%s%d", p.PlacementBindingDefaults.Name, plcBindingCount)
+				}
+			}
+
+			names := []string{bindingName}
+
+			overrides := p.PlacementBindingDefaults.BindingOverrides
+			if overrides != nil && overrides.SubFilter != "" &&
+				p.bindingOverridesApply(subjects.policyConfs, subjects.policySetConfs) {
+				names = append(names, getRestrictedBindingName(bindingName))
 			}
+
+			for _, name := range names {
+				if otherPlcName, ok := bindingPlcNames[name]; ok {
+					return wrapSentinel(fmt.Errorf(
+						"a duplicate placement binding name was detected: %s is generated for the placements %s and "+
+							"%s; set placementBindingDefaults.name or rename the policies and policy sets so that the "+
+							"placement binding names are unique",
+						name, otherPlcName, plcName,
+					), ErrDuplicatePlacementBinding)
+				}
+
+				bindingPlcNames[name] = plcName
+			}
+
+			bindings = append(bindings, plannedBinding{name: bindingName, plcName: plcName, subjects: subjects})
 		}
+	}
 
-		err := p.createPlacementBinding(bindingName, plcName, policyConfs, policySetConfs)
+	for _, binding := range bindings {
+		err := p.createPlacementBinding(
+			binding.name, binding.plcName, binding.subjects.policyConfs, binding.subjects.policySetConfs,
+		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create a placement binding: %w", err)
+			return fmt.Errorf("failed to create a placement binding: %w", err)
 		}
 	}
 
-	return p.outputBuffer.Bytes(), nil
+	return nil
+}
+
+// plannedBinding is a placement binding to generate with the name determined by generatePlacements.
+type plannedBinding struct {
+	name     string
+	plcName  string
+	subjects bindingSubjects
+}
+
+// bindingSubjects are the policies and policy sets that are the subjects of a placement binding.
+type bindingSubjects struct {
+	policyConfs    []*types.PolicyConfig
+	policySetConfs []*types.PolicySetConfig
+}
+
+// assertValidBindingOverrides verifies that the input binding overrides set a supported
+// remediationAction or subFilter and that the policy label, if set, is a valid label.
+func assertValidBindingOverrides(overrides *types.BindingOverrides) error {
+	if overrides.RemediationAction == "" && overrides.SubFilter == "" {
+		return errors.New("remediationAction or subFilter must be set")
+	}
+
+	if overrides.RemediationAction != "" && !strings.EqualFold(overrides.RemediationAction, "enforce") {
+		return fmt.Errorf("the remediationAction `%s` is invalid: it must be enforce", overrides.RemediationAction)
+	}
+
+	if overrides.SubFilter != "" && !strings.EqualFold(overrides.SubFilter, "restricted") {
+		return fmt.Errorf("the subFilter `%s` is invalid: it must be restricted", overrides.SubFilter)
+	}
+
+	if overrides.PolicyLabel != "" {
+		key, value, _ := strings.Cut(overrides.PolicyLabel, "=")
+
+		if len(validation.IsQualifiedName(key)) != 0 || len(validation.IsValidLabelValue(value)) != 0 {
+			return fmt.Errorf(
+				"the policyLabel `%s` is invalid: it must be a label key or key=value", overrides.PolicyLabel,
+			)
+		}
+	}
+
+	return nil
+}
+
+// groupBindingSubjects returns the groups of subjects of the placement bindings to a placement. When
+// the binding overrides only apply to policies with a label, the policies with the label are grouped
+// separately from the other policies and the policy sets so that only their placement bindings get
+// the binding overrides.
+func (p *Plugin) groupBindingSubjects(
+	policyConfs []*types.PolicyConfig, policySetConfs []*types.PolicySetConfig,
+) []bindingSubjects {
+	maxSubjects := p.PlacementBindingDefaults.MaxSubjectsPerBinding
+	overrides := p.PlacementBindingDefaults.BindingOverrides
+
+	if overrides == nil || overrides.PolicyLabel == "" {
+		return splitBindingSubjects(policyConfs, policySetConfs, maxSubjects)
+	}
+
+	labeledPolicyConfs := []*types.PolicyConfig{}
+	otherPolicyConfs := []*types.PolicyConfig{}
+
+	for _, policyConf := range policyConfs {
+		if hasPolicyLabel(policyConf, overrides.PolicyLabel) {
+			labeledPolicyConfs = append(labeledPolicyConfs, policyConf)
+		} else {
+			otherPolicyConfs = append(otherPolicyConfs, policyConf)
+		}
+	}
+
+	groups := []bindingSubjects{}
+
+	if len(labeledPolicyConfs) != 0 {
+		groups = append(groups, splitBindingSubjects(labeledPolicyConfs, nil, maxSubjects)...)
+	}
+
+	if len(otherPolicyConfs) != 0 || len(policySetConfs) != 0 {
+		groups = append(groups, splitBindingSubjects(otherPolicyConfs, policySetConfs, maxSubjects)...)
+	}
+
+	return groups
+}
+
+// hasPolicyLabel returns true if the input policy has the input label, which is in the format of key
+// or key=value.
+func hasPolicyLabel(policyConf *types.PolicyConfig, label string) bool {
+	key, value, hasValue := strings.Cut(label, "=")
+
+	policyValue, found := policyConf.PolicyLabels[key]
+	if !found {
+		return false
+	}
+
+	return !hasValue || policyValue == value
+}
+
+// splitBindingSubjects splits the input policies and policy sets into groups of at most maxSubjects
+// subjects, with the policies before the policy sets. If maxSubjects is 0, a single group is returned.
+func splitBindingSubjects(
+	policyConfs []*types.PolicyConfig, policySetConfs []*types.PolicySetConfig, maxSubjects int,
+) []bindingSubjects {
+	if maxSubjects <= 0 || len(policyConfs)+len(policySetConfs) <= maxSubjects {
+		return []bindingSubjects{{policyConfs: policyConfs, policySetConfs: policySetConfs}}
+	}
+
+	groups := []bindingSubjects{}
+	group := bindingSubjects{}
+
+	for _, policyConf := range policyConfs {
+		if len(group.policyConfs) == maxSubjects {
+			groups = append(groups, group)
+			group = bindingSubjects{}
+		}
+
+		group.policyConfs = append(group.policyConfs, policyConf)
+	}
+
+	for _, policySetConf := range policySetConfs {
+		if len(group.policyConfs)+len(group.policySetConfs) == maxSubjects {
+			groups = append(groups, group)
+			group = bindingSubjects{}
+		}
+
+		group.policySetConfs = append(group.policySetConfs, policySetConf)
+	}
+
+	return append(groups, group)
+}
+
+// Warnings returns the warnings about likely mistakes in the configuration that don't prevent the
+// policies from being generated, such as an objectSelector that has no effect. This should be run
+// after Generate.
+func (p *Plugin) Warnings() []string {
+	return p.warnings
+}
+
+// InputPaths returns the sorted and deduplicated file paths that the PolicyGenerator configuration
+// reads from, such as manifest, OpenAPI schema, placement, imported PolicyGenerator, and values
+// file paths. This should be run after Config.
+func (p *Plugin) InputPaths() []string {
+	paths := map[string]bool{}
+
+	addPlacementPaths := func(placement types.PlacementConfig) {
+		paths[placement.PlacementPath] = true
+		paths[placement.PlacementRulePath] = true
+	}
+
+	addPlacementPaths(p.PolicyDefaults.Placement)
+	addPlacementPaths(p.PolicySetDefaults.Placement)
+
+	for _, placement := range p.Placements {
+		addPlacementPaths(placement)
+	}
+
+	for _, importPath := range p.importPaths {
+		paths[importPath] = true
+	}
+
+	if p.substitution != nil {
+		paths[p.substitution.ValuesPath] = true
+	}
+
+	for i := range p.Policies {
+		addPlacementPaths(p.Policies[i].Placement)
+
+		for _, manifest := range p.Policies[i].Manifests {
+			paths[manifest.Path] = true
+			paths[manifest.OpenAPI.Path] = true
+
+			for _, filePath := range manifest.FromFiles {
+				paths[filePath] = true
+			}
+
+			if manifest.ConfigMapGenerator != nil {
+				for _, file := range manifest.ConfigMapGenerator.Files {
+					if _, filePath, err := parseConfigMapFile(file); err == nil {
+						paths[filePath] = true
+					}
+				}
+			}
+
+			if manifest.PerCluster != nil {
+				paths[manifest.PerCluster.Path] = true
+			}
+		}
+	}
+
+	for i := range p.PolicySets {
+		addPlacementPaths(p.PolicySets[i].Placement)
+	}
+
+	for _, plcPath := range p.selectedPlcPaths {
+		paths[plcPath] = true
+	}
+
+	delete(paths, "")
+
+	sortedPaths := make([]string, 0, len(paths))
+	for path := range paths {
+		sortedPaths = append(sortedPaths, path)
+	}
+
+	sort.Strings(sortedPaths)
+
+	return sortedPaths
 }
 
 func getPolicyDefaultBool(config map[string]interface{}, key string) (value bool, set bool) {
@@ -495,6 +1066,14 @@ func (p *Plugin) applyDefaults(unmarshaledConfig map[string]interface{}) {
 		p.PolicyDefaults.RemediationAction = defaults.RemediationAction
 	}
 
+	// Normalize the casing of the remediationAction values unless non-canonical values are an error
+	normalizeAction := normalizeRemediationAction
+	if p.PolicyDefaults.StrictRemediationAction {
+		normalizeAction = func(action string) string { return action }
+	}
+
+	p.PolicyDefaults.RemediationAction = normalizeAction(p.PolicyDefaults.RemediationAction)
+
 	if p.PolicyDefaults.Severity == "" {
 		p.PolicyDefaults.Severity = defaults.Severity
 	}
@@ -503,8 +1082,16 @@ func (p *Plugin) applyDefaults(unmarshaledConfig map[string]interface{}) {
 		p.PolicyDefaults.Standards = defaults.Standards
 	}
 
-	// GeneratePolicyPlacement defaults to true unless explicitly set in the config.
-	gppValue, setGpp := getPolicyDefaultBool(unmarshaledConfig, "generatePolicyPlacement")
+	if p.PolicyDefaults.PolicyAPIVersion == "" {
+		p.PolicyDefaults.PolicyAPIVersion = policyAPIVersion
+	}
+
+	if p.PolicyDefaults.YAMLIndent == 0 {
+		p.PolicyDefaults.YAMLIndent = defaultYAMLIndent
+	}
+
+	// GeneratePolicyPlacement defaults to true unless explicitly set in the config.
+	gppValue, setGpp := getPolicyDefaultBool(unmarshaledConfig, "generatePolicyPlacement")
 	if setGpp {
 		p.PolicyDefaults.GeneratePolicyPlacement = gppValue
 	} else {
@@ -559,6 +1146,14 @@ func (p *Plugin) applyDefaults(unmarshaledConfig map[string]interface{}) {
 			policy.Categories = p.PolicyDefaults.Categories
 		}
 
+		if policy.Finalizers == nil {
+			policy.Finalizers = p.PolicyDefaults.Finalizers
+		}
+
+		if policy.DeriveLabelsFrom == nil {
+			policy.DeriveLabelsFrom = p.PolicyDefaults.DeriveLabelsFrom
+		}
+
 		if policy.ConfigurationPolicyAnnotations == nil {
 			annotations := map[string]string{}
 			for k, v := range p.PolicyDefaults.ConfigurationPolicyAnnotations {
@@ -568,6 +1163,15 @@ func (p *Plugin) applyDefaults(unmarshaledConfig map[string]interface{}) {
 			policy.ConfigurationPolicyAnnotations = annotations
 		}
 
+		if policy.ConfigurationPolicyLabels == nil {
+			labels := map[string]string{}
+			for k, v := range p.PolicyDefaults.ConfigurationPolicyLabels {
+				labels[k] = v
+			}
+
+			policy.ConfigurationPolicyLabels = labels
+		}
+
 		cpmValue, setCpm := getPolicyBool(unmarshaledConfig, i, "copyPolicyMetadata")
 		if setCpm {
 			policy.CopyPolicyMetadata = cpmValue
@@ -575,10 +1179,67 @@ func (p *Plugin) applyDefaults(unmarshaledConfig map[string]interface{}) {
 			policy.CopyPolicyMetadata = p.PolicyDefaults.CopyPolicyMetadata
 		}
 
+		aepValue, setAep := getPolicyBool(unmarshaledConfig, i, "alwaysEmitPruneBehavior")
+		if setAep {
+			policy.AlwaysEmitPruneBehavior = aepValue
+		} else {
+			policy.AlwaysEmitPruneBehavior = p.PolicyDefaults.AlwaysEmitPruneBehavior
+		}
+
+		ccaValue, setCca := getPolicyBool(unmarshaledConfig, i, "contentChecksumAnnotation")
+		if setCca {
+			policy.ContentChecksumAnnotation = ccaValue
+		} else {
+			policy.ContentChecksumAnnotation = p.PolicyDefaults.ContentChecksumAnnotation
+		}
+
+		ckoValue, setCko := getPolicyBool(unmarshaledConfig, i, "canonicalKeyOrder")
+		if setCko {
+			policy.CanonicalKeyOrder = ckoValue
+		} else {
+			policy.CanonicalKeyOrder = p.PolicyDefaults.CanonicalKeyOrder
+		}
+
+		pcValue, setPc := getPolicyBool(unmarshaledConfig, i, "preserveComments")
+		if setPc {
+			policy.PreserveComments = pcValue
+		} else {
+			policy.PreserveComments = p.PolicyDefaults.PreserveComments
+		}
+
+		adoValue, setAdo := getPolicyBool(unmarshaledConfig, i, "allowDuplicateObjects")
+		if setAdo {
+			policy.AllowDuplicateObjects = adoValue
+		} else {
+			policy.AllowDuplicateObjects = p.PolicyDefaults.AllowDuplicateObjects
+		}
+
+		doValue, setDo := getPolicyBool(unmarshaledConfig, i, "deduplicateObjects")
+		if setDo {
+			policy.DeduplicateObjects = doValue
+		} else {
+			policy.DeduplicateObjects = p.PolicyDefaults.DeduplicateObjects
+		}
+
+		spValue, setSp := getPolicyBool(unmarshaledConfig, i, "strictPatches")
+		if setSp {
+			policy.StrictPatches = spValue
+		} else {
+			policy.StrictPatches = p.PolicyDefaults.StrictPatches
+		}
+
 		if policy.Standards == nil {
 			policy.Standards = p.PolicyDefaults.Standards
 		}
 
+		if policy.AllowedKinds == nil {
+			policy.AllowedKinds = p.PolicyDefaults.AllowedKinds
+		}
+
+		if policy.DisallowedKinds == nil {
+			policy.DisallowedKinds = p.PolicyDefaults.DisallowedKinds
+		}
+
 		if policy.Controls == nil {
 			policy.Controls = p.PolicyDefaults.Controls
 		}
@@ -591,8 +1252,20 @@ func (p *Plugin) applyDefaults(unmarshaledConfig map[string]interface{}) {
 			policy.RecreateOption = p.PolicyDefaults.RecreateOption
 		}
 
+		policySets := policy.PolicySets
+		if policySets == nil {
+			policySets = p.PolicyDefaults.PolicySets
+		}
+
+		// Policies in a policy set prefer the policySetDefaults values over the policyDefaults values
+		inPolicySet := len(policySets) != 0 || len(plcToPlcset[policy.Name]) != 0
+
 		if policy.RecordDiff == "" {
-			policy.RecordDiff = p.PolicyDefaults.RecordDiff
+			if inPolicySet && p.PolicySetDefaults.RecordDiff != "" {
+				policy.RecordDiff = p.PolicySetDefaults.RecordDiff
+			} else {
+				policy.RecordDiff = p.PolicyDefaults.RecordDiff
+			}
 		}
 
 		if policy.ComplianceType == "" {
@@ -622,18 +1295,30 @@ func (p *Plugin) applyDefaults(unmarshaledConfig map[string]interface{}) {
 			}
 		}
 
-		// Only use the policyDefault customMessage value when it's not explicitly set on the policy.
+		// Only use the policyDefault or, for policies in a policy set, the policySetDefault customMessage
+		// value when it's not explicitly set on the policy.
+		defaultCustomMessage := p.PolicyDefaults.CustomMessage
+		if inPolicySet {
+			if p.PolicySetDefaults.CustomMessage.Compliant != "" {
+				defaultCustomMessage.Compliant = p.PolicySetDefaults.CustomMessage.Compliant
+			}
+
+			if p.PolicySetDefaults.CustomMessage.NonCompliant != "" {
+				defaultCustomMessage.NonCompliant = p.PolicySetDefaults.CustomMessage.NonCompliant
+			}
+		}
+
 		if policy.CustomMessage.Compliant == "" {
 			set := isCustomMessageSet(unmarshaledConfig, i, "compliant")
 			if !set {
-				policy.CustomMessage.Compliant = p.PolicyDefaults.CustomMessage.Compliant
+				policy.CustomMessage.Compliant = defaultCustomMessage.Compliant
 			}
 		}
 
 		if policy.CustomMessage.NonCompliant == "" {
 			set := isCustomMessageSet(unmarshaledConfig, i, "noncompliant")
 			if !set {
-				policy.CustomMessage.NonCompliant = p.PolicyDefaults.CustomMessage.NonCompliant
+				policy.CustomMessage.NonCompliant = defaultCustomMessage.NonCompliant
 			}
 		}
 
@@ -677,6 +1362,10 @@ func (p *Plugin) applyDefaults(unmarshaledConfig map[string]interface{}) {
 			policy.InformKyvernoPolicies = p.PolicyDefaults.InformKyvernoPolicies
 		}
 
+		if !isPolicyFieldSet(unmarshaledConfig, i, "kyvernoExpanderOptions") {
+			policy.KyvernoExpanderOptions = p.PolicyDefaults.KyvernoExpanderOptions
+		}
+
 		if !isPolicyFieldSet(unmarshaledConfig, i, "orderManifests") {
 			policy.OrderManifests = p.PolicyDefaults.OrderManifests
 		}
@@ -690,9 +1379,15 @@ func (p *Plugin) applyDefaults(unmarshaledConfig map[string]interface{}) {
 			policy.ConsolidateManifests = p.PolicyDefaults.ConsolidateManifests
 		}
 
+		if policy.DisableFromManifestAnnotation == "" {
+			policy.DisableFromManifestAnnotation = p.PolicyDefaults.DisableFromManifestAnnotation
+		}
+
 		disabledValue, setDisabled := getPolicyBool(unmarshaledConfig, i, "disabled")
 		if setDisabled {
 			policy.Disabled = disabledValue
+			// An explicit disabled value takes precedence over the manifest annotation
+			policy.DisableFromManifestAnnotation = ""
 		} else {
 			policy.Disabled = p.PolicyDefaults.Disabled
 		}
@@ -727,10 +1422,21 @@ func (p *Plugin) applyDefaults(unmarshaledConfig map[string]interface{}) {
 			policy.NamespaceSelector = defNsSelector
 		}
 
+		// Only use defaults when the objectSelector is not set on the policy
+		if policy.ObjectSelector.IsUnset() {
+			policy.ObjectSelector = p.PolicyDefaults.ObjectSelector
+		}
+
 		if policy.RemediationAction == "" {
 			policy.RemediationAction = p.PolicyDefaults.RemediationAction
 		}
 
+		policy.RemediationAction = normalizeAction(policy.RemediationAction)
+
+		if policy.Severity == "" {
+			policy.Severity = getSeverityByControl(policy.Controls, p.PolicyDefaults.SeverityByControl)
+		}
+
 		if policy.Severity == "" {
 			policy.Severity = p.PolicyDefaults.Severity
 		}
@@ -766,6 +1472,17 @@ func (p *Plugin) applyDefaults(unmarshaledConfig map[string]interface{}) {
 				}
 			}
 
+			if manifest.ConfigurationPolicyLabels == nil {
+				manifest.ConfigurationPolicyLabels = policy.ConfigurationPolicyLabels
+			}
+
+			// Hub templates can only access the namespaced objects in the namespace of the policy. The lookup
+			// function may leave the namespace empty for a cluster scoped object.
+			hubTemplate := manifest.HubTemplate
+			if hubTemplate != nil && hubTemplate.Namespace == "" && hubTemplate.Function != "lookup" {
+				hubTemplate.Namespace = p.PolicyDefaults.Namespace
+			}
+
 			if manifest.CustomMessage.Compliant == "" {
 				set := isCustomMessageSetManifest(unmarshaledConfig, i, j, "compliant")
 				if !set {
@@ -786,10 +1503,16 @@ func (p *Plugin) applyDefaults(unmarshaledConfig map[string]interface{}) {
 				manifest.NamespaceSelector = policy.NamespaceSelector
 			}
 
+			if manifest.ObjectSelector.IsUnset() {
+				manifest.ObjectSelector = policy.ObjectSelector
+			}
+
 			if manifest.RemediationAction == "" && policy.RemediationAction != "" {
 				manifest.RemediationAction = policy.RemediationAction
 			}
 
+			manifest.RemediationAction = normalizeAction(manifest.RemediationAction)
+
 			if manifest.PruneObjectBehavior == "" && policy.PruneObjectBehavior != "" {
 				manifest.PruneObjectBehavior = policy.PruneObjectBehavior
 			}
@@ -856,6 +1579,7 @@ func (p *Plugin) applyDefaults(unmarshaledConfig map[string]interface{}) {
 	// Sync up the declared policy sets in p.Policies[*]
 	for i := range p.PolicySets {
 		plcset := &p.PolicySets[i]
+		declaredPolicies := plcset.Policies
 		plcset.Policies = make([]string, 0, len(plcsetToPlc[plcset.Name]))
 
 		for plc := range plcsetToPlc[plcset.Name] {
@@ -874,7 +1598,60 @@ func (p *Plugin) applyDefaults(unmarshaledConfig map[string]interface{}) {
 
 		// Sort alphabetically to make it deterministic
 		sort.Strings(plcset.Policies)
+
+		if p.PolicySetDefaults.PreservePolicyOrder {
+			plcset.Policies = preservePolicyOrder(declaredPolicies, plcset.Policies)
+		}
+
+		// An explicit member order only applies to this policy set and takes precedence over the other orderings
+		if len(plcset.OrderedPolicies) != 0 {
+			plcset.Policies = preservePolicyOrder(plcset.OrderedPolicies, plcset.Policies)
+		}
+	}
+
+	// When explicitly set, bindPoliciesIndividually takes precedence over
+	// policyDefaults.generatePlacementWhenInSet for the policies in a policy set, but not over the value set on
+	// the policy itself.
+	bpiValue, setBpi := getPolicySetDefaultBool(unmarshaledConfig, "bindPoliciesIndividually")
+	if setBpi {
+		for i := range p.Policies {
+			if len(p.Policies[i].PolicySets) == 0 {
+				continue
+			}
+
+			if _, setGpset := getPolicyBool(unmarshaledConfig, i, "generatePlacementWhenInSet"); !setGpset {
+				p.Policies[i].GeneratePlacementWhenInSet = bpiValue
+			}
+		}
+	}
+}
+
+// preservePolicyOrder returns the policies of a policy set with the policies explicitly declared in
+// the policy set first in their declared order, followed by the rest of the sorted policies that
+// joined the policy set through policies[*].policySets.
+func preservePolicyOrder(declaredPolicies []string, sortedPolicies []string) []string {
+	ordered := make([]string, 0, len(sortedPolicies))
+	added := make(map[string]bool, len(sortedPolicies))
+	members := make(map[string]bool, len(sortedPolicies))
+
+	for _, plc := range sortedPolicies {
+		members[plc] = true
+	}
+
+	for _, plc := range declaredPolicies {
+		if members[plc] && !added[plc] {
+			ordered = append(ordered, plc)
+			added[plc] = true
+		}
+	}
+
+	for _, plc := range sortedPolicies {
+		if !added[plc] {
+			ordered = append(ordered, plc)
+		}
 	}
+
+	return ordered
 }
 
 func applyDefaultDependencyFields(deps []types.PolicyDependency, namespace string) {
@@ -897,211 +1674,1264 @@ func applyDefaultDependencyFields(deps []types.PolicyDependency, namespace strin
 	}
 }
 
-// applyDefaultPlacementFields is a helper for applyDefaults that handles default Placement configuration
-func applyDefaultPlacementFields(placement *types.PlacementConfig, defaultPlacement types.PlacementConfig) {
-	// Determine whether defaults are set for placement
-	plcDefaultSet := len(defaultPlacement.LabelSelector) != 0 ||
-		defaultPlacement.PlacementPath != "" ||
-		defaultPlacement.PlacementName != ""
-	plrDefaultSet := len(defaultPlacement.ClusterSelectors) != 0 ||
-		len(defaultPlacement.ClusterSelector) != 0 ||
-		defaultPlacement.PlacementRulePath != "" ||
-		defaultPlacement.PlacementRuleName != ""
-	policyPlcUnset := len(placement.LabelSelector) == 0 &&
-		placement.PlacementPath == "" &&
-		placement.PlacementName == ""
-	policyPlrUnset := len(placement.ClusterSelectors) == 0 &&
-		len(placement.ClusterSelector) == 0 &&
-		placement.PlacementRulePath == "" &&
-		placement.PlacementRuleName == ""
+// isValidDependencyNamespace determines if the namespace of the dependency, if set, is a valid
+// namespace name. The namespace may reference a policy in a namespace other than
+// policyDefaults.namespace.
+func isValidDependencyNamespace(dep types.PolicyDependency) bool {
+	return dep.Namespace == "" || len(validation.IsDNS1123Label(dep.Namespace)) == 0
+}
 
-	// If both cluster label selectors and placement path/name aren't set, then use the defaults with a
-	// priority on placement path followed by placement name.
-	if policyPlcUnset && plcDefaultSet {
-		if !policyPlrUnset {
-			return
-		} else if defaultPlacement.PlacementPath != "" {
-			placement.PlacementPath = defaultPlacement.PlacementPath
-		} else if defaultPlacement.PlacementName != "" {
-			placement.PlacementName = defaultPlacement.PlacementName
-		} else if len(defaultPlacement.LabelSelector) > 0 {
-			placement.LabelSelector = defaultPlacement.LabelSelector
-		}
-	} else if policyPlrUnset && plrDefaultSet {
-		// Else if both cluster selectors and placement rule path/name aren't set, then use the defaults with a
-		// priority on placement rule path followed by placement rule name.
-		if !policyPlcUnset {
-			return
-		} else if defaultPlacement.PlacementRulePath != "" {
-			placement.PlacementRulePath = defaultPlacement.PlacementRulePath
-		} else if defaultPlacement.PlacementRuleName != "" {
-			placement.PlacementRuleName = defaultPlacement.PlacementRuleName
-		} else if len(defaultPlacement.ClusterSelectors) > 0 {
-			placement.ClusterSelectors = defaultPlacement.ClusterSelectors
-		} else if len(defaultPlacement.ClusterSelector) > 0 {
-			placement.ClusterSelector = defaultPlacement.ClusterSelector
+// resolvePlacementRefs merges the named placements in placements into the placements that reference
+// them with placement.ref, using the same semantics as applying the default placement. The
+// placement is named after the named placement so that all the policies and policy sets that
+// reference it share a single generated placement. Note that this must be run before applyDefaults.
+func (p *Plugin) resolvePlacementRefs() error {
+	for key, named := range p.Placements {
+		if named.Ref != "" {
+			return fmt.Errorf("placements.%s may not set ref", key)
 		}
 	}
-}
 
-// assertValidConfig verifies that the user provided configuration has all the
-// required fields. Note that this should be run only after applyDefaults is run.
-func (p *Plugin) assertValidConfig() error {
-	if p.PolicyDefaults.Namespace == "" {
-		return errors.New("policyDefaults.namespace is empty but it must be set")
-	}
+	resolve := func(placement *types.PlacementConfig, path string) error {
+		if placement.Ref == "" {
+			return nil
+		}
 
-	// Validate default policy placement settings
-	err := p.assertValidPlacement(p.PolicyDefaults.Placement, "policyDefaults", nil)
-	if err != nil {
-		return err
-	}
+		named, ok := p.Placements[placement.Ref]
+		if !ok {
+			return fmt.Errorf("%s placement.ref `%s` is not defined in placements", path, placement.Ref)
+		}
 
-	// validate placement binding names are DNS compliant
-	if p.PlacementBindingDefaults.Name != "" &&
-		len(validation.IsDNS1123Subdomain(p.PlacementBindingDefaults.Name)) > 0 {
-		return fmt.Errorf(
-			"PlacementBindingDefaults.Name `%s` is not DNS compliant. See %s",
-			p.PlacementBindingDefaults.Name,
-			dnsReference,
-		)
-	}
+		if placement.AllClusters ||
+			len(placement.LabelSelector) != 0 ||
+			len(placement.ClusterSelectors) != 0 ||
+			len(placement.ClusterSelector) != 0 ||
+			len(placement.CelExpressions) != 0 ||
+			placement.ClusterVersion != "" ||
+			len(placement.Predicates) != 0 ||
+			placement.PlacementPath != "" ||
+			placement.PlacementRulePath != "" ||
+			placement.PlacementName != "" ||
+			placement.PlacementRuleName != "" {
+			return fmt.Errorf(
+				"%s placement.ref may not be set with a placement selector, placement path, or placement name", path,
+			)
+		}
 
-	if len(p.Policies) == 0 {
-		return errors.New("policies is empty but it must be set")
-	}
+		applyDefaultPlacementFields(placement, named)
 
-	if p.PolicyDefaults.OrderPolicies && len(p.PolicyDefaults.Dependencies) != 0 {
-		return errors.New("policyDefaults must specify only one of dependencies or orderPolicies")
-	}
+		if placement.Name == "" {
+			placement.Name = named.Name
+		}
 
-	for i, dep := range p.PolicyDefaults.Dependencies {
-		if dep.Name == "" {
-			return fmt.Errorf("dependency name must be set in policyDefaults dependency %v", i)
+		if placement.Name == "" {
+			placement.Name = "placement-" + placement.Ref
 		}
-	}
 
-	if p.PolicyDefaults.OrderManifests && p.PolicyDefaults.ConsolidateManifests {
-		return errors.New("policyDefaults may not specify both consolidateManifests and orderManifests")
+		return nil
 	}
 
-	if len(p.PolicyDefaults.ExtraDependencies) > 0 && p.PolicyDefaults.OrderManifests {
-		return errors.New("policyDefaults may not specify both extraDependencies and orderManifests")
+	err := resolve(&p.PolicyDefaults.Placement, "policyDefaults")
+	if err != nil {
+		return err
 	}
 
-	for i, dep := range p.PolicyDefaults.ExtraDependencies {
-		if dep.Name == "" {
-			return fmt.Errorf("extraDependency name must be set in policyDefaults extraDependency %v", i)
-		}
+	err = resolve(&p.PolicySetDefaults.Placement, "policySetDefaults")
+	if err != nil {
+		return err
 	}
 
-	seenPlc := map[string]bool{}
-	plCount := struct {
-		plc int
-		plr int
-	}{}
-
 	for i := range p.Policies {
-		policy := &p.Policies[i]
-		if policy.Name == "" {
-			return fmt.Errorf(
-				"each policy must have a name set, but did not find a name at policy array index %d", i,
-			)
-		}
-
-		if len(validation.IsDNS1123Subdomain(policy.Name)) > 0 {
-			return fmt.Errorf(
-				"policy name `%s` is not DNS compliant. See %s", policy.Name, dnsReference,
-			)
+		err := resolve(&p.Policies[i].Placement, fmt.Sprintf("policy %s", p.Policies[i].Name))
+		if err != nil {
+			return err
 		}
+	}
 
-		if seenPlc[policy.Name] {
-			return fmt.Errorf(
-				"each policy must have a unique name set, but found a duplicate name: %s", policy.Name,
-			)
+	for i := range p.PolicySets {
+		err := resolve(&p.PolicySets[i].Placement, fmt.Sprintf("policySet %s", p.PolicySets[i].Name))
+		if err != nil {
+			return err
 		}
+	}
 
-		seenPlc[policy.Name] = true
+	return nil
+}
 
-		if len(p.PolicyDefaults.Namespace+"."+policy.Name) > maxObjectNameLength {
-			return fmt.Errorf("the policy namespace and name cannot be more than 63 characters: %s.%s",
-				p.PolicyDefaults.Namespace, policy.Name)
+// resolvePlacementSelectors sets the placementName of each placement that sets placementSelector to
+// the name of the single Placement manifest in placementsDir whose labels match the selector. The
+// placementSelector and placementsDir fields are then cleared so that the placement is handled as
+// an existing placement. Note that this must be run before resolvePlacementRefs so that named
+// placements are resolved before they are referenced.
+func (p *Plugin) resolvePlacementSelectors() error {
+	p.selectedPlcPaths = nil
+
+	resolve := func(placement *types.PlacementConfig, path string) error {
+		if len(placement.PlacementSelector) == 0 && placement.PlacementsDir == "" {
+			return nil
 		}
 
-		if policy.EvaluationInterval.Compliant != "" && policy.EvaluationInterval.Compliant != "never" {
-			_, err := time.ParseDuration(policy.EvaluationInterval.Compliant)
-			if err != nil {
-				return fmt.Errorf(
-					"the policy %s has an invalid policy.evaluationInterval.compliant value: %w", policy.Name, err,
-				)
-			}
+		if len(placement.PlacementSelector) == 0 {
+			return fmt.Errorf("%s placement.placementsDir may only be set with placement.placementSelector", path)
 		}
 
-		if policy.EvaluationInterval.NonCompliant != "" && policy.EvaluationInterval.NonCompliant != "never" {
-			_, err := time.ParseDuration(policy.EvaluationInterval.NonCompliant)
-			if err != nil {
-				return fmt.Errorf(
-					"the policy %s has an invalid policy.evaluationInterval.noncompliant value: %w", policy.Name, err,
-				)
-			}
+		if placement.PlacementsDir == "" {
+			return fmt.Errorf("%s placement.placementSelector must be set with placement.placementsDir", path)
 		}
 
-		if len(policy.Manifests) == 0 {
+		if placement.PlacementName != "" || placement.PlacementRuleName != "" {
 			return fmt.Errorf(
-				"each policy must have at least one manifest, but found none in policy %s", policy.Name,
+				"%s placement.placementSelector may not be set with placement.placementName or "+
+					"placement.placementRuleName",
+				path,
 			)
 		}
 
-		if len(policy.Dependencies) > 0 && p.PolicyDefaults.OrderPolicies {
-			return fmt.Errorf(
-				"dependencies may not be set in policy %v when policyDefaults.orderPolicies is true", policy.Name,
-			)
-		}
+		placementsDir := rewritePathPrefix(placement.PlacementsDir, p.pathPrefixMappings)
 
-		for x, dep := range policy.Dependencies {
-			if dep.Name == "" {
-				return fmt.Errorf("dependency name must be set in policy %v dependency %v", policy.Name, x)
-			}
+		name, plcPath, err := p.findPlacementBySelector(placementsDir, placement.PlacementSelector)
+		if err != nil {
+			return fmt.Errorf("%s placement.placementSelector could not be resolved: %w", path, err)
 		}
 
-		if policy.ConsolidateManifests && policy.OrderManifests {
-			return fmt.Errorf("policy %v may not set orderManifests when consolidateManifests is true", policy.Name)
-		}
+		placement.PlacementName = name
+		placement.PlacementSelector = nil
+		placement.PlacementsDir = ""
+		p.selectedPlcPaths = append(p.selectedPlcPaths, plcPath)
 
-		if len(policy.ExtraDependencies) > 0 && policy.OrderManifests {
-			return fmt.Errorf("extraDependencies may not be set in policy %v when orderManifests is true", policy.Name)
-		}
+		return nil
+	}
 
-		for x, dep := range policy.ExtraDependencies {
-			if dep.Name == "" {
-				return fmt.Errorf("extraDependency name must be set in policy %v extraDependency %v", policy.Name, x)
-			}
-		}
+	namedKeys := make([]string, 0, len(p.Placements))
+	for key := range p.Placements {
+		namedKeys = append(namedKeys, key)
+	}
 
-		for j := range policy.Manifests {
-			manifest := &policy.Manifests[j]
+	sort.Strings(namedKeys)
 
-			if manifest.Path == "" {
-				return fmt.Errorf(
+	for _, key := range namedKeys {
+		named := p.Placements[key]
+
+		err := resolve(&named, "placements."+key)
+		if err != nil {
+			return err
+		}
+
+		p.Placements[key] = named
+	}
+
+	err := resolve(&p.PolicyDefaults.Placement, "policyDefaults")
+	if err != nil {
+		return err
+	}
+
+	err = resolve(&p.PolicySetDefaults.Placement, "policySetDefaults")
+	if err != nil {
+		return err
+	}
+
+	for i := range p.Policies {
+		err := resolve(&p.Policies[i].Placement, fmt.Sprintf("policy %s", p.Policies[i].Name))
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range p.PolicySets {
+		err := resolve(&p.PolicySets[i].Placement, fmt.Sprintf("policySet %s", p.PolicySets[i].Name))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findPlacementBySelector returns the name and file path of the single Placement in the YAML files
+// of the input directory whose labels contain all the key-value pairs of the input selector.
+// Placements in a different namespace than the placement bindings are ignored. An error is returned
+// if the directory can't be read or if there isn't exactly one matching Placement.
+func (p *Plugin) findPlacementBySelector(placementsDir string, selector map[string]string) (string, string, error) {
+	files, err := os.ReadDir(placementsDir)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read the placementsDir %s: %w", placementsDir, err)
+	}
+
+	var matchedName, matchedPath string
+
+	matches := []string{}
+
+	for _, f := range files {
+		ext := filepath.Ext(f.Name())
+		if f.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+
+		plcPath := filepath.Join(placementsDir, f.Name())
+
+		manifests, err := unmarshalManifestFile(plcPath)
+		if err != nil {
+			return "", "", err
+		}
+
+		for _, manifest := range manifests {
+			kind, _, _ := unstructured.NestedString(manifest, "kind")
+			if kind != placementKind {
+				continue
+			}
+
+			namespace, _, _ := unstructured.NestedString(manifest, "metadata", "namespace")
+			if namespace != "" && namespace != p.getPlacementNamespace() {
+				continue
+			}
+
+			labels, _, _ := unstructured.NestedStringMap(manifest, "metadata", "labels")
+
+			matched := true
+
+			for key, value := range selector {
+				if labelValue, ok := labels[key]; !ok || labelValue != value {
+					matched = false
+
+					break
+				}
+			}
+
+			if !matched {
+				continue
+			}
+
+			matchedName, _, _ = unstructured.NestedString(manifest, "metadata", "name")
+			if matchedName == "" {
+				return "", "", fmt.Errorf("the placement %s must have a name set", plcPath)
+			}
+
+			matchedPath = plcPath
+			matches = append(matches, fmt.Sprintf("%s (%s)", matchedName, plcPath))
+		}
+	}
+
+	if len(matches) == 0 {
+		return "", "", fmt.Errorf("no Placement in the placementsDir %s matches the selector", placementsDir)
+	}
+
+	if len(matches) > 1 {
+		return "", "", fmt.Errorf(
+			"multiple Placements in the placementsDir %s match the selector: %s",
+			placementsDir, strings.Join(matches, ", "),
+		)
+	}
+
+	return matchedName, matchedPath, nil
+}
+
+// resolveExtractedPlacements sets the placement path of each policy with a manifest that sets
+// extractPlacement to the manifest path so that the Placement or PlacementRule in the manifest is used
+// for the placement binding of the policy. The placement objects are then left out of the policy
+// templates by getManifests. Note that this must be run before applyDefaults so that the default
+// placement doesn't apply to the policy.
+func (p *Plugin) resolveExtractedPlacements() error {
+	for i := range p.Policies {
+		policy := &p.Policies[i]
+		extracted := false
+
+		for j, manifest := range policy.Manifests {
+			if !manifest.ExtractPlacement {
+				continue
+			}
+
+			if extracted {
+				return fmt.Errorf("the policy %s may only set extractPlacement on one manifest", policy.Name)
+			}
+
+			extracted = true
+
+			if !reflect.DeepEqual(policy.Placement, types.PlacementConfig{}) {
+				return fmt.Errorf(
+					"the policy %s has manifest[%d].extractPlacement set but the policy placement may not be set",
+					policy.Name, j,
+				)
+			}
+
+			fileInfo, err := os.Stat(manifest.Path)
+			if manifest.Path == "" || err != nil || fileInfo.IsDir() {
+				return fmt.Errorf(
+					"the policy %s has manifest[%d].extractPlacement set but the path %s is not a file",
+					policy.Name, j, manifest.Path,
+				)
+			}
+
+			objects, err := unmarshalManifestFile(manifest.Path)
+			if err != nil {
+				return fmt.Errorf("the policy %s has an invalid manifest[%d]: %w", policy.Name, j, err)
+			}
+
+			placementKinds := []string{}
+
+			for _, object := range objects {
+				kind, _, _ := unstructured.NestedString(object, "kind")
+				if kind == placementKind || kind == placementRuleKind {
+					placementKinds = append(placementKinds, kind)
+				}
+			}
+
+			if len(placementKinds) != 1 {
+				return fmt.Errorf(
+					"the policy %s has manifest[%d].extractPlacement set but the manifest %s has %d placements "+
+						"instead of one Placement or PlacementRule",
+					policy.Name, j, manifest.Path, len(placementKinds),
+				)
+			}
+
+			if placementKinds[0] == placementRuleKind {
+				policy.Placement.PlacementRulePath = manifest.Path
+			} else {
+				policy.Placement.PlacementPath = manifest.Path
+			}
+		}
+	}
+
+	return nil
+}
+
+// rewritePathPrefixes rewrites the paths in the configuration using the path prefix mappings. These are
+// the manifest path, fromFiles, configMapGenerator files, openapi path, and perCluster path of each
+// manifest and the placementPath, placementRulePath, and placementsDir of each placement, including
+// the named placements. The import paths are rewritten when the imports are resolved. Note that this
+// must be run before applyDefaults so that each path is only rewritten once.
+func (p *Plugin) rewritePathPrefixes() {
+	if len(p.pathPrefixMappings) == 0 {
+		return
+	}
+
+	rewrite := func(filePath *string) {
+		if *filePath != "" {
+			*filePath = rewritePathPrefix(*filePath, p.pathPrefixMappings)
+		}
+	}
+
+	rewritePlacement := func(placement *types.PlacementConfig) {
+		rewrite(&placement.PlacementPath)
+		rewrite(&placement.PlacementRulePath)
+		rewrite(&placement.PlacementsDir)
+	}
+
+	rewritePlacement(&p.PolicyDefaults.Placement)
+	rewritePlacement(&p.PolicySetDefaults.Placement)
+
+	for name, placement := range p.Placements {
+		rewritePlacement(&placement)
+		p.Placements[name] = placement
+	}
+
+	for i := range p.Policies {
+		rewritePlacement(&p.Policies[i].Placement)
+
+		for j := range p.Policies[i].Manifests {
+			manifest := &p.Policies[i].Manifests[j]
+			rewrite(&manifest.Path)
+			rewrite(&manifest.OpenAPI.Path)
+
+			if manifest.PerCluster != nil {
+				rewrite(&manifest.PerCluster.Path)
+			}
+
+			for key, filePath := range manifest.FromFiles {
+				manifest.FromFiles[key] = rewritePathPrefix(filePath, p.pathPrefixMappings)
+			}
+
+			if manifest.ConfigMapGenerator != nil {
+				for k, file := range manifest.ConfigMapGenerator.Files {
+					key, filePath, found := strings.Cut(file, "=")
+					if !found {
+						manifest.ConfigMapGenerator.Files[k] = rewritePathPrefix(file, p.pathPrefixMappings)
+					} else {
+						manifest.ConfigMapGenerator.Files[k] = key + "=" + rewritePathPrefix(filePath, p.pathPrefixMappings)
+					}
+				}
+			}
+		}
+	}
+
+	for i := range p.PolicySets {
+		rewritePlacement(&p.PolicySets[i].Placement)
+	}
+}
+
+// applyDefaultPlacementFields is a helper for applyDefaults that handles default Placement configuration
+func applyDefaultPlacementFields(placement *types.PlacementConfig, defaultPlacement types.PlacementConfig) {
+	if placement.DecisionStrategy == nil {
+		placement.DecisionStrategy = defaultPlacement.DecisionStrategy
+	}
+
+	if placement.SpecOverrides == nil {
+		placement.SpecOverrides = defaultPlacement.SpecOverrides
+	}
+
+	if placement.SpreadConstraints == nil {
+		placement.SpreadConstraints = defaultPlacement.SpreadConstraints
+	}
+
+	if defaultPlacement.RequireSelector {
+		placement.RequireSelector = true
+	}
+
+	// Explicitly targeting all clusters is a placement selector, so the default placement doesn't apply
+	if placement.AllClusters {
+		return
+	}
+
+	// Determine whether defaults are set for placement
+	plcDefaultSet := len(defaultPlacement.LabelSelector) != 0 ||
+		len(defaultPlacement.CelExpressions) != 0 ||
+		defaultPlacement.ClusterVersion != "" ||
+		len(defaultPlacement.Predicates) != 0 ||
+		defaultPlacement.PlacementPath != "" ||
+		defaultPlacement.PlacementName != ""
+	plrDefaultSet := len(defaultPlacement.ClusterSelectors) != 0 ||
+		len(defaultPlacement.ClusterSelector) != 0 ||
+		defaultPlacement.PlacementRulePath != "" ||
+		defaultPlacement.PlacementRuleName != ""
+	policyPlcUnset := len(placement.LabelSelector) == 0 &&
+		len(placement.CelExpressions) == 0 &&
+		placement.ClusterVersion == "" &&
+		len(placement.Predicates) == 0 &&
+		placement.PlacementPath == "" &&
+		placement.PlacementName == ""
+	policyPlrUnset := len(placement.ClusterSelectors) == 0 &&
+		len(placement.ClusterSelector) == 0 &&
+		placement.PlacementRulePath == "" &&
+		placement.PlacementRuleName == ""
+
+	if policyPlcUnset && policyPlrUnset && defaultPlacement.AllClusters {
+		placement.AllClusters = true
+
+		return
+	}
+
+	// If both cluster label selectors and placement path/name aren't set, then use the defaults with a
+	// priority on placement path followed by placement name.
+	if policyPlcUnset && plcDefaultSet {
+		if !policyPlrUnset {
+			return
+		} else if defaultPlacement.PlacementPath != "" {
+			placement.PlacementPath = defaultPlacement.PlacementPath
+		} else if defaultPlacement.PlacementName != "" {
+			placement.PlacementName = defaultPlacement.PlacementName
+			if placement.BindingPlacementRef == nil {
+				placement.BindingPlacementRef = defaultPlacement.BindingPlacementRef
+			}
+		} else if len(defaultPlacement.LabelSelector) > 0 || len(defaultPlacement.CelExpressions) > 0 ||
+			defaultPlacement.ClusterVersion != "" {
+			placement.LabelSelector = defaultPlacement.LabelSelector
+			placement.CelExpressions = defaultPlacement.CelExpressions
+			placement.ClusterVersion = defaultPlacement.ClusterVersion
+		} else if len(defaultPlacement.Predicates) > 0 {
+			placement.Predicates = defaultPlacement.Predicates
+		}
+	} else if policyPlrUnset && plrDefaultSet {
+		// Else if both cluster selectors and placement rule path/name aren't set, then use the defaults with a
+		// priority on placement rule path followed by placement rule name.
+		if !policyPlcUnset {
+			return
+		} else if defaultPlacement.PlacementRulePath != "" {
+			placement.PlacementRulePath = defaultPlacement.PlacementRulePath
+		} else if defaultPlacement.PlacementRuleName != "" {
+			placement.PlacementRuleName = defaultPlacement.PlacementRuleName
+			if placement.BindingPlacementRef == nil {
+				placement.BindingPlacementRef = defaultPlacement.BindingPlacementRef
+			}
+		} else if len(defaultPlacement.ClusterSelectors) > 0 {
+			placement.ClusterSelectors = defaultPlacement.ClusterSelectors
+		} else if len(defaultPlacement.ClusterSelector) > 0 {
+			placement.ClusterSelector = defaultPlacement.ClusterSelector
+		}
+	}
+}
+
+// resolveTemplateLibrary sets the object templates of the templateLibrary entries referenced in
+// manifest.useTemplates on each manifest. An error is returned if a templateLibrary entry is invalid
+// or a referenced entry doesn't exist.
+func (p *Plugin) resolveTemplateLibrary() error {
+	names := make([]string, 0, len(p.TemplateLibrary))
+	for name := range p.TemplateLibrary {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if len(p.TemplateLibrary[name]) == 0 {
+			return fmt.Errorf("templateLibrary.%s must have at least one object template", name)
+		}
+
+		for i, objTemplate := range p.TemplateLibrary[name] {
+			if _, ok := objTemplate["objectDefinition"].(map[string]interface{}); !ok {
+				return fmt.Errorf("templateLibrary.%s[%d] must have an objectDefinition object", name, i)
+			}
+		}
+	}
+
+	for i := range p.Policies {
+		policy := &p.Policies[i]
+
+		for j := range policy.Manifests {
+			manifest := &policy.Manifests[j]
+			manifest.LibraryTemplates = nil
+
+			for _, name := range manifest.UseTemplates {
+				objTemplates, ok := p.TemplateLibrary[name]
+				if !ok {
+					return fmt.Errorf(
+						"the policy %s has manifest[%d].useTemplates with the template %s that is not in the "+
+							"templateLibrary",
+						policy.Name, j, name,
+					)
+				}
+
+				manifest.LibraryTemplates = append(manifest.LibraryTemplates, objTemplates...)
+			}
+		}
+	}
+
+	return nil
+}
+
+// expandPerClusterPolicies replaces each policy with a perCluster manifest with a variant of the
+// policy for each cluster in the manifest's per-cluster table. The variants are named
+// <policy name>-<cluster name> and the table entry of the cluster is added as a patch to the
+// manifest. If perCluster.generatePlacement is true, each variant has a placement that selects
+// its cluster by the name label. Policy sets that contain the policy contain all the variants
+// instead. Note that this should be run only after applyDefaults is run.
+func (p *Plugin) expandPerClusterPolicies() error {
+	expandedPolicies := make([]types.PolicyConfig, 0, len(p.Policies))
+
+	for _, policy := range p.Policies {
+		perClusterIdx := -1
+
+		for j, manifest := range policy.Manifests {
+			if manifest.PerCluster == nil {
+				continue
+			}
+
+			if perClusterIdx != -1 {
+				return fmt.Errorf("the policy %s may only have one manifest with perCluster set", policy.Name)
+			}
+
+			perClusterIdx = j
+		}
+
+		if perClusterIdx == -1 {
+			expandedPolicies = append(expandedPolicies, policy)
+
+			continue
+		}
+
+		perCluster := policy.Manifests[perClusterIdx].PerCluster
+
+		clusterPatches, err := p.readPerClusterTable(perCluster.Path)
+		if err != nil {
+			return fmt.Errorf("the policy %s has an invalid manifest[%d].perCluster: %w", policy.Name, perClusterIdx, err)
+		}
+
+		clusterNames := make([]string, 0, len(clusterPatches))
+		for clusterName := range clusterPatches {
+			clusterNames = append(clusterNames, clusterName)
+		}
+
+		sort.Strings(clusterNames)
+
+		variantNames := make([]string, 0, len(clusterNames))
+
+		for _, clusterName := range clusterNames {
+			variant := policy
+			variant.Name = policy.Name + "-" + clusterName
+			variant.Manifests = append([]types.Manifest{}, policy.Manifests...)
+
+			manifest := &variant.Manifests[perClusterIdx]
+			manifest.Patches = append(
+				append([]map[string]interface{}{}, manifest.Patches...), clusterPatches[clusterName],
+			)
+
+			if perCluster.GeneratePlacement {
+				variant.Placement.ClusterSelectors = nil
+				variant.Placement.ClusterSelector = nil
+				variant.Placement.PlacementPath = ""
+				variant.Placement.PlacementRulePath = ""
+				variant.Placement.PlacementName = ""
+				variant.Placement.PlacementRuleName = ""
+				variant.Placement.BindingPlacementRef = nil
+				variant.Placement.AllClusters = false
+				variant.Placement.Name = ""
+				variant.Placement.Predicates = nil
+				variant.Placement.CelExpressions = nil
+				variant.Placement.ClusterVersion = ""
+				variant.Placement.LabelSelector = map[string]interface{}{"name": clusterName}
+			}
+
+			expandedPolicies = append(expandedPolicies, variant)
+			variantNames = append(variantNames, variant.Name)
+		}
+
+		for i := range p.PolicySets {
+			plcset := &p.PolicySets[i]
+			plcsetPolicies := make([]string, 0, len(plcset.Policies))
+
+			for _, plcName := range plcset.Policies {
+				if plcName == policy.Name {
+					plcsetPolicies = append(plcsetPolicies, variantNames...)
+				} else {
+					plcsetPolicies = append(plcsetPolicies, plcName)
+				}
+			}
+
+			plcset.Policies = plcsetPolicies
+		}
+	}
+
+	p.Policies = expandedPolicies
+
+	return nil
+}
+
+// readPerClusterTable reads the per-cluster table at the input path, which is a YAML map of cluster
+// names to the patch to apply to the manifest for that cluster. An error is returned if the path is
+// outside of the base directory or the table is invalid.
+func (p *Plugin) readPerClusterTable(tablePath string) (map[string]map[string]interface{}, error) {
+	if tablePath == "" {
+		return nil, errors.New("the path must be set")
+	}
+
+	err := verifyFilePath(p.baseDirectory, tablePath, "perCluster", p.standalone)
+	if err != nil {
+		return nil, err
+	}
+
+	// #nosec G304 -- the path is verified to be in the kustomization directory tree
+	tableBytes, err := os.ReadFile(tablePath)
+	if err != nil {
+		return nil, withErrorClass(fmt.Errorf("failed to read the path %s", tablePath), ErrManifestRead)
+	}
+
+	clusterPatches := map[string]map[string]interface{}{}
+
+	err = yaml.Unmarshal(tableBytes, &clusterPatches)
+	if err != nil {
+		return nil, fmt.Errorf("the path %s must be a map of cluster names to patches: %w", tablePath, err)
+	}
+
+	if len(clusterPatches) == 0 {
+		return nil, fmt.Errorf("the path %s must contain at least one cluster", tablePath)
+	}
+
+	for clusterName, patch := range clusterPatches {
+		if len(validation.IsDNS1123Subdomain(clusterName)) > 0 {
+			return nil, fmt.Errorf("the cluster name %s in the path %s is not a valid cluster name", clusterName, tablePath)
+		}
+
+		if patch == nil {
+			clusterPatches[clusterName] = map[string]interface{}{}
+		}
+	}
+
+	return clusterPatches, nil
+}
+
+// assertValidConfig verifies that the user provided configuration has all the
+// required fields. Note that this should be run only after applyDefaults is run.
+func (p *Plugin) assertValidConfig() error {
+	if p.PolicyDefaults.Namespace == "" {
+		return errors.New("policyDefaults.namespace is empty but it must be set")
+	}
+
+	for control, severity := range p.PolicyDefaults.SeverityByControl {
+		if control == "" {
+			return errors.New("policyDefaults.severityByControl may not have an empty control")
+		}
+
+		if !isValidSeverity(severity) {
+			return fmt.Errorf(
+				"policyDefaults.severityByControl.%s must be one of %s but got %s", control, severityValuesMsg, severity,
+			)
+		}
+	}
+
+	if fieldManager := p.PolicyDefaults.FieldManagerAnnotation; fieldManager != nil {
+		if len(validation.IsQualifiedName(fieldManager.Key)) != 0 {
+			return fmt.Errorf(
+				"policyDefaults.fieldManagerAnnotation.key must be a valid annotation key but got %q", fieldManager.Key,
+			)
+		}
+
+		if fieldManager.Value == "" || len(fieldManager.Value) > maxFieldManagerLength {
+			return fmt.Errorf(
+				"policyDefaults.fieldManagerAnnotation.value must be between 1 and %d characters but got %d",
+				maxFieldManagerLength, len(fieldManager.Value),
+			)
+		}
+	}
+
+	// Validate default policy placement settings
+	err := p.assertValidPlacement(p.PolicyDefaults.Placement, "policyDefaults", nil)
+	if err != nil {
+		return err
+	}
+
+	if p.PolicyDefaults.DescriptionTemplate != "" {
+		_, err := parseDescriptionTemplate(p.PolicyDefaults.DescriptionTemplate)
+		if err != nil {
+			return err
+		}
+	}
+
+	if p.PlacementBindingDefaults.NameTemplate != "" {
+		_, err := parseBindingNameTemplate(p.PlacementBindingDefaults.NameTemplate)
+		if err != nil {
+			return err
+		}
+	}
+
+	// validate placement binding names are DNS compliant
+	if p.PlacementBindingDefaults.Name != "" &&
+		len(validation.IsDNS1123Subdomain(p.PlacementBindingDefaults.Name)) > 0 {
+		return fmt.Errorf(
+			"PlacementBindingDefaults.Name `%s` is not DNS compliant. See %s",
+			p.PlacementBindingDefaults.Name,
+			dnsReference,
+		)
+	}
+
+	if p.PlacementBindingDefaults.Namespace != "" &&
+		len(validation.IsDNS1123Label(p.PlacementBindingDefaults.Namespace)) > 0 {
+		return fmt.Errorf(
+			"PlacementBindingDefaults.Namespace `%s` is not a valid namespace name", p.PlacementBindingDefaults.Namespace,
+		)
+	}
+
+	if p.PlacementBindingDefaults.MaxSubjectsPerBinding < 0 {
+		return fmt.Errorf(
+			"PlacementBindingDefaults.MaxSubjectsPerBinding `%d` must not be negative (0 or unset means no limit)",
+			p.PlacementBindingDefaults.MaxSubjectsPerBinding,
+		)
+	}
+
+	if overrides := p.PlacementBindingDefaults.BindingOverrides; overrides != nil {
+		if err := assertValidBindingOverrides(overrides); err != nil {
+			return fmt.Errorf("PlacementBindingDefaults.BindingOverrides is invalid: %w", err)
+		}
+	}
+
+	if len(p.Policies) == 0 {
+		return errors.New("policies is empty but it must be set")
+	}
+
+	if p.PolicyDefaults.PolicyAPIVersion != "" && p.PolicyDefaults.PolicyAPIVersion != policyAPIVersion &&
+		p.PolicyDefaults.PolicyAPIVersion != policyV1beta1APIVersion {
+		return fmt.Errorf(
+			"policyDefaults.policyApiVersion must be one of %s or %s but got %s",
+			policyAPIVersion, policyV1beta1APIVersion, p.PolicyDefaults.PolicyAPIVersion,
+		)
+	}
+
+	if p.PolicyDefaults.YAMLIndent != 0 && p.PolicyDefaults.YAMLIndent != 2 && p.PolicyDefaults.YAMLIndent != 4 {
+		return fmt.Errorf("policyDefaults.yamlIndent must be 2 or 4 but got %d", p.PolicyDefaults.YAMLIndent)
+	}
+
+	if p.PolicyDefaults.OrderPolicies && len(p.PolicyDefaults.Dependencies) != 0 {
+		return errors.New("policyDefaults must specify only one of dependencies or orderPolicies")
+	}
+
+	for i, dep := range p.PolicyDefaults.Dependencies {
+		if dep.Name == "" {
+			return fmt.Errorf("dependency name must be set in policyDefaults dependency %v", i)
+		}
+
+		if !isValidDependencyNamespace(dep) {
+			return fmt.Errorf(
+				"dependency namespace `%s` is not a valid namespace name in policyDefaults dependency %v",
+				dep.Namespace, i,
+			)
+		}
+	}
+
+	if p.PolicyDefaults.OrderManifests && p.PolicyDefaults.ConsolidateManifests {
+		return errors.New("policyDefaults may not specify both consolidateManifests and orderManifests")
+	}
+
+	if len(p.PolicyDefaults.ExtraDependencies) > 0 && p.PolicyDefaults.OrderManifests {
+		return errors.New("policyDefaults may not specify both extraDependencies and orderManifests")
+	}
+
+	for i, dep := range p.PolicyDefaults.ExtraDependencies {
+		if dep.Name == "" {
+			return fmt.Errorf("extraDependency name must be set in policyDefaults extraDependency %v", i)
+		}
+
+		if !isValidDependencyNamespace(dep) {
+			return fmt.Errorf(
+				"extraDependency namespace `%s` is not a valid namespace name in policyDefaults extraDependency %v",
+				dep.Namespace, i,
+			)
+		}
+	}
+
+	if !isValidRecordDiff(p.PolicyDefaults.RecordDiff) {
+		return fmt.Errorf(
+			"policyDefaults.recordDiff must be one of %s but got %s", recordDiffValuesMsg, p.PolicyDefaults.RecordDiff,
+		)
+	}
+
+	err = assertValidNamespaceSelector(p.PolicyDefaults.NamespaceSelector)
+	if err != nil {
+		return fmt.Errorf("policyDefaults.namespaceSelector is invalid: %w", err)
+	}
+
+	if !isValidRecordDiff(p.PolicySetDefaults.RecordDiff) {
+		return fmt.Errorf(
+			"policySetDefaults.recordDiff must be one of %s but got %s",
+			recordDiffValuesMsg, p.PolicySetDefaults.RecordDiff,
+		)
+	}
+
+	if !isValidKyvernoScope(p.PolicyDefaults.KyvernoExpanderOptions.Scope) {
+		return fmt.Errorf(
+			"policyDefaults.kyvernoExpanderOptions.scope must be one of %s but got %s",
+			kyvernoScopeValuesMsg, p.PolicyDefaults.KyvernoExpanderOptions.Scope,
+		)
+	}
+
+	seenPlc := map[string]bool{}
+	plCount := struct {
+		plc int
+		plr int
+	}{}
+
+	for i := range p.Policies {
+		policy := &p.Policies[i]
+		if policy.Name == "" {
+			return fmt.Errorf(
+				"each policy must have a name set, but did not find a name at policy array index %d", i,
+			)
+		}
+
+		if len(validation.IsDNS1123Subdomain(policy.Name)) > 0 {
+			return fmt.Errorf(
+				"policy name `%s` is not DNS compliant. See %s", policy.Name, dnsReference,
+			)
+		}
+
+		if seenPlc[policy.Name] {
+			return fmt.Errorf(
+				"each policy must have a unique name set, but found a duplicate name: %s", policy.Name,
+			)
+		}
+
+		seenPlc[policy.Name] = true
+
+		if len(p.PolicyDefaults.Namespace+"."+policy.Name) > maxObjectNameLength {
+			return fmt.Errorf("the policy namespace and name cannot be more than 63 characters: %s.%s",
+				p.PolicyDefaults.Namespace, policy.Name)
+		}
+
+		if policy.ConfigurationPolicyName != "" &&
+			len(validation.IsDNS1123Subdomain(policy.ConfigurationPolicyName)) > 0 {
+			return fmt.Errorf(
+				"the policy %s has a configurationPolicyName `%s` that is not DNS compliant. See %s",
+				policy.Name, policy.ConfigurationPolicyName, dnsReference,
+			)
+		}
+
+		if err := assertValidEvaluationInterval(
+			policy.Name, "policy.evaluationInterval", policy.EvaluationInterval,
+		); err != nil {
+			return err
+		}
+
+		if !isValidRecordDiff(policy.RecordDiff) {
+			return fmt.Errorf(
+				"the policy %s has an invalid recordDiff value %s; it must be one of %s",
+				policy.Name, policy.RecordDiff, recordDiffValuesMsg,
+			)
+		}
+
+		if err := assertValidNamespaceSelector(policy.NamespaceSelector); err != nil {
+			return fmt.Errorf("the policy %s has an invalid namespaceSelector: %w", policy.Name, err)
+		}
+
+		for key, value := range policy.ConfigurationPolicyLabels {
+			if len(validation.IsQualifiedName(key)) > 0 || len(validation.IsValidLabelValue(value)) > 0 {
+				return fmt.Errorf(
+					"the policy %s has an invalid configurationPolicyLabels entry `%s: %s`; it must be a valid label",
+					policy.Name, key, value,
+				)
+			}
+		}
+
+		if policy.Automation != nil {
+			if err := assertValidPolicyAutomation(policy.Automation); err != nil {
+				return fmt.Errorf("the policy %s has an invalid automation: %w", policy.Name, err)
+			}
+		}
+
+		if p.PolicyDefaults.StrictRemediationAction {
+			actions := [][2]string{{"remediationAction", policy.RemediationAction}}
+
+			for j := range policy.Manifests {
+				actions = append(actions, [2]string{
+					fmt.Sprintf("manifest[%d].remediationAction", j), policy.Manifests[j].RemediationAction,
+				})
+			}
+
+			for _, action := range actions {
+				if action[1] != "" && !slices.Contains(canonicalRemediationActions, action[1]) {
+					return fmt.Errorf(
+						"the policy %s has a non-canonical %s `%s`: it must be inform, enforce, or InformOnly when "+
+							"policyDefaults.strictRemediationAction is true",
+						policy.Name, action[0], action[1],
+					)
+				}
+			}
+		}
+
+		if policy.RootRemediationAction != "" &&
+			!strings.EqualFold(policy.RootRemediationAction, "inform") &&
+			!strings.EqualFold(policy.RootRemediationAction, "enforce") {
+			return fmt.Errorf(
+				"the policy %s has an invalid rootRemediationAction `%s`: it must be inform or enforce",
+				policy.Name, policy.RootRemediationAction,
+			)
+		}
+
+		if policy.ClearTemplateRemediationAction && policy.RootRemediationAction == "" {
+			return fmt.Errorf(
+				"the policy %s may only set clearTemplateRemediationAction with rootRemediationAction", policy.Name,
+			)
+		}
+
+		for _, fieldPath := range policy.DeriveLabelsFrom {
+			fields, ok := parseFieldPath(fieldPath)
+			if !ok || len(validation.IsQualifiedName(fields[len(fields)-1])) != 0 {
+				return fmt.Errorf(
+					"the policy %s has an invalid deriveLabelsFrom value `%s`; it must be a dot-separated field path, "+
+						"with the segments that contain dots in brackets, that ends in a valid label key",
+					policy.Name, fieldPath,
+				)
+			}
+		}
+
+		if policy.DisableFromManifestAnnotation != "" &&
+			len(validation.IsQualifiedName(policy.DisableFromManifestAnnotation)) != 0 {
+			return fmt.Errorf(
+				"the policy %s has an invalid disableFromManifestAnnotation value `%s`; it must be a valid "+
+					"annotation key",
+				policy.Name, policy.DisableFromManifestAnnotation,
+			)
+		}
+
+		for _, finalizer := range policy.Finalizers {
+			// Custom finalizers must be domain-qualified, such as example.com/cleanup
+			if !strings.Contains(finalizer, "/") || len(validation.IsQualifiedName(finalizer)) != 0 {
+				return fmt.Errorf(
+					"the policy %s has an invalid finalizers value `%s`; it must be a domain-qualified name such "+
+						"as example.com/cleanup",
+					policy.Name, finalizer,
+				)
+			}
+		}
+
+		if !isValidKyvernoScope(policy.KyvernoExpanderOptions.Scope) {
+			return fmt.Errorf(
+				"the policy %s has an invalid kyvernoExpanderOptions.scope value %s; it must be one of %s",
+				policy.Name, policy.KyvernoExpanderOptions.Scope, kyvernoScopeValuesMsg,
+			)
+		}
+
+		if len(policy.Manifests) == 0 {
+			return fmt.Errorf(
+				"each policy must have at least one manifest, but found none in policy %s", policy.Name,
+			)
+		}
+
+		if len(policy.Dependencies) > 0 && p.PolicyDefaults.OrderPolicies {
+			return fmt.Errorf(
+				"dependencies may not be set in policy %v when policyDefaults.orderPolicies is true", policy.Name,
+			)
+		}
+
+		if len(policy.After) > 0 && p.PolicyDefaults.OrderPolicies {
+			return fmt.Errorf(
+				"after may not be set in policy %v when policyDefaults.orderPolicies is true", policy.Name,
+			)
+		}
+
+		for x, dep := range policy.Dependencies {
+			if dep.Name == "" {
+				return fmt.Errorf("dependency name must be set in policy %v dependency %v", policy.Name, x)
+			}
+
+			if !isValidDependencyNamespace(dep) {
+				return fmt.Errorf(
+					"dependency namespace `%s` is not a valid namespace name in policy %v dependency %v",
+					dep.Namespace, policy.Name, x,
+				)
+			}
+		}
+
+		if policy.ConsolidateManifests && policy.OrderManifests {
+			return fmt.Errorf("policy %v may not set orderManifests when consolidateManifests is true", policy.Name)
+		}
+
+		if len(policy.ExtraDependencies) > 0 && policy.OrderManifests {
+			return fmt.Errorf("extraDependencies may not be set in policy %v when orderManifests is true", policy.Name)
+		}
+
+		for x, dep := range policy.ExtraDependencies {
+			if dep.Name == "" {
+				return fmt.Errorf("extraDependency name must be set in policy %v extraDependency %v", policy.Name, x)
+			}
+
+			if !isValidDependencyNamespace(dep) {
+				return fmt.Errorf(
+					"extraDependency namespace `%s` is not a valid namespace name in policy %v extraDependency %v",
+					dep.Namespace, policy.Name, x,
+				)
+			}
+		}
+
+		for j := range policy.Manifests {
+			manifest := &policy.Manifests[j]
+
+			if manifest.Path == "" && manifest.OCIRef == "" && manifest.ConfigMapGenerator == nil &&
+				manifest.HubTemplate == nil && manifest.Object == nil && len(manifest.UseTemplates) == 0 {
+				return fmt.Errorf(
 					"each policy manifest entry must have path set, but did not find a path in policy %s",
 					policy.Name,
 				)
 			}
 
-			_, err := os.Stat(manifest.Path)
-			if err != nil {
+			var err error
+
+			// A manifest with only library templates doesn't have any objects to render or modify
+			if manifest.Path == "" && manifest.OCIRef == "" && manifest.ConfigMapGenerator == nil &&
+				manifest.HubTemplate == nil && manifest.Object == nil {
+				if manifest.Renderer != "" || len(manifest.Patches) != 0 || len(manifest.FromFiles) != 0 ||
+					manifest.PerCluster != nil {
+					return fmt.Errorf(
+						"the policy %s has manifest[%d] with only useTemplates set, so renderer, patches, fromFiles, "+
+							"and perCluster may not be set",
+						policy.Name, j,
+					)
+				}
+			} else if manifest.Object != nil {
+				// An inline object is embedded as is instead of being read from a path
+				if manifest.Path != "" || manifest.OCIRef != "" || manifest.Renderer != "" ||
+					manifest.ConfigMapGenerator != nil || manifest.HubTemplate != nil {
+					return fmt.Errorf(
+						"the policy %s has manifest[%d].object set but path, ociRef, renderer, configMapGenerator, "+
+							"and hubTemplate may not be set with object",
+						policy.Name, j,
+					)
+				}
+
+				apiVersion, _, _ := unstructured.NestedString(manifest.Object, "apiVersion")
+				kind, _, _ := unstructured.NestedString(manifest.Object, "kind")
+
+				if apiVersion == "" || kind == "" {
+					return fmt.Errorf(
+						"the policy %s has manifest[%d].object set but it must have an apiVersion and kind",
+						policy.Name, j,
+					)
+				}
+			} else if manifest.HubTemplate != nil {
+				// A manifest from a hub template is generated as object-templates-raw instead of being read from a path
+				if manifest.Path != "" || manifest.OCIRef != "" || manifest.Renderer != "" ||
+					manifest.ConfigMapGenerator != nil {
+					return fmt.Errorf(
+						"the policy %s has manifest[%d].hubTemplate set but path, ociRef, renderer, and "+
+							"configMapGenerator may not be set with hubTemplate",
+						policy.Name, j,
+					)
+				}
+
+				err = assertValidHubTemplate(manifest.HubTemplate, p.PolicyDefaults.Namespace)
+				if err != nil {
+					return fmt.Errorf("the policy %s has an invalid manifest[%d].hubTemplate: %w", policy.Name, j, err)
+				}
+			} else if manifest.ConfigMapGenerator != nil {
+				// A manifest from a ConfigMap generator is generated instead of being read from a path
+				if manifest.Path != "" || manifest.OCIRef != "" || manifest.Renderer != "" {
+					return fmt.Errorf(
+						"the policy %s has manifest[%d].configMapGenerator set but path, ociRef, and renderer may "+
+							"not be set with configMapGenerator",
+						policy.Name, j,
+					)
+				}
+
+				filePaths, err := assertValidConfigMapGenerator(manifest.ConfigMapGenerator)
+				if err != nil {
+					return fmt.Errorf(
+						"the policy %s has an invalid manifest[%d].configMapGenerator: %w", policy.Name, j, err,
+					)
+				}
+
+				for _, filePath := range filePaths {
+					_, err = os.Stat(filePath)
+					if err != nil {
+						return wrapSentinel(fmt.Errorf(
+							"could not read the configMapGenerator file %s in policy %s", filePath, policy.Name,
+						), ErrManifestNotFound)
+					}
+
+					err = verifyFilePath(p.baseDirectory, filePath, "configMapGenerator file", p.standalone)
+					if err != nil {
+						return err
+					}
+				}
+			} else if manifest.OCIRef != "" {
+				// A manifest from an OCI artifact is pulled at generation time instead of being read locally
+				if manifest.Path != "" {
+					return fmt.Errorf(
+						"the policy %s has manifest[%d].ociRef set but only one of path or ociRef may be set",
+						policy.Name, j,
+					)
+				}
+
+				if manifest.Renderer != "" {
+					return fmt.Errorf(
+						"the policy %s has manifest[%d].ociRef set but renderer may not be set with ociRef",
+						policy.Name, j,
+					)
+				}
+
+				if _, err := parseOCIRef(manifest.OCIRef); err != nil {
+					return fmt.Errorf("the policy %s has an invalid manifest[%d].ociRef: %w", policy.Name, j, err)
+				}
+			} else {
+				_, err = os.Stat(manifest.Path)
+				if err != nil {
+					return wrapSentinel(fmt.Errorf(
+						"could not read the manifest path %s in policy %s", manifest.Path, policy.Name,
+					), ErrManifestNotFound)
+				}
+
+				err = verifyFilePath(p.baseDirectory, manifest.Path, "manifest", p.standalone)
+				if err != nil {
+					return err
+				}
+			}
+
+			if _, ok := getRenderers()[manifest.Renderer]; manifest.Renderer != "" && !ok {
 				return fmt.Errorf(
-					"could not read the manifest path %s in policy %s", manifest.Path, policy.Name,
+					"the policy %s has an invalid manifest[%d].renderer value %s; it must be one of %s",
+					policy.Name, j, manifest.Renderer, strings.Join(getRendererNames(), ", "),
 				)
 			}
 
-			err = verifyFilePath(p.baseDirectory, manifest.Path, "manifest")
-			if err != nil {
-				return err
+			if manifest.IncludeWhen != "" {
+				_, err := evaluateIncludeWhen(manifest.IncludeWhen, p.Values)
+				if err != nil {
+					return fmt.Errorf(
+						"the policy %s has an invalid manifest[%d].includeWhen value: %w", policy.Name, j, err,
+					)
+				}
+			}
+
+			if manifest.Renderer == kustomizeRenderer {
+				if info, err := os.Stat(manifest.Path); err != nil || !info.IsDir() {
+					return fmt.Errorf(
+						"the policy %s has manifest[%d].renderer set to kustomize but the path %s is not a directory",
+						policy.Name, j, manifest.Path,
+					)
+				}
+			}
+
+			if manifest.ObjectNamespace != "" && len(validation.IsDNS1123Label(manifest.ObjectNamespace)) > 0 {
+				return fmt.Errorf(
+					"the policy %s has an invalid manifest[%d].objectNamespace value %s; it must be a valid namespace name",
+					policy.Name, j, manifest.ObjectNamespace,
+				)
+			}
+
+			if manifest.OverrideObjectNamespace && manifest.ObjectNamespace == "" {
+				return fmt.Errorf(
+					"the policy %s has manifest[%d].overrideObjectNamespace set but objectNamespace is not set",
+					policy.Name, j,
+				)
+			}
+
+			if len(manifest.ClusterScopedKinds) != 0 && manifest.ObjectNamespace == "" {
+				return fmt.Errorf(
+					"the policy %s has manifest[%d].clusterScopedKinds set but objectNamespace is not set",
+					policy.Name, j,
+				)
+			}
+
+			if manifest.PerNamespace {
+				if err := assertValidPerNamespaceSelector(manifest.NamespaceSelector); err != nil {
+					return fmt.Errorf("the policy %s has manifest[%d].perNamespace set but %w", policy.Name, j, err)
+				}
+			}
+
+			for key, value := range manifest.ObjectLabels {
+				if len(validation.IsQualifiedName(key)) > 0 || len(validation.IsValidLabelValue(value)) > 0 {
+					return fmt.Errorf(
+						"the policy %s has an invalid manifest[%d].objectLabels entry `%s: %s`; it must be a valid label",
+						policy.Name, j, key, value,
+					)
+				}
+			}
+
+			for key, value := range manifest.ConfigurationPolicyLabels {
+				if len(validation.IsQualifiedName(key)) > 0 || len(validation.IsValidLabelValue(value)) > 0 {
+					return fmt.Errorf(
+						"the policy %s has an invalid manifest[%d].configurationPolicyLabels entry `%s: %s`; it must be "+
+							"a valid label",
+						policy.Name, j, key, value,
+					)
+				}
+			}
+
+			for key := range manifest.ObjectAnnotations {
+				if len(validation.IsQualifiedName(key)) > 0 {
+					return fmt.Errorf(
+						"the policy %s has an invalid manifest[%d].objectAnnotations key `%s`; it must be a valid "+
+							"annotation key",
+						policy.Name, j, key,
+					)
+				}
+			}
+
+			if manifest.OverrideObjectMetadata && len(manifest.ObjectLabels) == 0 && len(manifest.ObjectAnnotations) == 0 {
+				return fmt.Errorf(
+					"the policy %s has manifest[%d].overrideObjectMetadata set but objectLabels and objectAnnotations "+
+						"are not set",
+					policy.Name, j,
+				)
 			}
 
 			if manifest.OpenAPI.Path != "" {
-				err = verifyFilePath(p.baseDirectory, manifest.OpenAPI.Path, "openapi")
+				err = verifyFilePath(p.baseDirectory, manifest.OpenAPI.Path, "openapi", p.standalone)
+				if err != nil {
+					return err
+				}
+			}
+
+			fromFilesKeys := make([]string, 0, len(manifest.FromFiles))
+			for key := range manifest.FromFiles {
+				fromFilesKeys = append(fromFilesKeys, key)
+			}
+
+			sort.Strings(fromFilesKeys)
+
+			for _, key := range fromFilesKeys {
+				if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+					return fmt.Errorf(
+						"the policy %s has an invalid manifest[%d].fromFiles key %s: %s",
+						policy.Name, j, key, strings.Join(errs, "; "),
+					)
+				}
+
+				err = verifyFilePath(p.baseDirectory, manifest.FromFiles[key], "fromFiles", p.standalone)
 				if err != nil {
 					return err
 				}
@@ -1109,11 +2939,22 @@ func (p *Plugin) assertValidConfig() error {
 
 			evalInterval := manifest.EvaluationInterval
 
+			if manifest.Consolidate != nil && *manifest.Consolidate && policy.OrderManifests {
+				return fmt.Errorf(
+					"the policy %s may not set consolidate to true on manifest[%d] when orderManifests is true",
+					policy.Name, j,
+				)
+			}
+
 			// Verify that consolidated manifests fields match that of the policy configuration.
-			if policy.ConsolidateManifests {
+			if isManifestConsolidated(policy, j) {
+				consolidatedReason := "consolidateManifests is true"
+				if manifest.Consolidate != nil {
+					consolidatedReason = "consolidate is true on the manifest"
+				}
+
 				errorMsgFmt := fmt.Sprintf(
-					"the policy %s has the %%s value set on manifest[%d] but consolidateManifests is true",
-					policy.Name, j,
+					"the policy %s has the %%s value set on manifest[%d] but %s", policy.Name, j, consolidatedReason,
 				)
 
 				if !reflect.DeepEqual(evalInterval, policy.EvaluationInterval) {
@@ -1128,6 +2969,10 @@ func (p *Plugin) assertValidConfig() error {
 					return fmt.Errorf(errorMsgFmt, "namespaceSelector")
 				}
 
+				if !reflect.DeepEqual(manifest.ObjectSelector, policy.ObjectSelector) {
+					return fmt.Errorf(errorMsgFmt, "objectSelector")
+				}
+
 				if manifest.PruneObjectBehavior != policy.PruneObjectBehavior {
 					return fmt.Errorf(errorMsgFmt, "pruneObjectBehavior")
 				}
@@ -1140,6 +2985,10 @@ func (p *Plugin) assertValidConfig() error {
 					return fmt.Errorf(errorMsgFmt, "severity")
 				}
 
+				if !maps.Equal(manifest.ConfigurationPolicyLabels, policy.ConfigurationPolicyLabels) {
+					return fmt.Errorf(errorMsgFmt, "configurationPolicyLabels")
+				}
+
 				if !reflect.DeepEqual(manifest.ExtraDependencies, policy.ExtraDependencies) {
 					return fmt.Errorf(errorMsgFmt, "extraDependencies")
 				}
@@ -1147,28 +2996,49 @@ func (p *Plugin) assertValidConfig() error {
 				if manifest.IgnorePending != policy.IgnorePending {
 					return fmt.Errorf(errorMsgFmt, "ignorePending")
 				}
+
+				if manifest.Order != nil {
+					return fmt.Errorf(errorMsgFmt, "order")
+				}
+
+				if manifest.PerNamespace {
+					return fmt.Errorf(errorMsgFmt, "perNamespace")
+				}
 			}
 
-			if evalInterval.Compliant != "" && evalInterval.Compliant != "never" {
-				_, err := time.ParseDuration(evalInterval.Compliant)
-				if err != nil {
+			if err := assertValidEvaluationInterval(
+				policy.Name, fmt.Sprintf("manifest[%d].evaluationInterval", j), evalInterval,
+			); err != nil {
+				return err
+			}
+
+			if !isValidRecordDiff(manifest.RecordDiff) {
+				return fmt.Errorf(
+					"the policy %s has an invalid manifest[%d].recordDiff value %s; it must be one of %s",
+					policy.Name, j, manifest.RecordDiff, recordDiffValuesMsg,
+				)
+			}
+
+			if err := assertValidNamespaceSelector(manifest.NamespaceSelector); err != nil {
+				return fmt.Errorf(
+					"the policy %s has an invalid manifest[%d].namespaceSelector: %w", policy.Name, j, err,
+				)
+			}
+
+			for idx, complianceType := range manifest.ComplianceTypeByIndex {
+				if idx < 0 {
 					return fmt.Errorf(
-						"the policy %s has an invalid policy.evaluationInterval.manifest[%d].compliant value: %w",
-						policy.Name,
-						j,
-						err,
+						"the policy %s has an invalid manifest[%d].complianceTypeByIndex index %d; it must not be "+
+							"negative",
+						policy.Name, j, idx,
 					)
 				}
-			}
 
-			if evalInterval.NonCompliant != "" && evalInterval.NonCompliant != "never" {
-				_, err := time.ParseDuration(evalInterval.NonCompliant)
-				if err != nil {
+				if !isValidComplianceType(complianceType) {
 					return fmt.Errorf(
-						"the policy %s has an invalid policy.evaluationInterval.manifest[%d].noncompliant value: %w",
-						policy.Name,
-						j,
-						err,
+						"the policy %s has an invalid manifest[%d].complianceTypeByIndex[%d] value %s; it must be "+
+							"one of musthave, mustonlyhave, mustnothave",
+						policy.Name, j, idx, complianceType,
 					)
 				}
 			}
@@ -1187,6 +3057,13 @@ func (p *Plugin) assertValidConfig() error {
 						"extraDependency name must be set in policy %v manifest[%d] extraDependency %v",
 						policy.Name, j, x)
 				}
+
+				if !isValidDependencyNamespace(dep) {
+					return fmt.Errorf(
+						"extraDependency namespace `%s` is not a valid namespace name in policy %v manifest[%d] "+
+							"extraDependency %v",
+						dep.Namespace, policy.Name, j, x)
+				}
 			}
 		}
 
@@ -1196,6 +3073,12 @@ func (p *Plugin) assertValidConfig() error {
 		}
 	}
 
+	// Validate that the after lists of the policies reference existing policies without a cycle
+	_, err = sortPoliciesByAfter(p.Policies)
+	if err != nil {
+		return err
+	}
+
 	// Validate default policy set placement settings
 	err = p.assertValidPlacement(p.PolicySetDefaults.Placement, "policySetDefaults", nil)
 	if err != nil {
@@ -1207,47 +3090,234 @@ func (p *Plugin) assertValidConfig() error {
 	for i := range p.PolicySets {
 		plcset := &p.PolicySets[i]
 
-		if plcset.Name == "" {
-			return fmt.Errorf(
-				"each policySet must have a name set, but did not find a name at policySet array index %d", i,
-			)
+		if plcset.Name == "" {
+			return fmt.Errorf(
+				"each policySet must have a name set, but did not find a name at policySet array index %d", i,
+			)
+		}
+
+		if len(validation.IsDNS1123Subdomain(plcset.Name)) > 0 {
+			return fmt.Errorf(
+				"policy set name `%s` is not DNS compliant. See %s", plcset.Name, dnsReference,
+			)
+		}
+
+		if seenPlcset[plcset.Name] {
+			return fmt.Errorf(
+				"each policySet must have a unique name set, but found a duplicate name: %s", plcset.Name,
+			)
+		}
+
+		seenPlcset[plcset.Name] = true
+
+		seenOrdered := map[string]bool{}
+
+		for _, plc := range plcset.OrderedPolicies {
+			if !slices.Contains(plcset.Policies, plc) {
+				return fmt.Errorf(
+					"the policy set %s has the policy %s in orderedPolicies but it isn't a member of the policy set",
+					plcset.Name, plc,
+				)
+			}
+
+			if seenOrdered[plc] {
+				return fmt.Errorf(
+					"the policy set %s has the policy %s in orderedPolicies more than once", plcset.Name, plc,
+				)
+			}
+
+			seenOrdered[plc] = true
+		}
+
+		// Validate policy set Placement settings
+		err := p.assertValidPlacement(plcset.Placement, fmt.Sprintf("policySet %s", plcset.Name), &plCount)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Validate only one type of placement kind is in use
+	if plCount.plc != 0 && plCount.plr != 0 {
+		return fmt.Errorf(
+			"may not use a mix of Placement and PlacementRule for policies and policysets; found %d Placement and "+
+				"%d PlacementRule",
+			plCount.plc, plCount.plr,
+		)
+	}
+
+	p.usingPlR = plCount.plr != 0
+
+	return nil
+}
+
+// validateEvaluationInterval returns an error if the input evaluation interval value isn't the never
+// or watch keyword or a positive duration in the format of time.ParseDuration, such as 1h30m.
+func validateEvaluationInterval(value string) error {
+	if value == "never" || value == "watch" {
+		return nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+
+	if duration <= 0 {
+		return fmt.Errorf("the duration %s must be greater than zero", value)
+	}
+
+	return nil
+}
+
+// assertValidPerNamespaceSelector verifies that the input namespaceSelector is a concrete list of
+// namespaces that a ConfigurationPolicy can be generated for each of.
+func assertValidPerNamespaceSelector(selector types.NamespaceSelector) error {
+	if len(selector.Include) == 0 {
+		return errors.New("namespaceSelector.include is empty")
+	}
+
+	if len(selector.Exclude) != 0 || selector.MatchLabels != nil || selector.MatchExpressions != nil {
+		return errors.New("namespaceSelector may only set include")
+	}
+
+	for _, namespace := range selector.Include {
+		if len(validation.IsDNS1123Label(namespace)) != 0 {
+			return fmt.Errorf("the namespaceSelector.include entry `%s` is not a namespace name", namespace)
+		}
+	}
+
+	return nil
+}
+
+// assertValidEvaluationInterval returns an error if the compliant or noncompliant value of the input
+// evaluation interval is set and invalid. The fieldPath is the path of the evaluation interval in the
+// error message, such as manifest[0].evaluationInterval.
+func assertValidEvaluationInterval(
+	policyName string, fieldPath string, evalInterval types.EvaluationInterval,
+) error {
+	values := []struct {
+		field string
+		value string
+	}{
+		{"compliant", evalInterval.Compliant},
+		{"noncompliant", evalInterval.NonCompliant},
+	}
+
+	for _, value := range values {
+		if value.value == "" {
+			continue
 		}
 
-		if len(validation.IsDNS1123Subdomain(plcset.Name)) > 0 {
+		err := validateEvaluationInterval(value.value)
+		if err != nil {
 			return fmt.Errorf(
-				"policy set name `%s` is not DNS compliant. See %s", plcset.Name, dnsReference,
+				"the policy %s has an invalid %s.%s value: %w", policyName, fieldPath, value.field, err,
 			)
 		}
+	}
 
-		if seenPlcset[plcset.Name] {
-			return fmt.Errorf(
-				"each policySet must have a unique name set, but found a duplicate name: %s", plcset.Name,
-			)
-		}
+	return nil
+}
 
-		seenPlcset[plcset.Name] = true
+// getSeverityByControl returns the severity mapped to the first of the input controls in
+// severityByControl. A control matches a key if it's the same or starts with the key followed by a
+// space, so that the AC-3 key matches the "AC-3 Access Enforcement" control. If no control matches,
+// an empty string is returned.
+func getSeverityByControl(controls []string, severityByControl map[string]string) string {
+	for _, control := range controls {
+		if severity, ok := severityByControl[control]; ok {
+			return severity
+		}
 
-		// Validate policy set Placement settings
-		err := p.assertValidPlacement(plcset.Placement, fmt.Sprintf("policySet %s", plcset.Name), &plCount)
-		if err != nil {
-			return err
+		controlID, _, _ := strings.Cut(control, " ")
+		if severity, ok := severityByControl[controlID]; ok {
+			return severity
 		}
 	}
 
-	// Validate only one type of placement kind is in use
-	if plCount.plc != 0 && plCount.plr != 0 {
-		return fmt.Errorf(
-			"may not use a mix of Placement and PlacementRule for policies and policysets; found %d Placement and "+
-				"%d PlacementRule",
-			plCount.plc, plCount.plr,
-		)
+	return ""
+}
+
+// isValidRecordDiff returns whether the input recordDiff value is supported by the ConfigurationPolicy
+// API. An empty value is considered valid since it means the field is unset.
+func isValidRecordDiff(recordDiff string) bool {
+	switch recordDiff {
+	case "", "Log", "InStatus", "None":
+		return true
+	default:
+		return false
 	}
+}
 
-	p.usingPlR = plCount.plr != 0
+// isValidSeverity returns whether the input severity value is supported by the ConfigurationPolicy
+// API, which accepts each value in lowercase or capitalized.
+func isValidSeverity(severity string) bool {
+	switch severity {
+	case "low", "Low", "medium", "Medium", "high", "High", "critical", "Critical":
+		return true
+	default:
+		return false
+	}
+}
+
+// assertValidPolicyAutomation verifies that the mode of the policy automation is valid and that the
+// Ansible job template and secret are set.
+func assertValidPolicyAutomation(automation *types.PolicyAutomationConfig) error {
+	switch automation.Mode {
+	case "", "once", "everyEvent", "disabled":
+	default:
+		return fmt.Errorf("the mode %s must be one of once, everyEvent, or disabled", automation.Mode)
+	}
+
+	if automation.JobTemplateName == "" {
+		return errors.New("the jobTemplateName must be set")
+	}
+
+	if automation.Secret == "" {
+		return errors.New("the secret must be set")
+	}
 
 	return nil
 }
 
+func isValidComplianceType(complianceType string) bool {
+	switch complianceType {
+	case "musthave", "mustonlyhave", "mustnothave":
+		return true
+	default:
+		return false
+	}
+}
+
+// assertValidNamespaceSelector verifies that the label selector fields of the namespace selector,
+// such as the matchExpressions operators, are valid.
+func assertValidNamespaceSelector(nsSelector types.NamespaceSelector) error {
+	labelSelector := metav1.LabelSelector{}
+
+	if nsSelector.MatchLabels != nil {
+		labelSelector.MatchLabels = *nsSelector.MatchLabels
+	}
+
+	if nsSelector.MatchExpressions != nil {
+		labelSelector.MatchExpressions = *nsSelector.MatchExpressions
+	}
+
+	_, err := metav1.LabelSelectorAsSelector(&labelSelector)
+
+	return err
+}
+
+// isValidKyvernoScope returns whether the input kyvernoExpanderOptions.scope value is supported by
+// the Kyverno policy expander.
+func isValidKyvernoScope(scope string) bool {
+	switch scope {
+	case "", "All", "Cluster", "Namespaced":
+		return true
+	default:
+		return false
+	}
+}
+
 // assertValidPlacement is a helper for assertValidConfig to verify placement configurations
 func (p *Plugin) assertValidPlacement(
 	placement types.PlacementConfig,
@@ -1276,9 +3346,100 @@ func (p *Plugin) assertValidPlacement(
 		)
 	}
 
+	if placement.AllClusters &&
+		(len(placement.LabelSelector) != 0 ||
+			len(placement.Predicates) != 0 ||
+			len(placement.CelExpressions) != 0 ||
+			placement.ClusterVersion != "" ||
+			len(placement.ClusterSelectors) != 0 ||
+			len(placement.ClusterSelector) != 0 ||
+			placement.PlacementPath != "" ||
+			placement.PlacementRulePath != "" ||
+			placement.PlacementName != "" ||
+			placement.PlacementRuleName != "") {
+		return fmt.Errorf(
+			"%s placement.allClusters may not be set with a placement selector, placement path, or placement name",
+			path,
+		)
+	}
+
+	if len(placement.DecisionStrategy) != 0 &&
+		(len(placement.ClusterSelectors) != 0 ||
+			len(placement.ClusterSelector) != 0 ||
+			placement.PlacementRulePath != "" ||
+			placement.PlacementRuleName != "") {
+		return fmt.Errorf(
+			"%s placement.decisionStrategy may only be used with a Placement and not a PlacementRule", path,
+		)
+	}
+
+	if len(placement.SpreadConstraints) != 0 &&
+		(len(placement.ClusterSelectors) != 0 ||
+			len(placement.ClusterSelector) != 0 ||
+			placement.PlacementRulePath != "" ||
+			placement.PlacementRuleName != "") {
+		return fmt.Errorf(
+			"%s placement.spreadConstraints may only be used with a Placement and not a PlacementRule", path,
+		)
+	}
+
+	if len(placement.Predicates) != 0 {
+		if len(placement.LabelSelector) != 0 || len(placement.ClusterSelectors) != 0 ||
+			len(placement.ClusterSelector) != 0 {
+			return fmt.Errorf(
+				"%s placement.predicates may not be set with placement.labelSelector or placement.clusterSelector",
+				path,
+			)
+		}
+
+		if placement.PlacementRulePath != "" || placement.PlacementRuleName != "" {
+			return fmt.Errorf(
+				"%s placement.predicates may only be used with a Placement and not a PlacementRule", path,
+			)
+		}
+	}
+
+	if len(placement.CelExpressions) != 0 {
+		if len(placement.Predicates) != 0 {
+			return fmt.Errorf("%s placement.celExpressions may not be set with placement.predicates", path)
+		}
+
+		if len(placement.ClusterSelectors) != 0 || len(placement.ClusterSelector) != 0 ||
+			placement.PlacementRulePath != "" || placement.PlacementRuleName != "" {
+			return fmt.Errorf(
+				"%s placement.celExpressions may only be used with a Placement and not a PlacementRule", path,
+			)
+		}
+
+		for i, expression := range placement.CelExpressions {
+			if strings.TrimSpace(expression) == "" {
+				return fmt.Errorf("%s placement.celExpressions[%d] must not be empty", path, i)
+			}
+		}
+	}
+
+	if placement.ClusterVersion != "" {
+		if len(placement.Predicates) != 0 {
+			return fmt.Errorf("%s placement.clusterVersion may not be set with placement.predicates", path)
+		}
+
+		if len(placement.ClusterSelectors) != 0 || len(placement.ClusterSelector) != 0 ||
+			placement.PlacementRulePath != "" || placement.PlacementRuleName != "" {
+			return fmt.Errorf(
+				"%s placement.clusterVersion may only be used with a Placement and not a PlacementRule", path,
+			)
+		}
+
+		_, err := getClusterVersionExpressions(placement.ClusterVersion)
+		if err != nil {
+			return fmt.Errorf("%s placement.clusterVersion `%s` is invalid: %w", path, placement.ClusterVersion, err)
+		}
+	}
+
 	placementOptionCount := 0
 	if len(placement.LabelSelector) != 0 || len(placement.ClusterSelectors) != 0 ||
-		len(placement.ClusterSelector) != 0 {
+		len(placement.ClusterSelector) != 0 || len(placement.Predicates) != 0 ||
+		len(placement.CelExpressions) != 0 || placement.ClusterVersion != "" {
 		placementOptionCount++
 	}
 
@@ -1296,6 +3457,28 @@ func (p *Plugin) assertValidPlacement(
 		)
 	}
 
+	if ref := placement.BindingPlacementRef; ref != nil {
+		plcName := placement.PlacementName + placement.PlacementRuleName
+		if plcName == "" {
+			return fmt.Errorf(
+				"%s placement.bindingPlacementRef may only be used with placement.placementName or "+
+					"placement.placementRuleName",
+				path,
+			)
+		}
+
+		if ref.APIGroup == "" || ref.Kind == "" || ref.Name == "" {
+			return fmt.Errorf("%s placement.bindingPlacementRef must set apiGroup, kind, and name", path)
+		}
+
+		if ref.Name != plcName {
+			return fmt.Errorf(
+				"%s placement.bindingPlacementRef.name must match the placement name %s but got %s",
+				path, plcName, ref.Name,
+			)
+		}
+	}
+
 	// validate placement names are DNS compliant
 	defPlrName := placement.PlacementRuleName
 	if defPlrName != "" && len(validation.IsDNS1123Subdomain(defPlrName)) > 0 {
@@ -1327,20 +3510,30 @@ func (p *Plugin) assertValidPlacement(
 	if placement.PlacementRulePath != "" {
 		_, err := os.Stat(placement.PlacementRulePath)
 		if err != nil {
-			return fmt.Errorf(
+			return withErrorClass(fmt.Errorf(
 				"%s placement.placementRulePath could not read the path %s",
 				path, placement.PlacementRulePath,
-			)
+			), ErrManifestRead)
+		}
+
+		err = assertPlacementPathKind(path, "placementRulePath", placement.PlacementRulePath, placementRuleKind)
+		if err != nil {
+			return err
 		}
 	}
 
 	if placement.PlacementPath != "" {
 		_, err := os.Stat(placement.PlacementPath)
 		if err != nil {
-			return fmt.Errorf(
+			return withErrorClass(fmt.Errorf(
 				"%s placement.placementPath could not read the path %s",
 				path, placement.PlacementPath,
-			)
+			), ErrManifestRead)
+		}
+
+		err = assertPlacementPathKind(path, "placementPath", placement.PlacementPath, placementKind)
+		if err != nil {
+			return err
 		}
 	}
 
@@ -1348,6 +3541,9 @@ func (p *Plugin) assertValidPlacement(
 		foundPl := false
 
 		if len(placement.LabelSelector) != 0 ||
+			len(placement.Predicates) != 0 ||
+			len(placement.CelExpressions) != 0 ||
+			placement.ClusterVersion != "" ||
 			placement.PlacementPath != "" ||
 			placement.PlacementName != "" {
 			plCount.plc++
@@ -1396,18 +3592,28 @@ func (p *Plugin) assertValidPlacement(
 // The generated policy is written to the plugin's output buffer. An error is returned if the
 // manifests specified in the configuration are invalid or can't be read.
 func (p *Plugin) createPolicy(policyConf *types.PolicyConfig) error {
-	policyTemplates, err := getPolicyTemplates(policyConf)
-	if err != nil {
-		return err
+	var err error
+
+	policyTemplates, ok := p.policyTemplates[policyConf.Name]
+	if !ok {
+		policyTemplates, err = getPolicyTemplates(policyConf, p.Values)
+		if err != nil {
+			return err
+		}
+	}
+
+	p.warnings = append(p.warnings, getObjectSelectorWarnings(policyConf.Name, policyTemplates)...)
+
+	// The root policy remediationAction governs the policy templates when they don't set one
+	if policyConf.ClearTemplateRemediationAction {
+		clearTemplateRemediationActions(policyTemplates)
 	}
 
 	if policyConf.PolicyAnnotations == nil {
 		policyConf.PolicyAnnotations = map[string]string{}
 	}
 
-	if policyConf.PolicyLabels == nil {
-		policyConf.PolicyLabels = map[string]string{}
-	}
+	mergeDerivedLabels(policyConf, policyTemplates)
 
 	policyConf.PolicyAnnotations["policy.open-cluster-management.io/categories"] = strings.Join(
 		policyConf.Categories, ",",
@@ -1418,10 +3624,52 @@ func (p *Plugin) createPolicy(policyConf *types.PolicyConfig) error {
 	policyConf.PolicyAnnotations["policy.open-cluster-management.io/standards"] = strings.Join(
 		policyConf.Standards, ",",
 	)
-	policyConf.PolicyAnnotations["policy.open-cluster-management.io/description"] = policyConf.Description
+
+	// An explicit description takes precedence over the description template
+	description := policyConf.Description
+	if description == "" && p.PolicyDefaults.DescriptionTemplate != "" {
+		description, err = renderDescription(p.PolicyDefaults.DescriptionTemplate, policyConf)
+		if err != nil {
+			return fmt.Errorf("failed to compute the description of the policy %s: %w", policyConf.Name, err)
+		}
+	}
+
+	policyConf.PolicyAnnotations["policy.open-cluster-management.io/description"] = description
+
+	if policyConf.ContentChecksumAnnotation {
+		contentHash, err := getPolicyTemplatesHash(policyTemplates)
+		if err != nil {
+			return fmt.Errorf("failed to compute the content hash of the policy %s: %w", policyConf.Name, err)
+		}
+
+		policyConf.PolicyAnnotations[contentHashAnnotation] = contentHash
+	}
+
+	if p.PolicyDefaults.TimestampAnnotation {
+		if p.generatedAt.IsZero() {
+			p.generatedAt = time.Now().UTC()
+		}
+
+		policyConf.PolicyAnnotations[generatedAtAnnotation] = p.generatedAt.Format(time.RFC3339)
+	}
+
+	disabled := policyConf.Disabled
+
+	if policyConf.DisableFromManifestAnnotation != "" {
+		annotationDisabled, set, err := getDisabledFromAnnotation(
+			policyTemplates, policyConf.DisableFromManifestAnnotation,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to determine if the policy %s is disabled: %w", policyConf.Name, err)
+		}
+
+		if set {
+			disabled = annotationDisabled
+		}
+	}
 
 	spec := map[string]interface{}{
-		"disabled":         policyConf.Disabled,
+		"disabled":         disabled,
 		"policy-templates": policyTemplates,
 	}
 
@@ -1429,8 +3677,22 @@ func (p *Plugin) createPolicy(policyConf *types.PolicyConfig) error {
 		spec["hubTemplateOptions"] = policyConf.HubTemplateOptions
 	}
 
+	dependencies := slices.Clone(policyConf.Dependencies)
+
+	for _, name := range policyConf.After {
+		dependencies = append(dependencies, types.PolicyDependency{
+			Name:       name,
+			Namespace:  p.PolicyDefaults.Namespace,
+			Compliance: "Compliant",
+			Kind:       policyKind,
+			APIVersion: policyAPIVersion,
+		})
+	}
+
+	// The ordering dependency replaces any other dependencies. The policy configuration isn't modified so that
+	// generating again doesn't add an ordering dependency to the first policy.
 	if p.PolicyDefaults.OrderPolicies && p.previousPolicyName != "" {
-		policyConf.Dependencies = []types.PolicyDependency{{
+		dependencies = []types.PolicyDependency{{
 			Name:       p.previousPolicyName,
 			Namespace:  p.PolicyDefaults.Namespace,
 			Compliance: "Compliant",
@@ -1439,49 +3701,210 @@ func (p *Plugin) createPolicy(policyConf *types.PolicyConfig) error {
 		}}
 	}
 
-	p.previousPolicyName = policyConf.Name
+	p.previousPolicyName = policyConf.Name
+
+	if len(dependencies) != 0 {
+		spec["dependencies"] = dependencies
+	}
+
+	// When copyPolicyMetadata is unset, it defaults to the behavior of true, so this leaves it out entirely when set to
+	// true to avoid unnecessarily including it in the Policy YAML.
+	if !policyConf.CopyPolicyMetadata {
+		spec["copyPolicyMetadata"] = false
+	}
+
+	policy := map[string]interface{}{
+		"apiVersion": policyAPIVersion,
+		"kind":       policyKind,
+		"metadata": map[string]interface{}{
+			"annotations": policyConf.PolicyAnnotations,
+			"name":        policyConf.Name,
+			"namespace":   p.PolicyDefaults.Namespace,
+		},
+		"spec": spec,
+	}
+
+	if len(policyConf.PolicyLabels) != 0 {
+		policy["metadata"].(map[string]interface{})["labels"] = policyConf.PolicyLabels
+	}
+
+	if len(policyConf.Finalizers) != 0 {
+		policy["metadata"].(map[string]interface{})["finalizers"] = policyConf.Finalizers
+	}
+
+	// set the root policy remediation action if it is forced or if all the remediation actions match
+	if policyConf.RootRemediationAction != "" {
+		policy["spec"].(map[string]interface{})["remediationAction"] = policyConf.RootRemediationAction
+	} else if rootRemediationAction := getRootRemediationAction(policyTemplates); rootRemediationAction != "" {
+		policy["spec"].(map[string]interface{})["remediationAction"] = rootRemediationAction
+	}
+
+	p.setCommonMetadata(policy)
+
+	if p.PolicyDefaults.PolicyAPIVersion == policyV1beta1APIVersion {
+		policy = convertPolicyToV1beta1(policy)
+	}
+
+	var policyYAML []byte
+
+	if policyConf.PreserveComments || policyConf.CanonicalKeyOrder {
+		policyYAML, err = marshalPolicyNode(policy, policyConf, p.PolicyDefaults.YAMLIndent)
+		if err != nil {
+			return err
+		}
+	} else {
+		policyYAML, err = marshalYAML(policy, p.PolicyDefaults.YAMLIndent)
+		if err != nil {
+			return fmt.Errorf(
+				"an unexpected error occurred when converting the policy to YAML: %w", err,
+			)
+		}
+	}
+
+	p.outputBuffer.Write([]byte("---\n"))
+	p.outputBuffer.Write(policyYAML)
+
+	if policyConf.Automation != nil {
+		return p.createPolicyAutomation(policyConf)
+	}
+
+	return nil
+}
+
+// createPolicyAutomation generates the PolicyAutomation that runs the Ansible job template of the
+// input policy configuration when the policy is noncompliant. The generated PolicyAutomation is
+// written to the plugin's output buffer.
+func (p *Plugin) createPolicyAutomation(policyConf *types.PolicyConfig) error {
+	mode := policyConf.Automation.Mode
+	if mode == "" {
+		mode = "once"
+	}
+
+	automationDef := map[string]interface{}{
+		"type":   "AnsibleJob",
+		"name":   policyConf.Automation.JobTemplateName,
+		"secret": policyConf.Automation.Secret,
+	}
+
+	if len(policyConf.Automation.ExtraVars) != 0 {
+		automationDef["extra_vars"] = policyConf.Automation.ExtraVars
+	}
+
+	policyAutomation := map[string]interface{}{
+		"apiVersion": policyAutomationAPIVersion,
+		"kind":       policyAutomationKind,
+		"metadata": map[string]interface{}{
+			"name":      policyConf.Name + "-policy-automation",
+			"namespace": p.PolicyDefaults.Namespace,
+		},
+		"spec": map[string]interface{}{
+			"policyRef":     policyConf.Name,
+			"mode":          mode,
+			"eventHook":     "noncompliant",
+			"automationDef": automationDef,
+		},
+	}
+
+	p.setCommonMetadata(policyAutomation)
+
+	policyAutomationYAML, err := marshalYAML(policyAutomation, p.PolicyDefaults.YAMLIndent)
+	if err != nil {
+		return fmt.Errorf(
+			"an unexpected error occurred when converting the policy automation to YAML: %w", err,
+		)
+	}
+
+	p.outputBuffer.Write([]byte("---\n"))
+	p.outputBuffer.Write(policyAutomationYAML)
+
+	return nil
+}
+
+// v1beta1PolicySpecFields maps the v1 Policy spec fields to the experimental v1beta1 Policy spec
+// fields, which follow the Kubernetes convention of camelCase field names. The spec fields that
+// aren't listed are the same in both API versions.
+var v1beta1PolicySpecFields = map[string]string{
+	"policy-templates": "policyTemplates",
+}
+
+// convertPolicyToV1beta1 converts the input v1 Policy to the experimental v1beta1 Policy API and
+// returns the result. The spec fields are renamed with v1beta1PolicySpecFields. The input policy is
+// not modified. The v1beta1 Policy API is not finalized, so this is the single place where its spec
+// layout differences from v1 should be implemented.
+func convertPolicyToV1beta1(policy map[string]interface{}) map[string]interface{} {
+	converted := make(map[string]interface{}, len(policy))
+	for k, v := range policy {
+		converted[k] = v
+	}
+
+	converted["apiVersion"] = policyV1beta1APIVersion
+
+	spec, ok := policy["spec"].(map[string]interface{})
+	if !ok {
+		return converted
+	}
+
+	convertedSpec := make(map[string]interface{}, len(spec))
+	for k, v := range spec {
+		if v1beta1Field, ok := v1beta1PolicySpecFields[k]; ok {
+			k = v1beta1Field
+		}
 
-	if len(policyConf.Dependencies) != 0 {
-		spec["dependencies"] = policyConf.Dependencies
+		convertedSpec[k] = v
 	}
 
-	// When copyPolicyMetadata is unset, it defaults to the behavior of true, so this leaves it out entirely when set to
-	// true to avoid unnecessarily including it in the Policy YAML.
-	if !policyConf.CopyPolicyMetadata {
-		spec["copyPolicyMetadata"] = false
+	converted["spec"] = convertedSpec
+
+	return converted
+}
+
+// setCommonMetadata adds policyDefaults.commonLabels and policyDefaults.commonAnnotations to the
+// metadata of the input generated object. Labels and annotations already set on the object, such as
+// the ones required by the generator, take precedence. The annotation in
+// policyDefaults.fieldManagerAnnotation is added the same way when it is set. The namespace is removed when
+// policyDefaults.omitNamespace is set.
+func (p *Plugin) setCommonMetadata(obj map[string]interface{}) {
+	metadata, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		return
 	}
 
-	policy := map[string]interface{}{
-		"apiVersion": policyAPIVersion,
-		"kind":       policyKind,
-		"metadata": map[string]interface{}{
-			"annotations": policyConf.PolicyAnnotations,
-			"name":        policyConf.Name,
-			"namespace":   p.PolicyDefaults.Namespace,
-		},
-		"spec": spec,
+	if p.PolicyDefaults.OmitNamespace {
+		delete(metadata, "namespace")
 	}
 
-	if len(policyConf.PolicyLabels) != 0 {
-		policy["metadata"].(map[string]interface{})["labels"] = policyConf.PolicyLabels
+	mergeCommonMetadata(metadata, "labels", p.PolicyDefaults.CommonLabels)
+	mergeCommonMetadata(metadata, "annotations", p.PolicyDefaults.CommonAnnotations)
+
+	if fieldManager := p.PolicyDefaults.FieldManagerAnnotation; fieldManager != nil {
+		mergeCommonMetadata(metadata, "annotations", map[string]string{fieldManager.Key: fieldManager.Value})
 	}
+}
 
-	// set the root policy remediation action if all the remediation actions match
-	if rootRemediationAction := getRootRemediationAction(policyTemplates); rootRemediationAction != "" {
-		policy["spec"].(map[string]interface{})["remediationAction"] = rootRemediationAction
+// mergeCommonMetadata merges the common key-value pairs into the metadata field at the input key
+// without overwriting the existing values.
+func mergeCommonMetadata(metadata map[string]interface{}, key string, common map[string]string) {
+	if len(common) == 0 {
+		return
 	}
 
-	policyYAML, err := yaml.Marshal(policy)
-	if err != nil {
-		return fmt.Errorf(
-			"an unexpected error occurred when converting the policy to YAML: %w", err,
-		)
+	merged := make(map[string]string, len(common))
+	for k, v := range common {
+		merged[k] = v
 	}
 
-	p.outputBuffer.Write([]byte("---\n"))
-	p.outputBuffer.Write(policyYAML)
+	switch existing := metadata[key].(type) {
+	case map[string]string:
+		for k, v := range existing {
+			merged[k] = v
+		}
+	case map[string]interface{}:
+		for k, v := range existing {
+			merged[k] = fmt.Sprint(v)
+		}
+	}
 
-	return nil
+	metadata[key] = merged
 }
 
 // createPolicySet will generate the policyset based on the Policy Generator configuration.
@@ -1501,7 +3924,9 @@ func (p *Plugin) createPolicySet(policySetConf *types.PolicySetConfig) error {
 		},
 	}
 
-	policysetYAML, err := yaml.Marshal(policyset)
+	p.setCommonMetadata(policyset)
+
+	policysetYAML, err := marshalYAML(policyset, p.PolicyDefaults.YAMLIndent)
 	if err != nil {
 		return fmt.Errorf(
 			"an unexpected error occurred when converting the policyset to YAML: %w", err,
@@ -1514,6 +3939,37 @@ func (p *Plugin) createPolicySet(policySetConf *types.PolicySetConfig) error {
 	return nil
 }
 
+// assertPlacementPathKind verifies that the first Placement or PlacementRule in the input placement
+// path file has the kind expected by the placement path option. Since the kind of the placements of a
+// configuration is determined from the options, this ensures that a configuration that only has
+// placement paths uses the kind of the placements in the files. A file without a Placement or
+// PlacementRule is left for getPlcFromPath to report.
+func assertPlacementPathKind(path string, option string, plcPath string, expectedKind string) error {
+	manifests, err := unmarshalManifestFile(plcPath)
+	if err != nil {
+		return withErrorClass(
+			fmt.Errorf("%s placement.%s could not read the placement: %w", path, option, err), ErrManifestRead,
+		)
+	}
+
+	for _, manifest := range manifests {
+		kind, _, _ := unstructured.NestedString(manifest, "kind")
+		if kind != placementRuleKind && kind != placementKind {
+			continue
+		}
+
+		if kind != expectedKind {
+			return fmt.Errorf(
+				"%s placement.%s %s must have a %s but it has a %s", path, option, plcPath, expectedKind, kind,
+			)
+		}
+
+		return nil
+	}
+
+	return nil
+}
+
 // getPlcFromPath finds the placement manifest in the input manifest file. It will return the name
 // of the placement, the unmarshaled placement manifest, and an error. An error is returned if the
 // placement manifest cannot be found or is invalid.
@@ -1564,11 +4020,17 @@ func (p *Plugin) getPlcFromPath(plcPath string) (string, map[string]interface{},
 			return "", nil, fmt.Errorf("the placement %s must have a namespace set", plcPath)
 		}
 
-		if namespace != p.PolicyDefaults.Namespace {
+		if namespace != p.getPlacementNamespace() {
+			namespaceOwner := "the policy"
+			if p.PlacementBindingDefaults.Namespace != "" {
+				namespaceOwner = "placementBindingDefaults.namespace"
+			}
+
 			err = fmt.Errorf(
-				"the placement %s must have the same namespace as the policy (%s)",
+				"the placement %s must have the same namespace as %s (%s)",
 				plcPath,
-				p.PolicyDefaults.Namespace,
+				namespaceOwner,
+				p.getPlacementNamespace(),
 			)
 
 			return "", nil, err
@@ -1590,10 +4052,77 @@ func (p *Plugin) getPlcFromPath(plcPath string) (string, map[string]interface{},
 	return name, placement, nil
 }
 
-// getCsKey generates the key for the policy's cluster/label selectors to be used in
-// Policies.csToPlc.
+// hasClusterSelector returns whether the input Placement or PlacementRule manifest has a cluster
+// selector. A Placement has one if a predicate sets a requiredClusterSelector with a labelSelector or
+// with claim or CEL expressions. A PlacementRule has one if it sets a clusterSelector or clusters. An
+// explicitly empty labelSelector or clusterSelector is considered an intentional choice to select all
+// clusters.
+func hasClusterSelector(placement map[string]interface{}) bool {
+	spec, _ := placement["spec"].(map[string]interface{})
+
+	if kind, _ := placement["kind"].(string); kind == placementRuleKind {
+		clusters, _ := spec["clusters"].([]interface{})
+		_, hasClusterSelector := spec["clusterSelector"].(map[string]interface{})
+
+		return len(clusters) != 0 || hasClusterSelector
+	}
+
+	predicates, _ := spec["predicates"].([]interface{})
+
+	for _, predicate := range predicates {
+		predicate, _ := predicate.(map[string]interface{})
+		requiredClusterSelector, _ := predicate["requiredClusterSelector"].(map[string]interface{})
+
+		if _, ok := requiredClusterSelector["labelSelector"].(map[string]interface{}); ok {
+			return true
+		}
+
+		claimSelector, _ := requiredClusterSelector["claimSelector"].(map[string]interface{})
+		claimExpressions, _ := claimSelector["matchExpressions"].([]interface{})
+		celSelector, _ := requiredClusterSelector["celSelector"].(map[string]interface{})
+		celExpressions, _ := celSelector["celExpressions"].([]interface{})
+
+		if len(claimExpressions) != 0 || len(celExpressions) != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getCsKey generates the key for the policy's cluster/label selectors and the other generated
+// placement options to be used in Policies.csToPlc. Only placements with the same key are
+// consolidated, so that the options of a placement aren't lost when its selectors match another.
 func getCsKey(placementConfig types.PlacementConfig) string {
-	return fmt.Sprintf("%#v", placementConfig.ClusterSelectors)
+	return fmt.Sprintf(
+		"%#v%#v%#v%#v%#v%#v%#v%#v%#v", placementConfig.ClusterSelectors, placementConfig.ClusterSelector,
+		placementConfig.LabelSelector, placementConfig.Predicates, placementConfig.CelExpressions,
+		placementConfig.ClusterVersion, placementConfig.DecisionStrategy, placementConfig.SpecOverrides,
+		placementConfig.SpreadConstraints,
+	)
+}
+
+// assertSamePlcSelectors returns an error if a placement with the input name was already generated
+// with different cluster/label selectors or placement options than the input placement config, since
+// the selectors and options of the input placement config would otherwise be silently ignored.
+func (p *Plugin) assertSamePlcSelectors(name string, placementConfig types.PlacementConfig) error {
+	csKey := getCsKey(placementConfig)
+
+	for existingCsKey, plcName := range p.csToPlc {
+		if plcName != name || existingCsKey == csKey {
+			continue
+		}
+
+		err := fmt.Errorf(
+			"the placement name %s is used by multiple placements with different cluster selectors or placement "+
+				"options; set a unique placement name for each set of cluster selectors and placement options",
+			name,
+		)
+
+		return wrapSentinel(err, ErrDuplicatePlacement)
+	}
+
+	return nil
 }
 
 // getPlcName will generate a placement name for the policy. If the placement has
@@ -1668,6 +4197,22 @@ func (p *Plugin) createPlacement(
 		return
 	}
 
+	// Policies and policy sets that reference the same named placement share the generated placement
+	if placementConfig.Ref != "" {
+		if p.refPlcs[placementConfig.Name] {
+			name = placementConfig.Name
+			err = p.assertSamePlcSelectors(name, placementConfig)
+
+			return
+		}
+
+		defer func() {
+			if err == nil {
+				p.refPlcs[name] = true
+			}
+		}()
+	}
+
 	plrPath := placementConfig.PlacementRulePath
 	plcPath := placementConfig.PlacementPath
 	var placement map[string]interface{}
@@ -1685,6 +4230,16 @@ func (p *Plugin) createPlacement(
 			return
 		}
 
+		if placementConfig.RequireSelector && !hasClusterSelector(placement) {
+			err = fmt.Errorf(
+				"the placement %s doesn't have a cluster selector, so it selects all clusters; add a cluster "+
+					"selector or set an explicitly empty one to select all clusters",
+				resolvedPlPath,
+			)
+
+			return
+		}
+
 		// processedPlcs keeps track of which placements have been seen by name. This is so
 		// that if the same placement path is provided for multiple policies, it's not re-included
 		// in the generated output of the plugin.
@@ -1716,13 +4271,20 @@ func (p *Plugin) createPlacement(
 			return "", err
 		}
 
+		if placementConfig.ClusterVersion != "" {
+			selectorObj, err = addClusterVersionExpressions(selectorObj, placementConfig.ClusterVersion)
+			if err != nil {
+				return "", err
+			}
+		}
+
 		if p.usingPlR {
 			placement = map[string]interface{}{
 				"apiVersion": placementRuleAPIVersion,
 				"kind":       placementRuleKind,
 				"metadata": map[string]interface{}{
 					"name":      name,
-					"namespace": p.PolicyDefaults.Namespace,
+					"namespace": p.getPlacementNamespace(),
 				},
 				"spec": map[string]interface{}{
 					"clusterSelector": selectorObj,
@@ -1734,7 +4296,7 @@ func (p *Plugin) createPlacement(
 				"kind":       placementKind,
 				"metadata": map[string]interface{}{
 					"name":      name,
-					"namespace": p.PolicyDefaults.Namespace,
+					"namespace": p.getPlacementNamespace(),
 				},
 				"spec": map[string]interface{}{
 					"predicates": []map[string]interface{}{
@@ -1756,6 +4318,51 @@ func (p *Plugin) createPlacement(
 					},
 				},
 			}
+
+			spec := placement["spec"].(map[string]interface{})
+
+			if len(placementConfig.CelExpressions) != 0 {
+				requiredClusterSelector := map[string]interface{}{
+					"celSelector": map[string]interface{}{
+						"celExpressions": placementConfig.CelExpressions,
+					},
+				}
+
+				// Only keep the label selector if it was set since the CEL expressions may be the only selector
+				if len(placementConfig.LabelSelector) != 0 || placementConfig.ClusterVersion != "" {
+					requiredClusterSelector["labelSelector"] = selectorObj
+				}
+
+				spec["predicates"] = []map[string]interface{}{
+					{"requiredClusterSelector": requiredClusterSelector},
+				}
+			}
+
+			// Advanced predicates are used verbatim instead of the predicate built from the label selector
+			if len(placementConfig.Predicates) != 0 {
+				spec["predicates"] = placementConfig.Predicates
+			}
+
+			if len(placementConfig.DecisionStrategy) != 0 {
+				spec["decisionStrategy"] = placementConfig.DecisionStrategy
+			}
+
+			if len(placementConfig.SpreadConstraints) != 0 {
+				spec["spreadPolicy"] = map[string]interface{}{
+					"spreadConstraints": placementConfig.SpreadConstraints,
+				}
+			}
+		}
+
+		if len(placementConfig.SpecOverrides) != 0 {
+			mergePlacementSpec(placement["spec"].(map[string]interface{}), placementConfig.SpecOverrides)
+		}
+
+		p.setCommonMetadata(placement)
+
+		err = p.assertSamePlcSelectors(name, placementConfig)
+		if err != nil {
+			return "", err
 		}
 
 		csKey := getCsKey(placementConfig)
@@ -1763,14 +4370,14 @@ func (p *Plugin) createPlacement(
 	}
 
 	if p.allPlcs[name] {
-		return "", fmt.Errorf("a duplicate placement name was detected: %s", name)
+		return "", wrapSentinel(fmt.Errorf("a duplicate placement name was detected: %s", name), ErrDuplicatePlacement)
 	}
 
 	p.allPlcs[name] = true
 
 	var placementYAML []byte
 
-	placementYAML, err = yaml.Marshal(placement)
+	placementYAML, err = marshalYAML(placement, p.PolicyDefaults.YAMLIndent)
 	if err != nil {
 		err = fmt.Errorf(
 			"an unexpected error occurred when converting the placement to YAML: %w", err,
@@ -1786,7 +4393,8 @@ func (p *Plugin) createPlacement(
 }
 
 // generateSelector determines the type of input and creates a map of selectors to be used in either the
-// clusterSelector or labelSelector field
+// clusterSelector or labelSelector field. A label selector, such as one with only matchLabels, is kept
+// as is, while a legacy key-value map is converted to matchExpressions.
 func (p *Plugin) generateSelector(
 	resolvedSelectors map[string]interface{},
 ) (map[string]interface{}, error) {
@@ -1794,6 +4402,12 @@ func (p *Plugin) generateSelector(
 		return map[string]interface{}{"matchExpressions": []interface{}{}}, nil
 	}
 
+	// Label values such as true or 4 are parsed from YAML as booleans and numbers, but they are strings in
+	// matchLabels, so convert them rather than treating the selector as a legacy key-value map
+	if matchLabels, ok := resolvedSelectors["matchLabels"].(map[string]interface{}); ok {
+		resolvedSelectors = copyMatchLabelsAsStrings(resolvedSelectors, matchLabels)
+	}
+
 	resolvedSelectorsJSON, err := json.Marshal(resolvedSelectors)
 	if err != nil {
 		return nil, err
@@ -1844,9 +4458,180 @@ func (p *Plugin) generateSelector(
 	return resolvedSelectors, nil
 }
 
+// copyMatchLabelsAsStrings returns a copy of the input selector with the boolean and number values in
+// the input matchLabels of the selector converted to strings.
+func copyMatchLabelsAsStrings(
+	selector map[string]interface{}, matchLabels map[string]interface{},
+) map[string]interface{} {
+	selectorCopy := make(map[string]interface{}, len(selector))
+	for key, value := range selector {
+		selectorCopy[key] = value
+	}
+
+	matchLabelsCopy := make(map[string]interface{}, len(matchLabels))
+
+	for key, value := range matchLabels {
+		switch value.(type) {
+		case bool, int, float64:
+			matchLabelsCopy[key] = fmt.Sprint(value)
+		default:
+			matchLabelsCopy[key] = value
+		}
+	}
+
+	selectorCopy["matchLabels"] = matchLabelsCopy
+
+	return selectorCopy
+}
+
+// getClusterVersionExpressions converts the input cluster version constraints to label selector
+// match expressions on the OpenShift version labels of the managed clusters. The constraints are a
+// comma-separated list of major.minor versions, each with an optional ==, !=, >=, >, <=, or <
+// operator. The comparison operators only match versions with the same major version since the
+// label selector operators can't compare versions. An error is returned if a constraint is invalid.
+func getClusterVersionExpressions(clusterVersion string) ([]interface{}, error) {
+	const (
+		majorLabel      = "openshiftVersion-major"
+		majorMinorLabel = "openshiftVersion-major-minor"
+	)
+
+	expressions := []interface{}{}
+
+	addExpression := func(key string, operator metav1.LabelSelectorOperator, values ...string) {
+		expression := map[string]interface{}{"key": key, "operator": string(operator)}
+		if len(values) != 0 {
+			expression["values"] = values
+		}
+
+		expressions = append(expressions, expression)
+	}
+
+	// minorVersions returns the major.minor versions of the major version from minor start to end
+	minorVersions := func(major, start, end int) []string {
+		versions := make([]string, 0, end-start+1)
+		for minor := start; minor <= end; minor++ {
+			versions = append(versions, fmt.Sprintf("%d.%d", major, minor))
+		}
+
+		return versions
+	}
+
+	for _, constraint := range strings.Split(clusterVersion, ",") {
+		constraint = strings.TrimSpace(constraint)
+
+		// The operator is everything before the first digit of the version
+		operator, version := constraint, ""
+		if i := strings.IndexAny(constraint, "0123456789"); i != -1 {
+			operator, version = strings.TrimSpace(constraint[:i]), constraint[i:]
+		}
+
+		var major, minor int
+
+		_, err := fmt.Sscanf(version, "%d.%d", &major, &minor)
+		if err != nil || fmt.Sprintf("%d.%d", major, minor) != version {
+			return nil, fmt.Errorf("the version `%s` must be in the format of major.minor, such as 4.14", version)
+		}
+
+		switch operator {
+		case "", "==":
+			addExpression(majorMinorLabel, metav1.LabelSelectorOpIn, version)
+		case "!=":
+			addExpression(majorMinorLabel, metav1.LabelSelectorOpExists)
+			addExpression(majorMinorLabel, metav1.LabelSelectorOpNotIn, version)
+		case ">=", ">":
+			if operator == ">" {
+				minor++
+			}
+
+			addExpression(majorLabel, metav1.LabelSelectorOpIn, strconv.Itoa(major))
+
+			if minor > 0 {
+				addExpression(majorMinorLabel, metav1.LabelSelectorOpNotIn, minorVersions(major, 0, minor-1)...)
+			}
+		case "<=", "<":
+			if operator == "<" {
+				minor--
+			}
+
+			if minor < 0 {
+				return nil, fmt.Errorf("the constraint `%s` doesn't match any version", constraint)
+			}
+
+			addExpression(majorMinorLabel, metav1.LabelSelectorOpIn, minorVersions(major, 0, minor)...)
+		default:
+			return nil, fmt.Errorf(
+				"the operator `%s` of the constraint `%s` must be one of ==, !=, >=, >, <=, or <", operator, constraint,
+			)
+		}
+	}
+
+	return expressions, nil
+}
+
+// addClusterVersionExpressions returns a copy of the input label selector with the match expressions
+// of the input cluster version constraints added to its matchExpressions.
+func addClusterVersionExpressions(
+	selector map[string]interface{}, clusterVersion string,
+) (map[string]interface{}, error) {
+	versionExpressions, err := getClusterVersionExpressions(clusterVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	selectorCopy := make(map[string]interface{}, len(selector)+1)
+	for key, value := range selector {
+		selectorCopy[key] = value
+	}
+
+	matchExpressions := []interface{}{}
+
+	switch existing := selector["matchExpressions"].(type) {
+	case []interface{}:
+		matchExpressions = append(matchExpressions, existing...)
+	case []map[string]interface{}:
+		for _, expression := range existing {
+			matchExpressions = append(matchExpressions, expression)
+		}
+	}
+
+	selectorCopy["matchExpressions"] = append(matchExpressions, versionExpressions...)
+
+	return selectorCopy, nil
+}
+
+// getPlacementNamespace returns the namespace of the placement bindings and the generated placements,
+// which is placementBindingDefaults.namespace if set and otherwise the namespace of the policies.
+func (p *Plugin) getPlacementNamespace() string {
+	if p.PlacementBindingDefaults.Namespace != "" {
+		return p.PlacementBindingDefaults.Namespace
+	}
+
+	return p.PolicyDefaults.Namespace
+}
+
+// setBindingPlcRef records the explicit placementRef to use in the PlacementBinding of the input
+// placement. An error is returned if a different placementRef was already recorded for the placement.
+func (p *Plugin) setBindingPlcRef(plcName string, ref *types.PlacementRef) error {
+	if ref == nil {
+		return nil
+	}
+
+	if existing := p.bindingPlcRefs[plcName]; existing != nil && *existing != *ref {
+		return fmt.Errorf(
+			"the placement %s has conflicting placement.bindingPlacementRef values %v and %v", plcName, *existing, *ref,
+		)
+	}
+
+	p.bindingPlcRefs[plcName] = ref
+
+	return nil
+}
+
 // createPlacementBinding creates a placement binding for the input placement, policies and policy sets by
-// writing it to the policy generator's output buffer. An error is returned if the placement binding
-// cannot be created.
+// writing it to the policy generator's output buffer. When the binding overrides with a subFilter apply
+// to the policies, the overrides are set on an additional restricted placement binding since a
+// restricted placement binding doesn't propagate the policies on its own. An error is returned if the
+// placement binding cannot be created.
 func (p *Plugin) createPlacementBinding(
 	bindingName, plcName string, policyConfs []*types.PolicyConfig, policySetConfs []*types.PolicySetConfig,
 ) error {
@@ -1881,31 +4666,124 @@ func (p *Plugin) createPlacementBinding(
 		resolvedPlcAPIVersion = placementAPIVersion
 	}
 
-	binding := map[string]interface{}{
-		"apiVersion": placementBindingAPIVersion,
-		"kind":       placementBindingKind,
-		"metadata": map[string]interface{}{
-			"name":      bindingName,
-			"namespace": p.PolicyDefaults.Namespace,
-		},
-		"placementRef": map[string]string{
-			// Remove the version at the end
-			"apiGroup": strings.Split(resolvedPlcAPIVersion, "/")[0],
-			"name":     plcName,
-			"kind":     resolvedPlcKind,
-		},
-		"subjects": subjects,
+	placementRef := map[string]string{
+		// Remove the version at the end
+		"apiGroup": strings.Split(resolvedPlcAPIVersion, "/")[0],
+		"name":     plcName,
+		"kind":     resolvedPlcKind,
 	}
 
-	bindingYAML, err := yaml.Marshal(binding)
-	if err != nil {
-		return fmt.Errorf(
-			"an unexpected error occurred when converting the placement binding to YAML: %w", err,
-		)
+	// Use an explicit placementRef verbatim when one is configured for the placement
+	if ref := p.bindingPlcRefs[plcName]; ref != nil {
+		placementRef = map[string]string{
+			"apiGroup": ref.APIGroup,
+			"name":     ref.Name,
+			"kind":     ref.Kind,
+		}
 	}
 
-	p.outputBuffer.Write([]byte("---\n"))
-	p.outputBuffer.Write(bindingYAML)
+	newBinding := func(name string) map[string]interface{} {
+		return map[string]interface{}{
+			"apiVersion": placementBindingAPIVersion,
+			"kind":       placementBindingKind,
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": p.getPlacementNamespace(),
+			},
+			"placementRef": placementRef,
+			"subjects":     subjects,
+		}
+	}
+
+	bindings := []map[string]interface{}{newBinding(bindingName)}
+
+	if overrides := p.PlacementBindingDefaults.BindingOverrides; overrides != nil &&
+		p.bindingOverridesApply(policyConfs, policySetConfs) {
+		overridesBinding := bindings[0]
+
+		if overrides.SubFilter != "" {
+			overridesBinding = newBinding(getRestrictedBindingName(bindingName))
+			overridesBinding["subFilter"] = strings.ToLower(overrides.SubFilter)
+			bindings = append(bindings, overridesBinding)
+		}
+
+		if overrides.RemediationAction != "" {
+			overridesBinding["bindingOverrides"] = map[string]string{
+				"remediationAction": strings.ToLower(overrides.RemediationAction),
+			}
+		}
+	}
+
+	for _, binding := range bindings {
+		p.setCommonMetadata(binding)
+
+		bindingYAML, err := marshalYAML(binding, p.PolicyDefaults.YAMLIndent)
+		if err != nil {
+			return fmt.Errorf(
+				"an unexpected error occurred when converting the placement binding to YAML: %w", err,
+			)
+		}
+
+		p.outputBuffer.Write([]byte("---\n"))
+		p.outputBuffer.Write(bindingYAML)
+	}
+
+	return nil
+}
+
+// setDerivedLabels adds the labels derived from the policy templates of the policies with
+// deriveLabelsFrom to their policy labels. The policy templates are kept for createPolicy so that the
+// manifests are only read once.
+func (p *Plugin) setDerivedLabels() error {
+	for i := range p.Policies {
+		if len(p.Policies[i].DeriveLabelsFrom) == 0 {
+			continue
+		}
+
+		policyTemplates, err := getPolicyTemplates(&p.Policies[i], p.Values)
+		if err != nil {
+			return err
+		}
+
+		p.policyTemplates[p.Policies[i].Name] = policyTemplates
+
+		mergeDerivedLabels(&p.Policies[i], policyTemplates)
+	}
 
 	return nil
 }
+
+// mergeDerivedLabels adds the labels derived from the input policy templates to the policy labels of
+// the input policy. Labels explicitly set in policyLabels take precedence over the derived labels.
+func mergeDerivedLabels(policyConf *types.PolicyConfig, policyTemplates []map[string]interface{}) {
+	if policyConf.PolicyLabels == nil {
+		policyConf.PolicyLabels = map[string]string{}
+	}
+
+	for key, value := range getDerivedLabels(policyTemplates, policyConf.DeriveLabelsFrom) {
+		if _, ok := policyConf.PolicyLabels[key]; !ok {
+			policyConf.PolicyLabels[key] = value
+		}
+	}
+}
+
+// bindingOverridesApply returns whether the binding overrides apply to the placement binding of the
+// input policies and policy sets. When a policy label is set, the subjects were grouped so that either
+// all or none of the policies have the label.
+func (p *Plugin) bindingOverridesApply(
+	policyConfs []*types.PolicyConfig, policySetConfs []*types.PolicySetConfig,
+) bool {
+	overrides := p.PlacementBindingDefaults.BindingOverrides
+	if overrides == nil {
+		return false
+	}
+
+	return overrides.PolicyLabel == "" ||
+		(len(policyConfs) != 0 && len(policySetConfs) == 0 && hasPolicyLabel(policyConfs[0], overrides.PolicyLabel))
+}
+
+// getRestrictedBindingName returns the name of the additional restricted placement binding that has
+// the binding overrides of the input placement binding.
+func getRestrictedBindingName(bindingName string) string {
+	return bindingName + "-restricted"
+}
@@ -2,12 +2,16 @@
 package internal
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
 	"open-cluster-management.io/policy-generator-plugin/internal/types"
 )
 
@@ -376,6 +380,38 @@ policies:
 	assertEqual(t, err.Error(), expected)
 }
 
+func TestConfigInvalidPolicyAPIVersion(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  policyApiVersion: policy.open-cluster-management.io/v2
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+`,
+		path.Join(tmpDir, "configmap.yaml"),
+	)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := "policyDefaults.policyApiVersion must be one of policy.open-cluster-management.io/v1 or " +
+		"policy.open-cluster-management.io/v1beta1 but got policy.open-cluster-management.io/v2"
+	assertEqual(t, err.Error(), expected)
+}
+
 func TestConfigNoPolicies(t *testing.T) {
 	t.Parallel()
 	const config = `
@@ -888,10 +924,24 @@ policies:
 	assertEqual(t, err.Error(), expected)
 }
 
-func TestConfigPlacementPathNotFound(t *testing.T) {
+func TestConfigPlacementRulePathOnly(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
 	createConfigMap(t, tmpDir, "configmap.yaml")
+	plrPath := path.Join(tmpDir, "plr.yaml")
+	plrYAML := `
+apiVersion: apps.open-cluster-management.io/v1
+kind: PlacementRule
+metadata:
+  name: my-plr
+  namespace: my-policies
+`
+
+	err := os.WriteFile(plrPath, []byte(plrYAML), 0o666)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
 	config := fmt.Sprintf(`
 apiVersion: policy.open-cluster-management.io/v1
 kind: PolicyGenerator
@@ -902,262 +952,230 @@ policyDefaults:
 policies:
 - name: policy-app-config
   placement:
-    placementPath: path/to/pl.yaml
+    placementRulePath: %s
   manifests:
     - path: %s
 `,
-		path.Join(tmpDir, "configmap.yaml"),
+		plrPath, path.Join(tmpDir, "configmap.yaml"),
 	)
 	p := Plugin{}
 
-	err := p.Config([]byte(config), tmpDir)
-	if err == nil {
-		t.Fatal("Expected an error but did not get one")
+	err = p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
 	}
 
-	expected := "policy policy-app-config placement.placementPath could not read the path path/to/pl.yaml"
-	assertEqual(t, err.Error(), expected)
+	assertEqual(t, p.usingPlR, true)
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	manifests, err := unmarshalManifestBytes(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, len(manifests), 3)
+	assertEqual(t, manifests[1]["kind"], placementRuleKind)
+	assertEqual(t, manifests[2]["kind"], placementBindingKind)
+
+	placementRef, _, _ := unstructured.NestedStringMap(manifests[2], "placementRef")
+	assertReflectEqual(t, placementRef, map[string]string{
+		"apiGroup": "apps.open-cluster-management.io",
+		"kind":     placementRuleKind,
+		"name":     "my-plr",
+	})
 }
 
-func TestConfigDuplicateNames(t *testing.T) {
+func TestConfigPlacementPathKindMismatch(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
 	createConfigMap(t, tmpDir, "configmap.yaml")
-	createConfigMap(t, tmpDir, "configmap2.yaml")
+	plrPath := path.Join(tmpDir, "plr.yaml")
+	plrYAML := `
+apiVersion: apps.open-cluster-management.io/v1
+kind: PlacementRule
+metadata:
+  name: my-plr
+  namespace: my-policies
+`
+
+	err := os.WriteFile(plrPath, []byte(plrYAML), 0o666)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
 	config := fmt.Sprintf(`
 apiVersion: policy.open-cluster-management.io/v1
 kind: PolicyGenerator
 metadata:
   name: policy-generator-name
-placementBindingDefaults:
-  name: my-pb
 policyDefaults:
   namespace: my-policies
 policies:
 - name: policy-app-config
-  manifests:
-    - path: %s
-- name: policy-app-config
+  placement:
+    placementPath: %s
   manifests:
     - path: %s
 `,
-		path.Join(tmpDir, "configmap.yaml"),
-		path.Join(tmpDir, "configmap2.yaml"),
+		plrPath, path.Join(tmpDir, "configmap.yaml"),
 	)
 	p := Plugin{}
 
-	err := p.Config([]byte(config), tmpDir)
+	err = p.Config([]byte(config), tmpDir)
 	if err == nil {
 		t.Fatal("Expected an error but did not get one")
 	}
 
-	expected := "each policy must have a unique name set, " +
-		"but found a duplicate name: policy-app-config"
+	expected := fmt.Sprintf(
+		"policy policy-app-config placement.placementPath %s must have a Placement but it has a PlacementRule", plrPath,
+	)
 	assertEqual(t, err.Error(), expected)
 }
 
-func TestConfigInvalidEvalInterval(t *testing.T) {
+func TestConfigPlacementSelectorsInvalid(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
 	createConfigMap(t, tmpDir, "configmap.yaml")
 
-	tests := []struct {
-		// Individual values can't be used for compliant/noncompliant since an empty string means
-		// to not inherit from the policy defaults.
-		defaultEvalInterval  string
-		policyEvalInterval   string
-		manifestEvalInterval string
-		expectedMsg          string
+	predicates := "predicates: [{requiredClusterSelector: {labelSelector: {matchLabels: {cloud: red hat}}}}]"
+
+	tests := map[string]struct {
+		placement   string
+		expectedErr string
 	}{
-		{
-			`{"compliant": "not a duration"}`,
-			"",
-			"",
-			`the policy policy-app has an invalid policy.evaluationInterval.compliant value: time: invalid duration ` +
-				`"not a duration"`,
+		"with a labelSelector": {
+			"{labelSelector: {cloud: red hat}, " + predicates + "}",
+			"policy policy-app-config placement.predicates may not be set with placement.labelSelector or " +
+				"placement.clusterSelector",
 		},
-		{
-			`{"noncompliant": "not a duration"}`,
-			"",
-			"",
-			`the policy policy-app has an invalid policy.evaluationInterval.noncompliant value: time: invalid ` +
-				`duration "not a duration"`,
+		"with a clusterSelector": {
+			"{clusterSelector: {matchLabels: {cloud: red hat}}, " + predicates + "}",
+			"policy policy-app-config placement.predicates may not be set with placement.labelSelector or " +
+				"placement.clusterSelector",
 		},
-		{
-			"",
-			`{"compliant": "not a duration"}`,
-			"",
-			`the policy policy-app has an invalid policy.evaluationInterval.compliant value: time: invalid duration ` +
-				`"not a duration"`,
+		"with a placementRuleName": {
+			"{placementRuleName: my-placement-rule, " + predicates + "}",
+			"policy policy-app-config placement.predicates may only be used with a Placement and not a " +
+				"PlacementRule",
 		},
-		{
-			"",
-			`{"noncompliant": "not a duration"}`,
-			"",
-			`the policy policy-app has an invalid policy.evaluationInterval.noncompliant value: time: invalid ` +
-				`duration "not a duration"`,
+		"celExpressions with predicates": {
+			"{celExpressions: [cluster.metadata.name == 'local-cluster'], " + predicates + "}",
+			"policy policy-app-config placement.celExpressions may not be set with placement.predicates",
 		},
-		{
-			"",
-			"",
-			`{"compliant": "not a duration"}`,
-			`the policy policy-app has the evaluationInterval value set on manifest[0] but consolidateManifests is ` +
-				`true`,
+		"celExpressions with a clusterSelector": {
+			"{celExpressions: [cluster.metadata.name == 'local-cluster'], clusterSelector: {matchLabels: {a: b}}}",
+			"policy policy-app-config placement.celExpressions may only be used with a Placement and not a " +
+				"PlacementRule",
 		},
-		{
-			"",
-			"",
-			`{"noncompliant": "not a duration"}`,
-			`the policy policy-app has the evaluationInterval value set on manifest[0] but consolidateManifests is ` +
-				`true`,
+		"empty celExpression": {
+			"{celExpressions: ['  ']}",
+			"policy policy-app-config placement.celExpressions[0] must not be empty",
 		},
-		{
-			"",
-			`{"compliant": "10d5h1m"}`,
-			"",
-			`the policy policy-app has an invalid policy.evaluationInterval.compliant value: time: unknown unit "d" ` +
-				`in duration "10d5h1m"`,
+		"clusterVersion with predicates": {
+			"{clusterVersion: '>=4.14', " + predicates + "}",
+			"policy policy-app-config placement.clusterVersion may not be set with placement.predicates",
 		},
-		{
-			"",
-			`{"noncompliant": "1w2d"}`,
-			"",
-			`the policy policy-app has an invalid policy.evaluationInterval.noncompliant value: time: unknown unit ` +
-				`"w" in duration "1w2d"`,
+		"clusterVersion with a clusterSelector": {
+			"{clusterVersion: '>=4.14', clusterSelector: {matchLabels: {a: b}}}",
+			"policy policy-app-config placement.clusterVersion may only be used with a Placement and not a " +
+				"PlacementRule",
+		},
+		"clusterVersion with an invalid version": {
+			"{clusterVersion: '>=4.x'}",
+			"policy policy-app-config placement.clusterVersion `>=4.x` is invalid: the version `4.x` must be in " +
+				"the format of major.minor, such as 4.14",
+		},
+		"clusterVersion with an invalid operator": {
+			"{clusterVersion: '=~4.14'}",
+			"policy policy-app-config placement.clusterVersion `=~4.14` is invalid: the operator `=~` of the " +
+				"constraint `=~4.14` must be one of ==, !=, >=, >, <=, or <",
+		},
+		"clusterVersion that matches nothing": {
+			"{clusterVersion: '<4.0'}",
+			"policy policy-app-config placement.clusterVersion `<4.0` is invalid: the constraint `<4.0` doesn't " +
+				"match any version",
 		},
 	}
 
-	for _, test := range tests {
+	for name, test := range tests {
 		test := test
 
-		t.Run(
-			fmt.Sprintf("expected=%s", test.expectedMsg),
-			func(t *testing.T) {
-				t.Parallel()
-				config := fmt.Sprintf(`
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
 apiVersion: policy.open-cluster-management.io/v1
 kind: PolicyGenerator
 metadata:
   name: policy-generator-name
 policyDefaults:
   namespace: my-policies
-  evaluationInterval: %s
 policies:
-- name: policy-app
-  evaluationInterval: %s
+- name: policy-app-config
+  placement: %s
   manifests:
     - path: %s
-      evaluationInterval: %s
 `,
-					test.defaultEvalInterval,
-					test.policyEvalInterval,
-					path.Join(tmpDir, "configmap.yaml"),
-					test.manifestEvalInterval,
-				)
+				test.placement, path.Join(tmpDir, "configmap.yaml"),
+			)
+			p := Plugin{}
 
-				p := Plugin{}
-				err := p.Config([]byte(config), tmpDir)
-				if err == nil {
-					t.Fatal("Expected an error but did not get one")
-				}
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
 
-				assertEqual(t, err.Error(), test.expectedMsg)
-			},
-		)
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
 	}
 }
 
-func TestConfigInvalidManifestKey(t *testing.T) {
+func TestConfigPlacementDecisionStrategyWithPlacementRule(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
 	createConfigMap(t, tmpDir, "configmap.yaml")
-
-	tests := map[string]struct {
-		// Individual values can't be used for compliant/noncompliant since an empty string means
-		// to not inherit from the policy defaults.
-		keyName     string
-		defaultKey  string
-		policyKey   string
-		manifestKey string
-		expectedMsg string
-	}{
-		"pruneObjectBehavior specified in manifest": {
-			"pruneObjectBehavior",
-			"",
-			"",
-			"None",
-			`the policy policy-app has the pruneObjectBehavior value set` +
-				` on manifest[0] but consolidateManifests is true`,
-		},
-		"namespaceSelector specified in manifest": {
-			"namespaceSelector",
-			"",
-			"",
-			`{"include": ["test"]}`,
-			`the policy policy-app has the namespaceSelector value set` +
-				` on manifest[0] but consolidateManifests is true`,
-		},
-		"remediationAction specified in manifest": {
-			"remediationAction",
-			"",
-			"",
-			"enforce",
-			`the policy policy-app has the remediationAction value set` +
-				` on manifest[0] but consolidateManifests is true`,
-		},
-		"severity specified in manifest": {
-			"severity",
-			"",
-			"",
-			"critical",
-			`the policy policy-app has the severity value set` +
-				` on manifest[0] but consolidateManifests is true`,
-		},
-	}
-
-	for testName, test := range tests {
-		test := test
-
-		t.Run(
-			testName,
-			func(t *testing.T) {
-				t.Parallel()
-				config := fmt.Sprintf(`
+	config := fmt.Sprintf(`
 apiVersion: policy.open-cluster-management.io/v1
 kind: PolicyGenerator
 metadata:
   name: policy-generator-name
 policyDefaults:
   namespace: my-policies
-  %s: %s
 policies:
-- name: policy-app
-  %s: %s
+- name: policy-app-config
+  placement:
+    clusterSelector:
+      matchLabels:
+        cloud: red hat
+    decisionStrategy:
+      groupStrategy:
+        clustersPerDecisionGroup: 25%%
   manifests:
     - path: %s
-      %s: %s
 `,
-					test.keyName, test.defaultKey,
-					test.keyName, test.policyKey,
-					path.Join(tmpDir, "configmap.yaml"),
-					test.keyName, test.manifestKey,
-				)
+		path.Join(tmpDir, "configmap.yaml"),
+	)
+	p := Plugin{}
 
-				p := Plugin{}
-				err := p.Config([]byte(config), tmpDir)
-				if err == nil {
-					t.Fatal("Expected an error but did not get one")
-				}
+	err := p.Config([]byte(config), tmpDir)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
 
-				assertEqual(t, err.Error(), test.expectedMsg)
-			},
-		)
-	}
+	expected := "policy policy-app-config placement.decisionStrategy may only be used with a Placement and not " +
+		"a PlacementRule"
+	assertEqual(t, err.Error(), expected)
 }
 
-func TestConfigNoManifests(t *testing.T) {
+func TestConfigPlacementSpreadConstraintsWithPlacementRule(t *testing.T) {
 	t.Parallel()
-	const config = `
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	config := fmt.Sprintf(`
 apiVersion: policy.open-cluster-management.io/v1
 kind: PolicyGenerator
 metadata:
@@ -1166,26 +1184,48 @@ policyDefaults:
   namespace: my-policies
 policies:
 - name: policy-app-config
-`
-
+  placement:
+    clusterSelector:
+      matchLabels:
+        cloud: red hat
+    spreadConstraints:
+      - topologyKey: region
+        topologyKeyType: Label
+  manifests:
+    - path: %s
+`,
+		path.Join(tmpDir, "configmap.yaml"),
+	)
 	p := Plugin{}
 
-	err := p.Config([]byte(config), "")
+	err := p.Config([]byte(config), tmpDir)
 	if err == nil {
 		t.Fatal("Expected an error but did not get one")
 	}
 
-	expected := "each policy must have at least one manifest, " +
-		"but found none in policy policy-app-config"
+	expected := "policy policy-app-config placement.spreadConstraints may only be used with a Placement and not " +
+		"a PlacementRule"
 	assertEqual(t, err.Error(), expected)
 }
 
-func TestConfigManifestNotFound(t *testing.T) {
+func TestConfigPlacementAllClustersConflict(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
-	manifestPath := path.Join(tmpDir, "configmap.yaml")
-	config := fmt.Sprintf(
-		`
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := map[string]string{
+		"labelSelector":   "labelSelector:\n      cloud: red hat",
+		"clusterSelector": "clusterSelector:\n      matchLabels:\n        cloud: red hat",
+		"placementName":   "placementName: my-placement",
+	}
+
+	for name, placement := range tests {
+		placement := placement
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
 apiVersion: policy.open-cluster-management.io/v1
 kind: PolicyGenerator
 metadata:
@@ -1194,30 +1234,94 @@ policyDefaults:
   namespace: my-policies
 policies:
 - name: policy-app-config
+  placement:
+    allClusters: true
+    %s
   manifests:
     - path: %s
 `,
-		manifestPath,
+				placement, path.Join(tmpDir, "configmap.yaml"),
+			)
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			expected := "policy policy-app-config placement.allClusters may not be set with a placement selector, " +
+				"placement path, or placement name"
+			assertEqual(t, err.Error(), expected)
+		})
+	}
+}
+
+func TestConfigPlacementAllClustersOverridesDefault(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  placement:
+    labelSelector:
+      cloud: red hat
+policies:
+- name: policy-app-config
+  placement:
+    allClusters: true
+  manifests:
+    - path: %s
+`,
+		path.Join(tmpDir, "configmap.yaml"),
 	)
 	p := Plugin{}
 
 	err := p.Config([]byte(config), tmpDir)
-	if err == nil {
-		t.Fatal("Expected an error but did not get one")
+	if err != nil {
+		t.Fatal(err.Error())
 	}
 
-	expected := fmt.Sprintf(
-		"could not read the manifest path %s in policy policy-app-config", manifestPath,
-	)
-	assertEqual(t, err.Error(), expected)
+	assertEqual(t, p.Policies[0].Placement.AllClusters, true)
+	assertEqual(t, len(p.Policies[0].Placement.LabelSelector), 0)
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	manifests, err := unmarshalManifestBytes(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for _, manifest := range manifests {
+		if manifest["kind"] != "Placement" {
+			continue
+		}
+
+		predicates, _, _ := unstructured.NestedSlice(manifest, "spec", "predicates")
+		//nolint:forcetypeassert
+		matchExpressions, _, _ := unstructured.NestedSlice(
+			predicates[0].(map[string]interface{}), "requiredClusterSelector", "labelSelector", "matchExpressions",
+		)
+		assertEqual(t, len(matchExpressions), 0)
+
+		return
+	}
+
+	t.Fatal("Expected a Placement to be generated")
 }
 
-func TestConfigNoPolicyName(t *testing.T) {
+func TestConfigPlacementPathNotFound(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
 	createConfigMap(t, tmpDir, "configmap.yaml")
-	config := fmt.Sprintf(
-		`
+	config := fmt.Sprintf(`
 apiVersion: policy.open-cluster-management.io/v1
 kind: PolicyGenerator
 metadata:
@@ -1225,7 +1329,10 @@ metadata:
 policyDefaults:
   namespace: my-policies
 policies:
-- manifests:
+- name: policy-app-config
+  placement:
+    placementPath: path/to/pl.yaml
+  manifests:
     - path: %s
 `,
 		path.Join(tmpDir, "configmap.yaml"),
@@ -1237,32 +1344,34 @@ policies:
 		t.Fatal("Expected an error but did not get one")
 	}
 
-	expected := "each policy must have a name set, but did not find a name at policy array index 0"
+	expected := "policy policy-app-config placement.placementPath could not read the path path/to/pl.yaml"
 	assertEqual(t, err.Error(), expected)
 }
 
-func TestConfigPlrNotFound(t *testing.T) {
+func TestConfigDuplicateNames(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
 	createConfigMap(t, tmpDir, "configmap.yaml")
-	plrPath := path.Join(tmpDir, "plr.yaml")
-	config := fmt.Sprintf(
-		`
+	createConfigMap(t, tmpDir, "configmap2.yaml")
+	config := fmt.Sprintf(`
 apiVersion: policy.open-cluster-management.io/v1
 kind: PolicyGenerator
 metadata:
   name: policy-generator-name
+placementBindingDefaults:
+  name: my-pb
 policyDefaults:
   namespace: my-policies
-  placement:
-    placementRulePath: %s
 policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
 - name: policy-app-config
   manifests:
     - path: %s
 `,
-		plrPath,
 		path.Join(tmpDir, "configmap.yaml"),
+		path.Join(tmpDir, "configmap2.yaml"),
 	)
 	p := Plugin{}
 
@@ -1271,427 +1380,3225 @@ policies:
 		t.Fatal("Expected an error but did not get one")
 	}
 
-	expected := fmt.Sprintf("policyDefaults placement.placementRulePath could not read the path %s", plrPath)
+	expected := "each policy must have a unique name set, " +
+		"but found a duplicate name: policy-app-config"
 	assertEqual(t, err.Error(), expected)
 }
 
-func TestPolicySetConfig(t *testing.T) {
+func TestConfigInvalidEvalInterval(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
 	createConfigMap(t, tmpDir, "configmap.yaml")
 
-	testCases := []testCase{
+	tests := []struct {
+		// Individual values can't be used for compliant/noncompliant since an empty string means
+		// to not inherit from the policy defaults.
+		defaultEvalInterval  string
+		policyEvalInterval   string
+		manifestEvalInterval string
+		expectedMsg          string
+	}{
 		{
-			name: "policySet must have a name set",
-			setupFunc: func(p *Plugin) {
-				p.PolicySets = []types.PolicySetConfig{
-					{
-						PolicySetOptions: types.PolicySetOptions{
-							Placement: types.PlacementConfig{
-								Name:             "policyset-placement",
-								ClusterSelectors: map[string]interface{}{"my": "app"},
-							},
-						},
-					},
-				}
-			},
-			expectedErrMsg: "each policySet must have a name set, but did not find a name at policySet array index 0",
+			`{"compliant": "not a duration"}`,
+			"",
+			"",
+			`the policy policy-app has an invalid policy.evaluationInterval.compliant value: time: invalid duration ` +
+				`"not a duration"`,
 		},
 		{
-			name: "policySet must be unique",
-			setupFunc: func(p *Plugin) {
-				p.PolicySets = []types.PolicySetConfig{
-					{
-						Name: "my-policyset",
-					},
-					{
-						Name: "my-policyset",
-					},
-				}
-			},
-			expectedErrMsg: "each policySet must have a unique name set, but found a duplicate name: my-policyset",
+			`{"noncompliant": "not a duration"}`,
+			"",
+			"",
+			`the policy policy-app has an invalid policy.evaluationInterval.noncompliant value: time: invalid ` +
+				`duration "not a duration"`,
 		},
 		{
-			name: "policySet must provide only one of placementRulePath or placementPath",
-			setupFunc: func(p *Plugin) {
-				p.PolicySets = []types.PolicySetConfig{
-					{
-						Name: "my-policyset",
-						PolicySetOptions: types.PolicySetOptions{
-							Placement: types.PlacementConfig{
-								PlacementPath:     "../config/plc.yaml",
-								PlacementRulePath: "../config/plr.yaml",
-							},
-						},
-					},
-				}
-			},
-			expectedErrMsg: "policySet my-policyset must provide only one of " +
-				"placement.placementPath or placement.placementRulePath",
+			"",
+			`{"compliant": "not a duration"}`,
+			"",
+			`the policy policy-app has an invalid policy.evaluationInterval.compliant value: time: invalid duration ` +
+				`"not a duration"`,
 		},
 		{
-			name: "policySet must provide only one of placementRuleName or placementName",
-			setupFunc: func(p *Plugin) {
-				p.PolicySets = []types.PolicySetConfig{
-					{
-						Name: "my-policyset",
-						PolicySetOptions: types.PolicySetOptions{
-							Placement: types.PlacementConfig{
-								PlacementName:     "plExistingName",
-								PlacementRuleName: "plrExistingName",
-							},
-						},
-					},
-				}
-			},
-			expectedErrMsg: "policySet my-policyset must provide only one of " +
-				"placement.placementName or placement.placementRuleName",
+			"",
+			`{"noncompliant": "not a duration"}`,
+			"",
+			`the policy policy-app has an invalid policy.evaluationInterval.noncompliant value: time: invalid ` +
+				`duration "not a duration"`,
 		},
 		{
-			name: "policySet must provide only one of labelSelector or clusterSelectors",
-			setupFunc: func(p *Plugin) {
-				p.PolicySets = []types.PolicySetConfig{
-					{
-						Name: "my-policyset",
-						PolicySetOptions: types.PolicySetOptions{
-							Placement: types.PlacementConfig{
-								LabelSelector:    map[string]interface{}{"cloud": "red hat"},
-								ClusterSelectors: map[string]interface{}{"cloud": "red hat"},
-							},
-						},
-					},
-				}
-			},
-			expectedErrMsg: "policySet my-policyset must provide only one of placement.labelSelector or " +
-				"placement.clusterSelectors",
+			"",
+			"",
+			`{"compliant": "not a duration"}`,
+			`the policy policy-app has the evaluationInterval value set on manifest[0] but consolidateManifests is ` +
+				`true`,
 		},
 		{
-			name: "policySet may not specify a cluster selector and placement path together",
-			setupFunc: func(p *Plugin) {
-				p.PolicySets = []types.PolicySetConfig{
-					{
-						Name: "my-policyset",
-						PolicySetOptions: types.PolicySetOptions{
-							Placement: types.PlacementConfig{
-								PlacementPath:    "../config/plc.yaml",
-								ClusterSelectors: map[string]interface{}{"cloud": "red hat"},
-							},
-						},
-					},
-				}
-			},
-			expectedErrMsg: "policySet my-policyset must specify only one of placement selector, placement path, or " +
-				"placement name",
+			"",
+			"",
+			`{"noncompliant": "not a duration"}`,
+			`the policy policy-app has the evaluationInterval value set on manifest[0] but consolidateManifests is ` +
+				`true`,
 		},
 		{
-			name: "policySet may not specify a cluster selector and placement name together",
-			setupFunc: func(p *Plugin) {
-				p.PolicySets = []types.PolicySetConfig{
-					{
-						Name: "my-policyset",
-						PolicySetOptions: types.PolicySetOptions{
-							Placement: types.PlacementConfig{
-								PlacementName:    "plexistingname",
-								ClusterSelectors: map[string]interface{}{"cloud": "red hat"},
-							},
-						},
-					},
-				}
-			},
-			expectedErrMsg: "policySet my-policyset must specify only one of placement selector, placement path, or " +
-				"placement name",
+			"",
+			`{"compliant": "10d5h1m"}`,
+			"",
+			`the policy policy-app has an invalid policy.evaluationInterval.compliant value: time: unknown unit "d" ` +
+				`in duration "10d5h1m"`,
 		},
 		{
-			name: "policySet may not specify a label selector and placement path together",
-			setupFunc: func(p *Plugin) {
-				p.PolicySets = []types.PolicySetConfig{
-					{
-						Name: "my-policyset",
-						PolicySetOptions: types.PolicySetOptions{
-							Placement: types.PlacementConfig{
-								PlacementPath: "../config/plc.yaml",
-								LabelSelector: map[string]interface{}{"cloud": "red hat"},
-							},
-						},
-					},
-				}
-			},
-			expectedErrMsg: "policySet my-policyset must specify only one of placement selector, placement path, or " +
-				"placement name",
+			"",
+			`{"noncompliant": "1w2d"}`,
+			"",
+			`the policy policy-app has an invalid policy.evaluationInterval.noncompliant value: time: unknown unit ` +
+				`"w" in duration "1w2d"`,
 		},
-		{
-			name: "policySet may not specify a label selector and placement name together",
-			setupFunc: func(p *Plugin) {
-				p.PolicySets = []types.PolicySetConfig{
-					{
-						Name: "my-policyset",
-						PolicySetOptions: types.PolicySetOptions{
-							Placement: types.PlacementConfig{
-								PlacementName: "plexistingname",
-								LabelSelector: map[string]interface{}{"cloud": "red hat"},
-							},
-						},
-					},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(
+			fmt.Sprintf("expected=%s", test.expectedMsg),
+			func(t *testing.T) {
+				t.Parallel()
+				config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  evaluationInterval: %s
+policies:
+- name: policy-app
+  evaluationInterval: %s
+  manifests:
+    - path: %s
+      evaluationInterval: %s
+`,
+					test.defaultEvalInterval,
+					test.policyEvalInterval,
+					path.Join(tmpDir, "configmap.yaml"),
+					test.manifestEvalInterval,
+				)
+
+				p := Plugin{}
+				err := p.Config([]byte(config), tmpDir)
+				if err == nil {
+					t.Fatal("Expected an error but did not get one")
 				}
+
+				assertEqual(t, err.Error(), test.expectedMsg)
 			},
-			expectedErrMsg: "policySet my-policyset must specify only one of placement selector, placement path, or " +
-				"placement name",
+		)
+	}
+}
+
+func TestConfigEvalIntervalValues(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		evalInterval string
+		expectedErr  string
+	}{
+		"compound duration": {evalInterval: "1h30m"},
+		"never":             {evalInterval: "never"},
+		"watch":             {evalInterval: "watch"},
+		"zero duration": {
+			evalInterval: "0s",
+			expectedErr:  "the duration 0s must be greater than zero",
 		},
-		{
-			name: "policySet may not specify a cluster selector and placementrule path together",
-			setupFunc: func(p *Plugin) {
-				p.PolicySets = []types.PolicySetConfig{
-					{
-						Name: "my-policyset",
-						PolicySetOptions: types.PolicySetOptions{
-							Placement: types.PlacementConfig{
-								PlacementRulePath: "../config/plc.yaml",
-								ClusterSelectors:  map[string]interface{}{"cloud": "red hat"},
-							},
-						},
-					},
-				}
-			},
-			expectedErrMsg: "policySet my-policyset must specify only one of placement selector, placement path, or " +
-				"placement name",
+		"negative duration": {
+			evalInterval: "-5m",
+			expectedErr:  "the duration -5m must be greater than zero",
 		},
-		{
-			name: "policySet may not specify a cluster selector and placementrule name together",
-			setupFunc: func(p *Plugin) {
-				p.PolicySets = []types.PolicySetConfig{
-					{
-						Name: "my-policyset",
-						PolicySetOptions: types.PolicySetOptions{
-							Placement: types.PlacementConfig{
-								PlacementRuleName: "plrexistingname",
-								ClusterSelectors:  map[string]interface{}{"cloud": "red hat"},
-							},
-						},
-					},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, level := range []string{"policy", "manifest"} {
+				policyEvalInterval := "{}"
+				manifestEvalInterval := "{}"
+				expectedField := "policy.evaluationInterval.noncompliant"
+
+				if level == "policy" {
+					policyEvalInterval = fmt.Sprintf(`{"noncompliant": "%s"}`, test.evalInterval)
+				} else {
+					manifestEvalInterval = fmt.Sprintf(`{"noncompliant": "%s"}`, test.evalInterval)
+					expectedField = "manifest[0].evaluationInterval.noncompliant"
 				}
-			},
-			expectedErrMsg: "policySet my-policyset must specify only one of placement selector, placement path, or " +
-				"placement name",
-		},
-		{
-			name: "policySet may not specify a label selector and placementrule path together",
-			setupFunc: func(p *Plugin) {
-				p.PolicySets = []types.PolicySetConfig{
-					{
-						Name: "my-policyset",
-						PolicySetOptions: types.PolicySetOptions{
-							Placement: types.PlacementConfig{
-								PlacementRulePath: "../config/plc.yaml",
-								LabelSelector:     map[string]interface{}{"cloud": "red hat"},
-							},
-						},
-					},
+
+				config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app
+  consolidateManifests: false
+  evaluationInterval: %s
+  manifests:
+    - path: %s
+      evaluationInterval: %s
+`,
+					policyEvalInterval, path.Join(tmpDir, "configmap.yaml"), manifestEvalInterval,
+				)
+
+				p := Plugin{}
+
+				err := p.Config([]byte(config), tmpDir)
+				if test.expectedErr == "" {
+					if err != nil {
+						t.Fatal(err.Error())
+					}
+
+					continue
 				}
-			},
-			expectedErrMsg: "policySet my-policyset must specify only one of placement selector, placement path, or " +
-				"placement name",
-		},
-		{
-			name: "policySet may not specify a label selector and placementrule name together",
-			setupFunc: func(p *Plugin) {
-				p.PolicySets = []types.PolicySetConfig{
-					{
-						Name: "my-policyset",
-						PolicySetOptions: types.PolicySetOptions{
-							Placement: types.PlacementConfig{
-								PlacementRuleName: "plrexistingname",
-								LabelSelector:     map[string]interface{}{"cloud": "red hat"},
-							},
-						},
-					},
+
+				if err == nil {
+					t.Fatal("Expected an error but did not get one")
 				}
-			},
-			expectedErrMsg: "policySet my-policyset must specify only one of placement selector, placement path, or " +
-				"placement name",
+
+				expected := fmt.Sprintf(
+					"the policy policy-app has an invalid %s value: %s", expectedField, test.expectedErr,
+				)
+				assertEqual(t, err.Error(), expected)
+			}
+		})
+	}
+}
+
+func TestConfigInvalidManifestKey(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		// Individual values can't be used for compliant/noncompliant since an empty string means
+		// to not inherit from the policy defaults.
+		keyName     string
+		defaultKey  string
+		policyKey   string
+		manifestKey string
+		expectedMsg string
+	}{
+		"pruneObjectBehavior specified in manifest": {
+			"pruneObjectBehavior",
+			"",
+			"",
+			"None",
+			`the policy policy-app has the pruneObjectBehavior value set` +
+				` on manifest[0] but consolidateManifests is true`,
 		},
-		{
-			name: "policySet placementrule path not resolvable",
-			setupFunc: func(p *Plugin) {
-				p.PolicySets = []types.PolicySetConfig{
-					{
-						Name: "my-policyset",
-						PolicySetOptions: types.PolicySetOptions{
-							Placement: types.PlacementConfig{
-								PlacementRulePath: "../config/plc.yaml",
-							},
-						},
-					},
-				}
-			},
-			expectedErrMsg: "policySet my-policyset placement.placementRulePath " +
-				"could not read the path ../config/plc.yaml",
+		"namespaceSelector specified in manifest": {
+			"namespaceSelector",
+			"",
+			"",
+			`{"include": ["test"]}`,
+			`the policy policy-app has the namespaceSelector value set` +
+				` on manifest[0] but consolidateManifests is true`,
 		},
-		{
-			name: "policySet placement path not resolvable",
-			setupFunc: func(p *Plugin) {
-				p.PolicySets = []types.PolicySetConfig{
-					{
-						Name: "my-policyset",
-						PolicySetOptions: types.PolicySetOptions{
-							Placement: types.PlacementConfig{
-								PlacementPath: "../config/plc.yaml",
-							},
-						},
-					},
-				}
-			},
-			expectedErrMsg: "policySet my-policyset placement.placementPath could not read the path ../config/plc.yaml",
+		"remediationAction specified in manifest": {
+			"remediationAction",
+			"",
+			"",
+			"enforce",
+			`the policy policy-app has the remediationAction value set` +
+				` on manifest[0] but consolidateManifests is true`,
 		},
-		{
-			name: "Placement and PlacementRule can't be mixed",
-			setupFunc: func(p *Plugin) {
-				p.Policies[0].Placement = types.PlacementConfig{
-					LabelSelector: map[string]interface{}{"cloud": "red hat"},
-				}
-				p.PolicySets = []types.PolicySetConfig{
-					{
-						Name: "my-policyset",
-						PolicySetOptions: types.PolicySetOptions{
-							Placement: types.PlacementConfig{
-								ClusterSelectors: map[string]interface{}{"cloud": "red hat"},
-							},
-						},
-					},
+		"severity specified in manifest": {
+			"severity",
+			"",
+			"",
+			"critical",
+			`the policy policy-app has the severity value set` +
+				` on manifest[0] but consolidateManifests is true`,
+		},
+	}
+
+	for testName, test := range tests {
+		test := test
+
+		t.Run(
+			testName,
+			func(t *testing.T) {
+				t.Parallel()
+				config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  %s: %s
+policies:
+- name: policy-app
+  %s: %s
+  manifests:
+    - path: %s
+      %s: %s
+`,
+					test.keyName, test.defaultKey,
+					test.keyName, test.policyKey,
+					path.Join(tmpDir, "configmap.yaml"),
+					test.keyName, test.manifestKey,
+				)
+
+				p := Plugin{}
+				err := p.Config([]byte(config), tmpDir)
+				if err == nil {
+					t.Fatal("Expected an error but did not get one")
 				}
+
+				assertEqual(t, err.Error(), test.expectedMsg)
 			},
-			expectedErrMsg: "may not use a mix of Placement and PlacementRule for policies and policysets; found 1 " +
-				"Placement and 1 PlacementRule",
+		)
+	}
+}
+
+func TestConfigInvalidRecordDiff(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	configMapPath := path.Join(tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		policyDefaults    string
+		policySetDefaults string
+		policy            string
+		manifest          string
+		expectedErr       string
+	}{
+		"policyDefaults": {
+			policyDefaults: "Logs",
+			expectedErr:    "policyDefaults.recordDiff must be one of Log, InStatus, or None but got Logs",
+		},
+		"policySetDefaults": {
+			policySetDefaults: "Logs",
+			expectedErr:       "policySetDefaults.recordDiff must be one of Log, InStatus, or None but got Logs",
+		},
+		"policy": {
+			policy: "Logs",
+			expectedErr: "the policy policy-app-config has an invalid recordDiff value Logs; it must be one of " +
+				"Log, InStatus, or None",
+		},
+		"manifest": {
+			manifest: "Logs",
+			expectedErr: "the policy policy-app-config has an invalid manifest[0].recordDiff value Logs; it must be " +
+				"one of Log, InStatus, or None",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  recordDiff: "%s"
+policySetDefaults:
+  recordDiff: "%s"
+policies:
+- name: policy-app-config
+  recordDiff: "%s"
+  manifests:
+    - path: %s
+      recordDiff: "%s"
+`,
+				test.policyDefaults, test.policySetDefaults, test.policy, configMapPath, test.manifest,
+			)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+func TestConfigPolicySetDefaultsRecordDiff(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	configMapPath := path.Join(tmpDir, "configmap.yaml")
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  recordDiff: None
+policySetDefaults:
+  recordDiff: Log
+policies:
+- name: policy-in-set
+  policySets:
+    - my-set
+  manifests:
+    - path: %s
+- name: policy-in-set-override
+  recordDiff: InStatus
+  manifests:
+    - path: %s
+- name: policy-not-in-set
+  manifests:
+    - path: %s
+policySets:
+- name: my-set
+  policies:
+    - policy-in-set-override
+`,
+		configMapPath, configMapPath, configMapPath,
+	)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, p.Policies[0].RecordDiff, "Log")
+	assertEqual(t, p.Policies[0].Manifests[0].RecordDiff, "Log")
+	assertEqual(t, p.Policies[1].RecordDiff, "InStatus")
+	assertEqual(t, p.Policies[1].Manifests[0].RecordDiff, "InStatus")
+	assertEqual(t, p.Policies[2].RecordDiff, "None")
+	assertEqual(t, p.Policies[2].Manifests[0].RecordDiff, "None")
+}
+
+func TestConfigPolicySetDefaultsCustomMessage(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	configMapPath := path.Join(tmpDir, "configmap.yaml")
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  customMessage:
+    compliant: "default compliant"
+    noncompliant: "default noncompliant"
+policySetDefaults:
+  customMessage:
+    noncompliant: "set noncompliant"
+policies:
+- name: policy-in-set
+  policySets:
+    - my-set
+  manifests:
+    - path: %s
+- name: policy-in-set-override
+  customMessage:
+    noncompliant: "policy noncompliant"
+  manifests:
+    - path: %s
+- name: policy-not-in-set
+  manifests:
+    - path: %s
+policySets:
+- name: my-set
+  policies:
+    - policy-in-set-override
+`,
+		configMapPath, configMapPath, configMapPath,
+	)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, p.Policies[0].CustomMessage.Compliant, "default compliant")
+	assertEqual(t, p.Policies[0].CustomMessage.NonCompliant, "set noncompliant")
+	assertEqual(t, p.Policies[0].Manifests[0].CustomMessage.NonCompliant, "set noncompliant")
+	assertEqual(t, p.Policies[1].CustomMessage.Compliant, "default compliant")
+	assertEqual(t, p.Policies[1].CustomMessage.NonCompliant, "policy noncompliant")
+	assertEqual(t, p.Policies[1].Manifests[0].CustomMessage.NonCompliant, "policy noncompliant")
+	assertEqual(t, p.Policies[2].CustomMessage.Compliant, "default compliant")
+	assertEqual(t, p.Policies[2].CustomMessage.NonCompliant, "default noncompliant")
+}
+
+func TestConfigNoManifests(t *testing.T) {
+	t.Parallel()
+	const config = `
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+`
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), "")
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := "each policy must have at least one manifest, " +
+		"but found none in policy policy-app-config"
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestConfigManifestNotFound(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	manifestPath := path.Join(tmpDir, "configmap.yaml")
+	config := fmt.Sprintf(
+		`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+`,
+		manifestPath,
+	)
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := fmt.Sprintf(
+		"could not read the manifest path %s in policy policy-app-config", manifestPath,
+	)
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestConfigNoPolicyName(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	config := fmt.Sprintf(
+		`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- manifests:
+    - path: %s
+`,
+		path.Join(tmpDir, "configmap.yaml"),
+	)
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := "each policy must have a name set, but did not find a name at policy array index 0"
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestConfigPlrNotFound(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	plrPath := path.Join(tmpDir, "plr.yaml")
+	config := fmt.Sprintf(
+		`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  placement:
+    placementRulePath: %s
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+`,
+		plrPath,
+		path.Join(tmpDir, "configmap.yaml"),
+	)
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := fmt.Sprintf("policyDefaults placement.placementRulePath could not read the path %s", plrPath)
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestPolicySetConfig(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	testCases := []testCase{
+		{
+			name: "policySet must have a name set",
+			setupFunc: func(p *Plugin) {
+				p.PolicySets = []types.PolicySetConfig{
+					{
+						PolicySetOptions: types.PolicySetOptions{
+							Placement: types.PlacementConfig{
+								Name:             "policyset-placement",
+								ClusterSelectors: map[string]interface{}{"my": "app"},
+							},
+						},
+					},
+				}
+			},
+			expectedErrMsg: "each policySet must have a name set, but did not find a name at policySet array index 0",
+		},
+		{
+			name: "policySet must be unique",
+			setupFunc: func(p *Plugin) {
+				p.PolicySets = []types.PolicySetConfig{
+					{
+						Name: "my-policyset",
+					},
+					{
+						Name: "my-policyset",
+					},
+				}
+			},
+			expectedErrMsg: "each policySet must have a unique name set, but found a duplicate name: my-policyset",
+		},
+		{
+			name: "policySet must provide only one of placementRulePath or placementPath",
+			setupFunc: func(p *Plugin) {
+				p.PolicySets = []types.PolicySetConfig{
+					{
+						Name: "my-policyset",
+						PolicySetOptions: types.PolicySetOptions{
+							Placement: types.PlacementConfig{
+								PlacementPath:     "../config/plc.yaml",
+								PlacementRulePath: "../config/plr.yaml",
+							},
+						},
+					},
+				}
+			},
+			expectedErrMsg: "policySet my-policyset must provide only one of " +
+				"placement.placementPath or placement.placementRulePath",
+		},
+		{
+			name: "policySet must provide only one of placementRuleName or placementName",
+			setupFunc: func(p *Plugin) {
+				p.PolicySets = []types.PolicySetConfig{
+					{
+						Name: "my-policyset",
+						PolicySetOptions: types.PolicySetOptions{
+							Placement: types.PlacementConfig{
+								PlacementName:     "plExistingName",
+								PlacementRuleName: "plrExistingName",
+							},
+						},
+					},
+				}
+			},
+			expectedErrMsg: "policySet my-policyset must provide only one of " +
+				"placement.placementName or placement.placementRuleName",
+		},
+		{
+			name: "policySet must provide only one of labelSelector or clusterSelectors",
+			setupFunc: func(p *Plugin) {
+				p.PolicySets = []types.PolicySetConfig{
+					{
+						Name: "my-policyset",
+						PolicySetOptions: types.PolicySetOptions{
+							Placement: types.PlacementConfig{
+								LabelSelector:    map[string]interface{}{"cloud": "red hat"},
+								ClusterSelectors: map[string]interface{}{"cloud": "red hat"},
+							},
+						},
+					},
+				}
+			},
+			expectedErrMsg: "policySet my-policyset must provide only one of placement.labelSelector or " +
+				"placement.clusterSelectors",
+		},
+		{
+			name: "policySet may not specify a cluster selector and placement path together",
+			setupFunc: func(p *Plugin) {
+				p.PolicySets = []types.PolicySetConfig{
+					{
+						Name: "my-policyset",
+						PolicySetOptions: types.PolicySetOptions{
+							Placement: types.PlacementConfig{
+								PlacementPath:    "../config/plc.yaml",
+								ClusterSelectors: map[string]interface{}{"cloud": "red hat"},
+							},
+						},
+					},
+				}
+			},
+			expectedErrMsg: "policySet my-policyset must specify only one of placement selector, placement path, or " +
+				"placement name",
+		},
+		{
+			name: "policySet may not specify a cluster selector and placement name together",
+			setupFunc: func(p *Plugin) {
+				p.PolicySets = []types.PolicySetConfig{
+					{
+						Name: "my-policyset",
+						PolicySetOptions: types.PolicySetOptions{
+							Placement: types.PlacementConfig{
+								PlacementName:    "plexistingname",
+								ClusterSelectors: map[string]interface{}{"cloud": "red hat"},
+							},
+						},
+					},
+				}
+			},
+			expectedErrMsg: "policySet my-policyset must specify only one of placement selector, placement path, or " +
+				"placement name",
+		},
+		{
+			name: "policySet may not specify a label selector and placement path together",
+			setupFunc: func(p *Plugin) {
+				p.PolicySets = []types.PolicySetConfig{
+					{
+						Name: "my-policyset",
+						PolicySetOptions: types.PolicySetOptions{
+							Placement: types.PlacementConfig{
+								PlacementPath: "../config/plc.yaml",
+								LabelSelector: map[string]interface{}{"cloud": "red hat"},
+							},
+						},
+					},
+				}
+			},
+			expectedErrMsg: "policySet my-policyset must specify only one of placement selector, placement path, or " +
+				"placement name",
+		},
+		{
+			name: "policySet may not specify a label selector and placement name together",
+			setupFunc: func(p *Plugin) {
+				p.PolicySets = []types.PolicySetConfig{
+					{
+						Name: "my-policyset",
+						PolicySetOptions: types.PolicySetOptions{
+							Placement: types.PlacementConfig{
+								PlacementName: "plexistingname",
+								LabelSelector: map[string]interface{}{"cloud": "red hat"},
+							},
+						},
+					},
+				}
+			},
+			expectedErrMsg: "policySet my-policyset must specify only one of placement selector, placement path, or " +
+				"placement name",
+		},
+		{
+			name: "policySet may not specify a cluster selector and placementrule path together",
+			setupFunc: func(p *Plugin) {
+				p.PolicySets = []types.PolicySetConfig{
+					{
+						Name: "my-policyset",
+						PolicySetOptions: types.PolicySetOptions{
+							Placement: types.PlacementConfig{
+								PlacementRulePath: "../config/plc.yaml",
+								ClusterSelectors:  map[string]interface{}{"cloud": "red hat"},
+							},
+						},
+					},
+				}
+			},
+			expectedErrMsg: "policySet my-policyset must specify only one of placement selector, placement path, or " +
+				"placement name",
+		},
+		{
+			name: "policySet may not specify a cluster selector and placementrule name together",
+			setupFunc: func(p *Plugin) {
+				p.PolicySets = []types.PolicySetConfig{
+					{
+						Name: "my-policyset",
+						PolicySetOptions: types.PolicySetOptions{
+							Placement: types.PlacementConfig{
+								PlacementRuleName: "plrexistingname",
+								ClusterSelectors:  map[string]interface{}{"cloud": "red hat"},
+							},
+						},
+					},
+				}
+			},
+			expectedErrMsg: "policySet my-policyset must specify only one of placement selector, placement path, or " +
+				"placement name",
+		},
+		{
+			name: "policySet may not specify a label selector and placementrule path together",
+			setupFunc: func(p *Plugin) {
+				p.PolicySets = []types.PolicySetConfig{
+					{
+						Name: "my-policyset",
+						PolicySetOptions: types.PolicySetOptions{
+							Placement: types.PlacementConfig{
+								PlacementRulePath: "../config/plc.yaml",
+								LabelSelector:     map[string]interface{}{"cloud": "red hat"},
+							},
+						},
+					},
+				}
+			},
+			expectedErrMsg: "policySet my-policyset must specify only one of placement selector, placement path, or " +
+				"placement name",
+		},
+		{
+			name: "policySet may not specify a label selector and placementrule name together",
+			setupFunc: func(p *Plugin) {
+				p.PolicySets = []types.PolicySetConfig{
+					{
+						Name: "my-policyset",
+						PolicySetOptions: types.PolicySetOptions{
+							Placement: types.PlacementConfig{
+								PlacementRuleName: "plrexistingname",
+								LabelSelector:     map[string]interface{}{"cloud": "red hat"},
+							},
+						},
+					},
+				}
+			},
+			expectedErrMsg: "policySet my-policyset must specify only one of placement selector, placement path, or " +
+				"placement name",
+		},
+		{
+			name: "policySet placementrule path not resolvable",
+			setupFunc: func(p *Plugin) {
+				p.PolicySets = []types.PolicySetConfig{
+					{
+						Name: "my-policyset",
+						PolicySetOptions: types.PolicySetOptions{
+							Placement: types.PlacementConfig{
+								PlacementRulePath: "../config/plc.yaml",
+							},
+						},
+					},
+				}
+			},
+			expectedErrMsg: "policySet my-policyset placement.placementRulePath " +
+				"could not read the path ../config/plc.yaml",
+		},
+		{
+			name: "policySet placement path not resolvable",
+			setupFunc: func(p *Plugin) {
+				p.PolicySets = []types.PolicySetConfig{
+					{
+						Name: "my-policyset",
+						PolicySetOptions: types.PolicySetOptions{
+							Placement: types.PlacementConfig{
+								PlacementPath: "../config/plc.yaml",
+							},
+						},
+					},
+				}
+			},
+			expectedErrMsg: "policySet my-policyset placement.placementPath could not read the path ../config/plc.yaml",
+		},
+		{
+			name: "Placement and PlacementRule can't be mixed",
+			setupFunc: func(p *Plugin) {
+				p.Policies[0].Placement = types.PlacementConfig{
+					LabelSelector: map[string]interface{}{"cloud": "red hat"},
+				}
+				p.PolicySets = []types.PolicySetConfig{
+					{
+						Name: "my-policyset",
+						PolicySetOptions: types.PolicySetOptions{
+							Placement: types.PlacementConfig{
+								ClusterSelectors: map[string]interface{}{"cloud": "red hat"},
+							},
+						},
+					},
+				}
+			},
+			expectedErrMsg: "may not use a mix of Placement and PlacementRule for policies and policysets; found 1 " +
+				"Placement and 1 PlacementRule",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			p := Plugin{}
+			var err error
+			p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			p.PlacementBindingDefaults.Name = "my-placement-binding"
+			p.PolicyDefaults.Placement.Name = "my-placement-rule"
+			p.PolicyDefaults.Namespace = "my-policies"
+			policyConf1 := types.PolicyConfig{
+				Name: "policy-app-config",
+				Manifests: []types.Manifest{
+					{
+						Path: path.Join(tmpDir, "configmap.yaml"),
+					},
+				},
+			}
+			policyConf2 := types.PolicyConfig{
+				Name: "policy-app-config2",
+				Manifests: []types.Manifest{
+					{
+						Path: path.Join(tmpDir, "configmap.yaml"),
+					},
+				},
+			}
+			p.Policies = append(p.Policies, policyConf1, policyConf2)
+			tc.setupFunc(&p)
+			p.applyDefaults(map[string]interface{}{})
+			err = p.assertValidConfig()
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+			assertEqual(t, err.Error(), tc.expectedErrMsg)
+		})
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	configMapPath := path.Join(tmpDir, "configmap.yaml")
+	defaultsConfig := fmt.Sprintf(
+		`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  disabled: true
+policies:
+- name: policy-app-config
+  disabled: false
+  manifests:
+    - path: %s
+  namespaceSelector:
+    include:
+      - app-ns
+  remediationAction: inform
+- name: policy-app-config2
+  manifests:
+    - path: %s
+`,
+		configMapPath,
+		configMapPath,
+	)
+	p := Plugin{}
+
+	err := p.Config([]byte(defaultsConfig), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, p.PolicyDefaults.Disabled, true)
+
+	enabledPolicy := p.Policies[0]
+	assertEqual(t, enabledPolicy.Disabled, false)
+
+	disabledPolicy := p.Policies[1]
+	assertEqual(t, disabledPolicy.Disabled, true)
+}
+
+func TestConflictingPlacementSelectors(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	configMapPath := path.Join(tmpDir, "configmap.yaml")
+	policyNS := "my-policies"
+	policyName := "policy-app"
+	defaultsConfig := fmt.Sprintf(
+		`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: %s
+  placement:
+    labelSelector:
+      matchExpressions:
+      - key: cloud
+        operator: In
+        values:
+          - red hat
+          - hello
+      cloud: red hat
+      clusterID: 1234-5678
+policies:
+- name: %s
+  manifests:
+    - path: %s
+`,
+		policyNS, policyName, configMapPath,
+	)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(defaultsConfig), tmpDir)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := "policyDefaults placement has invalid selectors: " +
+		"the input is not a valid label selector or key-value label matching map"
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestConfigInvalidRenderer(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	configMapPath := path.Join(tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		renderer    string
+		expectedErr string
+	}{
+		"unknown renderer": {
+			renderer: "helm",
+			expectedErr: "the policy policy-app-config has an invalid manifest[0].renderer value helm; it must be one " +
+				"of cue, jsonnet, kustomize, raw",
+		},
+		"kustomize file": {
+			renderer: "kustomize",
+			expectedErr: fmt.Sprintf(
+				"the policy policy-app-config has manifest[0].renderer set to kustomize but the path %s is not a "+
+					"directory",
+				configMapPath,
+			),
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+      renderer: %s
+`, configMapPath, test.renderer)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+func TestConfigPolicySetPreservePolicyOrder(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	configMapPath := path.Join(tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		preservePolicyOrder bool
+		expected            []string
+	}{
+		"sorted by default": {
+			expected: []string{"policy-a", "policy-b", "policy-c"},
+		},
+		"declared order preserved": {
+			preservePolicyOrder: true,
+			expected:            []string{"policy-c", "policy-a", "policy-b"},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policySetDefaults:
+  preservePolicyOrder: %t
+policies:
+- name: policy-a
+  manifests:
+    - path: %s
+- name: policy-b
+  policySets:
+    - my-policyset
+  manifests:
+    - path: %s
+- name: policy-c
+  manifests:
+    - path: %s
+policySets:
+- name: my-policyset
+  policies:
+    - policy-c
+    - policy-a
+`, test.preservePolicyOrder, configMapPath, configMapPath, configMapPath)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			assertEqual(t, p.PolicySets[0].Policies, test.expected)
+		})
+	}
+}
+
+func TestConfigManifestConsolidate(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	configMapPath := path.Join(tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		policyOptions string
+		manifest      string
+		expectedErr   string
+	}{
+		"standalone manifest in a consolidated policy": {
+			policyOptions: "consolidateManifests: true",
+			manifest:      "consolidate: false\n      remediationAction: enforce",
+		},
+		"consolidated manifest in a policy without consolidation": {
+			policyOptions: "consolidateManifests: false",
+			manifest:      "consolidate: true\n      remediationAction: enforce",
+			expectedErr: "the policy policy-app has the remediationAction value set on manifest[0] but consolidate " +
+				"is true on the manifest",
+		},
+		"consolidated manifest with orderManifests": {
+			policyOptions: "orderManifests: true",
+			manifest:      "consolidate: true",
+			expectedErr: "the policy policy-app may not set consolidate to true on manifest[0] when orderManifests " +
+				"is true",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app
+  %s
+  manifests:
+    - path: %s
+      %s
+    - path: %s
+`, test.policyOptions, configMapPath, test.manifest, configMapPath)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if test.expectedErr == "" {
+				if err != nil {
+					t.Fatal(err.Error())
+				}
+
+				return
+			}
+
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+func TestConfigPolicySetOrderedPolicies(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	configMapPath := path.Join(tmpDir, "configmap.yaml")
+
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-a
+  manifests:
+    - path: %s
+- name: policy-b
+  manifests:
+    - path: %s
+- name: policy-shared
+  policySets:
+    - first-policyset
+    - second-policyset
+  manifests:
+    - path: %s
+policySets:
+- name: first-policyset
+  policies:
+    - policy-a
+    - policy-b
+  orderedPolicies:
+    - policy-shared
+    - policy-b
+- name: second-policyset
+  policies:
+    - policy-a
+    - policy-b
+  orderedPolicies:
+    - policy-b
+- name: third-policyset
+  policies:
+    - policy-b
+    - policy-a
+`, configMapPath, configMapPath, configMapPath)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, p.PolicySets[0].Policies, []string{"policy-shared", "policy-b", "policy-a"})
+	assertEqual(t, p.PolicySets[1].Policies, []string{"policy-b", "policy-a", "policy-shared"})
+	assertEqual(t, p.PolicySets[2].Policies, []string{"policy-a", "policy-b"})
+}
+
+func TestConfigPolicySetOrderedPoliciesInvalid(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	configMapPath := path.Join(tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		orderedPolicies string
+		expectedErr     string
+	}{
+		"not a member": {
+			orderedPolicies: "[policy-b]",
+			expectedErr: "the policy set my-policyset has the policy policy-b in orderedPolicies but it isn't a " +
+				"member of the policy set",
+		},
+		"duplicate": {
+			orderedPolicies: "[policy-a, policy-a]",
+			expectedErr:     "the policy set my-policyset has the policy policy-a in orderedPolicies more than once",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-a
+  manifests:
+    - path: %s
+- name: policy-b
+  manifests:
+    - path: %s
+policySets:
+- name: my-policyset
+  policies:
+    - policy-a
+  orderedPolicies: %s
+`, configMapPath, configMapPath, test.orderedPolicies)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+func TestConfigKyvernoExpanderOptions(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	configMapPath := path.Join(tmpDir, "configmap.yaml")
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  kyvernoExpanderOptions:
+    includeWarn: true
+    scope: Cluster
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+- name: policy-app-config2
+  kyvernoExpanderOptions:
+    scope: Namespaced
+  manifests:
+    - path: %s
+`,
+		configMapPath, configMapPath,
+	)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, p.Policies[0].KyvernoExpanderOptions.IncludeWarn, true)
+	assertEqual(t, p.Policies[0].KyvernoExpanderOptions.Scope, "Cluster")
+	assertEqual(t, p.Policies[1].KyvernoExpanderOptions.IncludeWarn, false)
+	assertEqual(t, p.Policies[1].KyvernoExpanderOptions.Scope, "Namespaced")
+}
+
+func TestConfigInvalidKyvernoExpanderScope(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	configMapPath := path.Join(tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		policyDefaults string
+		policy         string
+		expectedErr    string
+	}{
+		"policyDefaults": {
+			policyDefaults: "Namespace",
+			expectedErr: "policyDefaults.kyvernoExpanderOptions.scope must be one of All, Cluster, or Namespaced " +
+				"but got Namespace",
+		},
+		"policy": {
+			policy: "Namespace",
+			expectedErr: "the policy policy-app-config has an invalid kyvernoExpanderOptions.scope value Namespace; " +
+				"it must be one of All, Cluster, or Namespaced",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  kyvernoExpanderOptions:
+    scope: "%s"
+policies:
+- name: policy-app-config
+  kyvernoExpanderOptions:
+    scope: "%s"
+  manifests:
+    - path: %s
+`,
+				test.policyDefaults, test.policy, configMapPath,
+			)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+func TestConfigSubstitution(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	configMapPath := path.Join(tmpDir, "configmap.yaml")
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: ${NAMESPACE}
+  placement:
+    labelSelector:
+      cloud: ${CLOUD}
+  policyAnnotations:
+    escaped: $${NAMESPACE}
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+`,
+		configMapPath,
+	)
+
+	p := Plugin{}
+	p.SetSubstitutionOptions(SubstitutionOptions{
+		Values: map[string]string{"NAMESPACE": "my-policies", "CLOUD": "red hat"},
+	})
+
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, p.PolicyDefaults.Namespace, "my-policies")
+	assertEqual(t, p.PolicyDefaults.Placement.LabelSelector["cloud"], "red hat")
+	assertEqual(t, p.PolicyDefaults.PolicyAnnotations["escaped"], "${NAMESPACE}")
+}
+
+func TestConfigSubstitutionUndefined(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	configMapPath := path.Join(tmpDir, "configmap.yaml")
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  placement:
+    labelSelector:
+      cloud: "${CLOUD}"
+      region: "${REGION}"
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+`,
+		configMapPath,
+	)
+
+	p := Plugin{}
+	p.SetSubstitutionOptions(SubstitutionOptions{})
+
+	err := p.Config([]byte(config), tmpDir)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := "the PolicyGenerator configuration file is invalid: the configuration references undefined " +
+		"variables: CLOUD, REGION"
+	assertEqual(t, err.Error(), expected)
+
+	p = Plugin{}
+	p.SetSubstitutionOptions(SubstitutionOptions{AllowUndefined: true})
+
+	err = p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, p.PolicyDefaults.Placement.LabelSelector["cloud"], "")
+}
+
+func TestConfigInvalidBindingPlacementRef(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		placement   string
+		expectedErr string
+	}{
+		"generated placement": {
+			placement: "labelSelector:\n      cloud: red hat\n    bindingPlacementRef:\n      apiGroup: " +
+				"cluster.open-cluster-management.io\n      kind: Placement\n      name: my-placement",
+			expectedErr: "policy policy-app-config placement.bindingPlacementRef may only be used with " +
+				"placement.placementName or placement.placementRuleName",
+		},
+		"missing kind": {
+			placement: "placementName: my-placement\n    bindingPlacementRef:\n      apiGroup: " +
+				"cluster.open-cluster-management.io\n      name: my-placement",
+			expectedErr: "policy policy-app-config placement.bindingPlacementRef must set apiGroup, kind, and name",
+		},
+		"mismatched name": {
+			placement: "placementName: my-placement\n    bindingPlacementRef:\n      apiGroup: " +
+				"cluster.open-cluster-management.io\n      kind: Placement\n      name: other-placement",
+			expectedErr: "policy policy-app-config placement.bindingPlacementRef.name must match the placement " +
+				"name my-placement but got other-placement",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  placement:
+    %s
+  manifests:
+    - path: %s
+`,
+				test.placement, path.Join(tmpDir, "configmap.yaml"),
+			)
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+func TestConfigErrorClass(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	tests := map[string]struct {
+		manifestPath string
+		namespace    string
+		class        error
+	}{
+		"invalid config": {
+			manifestPath: path.Join(tmpDir, "configmap.yaml"),
+			class:        ErrInvalidConfig,
+		},
+		"missing manifest": {
+			manifestPath: path.Join(tmpDir, "does-not-exist.yaml"),
+			namespace:    "my-policies",
+			class:        ErrManifestRead,
+		},
+	}
+
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: "%s"
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+`,
+				test.namespace, test.manifestPath,
+			)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			if !errors.Is(err, test.class) {
+				t.Fatalf("Expected the error to have the class %v: %v", test.class, err)
+			}
+		})
+	}
+}
+
+func TestConfigInvalidPlacementBindingNamespace(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+placementBindingDefaults:
+  namespace: Global_Hub
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+`,
+		path.Join(tmpDir, "configmap.yaml"),
+	)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := "PlacementBindingDefaults.Namespace `Global_Hub` is not a valid namespace name"
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestConfigInvalidMaxSubjectsPerBinding(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+placementBindingDefaults:
+  name: my-placement-binding
+  maxSubjectsPerBinding: -1
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+`,
+		path.Join(tmpDir, "configmap.yaml"),
+	)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := "PlacementBindingDefaults.MaxSubjectsPerBinding `-1` must not be negative (0 or unset means no limit)"
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestConfigRemediationActionNormalized(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  consolidateManifests: false
+  remediationAction: Inform
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+    - path: %s
+      remediationAction: INFORMONLY
+- name: policy-app-config2
+  remediationAction: Enforce
+  manifests:
+    - path: %s
+`,
+		path.Join(tmpDir, "configmap.yaml"), path.Join(tmpDir, "configmap.yaml"), path.Join(tmpDir, "configmap.yaml"),
+	)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, p.PolicyDefaults.RemediationAction, "inform")
+	assertEqual(t, p.Policies[0].RemediationAction, "inform")
+	assertEqual(t, p.Policies[0].Manifests[0].RemediationAction, "inform")
+	assertEqual(t, p.Policies[0].Manifests[1].RemediationAction, "InformOnly")
+	assertEqual(t, p.Policies[1].RemediationAction, "enforce")
+	assertEqual(t, p.Policies[1].Manifests[0].RemediationAction, "enforce")
+}
+
+func TestConfigStrictRemediationAction(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  strictRemediationAction: true
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+      remediationAction: Inform
+`,
+		path.Join(tmpDir, "configmap.yaml"),
+	)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := "the policy policy-app-config has a non-canonical manifest[0].remediationAction `Inform`: it must " +
+		"be inform, enforce, or InformOnly when policyDefaults.strictRemediationAction is true"
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestConfigInvalidObjectMetadata(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		manifestOptions string
+		expectedErr     string
+	}{
+		"invalid label value": {
+			manifestOptions: "objectLabels: {team: 'platform team'}",
+			expectedErr: "the policy policy-app-config has an invalid manifest[0].objectLabels entry `team: platform " +
+				"team`; it must be a valid label",
+		},
+		"invalid annotation key": {
+			manifestOptions: "objectAnnotations: {'owner/team/name': platform}",
+			expectedErr: "the policy policy-app-config has an invalid manifest[0].objectAnnotations key " +
+				"`owner/team/name`; it must be a valid annotation key",
+		},
+		"override without metadata": {
+			manifestOptions: "overrideObjectMetadata: true",
+			expectedErr: "the policy policy-app-config has manifest[0].overrideObjectMetadata set but objectLabels " +
+				"and objectAnnotations are not set",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+      %s
+`,
+				path.Join(tmpDir, "configmap.yaml"), test.manifestOptions,
+			)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+func TestConfigInvalidPerNamespace(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		manifestOptions string
+		expectedErr     string
+	}{
+		"no include": {
+			manifestOptions: "{perNamespace: true}",
+			expectedErr: "the policy policy-app-config has manifest[0].perNamespace set but namespaceSelector.include " +
+				"is empty",
+		},
+		"exclude": {
+			manifestOptions: "{perNamespace: true, namespaceSelector: {include: [app1], exclude: [kube-*]}}",
+			expectedErr: "the policy policy-app-config has manifest[0].perNamespace set but namespaceSelector may " +
+				"only set include",
+		},
+		"wildcard": {
+			manifestOptions: "{perNamespace: true, namespaceSelector: {include: [app-*]}}",
+			expectedErr: "the policy policy-app-config has manifest[0].perNamespace set but the " +
+				"namespaceSelector.include entry `app-*` is not a namespace name",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  consolidateManifests: false
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+      <<: %s
+`,
+				path.Join(tmpDir, "configmap.yaml"), test.manifestOptions,
+			)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+func TestConfigInvalidExtractPlacement(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	manifestPath := path.Join(tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		policyOptions string
+		expectedErr   string
+	}{
+		"policy placement set": {
+			policyOptions: "{placement: {labelSelector: {env: dev}}}",
+			expectedErr: "the policy policy-app-config has manifest[0].extractPlacement set but the policy placement " +
+				"may not be set",
+		},
+		"no placement": {
+			policyOptions: "{}",
+			expectedErr: fmt.Sprintf(
+				"the policy policy-app-config has manifest[0].extractPlacement set but the manifest %s has 0 "+
+					"placements instead of one Placement or PlacementRule",
+				manifestPath,
+			),
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  <<: %s
+  manifests:
+    - path: %s
+      extractPlacement: true
+`,
+				test.policyOptions, manifestPath,
+			)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+func TestConfigInvalidBindingOverrides(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		bindingOverrides string
+		expectedErr      string
+	}{
+		"empty": {
+			bindingOverrides: "{policyLabel: remediation}",
+			expectedErr: "PlacementBindingDefaults.BindingOverrides is invalid: remediationAction or subFilter " +
+				"must be set",
+		},
+		"invalid remediationAction": {
+			bindingOverrides: "{remediationAction: inform}",
+			expectedErr: "PlacementBindingDefaults.BindingOverrides is invalid: the remediationAction `inform` is " +
+				"invalid: it must be enforce",
+		},
+		"invalid subFilter": {
+			bindingOverrides: "{remediationAction: enforce, subFilter: all}",
+			expectedErr: "PlacementBindingDefaults.BindingOverrides is invalid: the subFilter `all` is invalid: it " +
+				"must be restricted",
+		},
+		"invalid policyLabel": {
+			bindingOverrides: "{remediationAction: enforce, policyLabel: '=enforce'}",
+			expectedErr: "PlacementBindingDefaults.BindingOverrides is invalid: the policyLabel `=enforce` is " +
+				"invalid: it must be a label key or key=value",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+placementBindingDefaults:
+  bindingOverrides: %s
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+`,
+				test.bindingOverrides, path.Join(tmpDir, "configmap.yaml"),
+			)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+func TestConfigInvalidNamespaceSelectorExpressions(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	invalidOperator := "matchExpressions: [{key: environment, operator: Inn, values: [dev]}]"
+	existsWithValues := "matchExpressions: [{key: environment, operator: Exists, values: [dev]}]"
+
+	tests := map[string]struct {
+		policyDefaults string
+		policy         string
+		manifest       string
+		expectedErr    string
+	}{
+		"policyDefaults invalid operator": {
+			policyDefaults: invalidOperator,
+			expectedErr: `policyDefaults.namespaceSelector is invalid: "Inn" is not a valid label selector ` +
+				`operator`,
+		},
+		"policy exists with values": {
+			policy: existsWithValues,
+			expectedErr: "the policy policy-app-config has an invalid namespaceSelector: values: Invalid value: " +
+				"[]string{\"dev\"}: values set must be empty for exists and does not exist",
+		},
+		"manifest invalid operator": {
+			manifest: invalidOperator,
+			expectedErr: `the policy policy-app-config has an invalid manifest[0].namespaceSelector: "Inn" is not ` +
+				`a valid label selector operator`,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  consolidateManifests: false
+  namespaceSelector: {%s}
+policies:
+- name: policy-app-config
+  namespaceSelector: {%s}
+  manifests:
+    - path: %s
+      namespaceSelector: {%s}
+`,
+				test.policyDefaults, test.policy, path.Join(tmpDir, "configmap.yaml"), test.manifest,
+			)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+func TestConfigMultipleDocuments(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	configMapPath := path.Join(tmpDir, "configmap.yaml")
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+---
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policies:
+- name: policy-app-config2
+  manifests:
+    - path: %s
+`,
+		configMapPath, configMapPath,
+	)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, len(p.Policies), 2)
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	manifests, err := unmarshalManifestBytes(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	policyNames := []string{}
+
+	for _, manifest := range manifests {
+		if manifest["kind"] == policyKind {
+			name, _, _ := unstructured.NestedString(manifest, "metadata", "name")
+			policyNames = append(policyNames, name)
+		}
+	}
+
+	assertReflectEqual(t, policyNames, []string{"policy-app-config", "policy-app-config2"})
+}
+
+func TestConfigMultipleDocumentsConflict(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	configMapPath := path.Join(tmpDir, "configmap.yaml")
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+---
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: other-policies
+policies: []
+`,
+		configMapPath,
+	)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := "the PolicyGenerator configuration file is invalid: the PolicyGenerator document 2 has a " +
+		"policyDefaults value that conflicts with a previous document"
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestConfigInvalidPlacementRef(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		placements  string
+		placement   string
+		expectedErr string
+	}{
+		"undefined ref": {
+			"{prod: {labelSelector: {env: prod}}}",
+			"{ref: dev}",
+			"policy policy-app-config placement.ref `dev` is not defined in placements",
+		},
+		"ref with a selector": {
+			"{prod: {labelSelector: {env: prod}}}",
+			"{ref: prod, labelSelector: {env: dev}}",
+			"policy policy-app-config placement.ref may not be set with a placement selector, placement path, or " +
+				"placement name",
+		},
+		"named placement with a ref": {
+			"{prod: {ref: dev}, dev: {labelSelector: {env: dev}}}",
+			"{ref: prod}",
+			"placements.prod may not set ref",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+placements: %s
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  placement: %s
+  manifests:
+    - path: %s
+`,
+				test.placements, test.placement, path.Join(tmpDir, "configmap.yaml"),
+			)
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+func TestConfigInvalidDeriveLabelsFrom(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  deriveLabelsFrom:
+    - metadata..team
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+`,
+		path.Join(tmpDir, "configmap.yaml"),
+	)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := "the policy policy-app-config has an invalid deriveLabelsFrom value `metadata..team`; it must be a " +
+		"dot-separated field path, with the segments that contain dots in brackets, that ends in a valid label key"
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestConfigInvalidComplianceTypeByIndex(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+      complianceTypeByIndex:
+        0: mustnot
+`,
+		path.Join(tmpDir, "configmap.yaml"),
+	)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := "the policy policy-app-config has an invalid manifest[0].complianceTypeByIndex[0] value mustnot; " +
+		"it must be one of musthave, mustonlyhave, mustnothave"
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestConfigInvalidPolicyAutomation(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		automation  string
+		expectedErr string
+	}{
+		"invalid mode": {
+			automation: "{mode: always, jobTemplateName: my-job, secret: my-secret}",
+			expectedErr: "the policy policy-app-config has an invalid automation: the mode always must be one of " +
+				"once, everyEvent, or disabled",
+		},
+		"missing jobTemplateName": {
+			automation:  "{mode: once, secret: my-secret}",
+			expectedErr: "the policy policy-app-config has an invalid automation: the jobTemplateName must be set",
+		},
+		"missing secret": {
+			automation:  "{jobTemplateName: my-job}",
+			expectedErr: "the policy policy-app-config has an invalid automation: the secret must be set",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  automation: %s
+  manifests:
+    - path: %s
+`,
+				test.automation, path.Join(tmpDir, "configmap.yaml"),
+			)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+func TestConfigInvalidRootRemediationAction(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		options     string
+		expectedErr string
+	}{
+		"invalid rootRemediationAction": {
+			options: "rootRemediationAction: informOnly",
+			expectedErr: "the policy policy-app-config has an invalid rootRemediationAction `informOnly`: it must be " +
+				"inform or enforce",
+		},
+		"clearTemplateRemediationAction without rootRemediationAction": {
+			options: "clearTemplateRemediationAction: true",
+			expectedErr: "the policy policy-app-config may only set clearTemplateRemediationAction with " +
+				"rootRemediationAction",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  %s
+  manifests:
+    - path: %s
+`,
+				test.options, path.Join(tmpDir, "configmap.yaml"),
+			)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+func TestConfigInvalidFromFiles(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	otherDir := t.TempDir()
+	createConfigMap(t, otherDir, "configmap.yaml")
+	outsidePath := path.Join(otherDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		fromFiles   string
+		expectedErr string
+	}{
+		"invalid key": {
+			fromFiles: fmt.Sprintf("{app/properties: %s}", path.Join(tmpDir, "configmap.yaml")),
+			expectedErr: "the policy policy-app-config has an invalid manifest[0].fromFiles key app/properties: a " +
+				"valid config key must consist of alphanumeric characters, '-', '_' or '.' (e.g. 'key.name',  or " +
+				"'KEY_NAME',  or 'key-name', regex used for validation is '[-._a-zA-Z0-9]+')",
+		},
+		"path outside of the base directory": {
+			fromFiles: fmt.Sprintf("{app.properties: %s}", outsidePath),
+			expectedErr: fmt.Sprintf(
+				"the fromFiles path %s is not in the same directory tree as the kustomization.yaml file", outsidePath,
+			),
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+      fromFiles: %s
+`,
+				path.Join(tmpDir, "configmap.yaml"), test.fromFiles,
+			)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+func TestConfigInvalidOCIRef(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		manifest    string
+		expectedErr string
+	}{
+		"path and ociRef": {
+			manifest: fmt.Sprintf(
+				"{path: %s, ociRef: 'oci://quay.io/my-org/my-policies:v1'}", path.Join(tmpDir, "configmap.yaml"),
+			),
+			expectedErr: "the policy policy-app-config has manifest[0].ociRef set but only one of path or ociRef " +
+				"may be set",
+		},
+		"renderer and ociRef": {
+			manifest: "{ociRef: 'oci://quay.io/my-org/my-policies:v1', renderer: raw}",
+			expectedErr: "the policy policy-app-config has manifest[0].ociRef set but renderer may not be set " +
+				"with ociRef",
+		},
+		"invalid ociRef": {
+			manifest: "{ociRef: 'quay.io/my-org/my-policies:v1'}",
+			expectedErr: "the policy policy-app-config has an invalid manifest[0].ociRef: the OCI reference " +
+				"quay.io/my-org/my-policies:v1 must be in the format of oci://registry/repository:tag or " +
+				"oci://registry/repository@digest",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  manifests:
+    - %s
+`,
+				test.manifest,
+			)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+func TestConfigPathPrefixMappings(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	config := `
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  manifests:
+    - path: /original/root/configmap.yaml
+`
+
+	p := Plugin{}
+	p.SetPathPrefixMappings([]PathPrefixMapping{{Old: "/original/root", New: tmpDir}})
+
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, p.Policies[0].Manifests[0].Path, path.Join(tmpDir, "configmap.yaml"))
+
+	// The rewritten paths must still be in the base directory
+	baseDir := path.Join(tmpDir, "base")
+
+	err = os.Mkdir(baseDir, 0o777)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p = Plugin{}
+	p.SetPathPrefixMappings([]PathPrefixMapping{{Old: "/original/root", New: tmpDir}})
+
+	err = p.Config([]byte(config), baseDir)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := fmt.Sprintf("the manifest path %s is not in the same directory tree as the kustomization.yaml file",
+		path.Join(tmpDir, "configmap.yaml"))
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestRewritePathPrefixes(t *testing.T) {
+	t.Parallel()
+
+	placement := func() types.PlacementConfig {
+		return types.PlacementConfig{
+			PlacementPath:     "/original/root/placement.yaml",
+			PlacementRulePath: "/original/root/placement-rule.yaml",
+			PlacementsDir:     "/original/root/placements",
+		}
+	}
+
+	p := Plugin{}
+	p.SetPathPrefixMappings([]PathPrefixMapping{{Old: "/original/root", New: "/new/root"}})
+	p.PolicyDefaults.Placement = placement()
+	p.PolicySetDefaults.Placement = placement()
+	p.Placements = map[string]types.PlacementConfig{"my-placement": placement()}
+	p.Policies = []types.PolicyConfig{{
+		Name:          "policy-app-config",
+		PolicyOptions: types.PolicyOptions{Placement: placement()},
+		Manifests: []types.Manifest{{
+			Path:      "/original/root/configmap.yaml",
+			FromFiles: map[string]string{"game.properties": "/original/root/game.properties"},
+			ConfigMapGenerator: &types.ConfigMapGeneratorOptions{
+				Files: []string{"/original/root/ui.properties", "game=/original/root/game.properties"},
+			},
+			OpenAPI:    types.Filepath{Path: "/original/root/schema.json"},
+			PerCluster: &types.PerClusterOptions{Path: "/original/root/clusters.yaml"},
+		}},
+	}}
+	p.PolicySets = []types.PolicySetConfig{{
+		Name:             "my-policyset",
+		PolicySetOptions: types.PolicySetOptions{Placement: placement()},
+	}}
+
+	p.rewritePathPrefixes()
+
+	expectedPlacement := types.PlacementConfig{
+		PlacementPath:     "/new/root/placement.yaml",
+		PlacementRulePath: "/new/root/placement-rule.yaml",
+		PlacementsDir:     "/new/root/placements",
+	}
+
+	assertReflectEqual(t, p.PolicyDefaults.Placement, expectedPlacement)
+	assertReflectEqual(t, p.PolicySetDefaults.Placement, expectedPlacement)
+	assertReflectEqual(t, p.Placements["my-placement"], expectedPlacement)
+	assertReflectEqual(t, p.Policies[0].Placement, expectedPlacement)
+	assertReflectEqual(t, p.PolicySets[0].Placement, expectedPlacement)
+
+	manifest := p.Policies[0].Manifests[0]
+	assertEqual(t, manifest.Path, "/new/root/configmap.yaml")
+	assertReflectEqual(t, manifest.FromFiles, map[string]string{"game.properties": "/new/root/game.properties"})
+	assertReflectEqual(
+		t, manifest.ConfigMapGenerator.Files, []string{"/new/root/ui.properties", "game=/new/root/game.properties"},
+	)
+	assertEqual(t, manifest.OpenAPI.Path, "/new/root/schema.json")
+	assertEqual(t, manifest.PerCluster.Path, "/new/root/clusters.yaml")
+}
+
+func TestConfigPathPrefixMappingsImports(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	importYAML := `
+policies:
+- name: policy-imported
+  manifests:
+    - path: /original/root/configmap.yaml
+`
+
+	err := os.WriteFile(path.Join(tmpDir, "imported.yaml"), []byte(importYAML), 0o666)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	config := `
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+imports:
+- /original/root/imported.yaml
+policyDefaults:
+  namespace: my-policies
+policies: []
+`
+
+	p := Plugin{}
+	p.SetPathPrefixMappings([]PathPrefixMapping{{Old: "/original/root", New: tmpDir}})
+
+	err = p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, len(p.Policies), 1)
+	assertEqual(t, p.Policies[0].Manifests[0].Path, path.Join(tmpDir, "configmap.yaml"))
+}
+
+func createPlacementsDir(t *testing.T, tmpDir string) string {
+	t.Helper()
+
+	placementsDir := path.Join(tmpDir, "placements")
+
+	err := os.Mkdir(placementsDir, 0o777)
+	if err != nil {
+		t.Fatalf("Failed to create %s", placementsDir)
+	}
+
+	placements := map[string]string{
+		"dev.yaml": `
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
+metadata:
+  name: placement-dev
+  namespace: my-policies
+  labels:
+    env: dev
+    region: us
+spec: {}
+`,
+		"prod.yaml": `
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
+metadata:
+  name: placement-prod-us
+  namespace: my-policies
+  labels:
+    env: prod
+    region: us
+spec: {}
+---
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
+metadata:
+  name: placement-prod-eu
+  namespace: my-policies
+  labels:
+    env: prod
+    region: eu
+spec: {}
+`,
+		"other-namespace.yaml": `
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
+metadata:
+  name: placement-dev-other
+  namespace: other-policies
+  labels:
+    env: dev
+spec: {}
+`,
+	}
+
+	for fileName, placement := range placements {
+		plcPath := path.Join(placementsDir, fileName)
+
+		err := os.WriteFile(plcPath, []byte(placement), 0o666)
+		if err != nil {
+			t.Fatalf("Failed to write %s", plcPath)
+		}
+	}
+
+	return placementsDir
+}
+
+func TestConfigPlacementSelector(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	placementsDir := createPlacementsDir(t, tmpDir)
+
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+placements:
+  prod-eu:
+    placementsDir: %s
+    placementSelector:
+      env: prod
+      region: eu
+policyDefaults:
+  namespace: my-policies
+  placement:
+    placementsDir: %s
+    placementSelector:
+      env: dev
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+- name: policy-app-config-prod
+  placement:
+    ref: prod-eu
+  manifests:
+    - path: %s
+`,
+		placementsDir, placementsDir, path.Join(tmpDir, "configmap.yaml"), path.Join(tmpDir, "configmap.yaml"),
+	)
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, p.PolicyDefaults.Placement.PlacementName, "placement-dev")
+	assertEqual(t, p.Policies[0].Placement.PlacementName, "placement-dev")
+	assertEqual(t, p.Policies[1].Placement.PlacementName, "placement-prod-eu")
+	assertEqual(t, len(p.Policies[0].Placement.PlacementSelector), 0)
+	assertEqual(t, p.Policies[0].Placement.PlacementsDir, "")
+
+	expectedPaths := []string{
+		path.Join(tmpDir, "configmap.yaml"),
+		path.Join(placementsDir, "dev.yaml"),
+		path.Join(placementsDir, "prod.yaml"),
+	}
+	assertReflectEqual(t, p.InputPaths(), expectedPaths)
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	manifests, err := unmarshalManifestBytes(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	plcRefs := []string{}
+
+	for _, manifest := range manifests {
+		if manifest["kind"] == "Placement" {
+			t.Fatalf("Expected no Placement to be generated but got %v", manifest)
+		}
+
+		if manifest["kind"] == "PlacementBinding" {
+			plcRef, _ := manifest["placementRef"].(map[string]interface{})
+			plcRefs = append(plcRefs, fmt.Sprint(plcRef["name"]))
+		}
+	}
+
+	assertReflectEqual(t, plcRefs, []string{"placement-dev", "placement-prod-eu"})
+}
+
+func TestConfigPlacementSelectorInvalid(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	placementsDir := createPlacementsDir(t, tmpDir)
+
+	tests := map[string]struct {
+		placement   string
+		expectedErr string
+	}{
+		"no match": {
+			fmt.Sprintf("{placementsDir: %s, placementSelector: {env: staging}}", placementsDir),
+			fmt.Sprintf(
+				"policy policy-app-config placement.placementSelector could not be resolved: no Placement in the "+
+					"placementsDir %s matches the selector",
+				placementsDir,
+			),
+		},
+		"multiple matches": {
+			fmt.Sprintf("{placementsDir: %s, placementSelector: {env: prod}}", placementsDir),
+			fmt.Sprintf(
+				"policy policy-app-config placement.placementSelector could not be resolved: multiple Placements in "+
+					"the placementsDir %s match the selector: placement-prod-us (%s), placement-prod-eu (%s)",
+				placementsDir, path.Join(placementsDir, "prod.yaml"), path.Join(placementsDir, "prod.yaml"),
+			),
+		},
+		"missing placementsDir": {
+			"{placementSelector: {env: dev}}",
+			"policy policy-app-config placement.placementSelector must be set with placement.placementsDir",
+		},
+		"missing placementSelector": {
+			fmt.Sprintf("{placementsDir: %s}", placementsDir),
+			"policy policy-app-config placement.placementsDir may only be set with placement.placementSelector",
+		},
+		"with a placementName": {
+			fmt.Sprintf("{placementsDir: %s, placementSelector: {env: dev}, placementName: other}", placementsDir),
+			"policy policy-app-config placement.placementSelector may not be set with placement.placementName or " +
+				"placement.placementRuleName",
+		},
+		"with a labelSelector": {
+			fmt.Sprintf("{placementsDir: %s, placementSelector: {env: dev}, labelSelector: {a: b}}", placementsDir),
+			"policy policy-app-config must specify only one of placement selector, placement path, or placement name",
 		},
 	}
 
-	for _, tc := range testCases {
-		tc := tc // capture range variable
-		t.Run(tc.name, func(t *testing.T) {
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
 			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  placement: %s
+  manifests:
+    - path: %s
+`,
+				test.placement, path.Join(tmpDir, "configmap.yaml"),
+			)
 			p := Plugin{}
-			var err error
-			p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
-			if err != nil {
-				t.Fatal(err.Error())
-			}
-			p.PlacementBindingDefaults.Name = "my-placement-binding"
-			p.PolicyDefaults.Placement.Name = "my-placement-rule"
-			p.PolicyDefaults.Namespace = "my-policies"
-			policyConf1 := types.PolicyConfig{
-				Name: "policy-app-config",
-				Manifests: []types.Manifest{
-					{
-						Path: path.Join(tmpDir, "configmap.yaml"),
-					},
-				},
-			}
-			policyConf2 := types.PolicyConfig{
-				Name: "policy-app-config2",
-				Manifests: []types.Manifest{
-					{
-						Path: path.Join(tmpDir, "configmap.yaml"),
-					},
-				},
-			}
-			p.Policies = append(p.Policies, policyConf1, policyConf2)
-			tc.setupFunc(&p)
-			p.applyDefaults(map[string]interface{}{})
-			err = p.assertValidConfig()
+
+			err := p.Config([]byte(config), tmpDir)
 			if err == nil {
 				t.Fatal("Expected an error but did not get one")
 			}
-			assertEqual(t, err.Error(), tc.expectedErrMsg)
+
+			assertEqual(t, err.Error(), test.expectedErr)
 		})
 	}
 }
 
-func TestDisabled(t *testing.T) {
+func TestConfigInvalidFieldManagerAnnotation(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
 	createConfigMap(t, tmpDir, "configmap.yaml")
-	configMapPath := path.Join(tmpDir, "configmap.yaml")
-	defaultsConfig := fmt.Sprintf(
-		`
+
+	tests := map[string]struct {
+		key         string
+		value       string
+		expectedErr string
+	}{
+		"invalid key": {
+			key:         "not a key",
+			value:       "policy-pipeline",
+			expectedErr: `policyDefaults.fieldManagerAnnotation.key must be a valid annotation key but got "not a key"`,
+		},
+		"empty value": {
+			key:         "example.com/field-manager",
+			expectedErr: "policyDefaults.fieldManagerAnnotation.value must be between 1 and 128 characters but got 0",
+		},
+		"long value": {
+			key:         "example.com/field-manager",
+			value:       strings.Repeat("a", 129),
+			expectedErr: "policyDefaults.fieldManagerAnnotation.value must be between 1 and 128 characters but got 129",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
 apiVersion: policy.open-cluster-management.io/v1
 kind: PolicyGenerator
 metadata:
   name: policy-generator-name
 policyDefaults:
   namespace: my-policies
-  disabled: true
+  fieldManagerAnnotation:
+    key: %q
+    value: %q
 policies:
 - name: policy-app-config
-  disabled: false
   manifests:
     - path: %s
-  namespaceSelector:
-    include:
-      - app-ns
-  remediationAction: inform
-- name: policy-app-config2
+`,
+				test.key, test.value, path.Join(tmpDir, "configmap.yaml"),
+			)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+func TestConfigSeverityByControl(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  severity: medium
+  severityByControl:
+    AC-3: high
+    SI-4 System Monitoring: critical
+policies:
+- name: policy-ac
+  controls:
+    - CM-2 Baseline Configuration
+    - AC-3 Access Enforcement
   manifests:
-    - path: %s
+    - path: %[1]s
+- name: policy-si
+  controls:
+    - SI-4 System Monitoring
+  manifests:
+    - path: %[1]s
+- name: policy-explicit
+  controls:
+    - AC-3
+  severity: low
+  manifests:
+    - path: %[1]s
+- name: policy-unmapped
+  manifests:
+    - path: %[1]s
 `,
-		configMapPath,
-		configMapPath,
+		path.Join(tmpDir, "configmap.yaml"),
 	)
+
 	p := Plugin{}
 
-	err := p.Config([]byte(defaultsConfig), tmpDir)
+	err := p.Config([]byte(config), tmpDir)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	assertEqual(t, p.PolicyDefaults.Disabled, true)
+	severities := map[string]string{}
+	for _, policy := range p.Policies {
+		severities[policy.Name] = policy.Severity
+	}
 
-	enabledPolicy := p.Policies[0]
-	assertEqual(t, enabledPolicy.Disabled, false)
+	expected := map[string]string{
+		"policy-ac":       "high",
+		"policy-si":       "critical",
+		"policy-explicit": "low",
+		"policy-unmapped": "medium",
+	}
+	assertEqual(t, severities, expected)
+}
 
-	disabledPolicy := p.Policies[1]
-	assertEqual(t, disabledPolicy.Disabled, true)
+func TestConfigInvalidSeverityByControl(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  severityByControl:
+    AC-3: severe
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+`,
+		path.Join(tmpDir, "configmap.yaml"),
+	)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := "policyDefaults.severityByControl.AC-3 must be one of low, medium, high, or critical but got severe"
+	assertEqual(t, err.Error(), expected)
 }
 
-func TestConflictingPlacementSelectors(t *testing.T) {
+func TestConfigInvalidDisableFromManifestAnnotation(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
 	createConfigMap(t, tmpDir, "configmap.yaml")
-	configMapPath := path.Join(tmpDir, "configmap.yaml")
-	policyNS := "my-policies"
-	policyName := "policy-app"
-	defaultsConfig := fmt.Sprintf(
-		`
+
+	config := fmt.Sprintf(`
 apiVersion: policy.open-cluster-management.io/v1
 kind: PolicyGenerator
 metadata:
   name: policy-generator-name
 policyDefaults:
-  namespace: %s
-  placement:
-    labelSelector:
-      matchExpressions:
-      - key: cloud
-        operator: In
-        values:
-          - red hat
-          - hello
-      cloud: red hat
-      clusterID: 1234-5678
+  namespace: my-policies
+  disableFromManifestAnnotation: policy.acme.com/disabled?
 policies:
-- name: %s
+- name: policy-app-config
   manifests:
     - path: %s
 `,
-		policyNS, policyName, configMapPath,
+		path.Join(tmpDir, "configmap.yaml"),
 	)
 
 	p := Plugin{}
 
-	err := p.Config([]byte(defaultsConfig), tmpDir)
+	err := p.Config([]byte(config), tmpDir)
 	if err == nil {
 		t.Fatal("Expected an error but did not get one")
 	}
 
-	expected := "policyDefaults placement has invalid selectors: " +
-		"the input is not a valid label selector or key-value label matching map"
+	expected := "the policy policy-app-config has an invalid disableFromManifestAnnotation value " +
+		"`policy.acme.com/disabled?`; it must be a valid annotation key"
 	assertEqual(t, err.Error(), expected)
 }
+
+func TestConfigInvalidFinalizers(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := map[string]string{
+		"not domain-qualified": "cleanup",
+		"invalid name":         "example.com/clean up",
+	}
+
+	for name, finalizer := range tests {
+		finalizer := finalizer
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  finalizers:
+    - %s
+  manifests:
+    - path: %s
+`,
+				finalizer, path.Join(tmpDir, "configmap.yaml"),
+			)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			expected := fmt.Sprintf(
+				"the policy policy-app-config has an invalid finalizers value `%s`; it must be a domain-qualified "+
+					"name such as example.com/cleanup",
+				finalizer,
+			)
+			assertEqual(t, err.Error(), expected)
+		})
+	}
+}
+
+func TestConfigInvalidTemplateLibrary(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		templateLibrary string
+		manifest        string
+		expectedErr     string
+	}{
+		"unknown template": {
+			templateLibrary: "{scc-check: [{objectDefinition: {kind: SecurityContextConstraints}}]}",
+			manifest:        fmt.Sprintf("{path: %s, useTemplates: [scc-checks]}", path.Join(tmpDir, "configmap.yaml")),
+			expectedErr: "the policy policy-app-config has manifest[0].useTemplates with the template scc-checks " +
+				"that is not in the templateLibrary",
+		},
+		"missing objectDefinition": {
+			templateLibrary: "{scc-check: [{complianceType: musthave}]}",
+			manifest:        "{useTemplates: [scc-check]}",
+			expectedErr:     "templateLibrary.scc-check[0] must have an objectDefinition object",
+		},
+		"empty template": {
+			templateLibrary: "{scc-check: []}",
+			manifest:        "{useTemplates: [scc-check]}",
+			expectedErr:     "templateLibrary.scc-check must have at least one object template",
+		},
+		"patches without a path": {
+			templateLibrary: "{scc-check: [{objectDefinition: {kind: SecurityContextConstraints}}]}",
+			manifest:        "{useTemplates: [scc-check], patches: [{metadata: {name: my-scc}}]}",
+			expectedErr: "the policy policy-app-config has manifest[0] with only useTemplates set, so renderer, " +
+				"patches, fromFiles, and perCluster may not be set",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+templateLibrary: %s
+policies:
+- name: policy-app-config
+  manifests:
+    - %s
+`,
+				test.templateLibrary, test.manifest,
+			)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
@@ -3,16 +3,20 @@ package internal
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"gopkg.in/yaml.v3"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"open-cluster-management.io/policy-generator-plugin/internal/types"
 )
@@ -1082,6 +1086,197 @@ func TestGenerateMissingBindingName(t *testing.T) {
 	assertEqual(t, err.Error(), expected)
 }
 
+func TestGenerateBindingNameTemplate(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	p := Plugin{}
+	var err error
+
+	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p.PlacementBindingDefaults.NameTemplate = "{{ .PlacementName }}-binding"
+	p.PlacementBindingDefaults.MaxSubjectsPerBinding = 1
+	p.PolicyDefaults.Placement.Name = "my-placement"
+	p.PolicyDefaults.Namespace = "my-policies"
+
+	for _, name := range []string{"policy-app-config", "policy-app-config2", "policy-app-config3"} {
+		policyConf := types.PolicyConfig{
+			Name: name,
+			Manifests: []types.Manifest{
+				{Path: path.Join(tmpDir, "configmap.yaml")},
+			},
+		}
+
+		if name == "policy-app-config3" {
+			policyConf.Placement.Name = "other-placement"
+		}
+
+		p.Policies = append(p.Policies, policyConf)
+	}
+
+	p.applyDefaults(map[string]interface{}{})
+
+	if err := p.assertValidConfig(); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	manifests, err := unmarshalManifestBytes(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	bindingNames := []string{}
+
+	for _, manifest := range manifests {
+		if manifest["kind"] == placementBindingKind {
+			name, _, _ := unstructured.NestedString(manifest, "metadata", "name")
+			bindingNames = append(bindingNames, name)
+		}
+	}
+
+	assertReflectEqual(
+		t, bindingNames, []string{"my-placement-binding", "my-placement-binding2", "other-placement-binding"},
+	)
+}
+
+func TestConfigInvalidBindingNameTemplate(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		nameTemplate string
+		expectedErr  string
+	}{
+		"unparsable": {
+			nameTemplate: "{{ .PlacementName ",
+			expectedErr: "failed to parse the placementBindingDefaults.nameTemplate: template: bindingName:1: " +
+				"unclosed action",
+		},
+		"unknown field": {
+			nameTemplate: "{{ .Name }}",
+			expectedErr: "the placementBindingDefaults.nameTemplate is invalid: template: bindingName:1:3: executing " +
+				`"bindingName" at <.Name>: can't evaluate field Name in type internal.bindingNameData`,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p := Plugin{}
+			p.PlacementBindingDefaults.NameTemplate = test.nameTemplate
+			p.PolicyDefaults.Namespace = "my-policies"
+			p.Policies = append(p.Policies, types.PolicyConfig{
+				Name: "policy-app-config",
+				Manifests: []types.Manifest{
+					{Path: path.Join(tmpDir, "configmap.yaml")},
+				},
+			})
+			p.applyDefaults(map[string]interface{}{})
+
+			err := p.assertValidConfig()
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+func TestGenerateBindingNameTemplateNotDNSCompliant(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	p := Plugin{}
+	var err error
+
+	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p.PlacementBindingDefaults.NameTemplate = "{{ .PolicyName }}_binding"
+	p.PolicyDefaults.Placement.Name = "my-placement"
+	p.PolicyDefaults.Namespace = "my-policies"
+	p.Policies = append(p.Policies, types.PolicyConfig{
+		Name: "policy-app-config",
+		Manifests: []types.Manifest{
+			{Path: path.Join(tmpDir, "configmap.yaml")},
+		},
+	})
+	p.applyDefaults(map[string]interface{}{})
+
+	if err := p.assertValidConfig(); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, err = p.Generate()
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := "the placement binding name `policy-app-config_binding` rendered from " +
+		"placementBindingDefaults.nameTemplate is not DNS compliant. See " + dnsReference
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestInputPaths(t *testing.T) {
+	t.Parallel()
+
+	p := Plugin{}
+	p.PolicyDefaults.Placement.PlacementPath = "input/placement.yaml"
+	p.Placements = map[string]types.PlacementConfig{"named": {PlacementPath: "input/named-placement.yaml"}}
+	p.importPaths = []string{"/imports/shared.yaml"}
+	p.SetSubstitutionOptions(SubstitutionOptions{ValuesPath: "values.yaml"})
+	p.Policies = []types.PolicyConfig{
+		{
+			Name: "policy-app-config",
+			Manifests: []types.Manifest{
+				{Path: "input/configmap.yaml"},
+				{Path: "input-folder", OpenAPI: types.Filepath{Path: "schema.json"}},
+			},
+			PolicyOptions: types.PolicyOptions{
+				Placement: types.PlacementConfig{PlacementPath: "input/placement.yaml"},
+			},
+		},
+	}
+	p.PolicySets = []types.PolicySetConfig{
+		{
+			Name: "my-set",
+			PolicySetOptions: types.PolicySetOptions{
+				Placement: types.PlacementConfig{PlacementPath: "input/set-placement.yaml"},
+			},
+		},
+	}
+
+	expected := []string{
+		"/imports/shared.yaml",
+		"input-folder",
+		"input/configmap.yaml",
+		"input/named-placement.yaml",
+		"input/placement.yaml",
+		"input/set-placement.yaml",
+		"schema.json",
+		"values.yaml",
+	}
+	assertReflectEqual(t, p.InputPaths(), expected)
+}
+
 func TestCreatePolicy(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
@@ -1142,6 +1337,92 @@ spec:
 	assertEqual(t, output, expected)
 }
 
+func TestCreatePolicyV1beta1(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	getPolicy := func(apiVersion string) map[string]interface{} {
+		t.Helper()
+
+		p := Plugin{}
+		p.PolicyDefaults.Namespace = "my-policies"
+		p.PolicyDefaults.PolicyAPIVersion = apiVersion
+		policyConf := types.PolicyConfig{
+			Name: "policy-app-config",
+			Manifests: []types.Manifest{
+				{Path: path.Join(tmpDir, "configmap.yaml")},
+			},
+		}
+		p.Policies = append(p.Policies, policyConf)
+		p.applyDefaults(map[string]interface{}{})
+
+		err := p.createPolicy(&p.Policies[0])
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		policyManifests, err := unmarshalManifestBytes(p.outputBuffer.Bytes())
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		return policyManifests[0]
+	}
+
+	v1Policy := getPolicy("")
+	assertEqual(t, v1Policy["apiVersion"], policyAPIVersion)
+	assertEqual(t, getPolicy(policyAPIVersion), v1Policy)
+
+	v1beta1Policy := getPolicy(policyV1beta1APIVersion)
+	assertEqual(t, v1beta1Policy["apiVersion"], policyV1beta1APIVersion)
+
+	v1Spec, _ := v1Policy["spec"].(map[string]interface{})
+	v1beta1Spec, _ := v1beta1Policy["spec"].(map[string]interface{})
+
+	if _, ok := v1beta1Spec["policy-templates"]; ok {
+		t.Fatal("Expected the v1beta1 policy to not have the policy-templates field")
+	}
+
+	assertReflectEqual(t, v1beta1Spec["policyTemplates"], v1Spec["policy-templates"])
+	assertEqual(t, v1beta1Spec["disabled"], v1Spec["disabled"])
+	assertEqual(t, v1beta1Spec["remediationAction"], v1Spec["remediationAction"])
+}
+
+func TestConvertPolicyToV1beta1(t *testing.T) {
+	t.Parallel()
+
+	policyTemplates := []map[string]interface{}{{"objectDefinition": map[string]interface{}{"kind": "ConfigMap"}}}
+	spec := map[string]interface{}{
+		"disabled":          false,
+		"remediationAction": "inform",
+		"policy-templates":  policyTemplates,
+	}
+	policy := map[string]interface{}{
+		"apiVersion": policyAPIVersion,
+		"kind":       policyKind,
+		"spec":       spec,
+	}
+
+	converted := convertPolicyToV1beta1(policy)
+	assertEqual(t, converted["apiVersion"], policyV1beta1APIVersion)
+
+	expectedSpec := map[string]interface{}{
+		"disabled":          false,
+		"remediationAction": "inform",
+		"policyTemplates":   policyTemplates,
+	}
+	assertReflectEqual(t, converted["spec"], expectedSpec)
+
+	// The input v1 policy must not be modified
+	assertEqual(t, policy["apiVersion"], policyAPIVersion)
+	assertReflectEqual(t, policy["spec"], map[string]interface{}{
+		"disabled":          false,
+		"remediationAction": "inform",
+		"policy-templates":  policyTemplates,
+	})
+}
+
 func TestCreatePolicyEmptyManifest(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
@@ -1496,6 +1777,58 @@ spec:
 	assertEqual(t, output, expected)
 }
 
+func TestCreatePolicyWithFinalizers(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	p := Plugin{}
+	p.PolicyDefaults.Namespace = "my-policies"
+	p.PolicyDefaults.Finalizers = []string{"example.com/cleanup"}
+
+	p.Policies = append(
+		p.Policies,
+		types.PolicyConfig{
+			Name:      "policy-default-finalizers",
+			Manifests: []types.Manifest{{Path: path.Join(tmpDir, "configmap.yaml")}},
+		},
+		types.PolicyConfig{
+			Name:          "policy-override-finalizers",
+			PolicyOptions: types.PolicyOptions{Finalizers: []string{"example.com/archive", "example.com/audit"}},
+			Manifests:     []types.Manifest{{Path: path.Join(tmpDir, "configmap.yaml")}},
+		},
+		types.PolicyConfig{
+			Name:          "policy-no-finalizers",
+			PolicyOptions: types.PolicyOptions{Finalizers: []string{}},
+			Manifests:     []types.Manifest{{Path: path.Join(tmpDir, "configmap.yaml")}},
+		},
+	)
+	p.applyDefaults(map[string]interface{}{})
+
+	expected := [][]interface{}{
+		{"example.com/cleanup"},
+		{"example.com/archive", "example.com/audit"},
+		nil,
+	}
+
+	for i := range p.Policies {
+		p.outputBuffer.Reset()
+
+		err := p.createPolicy(&p.Policies[i])
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		output, err := unmarshalManifestBytes(p.outputBuffer.Bytes())
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		finalizers, _, _ := unstructured.NestedSlice(output[0], "metadata", "finalizers")
+		assertReflectEqual(t, finalizers, expected[i])
+	}
+}
+
 func TestCreatePolicyHubTemplateOptions(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
@@ -1879,18 +2212,54 @@ spec:
 	assertEqual(t, output, expected)
 }
 
-func TestOverrideConstraintEnforcementAction(t *testing.T) {
+func TestCreatePolicyWithGkConstraintManifestSeverity(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
-	gatekeeperPath := path.Join(tmpDir, "gatekeeper.yaml")
-	yamlContent := `
+
+	for _, name := range []string{"constraint1", "constraint2"} {
+		gatekeeperPath := path.Join(tmpDir, name+".yaml")
+		yamlContent := fmt.Sprintf(`
 apiVersion: constraints.gatekeeper.sh/v1
 kind: MyConstrainingTemplate
 metadata:
-  name: thisthingimconstraining
-`
+  name: %s
+`, name)
 
-	common := `
+		err := os.WriteFile(gatekeeperPath, []byte(yamlContent), 0o666)
+		if err != nil {
+			t.Fatalf("Failed to write %s", gatekeeperPath)
+		}
+	}
+
+	p := Plugin{}
+
+	p.PolicyDefaults.Namespace = "gatekeeper-policies"
+	p.PolicyDefaults.InformGatekeeperPolicies = false
+	policyConf := types.PolicyConfig{
+		Name: "policy-gatekeeper",
+		Manifests: []types.Manifest{
+			{
+				Path:                       path.Join(tmpDir, "constraint1.yaml"),
+				ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{Severity: "critical"},
+			},
+			{Path: path.Join(tmpDir, "constraint2.yaml")},
+		},
+	}
+	p.Policies = append(p.Policies, policyConf)
+	p.applyDefaults(map[string]interface{}{
+		"policyDefaults": map[string]interface{}{
+			"consolidateManifests":     false,
+			"informGatekeeperPolicies": false,
+		},
+	})
+
+	err := p.createPolicy(&p.Policies[0])
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output := p.outputBuffer.String()
+	expected := `
 ---
 apiVersion: policy.open-cluster-management.io/v1
 kind: Policy
@@ -1905,22 +2274,67 @@ metadata:
 spec:
     disabled: false
     policy-templates:
+        - objectDefinition:
+            apiVersion: constraints.gatekeeper.sh/v1
+            kind: MyConstrainingTemplate
+            metadata:
+                annotations:
+                    policy.open-cluster-management.io/severity: critical
+                name: constraint1
         - objectDefinition:
             apiVersion: constraints.gatekeeper.sh/v1
             kind: MyConstrainingTemplate
             metadata:
                 annotations:
                     policy.open-cluster-management.io/severity: low
-                name: thisthingimconstraining
-            spec:
-                enforcementAction: `
+                name: constraint2
+`
+	expected = strings.TrimPrefix(expected, "\n")
+	assertEqual(t, output, expected)
+}
 
-	err := os.WriteFile(gatekeeperPath, []byte(yamlContent), 0o666)
-	if err != nil {
-		t.Fatalf("Failed to write %s", gatekeeperPath)
-	}
+func TestOverrideConstraintEnforcementAction(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	gatekeeperPath := path.Join(tmpDir, "gatekeeper.yaml")
+	yamlContent := `
+apiVersion: constraints.gatekeeper.sh/v1
+kind: MyConstrainingTemplate
+metadata:
+  name: thisthingimconstraining
+`
 
-	tests := []struct {
+	common := `
+---
+apiVersion: policy.open-cluster-management.io/v1
+kind: Policy
+metadata:
+    annotations:
+        policy.open-cluster-management.io/categories: CM Configuration Management
+        policy.open-cluster-management.io/controls: CM-2 Baseline Configuration
+        policy.open-cluster-management.io/description: ""
+        policy.open-cluster-management.io/standards: NIST SP 800-53
+    name: policy-gatekeeper
+    namespace: gatekeeper-policies
+spec:
+    disabled: false
+    policy-templates:
+        - objectDefinition:
+            apiVersion: constraints.gatekeeper.sh/v1
+            kind: MyConstrainingTemplate
+            metadata:
+                annotations:
+                    policy.open-cluster-management.io/severity: low
+                name: thisthingimconstraining
+            spec:
+                enforcementAction: `
+
+	err := os.WriteFile(gatekeeperPath, []byte(yamlContent), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write %s", gatekeeperPath)
+	}
+
+	tests := []struct {
 		policyConf      types.PolicyConfig
 		policyDefaultEA string
 		expectedEA      string
@@ -2120,6 +2534,7 @@ func TestCreatePolicyDir(t *testing.T) {
 	createConfigMap(t, tmpDir, "configmap2.yaml")
 
 	p := Plugin{}
+	p.PolicyDefaults.AllowDuplicateObjects = true
 	p.PolicyDefaults.Namespace = "my-policies"
 	policyConf := types.PolicyConfig{
 		Name:      "policy-app-config",
@@ -2443,1410 +2858,1620 @@ spec:
 	assertEqual(t, output, expected)
 }
 
-func TestCreatePlacementDuplicateName(t *testing.T) {
+func TestCreatePlacementRuleMatchLabels(t *testing.T) {
 	t.Parallel()
 
 	p := Plugin{}
+	p.usingPlR = true
 	p.allPlcs = map[string]bool{}
 	p.csToPlc = map[string]string{}
 	p.PolicyDefaults.Namespace = "my-policies"
-	policyConf := types.PolicyConfig{
-		Name: "policy-app-config",
-		PolicyOptions: types.PolicyOptions{
-			Placement: types.PlacementConfig{
-				Name: "my-placement",
-			},
-		},
-	}
-	policyConf2 := types.PolicyConfig{
-		Name: "policy-app-config2",
-		PolicyOptions: types.PolicyOptions{
-			Placement: types.PlacementConfig{
-				ClusterSelectors: map[string]interface{}{"my": "app"},
-				Name:             "my-placement",
-			},
+	policyConf := types.PolicyConfig{Name: "policy-app-config"}
+	policyConf.Placement.ClusterSelector = map[string]interface{}{
+		"matchLabels": map[string]interface{}{
+			"cloud": "red hat",
+			"gpu":   true,
+			"tier":  2,
 		},
 	}
 
-	_, err := p.createPolicyPlacement(policyConf.Placement, policyConf.Name)
+	name, err := p.createPolicyPlacement(policyConf.Placement, policyConf.Name)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	_, err = p.createPolicyPlacement(policyConf2.Placement, policyConf2.Name)
-	if err == nil {
-		t.Fatal("Expected an error but did not get one")
-	}
+	assertEqual(t, name, "placement-policy-app-config")
 
-	assertEqual(t, err.Error(), "a duplicate placement name was detected: my-placement")
+	output := p.outputBuffer.String()
+	expected := `
+---
+apiVersion: apps.open-cluster-management.io/v1
+kind: PlacementRule
+metadata:
+    name: placement-policy-app-config
+    namespace: my-policies
+spec:
+    clusterSelector:
+        matchLabels:
+            cloud: red hat
+            gpu: "true"
+            tier: "2"
+`
+	expected = strings.TrimPrefix(expected, "\n")
+	assertEqual(t, output, expected)
 }
 
-func plPathHelper(t *testing.T, plrYAML string, usingPlR bool) (*Plugin, string) {
-	t.Helper()
-	tmpDir := t.TempDir()
-	plrPath := path.Join(tmpDir, "pl.yaml")
-	plrYAML = strings.TrimPrefix(plrYAML, "\n")
-
-	err := os.WriteFile(plrPath, []byte(plrYAML), 0o666)
-	if err != nil {
-		t.Fatal(err.Error())
-	}
+func TestCreatePlacementMatchLabelsClusterVersion(t *testing.T) {
+	t.Parallel()
 
 	p := Plugin{}
-	p.usingPlR = usingPlR
 	p.allPlcs = map[string]bool{}
-	p.processedPlcs = map[string]bool{}
+	p.csToPlc = map[string]string{}
 	p.PolicyDefaults.Namespace = "my-policies"
 	policyConf := types.PolicyConfig{Name: "policy-app-config"}
+	policyConf.Placement.LabelSelector = map[string]interface{}{
+		"matchLabels": map[string]interface{}{"cloud": "red hat"},
+	}
+	policyConf.Placement.ClusterVersion = "4.14"
 
-	if usingPlR {
-		policyConf.Placement.PlacementRulePath = plrPath
-	} else {
-		policyConf.Placement.PlacementPath = plrPath
+	_, err := p.createPolicyPlacement(policyConf.Placement, policyConf.Name)
+	if err != nil {
+		t.Fatal(err.Error())
 	}
 
-	p.Policies = append(p.Policies, policyConf)
+	manifests, err := unmarshalManifestBytes(p.outputBuffer.Bytes())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
 
-	return &p, plrPath
+	predicates, _, _ := unstructured.NestedSlice(manifests[0], "spec", "predicates")
+	labelSelector, _, _ := unstructured.NestedMap(
+		predicates[0].(map[string]interface{}), "requiredClusterSelector", "labelSelector",
+	)
+
+	assertReflectEqual(t, labelSelector["matchLabels"], map[string]interface{}{"cloud": "red hat"})
+
+	matchExpressions, _ := labelSelector["matchExpressions"].([]interface{})
+	assertEqual(t, len(matchExpressions), 1)
 }
 
-func TestCreatePlacementPlrPath(t *testing.T) {
+func TestCreatePlacementPredicates(t *testing.T) {
 	t.Parallel()
 
-	plrYAML := `
----
-apiVersion: apps.open-cluster-management.io/v1
-kind: PlacementRule
-metadata:
-    name: my-plr
-    namespace: my-policies
-spec:
-    clusterSelector:
-        matchExpressions:
-            - key: game
-              operator: In
-              values:
-                - pacman
-`
-	plrYAML = strings.TrimPrefix(plrYAML, "\n")
-	p, _ := plPathHelper(t, plrYAML, true)
+	p := Plugin{}
+	p.allPlcs = map[string]bool{}
+	p.csToPlc = map[string]string{}
+	p.PolicyDefaults.Namespace = "my-policies"
+	policyConf := types.PolicyConfig{Name: "policy-app-config"}
+	policyConf.Placement.Predicates = []map[string]interface{}{
+		{
+			"requiredClusterSelector": map[string]interface{}{
+				"labelSelector": map[string]interface{}{
+					"matchLabels": map[string]interface{}{"cloud": "red hat"},
+				},
+			},
+		},
+		{
+			"requiredClusterSelector": map[string]interface{}{
+				"celSelector": map[string]interface{}{
+					"celExpressions": []interface{}{`cluster.metadata.labels["version"].versionGreaterThan("4.14")`},
+				},
+			},
+		},
+	}
+	applyDefaultPlacementFields(&policyConf.Placement, p.PolicyDefaults.Placement)
 
-	name, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
+	name, err := p.createPolicyPlacement(policyConf.Placement, policyConf.Name)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	assertEqual(t, name, "my-plr")
+	assertEqual(t, name, "placement-policy-app-config")
 
 	output := p.outputBuffer.String()
-
-	assertEqual(t, output, plrYAML)
-}
-
-func TestCreatePlacementPlrPathSkip(t *testing.T) {
-	t.Parallel()
-
-	plrYAML := `
+	expected := `
 ---
-apiVersion: apps.open-cluster-management.io/v1
-kind: PlacementRule
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
 metadata:
-    name: my-plr
+    name: placement-policy-app-config
     namespace: my-policies
+spec:
+    predicates:
+        - requiredClusterSelector:
+            labelSelector:
+                matchLabels:
+                    cloud: red hat
+        - requiredClusterSelector:
+            celSelector:
+                celExpressions:
+                    - cluster.metadata.labels["version"].versionGreaterThan("4.14")
+    tolerations:
+        - key: cluster.open-cluster-management.io/unavailable
+          operator: Exists
+        - key: cluster.open-cluster-management.io/unreachable
+          operator: Exists
 `
-	plrYAML = strings.TrimPrefix(plrYAML, "\n")
-	p, _ := plPathHelper(t, plrYAML, true)
+	expected = strings.TrimPrefix(expected, "\n")
+	assertEqual(t, output, expected)
+}
 
-	p.processedPlcs = map[string]bool{"my-plr": true}
+func TestCreatePlacementCelExpressions(t *testing.T) {
+	t.Parallel()
 
-	name, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
+	p := Plugin{}
+	p.allPlcs = map[string]bool{}
+	p.csToPlc = map[string]string{}
+	p.PolicyDefaults.Namespace = "my-policies"
+	policyConf := types.PolicyConfig{Name: "policy-app-config"}
+	policyConf.Placement.LabelSelector = map[string]interface{}{
+		"cloud": "red hat",
+	}
+	policyConf.Placement.CelExpressions = []string{
+		`cluster.metadata.labels["version"].versionGreaterThan("4.14")`,
+	}
+	applyDefaultPlacementFields(&policyConf.Placement, p.PolicyDefaults.Placement)
+
+	name, err := p.createPolicyPlacement(policyConf.Placement, policyConf.Name)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	assertEqual(t, name, "my-plr")
-	assertEqual(t, p.outputBuffer.String(), "")
-}
-
-func TestCreatePlacementPlrPathNoName(t *testing.T) {
-	t.Parallel()
+	assertEqual(t, name, "placement-policy-app-config")
 
-	plrYAML := `
+	output := p.outputBuffer.String()
+	expected := `
 ---
-apiVersion: apps.open-cluster-management.io/v1
-kind: PlacementRule
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
 metadata:
+    name: placement-policy-app-config
     namespace: my-policies
 spec:
     predicates:
         - requiredClusterSelector:
+            celSelector:
+                celExpressions:
+                    - cluster.metadata.labels["version"].versionGreaterThan("4.14")
             labelSelector:
-                matchExpressions: []
+                matchExpressions:
+                    - key: cloud
+                      operator: In
+                      values:
+                        - red hat
     tolerations:
         - key: cluster.open-cluster-management.io/unavailable
           operator: Exists
         - key: cluster.open-cluster-management.io/unreachable
           operator: Exists
 `
-	p, plrPath := plPathHelper(t, plrYAML, true)
-
-	_, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
-	if err == nil {
-		t.Fatal("Expected an error but did not get one")
-	}
-
-	expected := fmt.Sprintf("the placement %s must have a name set", plrPath)
-	assertEqual(t, err.Error(), expected)
+	expected = strings.TrimPrefix(expected, "\n")
+	assertEqual(t, output, expected)
 }
 
-func TestCreatePlacementPlrPathNoNamespace(t *testing.T) {
+func TestCreatePlacementClusterVersion(t *testing.T) {
 	t.Parallel()
 
-	plrYAML := `
----
-apiVersion: apps.open-cluster-management.io/v1
-kind: PlacementRule
-metadata:
-    name: my-plr
-spec:
-    clusterSelector:
-        matchExpressions: []
-`
-	p, plrPath := plPathHelper(t, plrYAML, true)
-
-	_, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
-	if err == nil {
-		t.Fatal("Expected an error but did not get one")
+	p := Plugin{}
+	p.allPlcs = map[string]bool{}
+	p.csToPlc = map[string]string{}
+	p.PolicyDefaults.Namespace = "my-policies"
+	policyConf := types.PolicyConfig{Name: "policy-app-config"}
+	policyConf.Placement.LabelSelector = map[string]interface{}{
+		"cloud": "red hat",
 	}
+	policyConf.Placement.ClusterVersion = ">=4.14, <4.17, !=4.15"
+	applyDefaultPlacementFields(&policyConf.Placement, p.PolicyDefaults.Placement)
 
-	expected := fmt.Sprintf("the placement %s must have a namespace set", plrPath)
-	assertEqual(t, err.Error(), expected)
-}
+	name, err := p.createPolicyPlacement(policyConf.Placement, policyConf.Name)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
 
-func TestCreatePlacementPlrPathWrongNamespace(t *testing.T) {
-	t.Parallel()
+	assertEqual(t, name, "placement-policy-app-config")
 
-	plrYAML := `
+	output := p.outputBuffer.String()
+	expected := `
 ---
-apiVersion: apps.open-cluster-management.io/v1
-kind: PlacementRule
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
 metadata:
-    name: my-plr
-    namespace: wrong-namespace
-spec:
-    clusterSelector:
-        matchExpressions: []
-`
-	p, plrPath := plPathHelper(t, plrYAML, true)
-
-	_, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
-	if err == nil {
-		t.Fatal("Expected an error but did not get one")
-	}
-
-	expected := fmt.Sprintf(
-		"the placement %s must have the same namespace as the policy (%s)",
-		plrPath,
-		p.PolicyDefaults.Namespace,
-	)
-	assertEqual(t, err.Error(), expected)
-}
-
-func TestCreatePlacementPlrPathNoPlr(t *testing.T) {
-	t.Parallel()
-
-	plrYAML := `
-apiVersion: v1
-kind: ConfigMap
-metadata:
-  name: my-configmap2
-  namespace: my-policies
-data:
-  game.properties: |
-    enemies=potato
-`
-	p, plrPath := plPathHelper(t, plrYAML, true)
-
-	_, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
-	if err == nil {
-		t.Fatal("Expected an error but did not get one")
-	}
-
-	expected := fmt.Sprintf("the placement manifest %s did not have a placement", plrPath)
-	assertEqual(t, err.Error(), expected)
-}
-
-func TestCreatePlacementPlPath(t *testing.T) {
-	t.Parallel()
-
-	plrYAML := `
----
-apiVersion: cluster.open-cluster-management.io/v1beta1
-kind: Placement
-metadata:
-    name: my-plr
+    name: placement-policy-app-config
     namespace: my-policies
 spec:
     predicates:
         - requiredClusterSelector:
             labelSelector:
                 matchExpressions:
-                    - key: game
+                    - key: cloud
                       operator: In
                       values:
-                        - pacman
+                        - red hat
+                    - key: openshiftVersion-major
+                      operator: In
+                      values:
+                        - "4"
+                    - key: openshiftVersion-major-minor
+                      operator: NotIn
+                      values:
+                        - "4.0"
+                        - "4.1"
+                        - "4.2"
+                        - "4.3"
+                        - "4.4"
+                        - "4.5"
+                        - "4.6"
+                        - "4.7"
+                        - "4.8"
+                        - "4.9"
+                        - "4.10"
+                        - "4.11"
+                        - "4.12"
+                        - "4.13"
+                    - key: openshiftVersion-major-minor
+                      operator: In
+                      values:
+                        - "4.0"
+                        - "4.1"
+                        - "4.2"
+                        - "4.3"
+                        - "4.4"
+                        - "4.5"
+                        - "4.6"
+                        - "4.7"
+                        - "4.8"
+                        - "4.9"
+                        - "4.10"
+                        - "4.11"
+                        - "4.12"
+                        - "4.13"
+                        - "4.14"
+                        - "4.15"
+                        - "4.16"
+                    - key: openshiftVersion-major-minor
+                      operator: Exists
+                    - key: openshiftVersion-major-minor
+                      operator: NotIn
+                      values:
+                        - "4.15"
     tolerations:
         - key: cluster.open-cluster-management.io/unavailable
           operator: Exists
         - key: cluster.open-cluster-management.io/unreachable
           operator: Exists
 `
-	plrYAML = strings.TrimPrefix(plrYAML, "\n")
-	p, _ := plPathHelper(t, plrYAML, false)
-
-	name, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
-	if err != nil {
-		t.Fatal(err.Error())
-	}
-
-	assertEqual(t, name, "my-plr")
-
-	output := p.outputBuffer.String()
+	expected = strings.TrimPrefix(expected, "\n")
+	assertEqual(t, output, expected)
 
-	assertEqual(t, output, plrYAML)
+	// The label selector of the configuration must not be modified
+	_, found := policyConf.Placement.LabelSelector["matchExpressions"]
+	assertEqual(t, found, false)
 }
 
-func TestCreatePlacementPlPathSkip(t *testing.T) {
+func TestCreatePlacementDecisionStrategy(t *testing.T) {
 	t.Parallel()
 
-	plrYAML := `
----
-apiVersion: cluster.open-cluster-management.io/v1beta1
-kind: Placement
-metadata:
-    name: my-plr
-    namespace: my-policies
-`
-	plrYAML = strings.TrimPrefix(plrYAML, "\n")
-	p, _ := plPathHelper(t, plrYAML, false)
-
-	p.processedPlcs = map[string]bool{"my-plr": true}
+	p := Plugin{}
+	p.allPlcs = map[string]bool{}
+	p.csToPlc = map[string]string{}
+	p.PolicyDefaults.Namespace = "my-policies"
+	p.PolicyDefaults.Placement.DecisionStrategy = map[string]interface{}{
+		"groupStrategy": map[string]interface{}{
+			"clustersPerDecisionGroup": "25%",
+			"decisionGroups": []interface{}{
+				map[string]interface{}{
+					"groupName": "canary",
+					"groupClusterSelector": map[string]interface{}{
+						"labelSelector": map[string]interface{}{
+							"matchLabels": map[string]interface{}{"canary": "true"},
+						},
+					},
+				},
+			},
+		},
+	}
+	policyConf := types.PolicyConfig{Name: "policy-app-config"}
+	policyConf.Placement.LabelSelector = map[string]interface{}{
+		"cloud": "red hat",
+	}
+	applyDefaultPlacementFields(&policyConf.Placement, p.PolicyDefaults.Placement)
 
-	name, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
+	name, err := p.createPolicyPlacement(policyConf.Placement, policyConf.Name)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	assertEqual(t, name, "my-plr")
-	assertEqual(t, p.outputBuffer.String(), "")
-}
-
-func TestCreatePlacementPlPathNoName(t *testing.T) {
-	t.Parallel()
+	assertEqual(t, name, "placement-policy-app-config")
 
-	plrYAML := `
+	output := p.outputBuffer.String()
+	expected := `
 ---
 apiVersion: cluster.open-cluster-management.io/v1beta1
 kind: Placement
 metadata:
+    name: placement-policy-app-config
     namespace: my-policies
 spec:
+    decisionStrategy:
+        groupStrategy:
+            clustersPerDecisionGroup: 25%
+            decisionGroups:
+                - groupClusterSelector:
+                    labelSelector:
+                        matchLabels:
+                            canary: "true"
+                  groupName: canary
     predicates:
         - requiredClusterSelector:
             labelSelector:
-                matchExpressions: []
+                matchExpressions:
+                    - key: cloud
+                      operator: In
+                      values:
+                        - red hat
     tolerations:
         - key: cluster.open-cluster-management.io/unavailable
           operator: Exists
         - key: cluster.open-cluster-management.io/unreachable
           operator: Exists
 `
-	p, plrPath := plPathHelper(t, plrYAML, false)
+	expected = strings.TrimPrefix(expected, "\n")
+	assertEqual(t, output, expected)
+}
 
-	_, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
-	if err == nil {
-		t.Fatal("Expected an error but did not get one")
+func TestCreatePlacementSpreadConstraints(t *testing.T) {
+	t.Parallel()
+
+	p := Plugin{}
+	p.allPlcs = map[string]bool{}
+	p.csToPlc = map[string]string{}
+	p.PolicyDefaults.Namespace = "my-policies"
+	p.PolicyDefaults.Placement.SpreadConstraints = []map[string]interface{}{
+		{"topologyKey": "region", "topologyKeyType": "Label", "maxSkew": 1},
+	}
+	policyConf := types.PolicyConfig{Name: "policy-app-config"}
+	policyConf.Placement.LabelSelector = map[string]interface{}{
+		"cloud": "red hat",
+	}
+	// The generated spread policy takes precedence over the spec overrides
+	policyConf.Placement.SpecOverrides = map[string]interface{}{
+		"spreadPolicy": map[string]interface{}{"spreadConstraints": []interface{}{}},
 	}
+	applyDefaultPlacementFields(&policyConf.Placement, p.PolicyDefaults.Placement)
 
-	expected := fmt.Sprintf("the placement %s must have a name set", plrPath)
-	assertEqual(t, err.Error(), expected)
-}
+	name, err := p.createPolicyPlacement(policyConf.Placement, policyConf.Name)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
 
-func TestCreatePlacementPlPathNoNamespace(t *testing.T) {
-	t.Parallel()
+	assertEqual(t, name, "placement-policy-app-config")
 
-	plrYAML := `
+	output := p.outputBuffer.String()
+	expected := `
 ---
 apiVersion: cluster.open-cluster-management.io/v1beta1
 kind: Placement
 metadata:
-    name: my-plr
+    name: placement-policy-app-config
+    namespace: my-policies
 spec:
     predicates:
         - requiredClusterSelector:
             labelSelector:
-                matchExpressions: []
+                matchExpressions:
+                    - key: cloud
+                      operator: In
+                      values:
+                        - red hat
+    spreadPolicy:
+        spreadConstraints:
+            - maxSkew: 1
+              topologyKey: region
+              topologyKeyType: Label
     tolerations:
         - key: cluster.open-cluster-management.io/unavailable
           operator: Exists
         - key: cluster.open-cluster-management.io/unreachable
           operator: Exists
 `
-	p, plrPath := plPathHelper(t, plrYAML, false)
+	expected = strings.TrimPrefix(expected, "\n")
+	assertEqual(t, output, expected)
+}
 
-	_, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
-	if err == nil {
-		t.Fatal("Expected an error but did not get one")
+func TestCreatePlacementSpecOverrides(t *testing.T) {
+	t.Parallel()
+
+	p := Plugin{}
+	p.allPlcs = map[string]bool{}
+	p.csToPlc = map[string]string{}
+	p.PolicyDefaults.Namespace = "my-policies"
+	policyConf := types.PolicyConfig{Name: "policy-app-config"}
+	policyConf.Placement.LabelSelector = map[string]interface{}{
+		"cloud": "red hat",
+	}
+	policyConf.Placement.SpecOverrides = map[string]interface{}{
+		"clusterSets": []interface{}{"prod"},
+		// The generated predicates can't be overridden
+		"predicates": []interface{}{},
+		"tolerations": []interface{}{
+			map[string]interface{}{
+				"key":      "cluster.open-cluster-management.io/unreachable",
+				"operator": "Exists",
+			},
+		},
 	}
 
-	expected := fmt.Sprintf("the placement %s must have a namespace set", plrPath)
-	assertEqual(t, err.Error(), expected)
-}
+	name, err := p.createPolicyPlacement(policyConf.Placement, policyConf.Name)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
 
-func TestCreatePlacementPlPathWrongNamespace(t *testing.T) {
-	t.Parallel()
+	assertEqual(t, name, "placement-policy-app-config")
 
-	plrYAML := `
+	output := p.outputBuffer.String()
+	expected := `
 ---
 apiVersion: cluster.open-cluster-management.io/v1beta1
 kind: Placement
 metadata:
-    name: my-plr
-    namespace: wrong-namespace
+    name: placement-policy-app-config
+    namespace: my-policies
 spec:
+    clusterSets:
+        - prod
     predicates:
         - requiredClusterSelector:
             labelSelector:
-                matchExpressions: []
+                matchExpressions:
+                    - key: cloud
+                      operator: In
+                      values:
+                        - red hat
     tolerations:
-        - key: cluster.open-cluster-management.io/unavailable
-          operator: Exists
         - key: cluster.open-cluster-management.io/unreachable
           operator: Exists
 `
-	p, plrPath := plPathHelper(t, plrYAML, false)
+	expected = strings.TrimPrefix(expected, "\n")
+	assertEqual(t, output, expected)
+}
 
-	_, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
+func TestCreatePlacementDuplicateName(t *testing.T) {
+	t.Parallel()
+
+	p := Plugin{}
+	p.allPlcs = map[string]bool{}
+	p.csToPlc = map[string]string{}
+	p.PolicyDefaults.Namespace = "my-policies"
+	policyConf := types.PolicyConfig{
+		Name: "policy-app-config",
+		PolicyOptions: types.PolicyOptions{
+			Placement: types.PlacementConfig{
+				Name: "my-placement",
+			},
+		},
+	}
+	policyConf2 := types.PolicyConfig{
+		Name: "policy-app-config2",
+		PolicyOptions: types.PolicyOptions{
+			Placement: types.PlacementConfig{
+				ClusterSelectors: map[string]interface{}{"my": "app"},
+				Name:             "my-placement",
+			},
+		},
+	}
+
+	_, err := p.createPolicyPlacement(policyConf.Placement, policyConf.Name)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, err = p.createPolicyPlacement(policyConf2.Placement, policyConf2.Name)
 	if err == nil {
 		t.Fatal("Expected an error but did not get one")
 	}
 
-	expected := fmt.Sprintf(
-		"the placement %s must have the same namespace as the policy (%s)",
-		plrPath,
-		p.PolicyDefaults.Namespace,
-	)
-	assertEqual(t, err.Error(), expected)
+	if !errors.Is(err, ErrDuplicatePlacement) {
+		t.Fatalf("Expected an ErrDuplicatePlacement error but got: %v", err)
+	}
 }
 
-func TestCreatePlacementPlPathFoundPlR(t *testing.T) {
+func TestCreatePlacementConflictingSelectors(t *testing.T) {
 	t.Parallel()
 
-	plrYAML := `
----
-apiVersion: apps.open-cluster-management.io/v1
-kind: PlacementRule
-metadata:
-    name: my-plr
-    namespace: my-policies
-`
-	p, plrPath := plPathHelper(t, plrYAML, false)
+	p := Plugin{}
+	p.allPlcs = map[string]bool{}
+	p.csToPlc = map[string]string{}
+	p.PolicyDefaults.Namespace = "my-policies"
+	placement := types.PlacementConfig{
+		LabelSelector: map[string]interface{}{"cloud": "red hat"},
+		Name:          "my-placement",
+	}
+	placement2 := types.PlacementConfig{
+		LabelSelector: map[string]interface{}{"cloud": "other"},
+		Name:          "my-placement",
+	}
 
-	_, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
+	_, err := p.createPolicyPlacement(placement, "policy-app-config")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, err = p.createPolicyPlacement(placement2, "policy-app-config2")
 	if err == nil {
 		t.Fatal("Expected an error but did not get one")
 	}
 
-	expected := fmt.Sprintf(
-		"the placement %s specified a placementRule kind but expected a placement kind",
-		plrPath,
-	)
+	if !errors.Is(err, ErrDuplicatePlacement) {
+		t.Fatalf("Expected an ErrDuplicatePlacement error but got: %v", err)
+	}
+
+	expected := "the placement name my-placement is used by multiple placements with different cluster selectors " +
+		"or placement options; set a unique placement name for each set of cluster selectors and placement options"
 	assertEqual(t, err.Error(), expected)
 }
 
-func TestCreatePlacementPlrPathFoundPl(t *testing.T) {
+func TestCreatePlacementConsolidationOptions(t *testing.T) {
 	t.Parallel()
 
-	plrYAML := `
----
-apiVersion: cluster.open-cluster-management.io/v1beta1
-kind: Placement
+	tests := map[string]struct {
+		placement2 types.PlacementConfig
+	}{
+		"decisionStrategy": {
+			placement2: types.PlacementConfig{
+				LabelSelector: map[string]interface{}{"cloud": "red hat"},
+				DecisionStrategy: map[string]interface{}{
+					"groupStrategy": map[string]interface{}{"clustersPerDecisionGroup": "25%"},
+				},
+			},
+		},
+		"specOverrides": {
+			placement2: types.PlacementConfig{
+				LabelSelector: map[string]interface{}{"cloud": "red hat"},
+				SpecOverrides: map[string]interface{}{"numberOfClusters": 2},
+			},
+		},
+		"spreadConstraints": {
+			placement2: types.PlacementConfig{
+				LabelSelector: map[string]interface{}{"cloud": "red hat"},
+				SpreadConstraints: []map[string]interface{}{
+					{"topologyKey": "region", "topologyKeyType": "Label", "maxSkew": 1},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p := Plugin{}
+			p.allPlcs = map[string]bool{}
+			p.csToPlc = map[string]string{}
+			p.PolicyDefaults.Namespace = "my-policies"
+			p.PolicyDefaults.Placement.Name = "my-placement"
+			placement := types.PlacementConfig{
+				LabelSelector: map[string]interface{}{"cloud": "red hat"},
+			}
+
+			name, err := p.createPolicyPlacement(placement, "policy-app-config")
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			assertEqual(t, name, "my-placement")
+
+			// The placement with the same selectors but different options isn't consolidated with the first one
+			name, err = p.createPolicyPlacement(test.placement2, "policy-app-config2")
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			assertEqual(t, name, "my-placement2")
+		})
+	}
+}
+
+func TestGenerateDuplicateBindingName(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
 metadata:
-    name: my-plr
-    namespace: my-policies
-`
-	p, plrPath := plPathHelper(t, plrYAML, true)
+  name: policy-generator-name
+placementBindingDefaults:
+  name: binding-policy-b
+policyDefaults:
+  namespace: my-policies
+  placement:
+    name: placement-shared
+policies:
+- name: policy-a1
+  manifests: &manifests
+  - path: %s
+- name: policy-a2
+  manifests: *manifests
+- name: policy-b
+  placement:
+    labelSelector:
+      env: prod
+  manifests: *manifests
+`,
+		path.Join(tmpDir, "configmap.yaml"),
+	)
 
-	_, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, err = p.Generate()
 	if err == nil {
 		t.Fatal("Expected an error but did not get one")
 	}
 
-	expected := fmt.Sprintf(
-		"the placement %s specified a placement kind but expected a placementRule kind",
-		plrPath,
-	)
+	if !errors.Is(err, ErrDuplicatePlacementBinding) {
+		t.Fatalf("Expected an ErrDuplicatePlacementBinding error but got: %v", err)
+	}
+
+	expected := "a duplicate placement binding name was detected: binding-policy-b is generated for the placements " +
+		"placement-shared and placement-shared2; set placementBindingDefaults.name or rename the policies and " +
+		"policy sets so that the placement binding names are unique"
 	assertEqual(t, err.Error(), expected)
 }
 
-func TestCreatePlacementPlPathNoPl(t *testing.T) {
+func TestGeneratePlacementRefsConflictingSelectors(t *testing.T) {
 	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
 
-	plrYAML := `
-apiVersion: v1
-kind: ConfigMap
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
 metadata:
-  name: my-configmap2
+  name: policy-generator-name
+placements:
+  prod:
+    name: shared-placement
+    labelSelector:
+      env: prod
+  dev:
+    name: shared-placement
+    labelSelector:
+      env: dev
+policyDefaults:
   namespace: my-policies
-data:
-  game.properties: |
-    enemies=potato
-`
-	p, plrPath := plPathHelper(t, plrYAML, false)
+policies:
+- name: policy-prod
+  placement:
+    ref: prod
+  manifests:
+    - path: %s
+- name: policy-dev
+  placement:
+    ref: dev
+  manifests:
+    - path: %s
+`,
+		path.Join(tmpDir, "configmap.yaml"), path.Join(tmpDir, "configmap.yaml"),
+	)
 
-	_, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, err = p.Generate()
 	if err == nil {
 		t.Fatal("Expected an error but did not get one")
 	}
 
-	expected := fmt.Sprintf("the placement manifest %s did not have a placement", plrPath)
+	expected := "the placement name shared-placement is used by multiple placements with different cluster " +
+		"selectors or placement options; set a unique placement name for each set of cluster selectors and " +
+		"placement options"
 	assertEqual(t, err.Error(), expected)
 }
 
-func TestCreatePlacementBinding(t *testing.T) {
+func TestGenerateDefaultPlacementNameDifferentLabelSelectors(t *testing.T) {
 	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
 
 	p := Plugin{}
 	p.PolicyDefaults.Namespace = "my-policies"
-	policyConf := types.PolicyConfig{Name: "policy-app-config"}
-	p.Policies = append(p.Policies, policyConf)
-	policyConf2 := types.PolicyConfig{Name: "policy-app-config2"}
-	p.Policies = append(p.Policies, policyConf2)
+	p.PolicyDefaults.Placement.Name = "my-placement"
+	p.PlacementBindingDefaults.Name = "my-binding"
 
-	bindingName := "my-placement-binding"
-	plrName := "my-placement-rule"
-	policyConfs := []*types.PolicyConfig{}
-	policyConfs = append(policyConfs, &p.Policies[0], &p.Policies[1])
+	for i, env := range []string{"prod", "dev", "prod"} {
+		p.Policies = append(p.Policies, types.PolicyConfig{
+			Name: fmt.Sprintf("policy-app-config%d", i),
+			PolicyOptions: types.PolicyOptions{
+				Placement: types.PlacementConfig{LabelSelector: map[string]interface{}{"env": env}},
+			},
+			Manifests: []types.Manifest{{Path: path.Join(tmpDir, "configmap.yaml")}},
+		})
+	}
 
-	policySetConfs := []*types.PolicySetConfig{
-		{
-			Name: "my-policyset",
-		},
+	p.applyDefaults(map[string]interface{}{})
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
 	}
 
-	err := p.createPlacementBinding(bindingName, plrName, policyConfs, policySetConfs)
+	manifests, err := unmarshalManifestBytes(output)
 	if err != nil {
-		t.Fatal(err)
+		t.Fatal(err.Error())
 	}
 
-	expected := `
+	// The policies with the same label selector share a placement
+	policyPlacements := map[string]string{}
+
+	for _, manifest := range manifests {
+		if manifest["kind"] != "PlacementBinding" {
+			continue
+		}
+
+		plcName, _, _ := unstructured.NestedString(manifest, "placementRef", "name")
+		subjects, _, _ := unstructured.NestedSlice(manifest, "subjects")
+
+		for _, subject := range subjects {
+			//nolint:forcetypeassert
+			policyPlacements[subject.(map[string]interface{})["name"].(string)] = plcName
+		}
+	}
+
+	expected := map[string]string{
+		"policy-app-config0": "my-placement",
+		"policy-app-config1": "my-placement2",
+		"policy-app-config2": "my-placement",
+	}
+	assertReflectEqual(t, policyPlacements, expected)
+}
+
+func plPathHelper(t *testing.T, plrYAML string, usingPlR bool) (*Plugin, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	plrPath := path.Join(tmpDir, "pl.yaml")
+	plrYAML = strings.TrimPrefix(plrYAML, "\n")
+
+	err := os.WriteFile(plrPath, []byte(plrYAML), 0o666)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p := Plugin{}
+	p.usingPlR = usingPlR
+	p.allPlcs = map[string]bool{}
+	p.processedPlcs = map[string]bool{}
+	p.PolicyDefaults.Namespace = "my-policies"
+	policyConf := types.PolicyConfig{Name: "policy-app-config"}
+
+	if usingPlR {
+		policyConf.Placement.PlacementRulePath = plrPath
+	} else {
+		policyConf.Placement.PlacementPath = plrPath
+	}
+
+	p.Policies = append(p.Policies, policyConf)
+
+	return &p, plrPath
+}
+
+func TestCreatePlacementPlrPath(t *testing.T) {
+	t.Parallel()
+
+	plrYAML := `
 ---
-apiVersion: policy.open-cluster-management.io/v1
-kind: PlacementBinding
+apiVersion: apps.open-cluster-management.io/v1
+kind: PlacementRule
 metadata:
-    name: my-placement-binding
+    name: my-plr
     namespace: my-policies
-placementRef:
-    apiGroup: cluster.open-cluster-management.io
-    kind: Placement
-    name: my-placement-rule
-subjects:
-    - apiGroup: policy.open-cluster-management.io
-      kind: Policy
-      name: policy-app-config
-    - apiGroup: policy.open-cluster-management.io
-      kind: Policy
-      name: policy-app-config2
-    - apiGroup: policy.open-cluster-management.io
-      kind: PolicySet
-      name: my-policyset
+spec:
+    clusterSelector:
+        matchExpressions:
+            - key: game
+              operator: In
+              values:
+                - pacman
 `
-	expected = strings.TrimPrefix(expected, "\n")
-	assertEqual(t, p.outputBuffer.String(), expected)
+	plrYAML = strings.TrimPrefix(plrYAML, "\n")
+	p, _ := plPathHelper(t, plrYAML, true)
+
+	name, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, name, "my-plr")
+
+	output := p.outputBuffer.String()
+
+	assertEqual(t, output, plrYAML)
 }
 
-func TestGeneratePolicySets(t *testing.T) {
+func TestCreatePlacementPlcPathSpecOverrides(t *testing.T) {
 	t.Parallel()
-	tmpDir := t.TempDir()
-	createConfigMap(t, tmpDir, "configmap.yaml")
 
-	testCases := []testCase{
-		{
-			name: "Use p.PolicyDefaults.PolicySets only",
-			setupFunc: func(p *Plugin) {
-				// PolicyDefaults.PolicySets should be applied to both policies
-				p.PolicyDefaults.PolicySets = []string{"policyset-default"}
-			},
-			expectedPolicySetConfigInPolicy: [][]string{
-				{"policyset-default"},
-				{"policyset-default"},
-			},
-			expectedPolicySetConfigs: []types.PolicySetConfig{
-				{
-					Name: "policyset-default",
-					Policies: []string{
-						"policy-app-config",
-						"policy-app-config2",
-					},
-					PolicySetOptions: types.PolicySetOptions{
-						GeneratePolicySetPlacement: true,
-					},
-				},
-			},
-		},
-		{
-			name: "Use p.Policies[0].PolicySets to override with a different policy set",
-			setupFunc: func(p *Plugin) {
-				// p.PolicyDefaults.PolicySets should be overridden by p.Policies[0].PolicySets
-				p.PolicyDefaults.PolicySets = []string{"policyset-default"}
-				p.Policies[0] = types.PolicyConfig{
-					Name: "policy-app-config",
-					Manifests: []types.Manifest{
-						{
-							Path: path.Join(tmpDir, "configmap.yaml"),
-						},
-					},
-					PolicyOptions: types.PolicyOptions{
-						PolicySets: []string{"policyset0"},
-					},
-				}
-			},
-			expectedPolicySetConfigInPolicy: [][]string{
-				{"policyset0"},
-				{"policyset-default"},
-			},
-			expectedPolicySetConfigs: []types.PolicySetConfig{
-				{
-					Name: "policyset0",
-					Policies: []string{
-						"policy-app-config",
-					},
-					PolicySetOptions: types.PolicySetOptions{
-						GeneratePolicySetPlacement: true,
-					},
-				},
-				{
-					Name: "policyset-default",
-					Policies: []string{
-						"policy-app-config2",
-					},
-					PolicySetOptions: types.PolicySetOptions{
-						GeneratePolicySetPlacement: true,
-					},
-				},
-			},
-		},
-		{
-			name: "Use p.Policies[0].PolicySets to override with an empty policyset",
-			setupFunc: func(p *Plugin) {
-				// p.PolicyDefaults.PolicySets should be overridden by p.Policies[0].PolicySets
-				p.PolicyDefaults.PolicySets = []string{"policyset-default"}
-				p.Policies[0] = types.PolicyConfig{
-					Name: "policy-app-config",
-					Manifests: []types.Manifest{
-						{
-							Path: path.Join(tmpDir, "configmap.yaml"),
-						},
-					},
-					PolicyOptions: types.PolicyOptions{
-						PolicySets: []string{},
-					},
-				}
-			},
-			expectedPolicySetConfigInPolicy: [][]string{
-				{},
-				{"policyset-default"},
-			},
-			expectedPolicySetConfigs: []types.PolicySetConfig{
-				{
-					Name: "policyset-default",
-					Policies: []string{
-						"policy-app-config2",
-					},
-					PolicySetOptions: types.PolicySetOptions{
-						GeneratePolicySetPlacement: true,
-					},
-				},
-			},
-		},
-		{
-			name: "Use p.Policies[0].PolicySets and p.PolicySets, should merge",
-			setupFunc: func(p *Plugin) {
-				// p.Policies[0].PolicySets and p.PolicySets should merge
-				p.PolicySets = []types.PolicySetConfig{
-					{
-						Name:        "policyset-default",
-						Description: "This is a default policyset.",
-						Policies: []string{
-							"policy-app-config",
-							"policy-app-config2",
-							"pre-exists-policy",
-						},
-					},
-				}
-			},
-			expectedPolicySetConfigInPolicy: [][]string{
-				{"policyset-default"},
-				{"policyset-default"},
-			},
-			expectedPolicySetConfigs: []types.PolicySetConfig{
-				{
-					Name:        "policyset-default",
-					Description: "This is a default policyset.",
-					Policies: []string{
-						"policy-app-config",
-						"policy-app-config2",
-						"pre-exists-policy",
-					},
-					PolicySetOptions: types.PolicySetOptions{
-						GeneratePolicySetPlacement: true,
-					},
-				},
-			},
-		},
-	}
-	for _, tc := range testCases {
-		tc := tc // capture range variable
-		t.Run(tc.name, func(t *testing.T) {
-			t.Parallel()
-			p := Plugin{}
-			var err error
-			p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
-			if err != nil {
-				t.Fatal(err.Error())
-			}
-			p.PlacementBindingDefaults.Name = "my-placement-binding"
-			p.PolicyDefaults.Placement.Name = "my-placement-rule"
-			p.PolicyDefaults.Namespace = "my-policies"
-			policyConf := types.PolicyConfig{
-				Name: "policy-app-config",
-				Manifests: []types.Manifest{
-					{
-						Path: path.Join(tmpDir, "configmap.yaml"),
-					},
-				},
-			}
-			policyConf2 := types.PolicyConfig{
-				Name: "policy-app-config2",
-				Manifests: []types.Manifest{
-					{Path: path.Join(tmpDir, "configmap.yaml")},
-				},
-			}
-			p.Policies = append(p.Policies, policyConf, policyConf2)
-			tc.setupFunc(&p)
-			p.applyDefaults(map[string]interface{}{})
-			assertReflectEqual(t, p.Policies[0].PolicySets, tc.expectedPolicySetConfigInPolicy[0])
-			assertReflectEqual(t, p.Policies[1].PolicySets, tc.expectedPolicySetConfigInPolicy[1])
-			assertReflectEqual(t, p.PolicySets, tc.expectedPolicySetConfigs)
-		})
-	}
-}
-
-func TestGeneratePolicySetsWithPlacement(t *testing.T) {
-	t.Parallel()
-	tmpDir := t.TempDir()
-	createConfigMap(t, tmpDir, "configmap.yaml")
-
-	p := Plugin{}
-	var err error
+	plcYAML := `
+---
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
+metadata:
+    name: my-plc
+    namespace: my-policies
+spec:
+    predicates:
+        - requiredClusterSelector:
+            labelSelector:
+                matchLabels:
+                    game: pacman
+`
+	plcYAML = strings.TrimPrefix(plcYAML, "\n")
+	p, _ := plPathHelper(t, plcYAML, false)
+	p.Policies[0].Placement.SpecOverrides = map[string]interface{}{"clusterSets": []interface{}{"prod"}}
 
-	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	name, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	p.PlacementBindingDefaults.Name = "my-placement-binding"
-	p.PolicyDefaults.Placement.Name = "my-placement"
-	p.PolicyDefaults.Namespace = "my-policies"
-
-	policyConf := types.PolicyConfig{
-		Name: "policy-app-config",
-		Manifests: []types.Manifest{
-			{
-				Path: path.Join(tmpDir, "configmap.yaml"),
-			},
-		},
-		PolicyOptions: types.PolicyOptions{
-			PolicySets: []string{"policyset"},
-		},
-	}
-	p.Policies = append(p.Policies, policyConf)
+	assertEqual(t, name, "my-plc")
 
-	p.applyDefaults(map[string]interface{}{})
+	// The overrides don't apply to an existing placement
+	output := p.outputBuffer.String()
+	assertEqual(t, output, plcYAML)
+}
 
-	if err := p.assertValidConfig(); err != nil {
-		t.Fatal(err.Error())
-	}
+func TestCreatePlacementPlPathRequireSelector(t *testing.T) {
+	t.Parallel()
 
-	expected := `
----
-apiVersion: policy.open-cluster-management.io/v1
-kind: Policy
+	tests := map[string]struct {
+		plYAML      string
+		usingPlR    bool
+		expectedErr bool
+	}{
+		"empty predicates": {
+			plYAML: `
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
 metadata:
-    annotations:
-        policy.open-cluster-management.io/categories: CM Configuration Management
-        policy.open-cluster-management.io/controls: CM-2 Baseline Configuration
-        policy.open-cluster-management.io/description: ""
-        policy.open-cluster-management.io/standards: NIST SP 800-53
-    name: policy-app-config
+    name: my-plc
     namespace: my-policies
 spec:
-    disabled: false
-    policy-templates:
-        - objectDefinition:
-            apiVersion: policy.open-cluster-management.io/v1
-            kind: ConfigurationPolicy
-            metadata:
-                name: policy-app-config
-            spec:
-                object-templates:
-                    - complianceType: musthave
-                      objectDefinition:
-                        apiVersion: v1
-                        data:
-                            game.properties: enemies=potato
-                        kind: ConfigMap
-                        metadata:
-                            name: my-configmap
-                remediationAction: inform
-                severity: low
-    remediationAction: inform
----
-apiVersion: policy.open-cluster-management.io/v1beta1
-kind: PolicySet
+    predicates: []
+`,
+			expectedErr: true,
+		},
+		"no spec": {
+			plYAML: `
+apiVersion: apps.open-cluster-management.io/v1
+kind: PlacementRule
 metadata:
-    name: policyset
+    name: my-plr
     namespace: my-policies
-spec:
-    description: ""
-    policies:
-        - policy-app-config
----
+`,
+			usingPlR:    true,
+			expectedErr: true,
+		},
+		"explicitly empty label selector": {
+			plYAML: `
 apiVersion: cluster.open-cluster-management.io/v1beta1
 kind: Placement
 metadata:
-    name: my-placement
+    name: my-plc
     namespace: my-policies
 spec:
     predicates:
         - requiredClusterSelector:
-            labelSelector:
-                matchExpressions: []
-    tolerations:
-        - key: cluster.open-cluster-management.io/unavailable
-          operator: Exists
-        - key: cluster.open-cluster-management.io/unreachable
-          operator: Exists
----
-apiVersion: policy.open-cluster-management.io/v1
-kind: PlacementBinding
+            labelSelector: {}
+`,
+		},
+		"CEL expressions": {
+			plYAML: `
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
 metadata:
-    name: my-placement-binding
+    name: my-plc
     namespace: my-policies
-placementRef:
-    apiGroup: cluster.open-cluster-management.io
-    kind: Placement
-    name: my-placement
-subjects:
-    - apiGroup: policy.open-cluster-management.io
-      kind: PolicySet
-      name: policyset
-`
-	expected = strings.TrimPrefix(expected, "\n")
-
-	output, err := p.Generate()
-	if err != nil {
-		t.Fatal(err.Error())
+spec:
+    predicates:
+        - requiredClusterSelector:
+            celSelector:
+                celExpressions:
+                    - managedCluster.metadata.labels["env"] == "prod"
+`,
+		},
+		"cluster selector": {
+			plYAML: `
+apiVersion: apps.open-cluster-management.io/v1
+kind: PlacementRule
+metadata:
+    name: my-plr
+    namespace: my-policies
+spec:
+    clusterSelector:
+        matchLabels:
+            game: pacman
+`,
+			usingPlR: true,
+		},
 	}
 
-	assertEqual(t, string(output), expected)
-}
+	for name, test := range tests {
+		test := test
 
-func TestGeneratePolicySetsOverridePlacement(t *testing.T) {
-	t.Parallel()
-	tmpDir := t.TempDir()
-	createConfigMap(t, tmpDir, "configmap.yaml")
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
 
-	p := Plugin{}
-	var err error
+			p, plPath := plPathHelper(t, test.plYAML, test.usingPlR)
+			p.Policies[0].Placement.RequireSelector = true
 
-	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
-	if err != nil {
-		t.Fatal(err.Error())
-	}
+			_, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
+			if !test.expectedErr {
+				if err != nil {
+					t.Fatal(err.Error())
+				}
 
-	p.PlacementBindingDefaults.Name = "my-placement-binding"
-	p.PolicyDefaults.Placement.Name = "my-placement"
-	p.PolicyDefaults.Namespace = "my-policies"
-	p.PolicySetDefaults.Placement.Name = "other-placement"
+				return
+			}
 
-	policyConf := types.PolicyConfig{
-		Name: "policy-app-config",
-		Manifests: []types.Manifest{
-			{
-				Path: path.Join(tmpDir, "configmap.yaml"),
-			},
-		},
-		PolicyOptions: types.PolicyOptions{
-			PolicySets: []string{"policyset-overrides"},
-		},
-	}
-	p.Policies = append(p.Policies, policyConf)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
 
-	policySetConf := types.PolicySetConfig{
-		Name: "policyset-overrides",
-		PolicySetOptions: types.PolicySetOptions{
-			Placement: types.PlacementConfig{
-				LabelSelector: map[string]interface{}{
-					"my-label": "my-cluster",
-				},
-			},
-		},
+			expected := fmt.Sprintf(
+				"the placement %s doesn't have a cluster selector, so it selects all clusters; add a cluster "+
+					"selector or set an explicitly empty one to select all clusters",
+				plPath,
+			)
+			assertEqual(t, err.Error(), expected)
+		})
 	}
-	p.PolicySets = append(p.PolicySets, policySetConf)
 
-	p.applyDefaults(map[string]interface{}{})
+	// Without requireSelector, a placement without a cluster selector is allowed
+	p, _ := plPathHelper(t, tests["empty predicates"].plYAML, false)
 
-	if err := p.assertValidConfig(); err != nil {
+	name, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
+	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	expected := `
----
-apiVersion: policy.open-cluster-management.io/v1
-kind: Policy
-metadata:
-    annotations:
-        policy.open-cluster-management.io/categories: CM Configuration Management
-        policy.open-cluster-management.io/controls: CM-2 Baseline Configuration
-        policy.open-cluster-management.io/description: ""
-        policy.open-cluster-management.io/standards: NIST SP 800-53
-    name: policy-app-config
-    namespace: my-policies
-spec:
-    disabled: false
-    policy-templates:
-        - objectDefinition:
-            apiVersion: policy.open-cluster-management.io/v1
-            kind: ConfigurationPolicy
-            metadata:
-                name: policy-app-config
-            spec:
-                object-templates:
-                    - complianceType: musthave
-                      objectDefinition:
-                        apiVersion: v1
-                        data:
-                            game.properties: enemies=potato
-                        kind: ConfigMap
-                        metadata:
-                            name: my-configmap
-                remediationAction: inform
-                severity: low
-    remediationAction: inform
+	assertEqual(t, name, "my-plc")
+}
+
+func TestCreatePlacementPlrPathSkip(t *testing.T) {
+	t.Parallel()
+
+	plrYAML := `
 ---
-apiVersion: policy.open-cluster-management.io/v1beta1
-kind: PolicySet
+apiVersion: apps.open-cluster-management.io/v1
+kind: PlacementRule
 metadata:
-    name: policyset-overrides
+    name: my-plr
     namespace: my-policies
-spec:
-    description: ""
-    policies:
-        - policy-app-config
+`
+	plrYAML = strings.TrimPrefix(plrYAML, "\n")
+	p, _ := plPathHelper(t, plrYAML, true)
+
+	p.processedPlcs = map[string]bool{"my-plr": true}
+
+	name, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, name, "my-plr")
+	assertEqual(t, p.outputBuffer.String(), "")
+}
+
+func TestCreatePlacementPlrPathNoName(t *testing.T) {
+	t.Parallel()
+
+	plrYAML := `
 ---
-apiVersion: cluster.open-cluster-management.io/v1beta1
-kind: Placement
+apiVersion: apps.open-cluster-management.io/v1
+kind: PlacementRule
 metadata:
-    name: other-placement
     namespace: my-policies
 spec:
     predicates:
         - requiredClusterSelector:
             labelSelector:
-                matchExpressions:
-                    - key: my-label
-                      operator: In
-                      values:
-                        - my-cluster
+                matchExpressions: []
     tolerations:
         - key: cluster.open-cluster-management.io/unavailable
           operator: Exists
         - key: cluster.open-cluster-management.io/unreachable
           operator: Exists
+`
+	p, plrPath := plPathHelper(t, plrYAML, true)
+
+	_, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := fmt.Sprintf("the placement %s must have a name set", plrPath)
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestCreatePlacementPlrPathNoNamespace(t *testing.T) {
+	t.Parallel()
+
+	plrYAML := `
 ---
-apiVersion: policy.open-cluster-management.io/v1
-kind: PlacementBinding
+apiVersion: apps.open-cluster-management.io/v1
+kind: PlacementRule
 metadata:
-    name: my-placement-binding
-    namespace: my-policies
-placementRef:
-    apiGroup: cluster.open-cluster-management.io
-    kind: Placement
-    name: other-placement
-subjects:
-    - apiGroup: policy.open-cluster-management.io
-      kind: PolicySet
-      name: policyset-overrides
+    name: my-plr
+spec:
+    clusterSelector:
+        matchExpressions: []
 `
-	expected = strings.TrimPrefix(expected, "\n")
+	p, plrPath := plPathHelper(t, plrYAML, true)
 
-	output, err := p.Generate()
-	if err != nil {
-		t.Fatal(err.Error())
+	_, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
 	}
 
-	assertEqual(t, string(output), expected)
+	expected := fmt.Sprintf("the placement %s must have a namespace set", plrPath)
+	assertEqual(t, err.Error(), expected)
 }
 
-func TestGeneratePolicySetsWithoutPlacement(t *testing.T) {
+func TestCreatePlacementPlrPathWrongNamespace(t *testing.T) {
 	t.Parallel()
-	tmpDir := t.TempDir()
-	createConfigMap(t, tmpDir, "configmap.yaml")
 
-	p := Plugin{}
-	var err error
+	plrYAML := `
+---
+apiVersion: apps.open-cluster-management.io/v1
+kind: PlacementRule
+metadata:
+    name: my-plr
+    namespace: wrong-namespace
+spec:
+    clusterSelector:
+        matchExpressions: []
+`
+	p, plrPath := plPathHelper(t, plrYAML, true)
 
-	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
-	if err != nil {
-		t.Fatal(err.Error())
+	_, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
 	}
 
-	p.PlacementBindingDefaults.Name = "my-placement-binding"
-	p.PolicyDefaults.Placement.Name = "my-placement-rule"
-	p.PolicyDefaults.Namespace = "my-policies"
+	expected := fmt.Sprintf(
+		"the placement %s must have the same namespace as the policy (%s)",
+		plrPath,
+		p.PolicyDefaults.Namespace,
+	)
+	assertEqual(t, err.Error(), expected)
+}
 
-	policyConf := types.PolicyConfig{
-		Name: "policy-app-config",
-		Manifests: []types.Manifest{
-			{
-				Path: path.Join(tmpDir, "configmap.yaml"),
-			},
-		},
-		PolicyOptions: types.PolicyOptions{
-			PolicySets: []string{"policyset"},
-		},
-	}
-	p.Policies = append(p.Policies, policyConf)
+func TestCreatePlacementPlrPathNoPlr(t *testing.T) {
+	t.Parallel()
 
-	p.applyDefaults(map[string]interface{}{
-		"policySetDefaults": map[string]interface{}{
-			"generatePolicySetPlacement": false,
-		},
-	})
+	plrYAML := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-configmap2
+  namespace: my-policies
+data:
+  game.properties: |
+    enemies=potato
+`
+	p, plrPath := plPathHelper(t, plrYAML, true)
 
-	if err := p.assertValidConfig(); err != nil {
-		t.Fatal(err.Error())
+	_, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
 	}
 
-	expected := `
----
-apiVersion: policy.open-cluster-management.io/v1
-kind: Policy
-metadata:
-    annotations:
-        policy.open-cluster-management.io/categories: CM Configuration Management
-        policy.open-cluster-management.io/controls: CM-2 Baseline Configuration
-        policy.open-cluster-management.io/description: ""
-        policy.open-cluster-management.io/standards: NIST SP 800-53
-    name: policy-app-config
-    namespace: my-policies
-spec:
-    disabled: false
-    policy-templates:
-        - objectDefinition:
-            apiVersion: policy.open-cluster-management.io/v1
-            kind: ConfigurationPolicy
-            metadata:
-                name: policy-app-config
-            spec:
-                object-templates:
-                    - complianceType: musthave
-                      objectDefinition:
-                        apiVersion: v1
-                        data:
-                            game.properties: enemies=potato
-                        kind: ConfigMap
-                        metadata:
-                            name: my-configmap
-                remediationAction: inform
-                severity: low
-    remediationAction: inform
+	expected := fmt.Sprintf("the placement manifest %s did not have a placement", plrPath)
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestCreatePlacementPlPath(t *testing.T) {
+	t.Parallel()
+
+	plrYAML := `
 ---
-apiVersion: policy.open-cluster-management.io/v1beta1
-kind: PolicySet
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
 metadata:
-    name: policyset
+    name: my-plr
     namespace: my-policies
 spec:
-    description: ""
-    policies:
-        - policy-app-config
+    predicates:
+        - requiredClusterSelector:
+            labelSelector:
+                matchExpressions:
+                    - key: game
+                      operator: In
+                      values:
+                        - pacman
+    tolerations:
+        - key: cluster.open-cluster-management.io/unavailable
+          operator: Exists
+        - key: cluster.open-cluster-management.io/unreachable
+          operator: Exists
 `
-	expected = strings.TrimPrefix(expected, "\n")
+	plrYAML = strings.TrimPrefix(plrYAML, "\n")
+	p, _ := plPathHelper(t, plrYAML, false)
 
-	output, err := p.Generate()
+	name, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	assertEqual(t, string(output), expected)
+	assertEqual(t, name, "my-plr")
+
+	output := p.outputBuffer.String()
+
+	assertEqual(t, output, plrYAML)
 }
 
-func TestGeneratePolicySetsWithPolicyPlacement(t *testing.T) {
+func TestCreatePlacementPlPathSkip(t *testing.T) {
 	t.Parallel()
-	tmpDir := t.TempDir()
-	createConfigMap(t, tmpDir, "configmap.yaml")
 
-	p := Plugin{}
-	var err error
+	plrYAML := `
+---
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
+metadata:
+    name: my-plr
+    namespace: my-policies
+`
+	plrYAML = strings.TrimPrefix(plrYAML, "\n")
+	p, _ := plPathHelper(t, plrYAML, false)
 
-	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	p.processedPlcs = map[string]bool{"my-plr": true}
+
+	name, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	p.PlacementBindingDefaults.Name = "my-placement-binding"
-	p.PolicyDefaults.Placement.Name = "my-placement"
-	p.PolicyDefaults.Namespace = "my-policies"
+	assertEqual(t, name, "my-plr")
+	assertEqual(t, p.outputBuffer.String(), "")
+}
 
-	policyConf := types.PolicyConfig{
-		Name: "policy-app-config",
-		Manifests: []types.Manifest{
-			{
-				Path: path.Join(tmpDir, "configmap.yaml"),
-			},
-		},
-		PolicyOptions: types.PolicyOptions{
-			PolicySets: []string{"my-policyset"},
-		},
-	}
-	p.Policies = append(p.Policies, policyConf)
-	p.PolicySets = []types.PolicySetConfig{
-		{
-			Name: "my-policyset",
-			PolicySetOptions: types.PolicySetOptions{
-				Placement: types.PlacementConfig{
-					Name:             "policyset-placement",
-					ClusterSelectors: map[string]interface{}{"my": "app"},
-				},
-			},
-		},
-	}
+func TestCreatePlacementPlPathNoName(t *testing.T) {
+	t.Parallel()
 
-	p.applyDefaults(map[string]interface{}{})
+	plrYAML := `
+---
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
+metadata:
+    namespace: my-policies
+spec:
+    predicates:
+        - requiredClusterSelector:
+            labelSelector:
+                matchExpressions: []
+    tolerations:
+        - key: cluster.open-cluster-management.io/unavailable
+          operator: Exists
+        - key: cluster.open-cluster-management.io/unreachable
+          operator: Exists
+`
+	p, plrPath := plPathHelper(t, plrYAML, false)
 
-	if err := p.assertValidConfig(); err != nil {
-		t.Fatal(err.Error())
+	_, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
 	}
 
-	p.Policies[0].GeneratePlacementWhenInSet = true
+	expected := fmt.Sprintf("the placement %s must have a name set", plrPath)
+	assertEqual(t, err.Error(), expected)
+}
 
-	expected := `
+func TestCreatePlacementPlPathNoNamespace(t *testing.T) {
+	t.Parallel()
+
+	plrYAML := `
 ---
-apiVersion: policy.open-cluster-management.io/v1
-kind: Policy
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
 metadata:
-    annotations:
-        policy.open-cluster-management.io/categories: CM Configuration Management
-        policy.open-cluster-management.io/controls: CM-2 Baseline Configuration
-        policy.open-cluster-management.io/description: ""
-        policy.open-cluster-management.io/standards: NIST SP 800-53
-    name: policy-app-config
-    namespace: my-policies
+    name: my-plr
 spec:
-    disabled: false
-    policy-templates:
-        - objectDefinition:
-            apiVersion: policy.open-cluster-management.io/v1
-            kind: ConfigurationPolicy
-            metadata:
-                name: policy-app-config
-            spec:
-                object-templates:
-                    - complianceType: musthave
-                      objectDefinition:
-                        apiVersion: v1
-                        data:
-                            game.properties: enemies=potato
-                        kind: ConfigMap
-                        metadata:
-                            name: my-configmap
-                remediationAction: inform
-                severity: low
-    remediationAction: inform
+    predicates:
+        - requiredClusterSelector:
+            labelSelector:
+                matchExpressions: []
+    tolerations:
+        - key: cluster.open-cluster-management.io/unavailable
+          operator: Exists
+        - key: cluster.open-cluster-management.io/unreachable
+          operator: Exists
+`
+	p, plrPath := plPathHelper(t, plrYAML, false)
+
+	_, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := fmt.Sprintf("the placement %s must have a namespace set", plrPath)
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestCreatePlacementPlPathWrongNamespace(t *testing.T) {
+	t.Parallel()
+
+	plrYAML := `
 ---
-apiVersion: policy.open-cluster-management.io/v1beta1
-kind: PolicySet
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
 metadata:
-    name: my-policyset
-    namespace: my-policies
-spec:
-    description: ""
-    policies:
-        - policy-app-config
----
-apiVersion: apps.open-cluster-management.io/v1
-kind: PlacementRule
-metadata:
-    name: my-placement
-    namespace: my-policies
+    name: my-plr
+    namespace: wrong-namespace
 spec:
-    clusterSelector:
-        matchExpressions: []
+    predicates:
+        - requiredClusterSelector:
+            labelSelector:
+                matchExpressions: []
+    tolerations:
+        - key: cluster.open-cluster-management.io/unavailable
+          operator: Exists
+        - key: cluster.open-cluster-management.io/unreachable
+          operator: Exists
+`
+	p, plrPath := plPathHelper(t, plrYAML, false)
+
+	_, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := fmt.Sprintf(
+		"the placement %s must have the same namespace as the policy (%s)",
+		plrPath,
+		p.PolicyDefaults.Namespace,
+	)
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestCreatePlacementPlPathFoundPlR(t *testing.T) {
+	t.Parallel()
+
+	plrYAML := `
 ---
 apiVersion: apps.open-cluster-management.io/v1
 kind: PlacementRule
 metadata:
-    name: policyset-placement
-    namespace: my-policies
-spec:
-    clusterSelector:
-        matchExpressions:
-            - key: my
-              operator: In
-              values:
-                - app
----
-apiVersion: policy.open-cluster-management.io/v1
-kind: PlacementBinding
-metadata:
-    name: binding-policy-app-config
+    name: my-plr
     namespace: my-policies
-placementRef:
-    apiGroup: apps.open-cluster-management.io
-    kind: PlacementRule
-    name: my-placement
-subjects:
-    - apiGroup: policy.open-cluster-management.io
-      kind: Policy
-      name: policy-app-config
+`
+	p, plrPath := plPathHelper(t, plrYAML, false)
+
+	_, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := fmt.Sprintf(
+		"the placement %s specified a placementRule kind but expected a placement kind",
+		plrPath,
+	)
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestCreatePlacementPlrPathFoundPl(t *testing.T) {
+	t.Parallel()
+
+	plrYAML := `
 ---
-apiVersion: policy.open-cluster-management.io/v1
-kind: PlacementBinding
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
 metadata:
-    name: my-placement-binding
+    name: my-plr
     namespace: my-policies
-placementRef:
-    apiGroup: apps.open-cluster-management.io
-    kind: PlacementRule
-    name: policyset-placement
-subjects:
-    - apiGroup: policy.open-cluster-management.io
-      kind: PolicySet
-      name: my-policyset
 `
-	expected = strings.TrimPrefix(expected, "\n")
+	p, plrPath := plPathHelper(t, plrYAML, true)
 
-	output, err := p.Generate()
-	if err != nil {
-		t.Fatal(err.Error())
+	_, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
 	}
 
-	assertEqual(t, string(output), expected)
+	expected := fmt.Sprintf(
+		"the placement %s specified a placement kind but expected a placementRule kind",
+		plrPath,
+	)
+	assertEqual(t, err.Error(), expected)
 }
 
-func TestCreatePolicySet(t *testing.T) {
+func TestCreatePlacementPlPathNoPl(t *testing.T) {
 	t.Parallel()
-	tmpDir := t.TempDir()
-	createConfigMap(t, tmpDir, "configmap.yaml")
 
-	p := Plugin{}
-	var err error
+	plrYAML := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-configmap2
+  namespace: my-policies
+data:
+  game.properties: |
+    enemies=potato
+`
+	p, plrPath := plPathHelper(t, plrYAML, false)
 
-	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
-	if err != nil {
-		t.Fatal(err.Error())
+	_, err := p.createPolicyPlacement(p.Policies[0].Placement, p.Policies[0].Name)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
 	}
 
-	p.PlacementBindingDefaults.Name = "my-placement-binding"
-	p.PolicyDefaults.Placement.Name = "my-placement-rule"
+	expected := fmt.Sprintf("the placement manifest %s did not have a placement", plrPath)
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestCreatePlacementBinding(t *testing.T) {
+	t.Parallel()
+
+	p := Plugin{}
 	p.PolicyDefaults.Namespace = "my-policies"
-	patch := map[string]interface{}{
-		"metadata": map[string]interface{}{
-			"labels": map[string]string{
-				"chandler": "bing",
-			},
-		},
-	}
-	policyConf := types.PolicyConfig{
-		Name: "policy-app-config",
-		Manifests: []types.Manifest{
-			{
-				Path:    path.Join(tmpDir, "configmap.yaml"),
-				Patches: []map[string]interface{}{patch},
-			},
-		},
-	}
-	policyConf2 := types.PolicyConfig{
-		Name: "policy-app-config2",
-		Manifests: []types.Manifest{
-			{Path: path.Join(tmpDir, "configmap.yaml")},
-		},
-	}
-	p.Policies = append(p.Policies, policyConf, policyConf2)
+	policyConf := types.PolicyConfig{Name: "policy-app-config"}
+	p.Policies = append(p.Policies, policyConf)
+	policyConf2 := types.PolicyConfig{Name: "policy-app-config2"}
+	p.Policies = append(p.Policies, policyConf2)
 
-	p.PolicyDefaults.PolicySets = []string{"policyset-default"}
-	p.PolicySets = []types.PolicySetConfig{
+	bindingName := "my-placement-binding"
+	plrName := "my-placement-rule"
+	policyConfs := []*types.PolicyConfig{}
+	policyConfs = append(policyConfs, &p.Policies[0], &p.Policies[1])
+
+	policySetConfs := []*types.PolicySetConfig{
 		{
-			Name:        "policyset-default",
-			Description: "This is a default policyset.",
-			Policies: []string{
-				"policy-app-config",
-				"policy-app-config2",
-				"pre-exists-policy",
-			},
+			Name: "my-policyset",
 		},
 	}
-	p.applyDefaults(map[string]interface{}{})
 
-	err = p.createPolicySet(&p.PolicySets[0])
+	err := p.createPlacementBinding(bindingName, plrName, policyConfs, policySetConfs)
 	if err != nil {
-		t.Fatal(err.Error())
+		t.Fatal(err)
 	}
 
-	output := p.outputBuffer.String()
 	expected := `
 ---
-apiVersion: policy.open-cluster-management.io/v1beta1
-kind: PolicySet
+apiVersion: policy.open-cluster-management.io/v1
+kind: PlacementBinding
 metadata:
-    name: policyset-default
+    name: my-placement-binding
     namespace: my-policies
-spec:
-    description: This is a default policyset.
-    policies:
-        - policy-app-config
-        - policy-app-config2
-        - pre-exists-policy
+placementRef:
+    apiGroup: cluster.open-cluster-management.io
+    kind: Placement
+    name: my-placement-rule
+subjects:
+    - apiGroup: policy.open-cluster-management.io
+      kind: Policy
+      name: policy-app-config
+    - apiGroup: policy.open-cluster-management.io
+      kind: Policy
+      name: policy-app-config2
+    - apiGroup: policy.open-cluster-management.io
+      kind: PolicySet
+      name: my-policyset
 `
 	expected = strings.TrimPrefix(expected, "\n")
-	assertEqual(t, output, expected)
-}
-
-func getYAMLEvaluationInterval(
-	t *testing.T, policyTemplate interface{}, skipFinalValidation bool,
-) map[string]interface{} {
-	t.Helper()
-
-	plcTemplate, ok := policyTemplate.(map[string]interface{})
-	assertEqual(t, ok, true)
-
-	configPolicy, ok := plcTemplate["objectDefinition"].(map[string]interface{})
-	assertEqual(t, ok, true)
-
-	configPolicyOptions, ok := configPolicy["spec"].(map[string]interface{})
-	assertEqual(t, ok, true)
-
-	evaluationInterval, ok := configPolicyOptions["evaluationInterval"].(map[string]interface{})
-
-	if !skipFinalValidation {
-		assertEqual(t, ok, true)
-	}
-
-	return evaluationInterval
+	assertEqual(t, p.outputBuffer.String(), expected)
 }
 
-func TestGenerateEvaluationInterval(t *testing.T) {
+func TestGeneratePolicySets(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
 	createConfigMap(t, tmpDir, "configmap.yaml")
-	createObjectTemplatesRawManifest(t, tmpDir, "object-templates-raw.yaml")
-
-	p := Plugin{}
-	var err error
 
-	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
-	if err != nil {
-		t.Fatal(err.Error())
-	}
-
-	p.PolicyDefaults.Namespace = "my-policies"
-	p.PolicyDefaults.EvaluationInterval = types.EvaluationInterval{
-		Compliant:    "never",
-		NonCompliant: "15s",
-	}
-
-	// Test that the policy evaluation interval gets inherited when not set on a manifest.
-	policyConf := types.PolicyConfig{
-		PolicyOptions: types.PolicyOptions{
-			ConsolidateManifests: false,
+	testCases := []testCase{
+		{
+			name: "Use p.PolicyDefaults.PolicySets only",
+			setupFunc: func(p *Plugin) {
+				// PolicyDefaults.PolicySets should be applied to both policies
+				p.PolicyDefaults.PolicySets = []string{"policyset-default"}
+			},
+			expectedPolicySetConfigInPolicy: [][]string{
+				{"policyset-default"},
+				{"policyset-default"},
+			},
+			expectedPolicySetConfigs: []types.PolicySetConfig{
+				{
+					Name: "policyset-default",
+					Policies: []string{
+						"policy-app-config",
+						"policy-app-config2",
+					},
+					PolicySetOptions: types.PolicySetOptions{
+						GeneratePolicySetPlacement: true,
+					},
+				},
+			},
 		},
-		ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
-			EvaluationInterval: types.EvaluationInterval{
-				Compliant:    "30m",
-				NonCompliant: "30s",
+		{
+			name: "Use p.Policies[0].PolicySets to override with a different policy set",
+			setupFunc: func(p *Plugin) {
+				// p.PolicyDefaults.PolicySets should be overridden by p.Policies[0].PolicySets
+				p.PolicyDefaults.PolicySets = []string{"policyset-default"}
+				p.Policies[0] = types.PolicyConfig{
+					Name: "policy-app-config",
+					Manifests: []types.Manifest{
+						{
+							Path: path.Join(tmpDir, "configmap.yaml"),
+						},
+					},
+					PolicyOptions: types.PolicyOptions{
+						PolicySets: []string{"policyset0"},
+					},
+				}
+			},
+			expectedPolicySetConfigInPolicy: [][]string{
+				{"policyset0"},
+				{"policyset-default"},
+			},
+			expectedPolicySetConfigs: []types.PolicySetConfig{
+				{
+					Name: "policyset0",
+					Policies: []string{
+						"policy-app-config",
+					},
+					PolicySetOptions: types.PolicySetOptions{
+						GeneratePolicySetPlacement: true,
+					},
+				},
+				{
+					Name: "policyset-default",
+					Policies: []string{
+						"policy-app-config2",
+					},
+					PolicySetOptions: types.PolicySetOptions{
+						GeneratePolicySetPlacement: true,
+					},
+				},
 			},
 		},
-		Name: "policy-app-config",
-		Manifests: []types.Manifest{
-			{Path: path.Join(tmpDir, "configmap.yaml")},
-			{
-				ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
-					EvaluationInterval: types.EvaluationInterval{
-						Compliant:    "25m",
-						NonCompliant: "5m",
+		{
+			name: "Use p.Policies[0].PolicySets to override with an empty policyset",
+			setupFunc: func(p *Plugin) {
+				// p.PolicyDefaults.PolicySets should be overridden by p.Policies[0].PolicySets
+				p.PolicyDefaults.PolicySets = []string{"policyset-default"}
+				p.Policies[0] = types.PolicyConfig{
+					Name: "policy-app-config",
+					Manifests: []types.Manifest{
+						{
+							Path: path.Join(tmpDir, "configmap.yaml"),
+						},
+					},
+					PolicyOptions: types.PolicyOptions{
+						PolicySets: []string{},
+					},
+				}
+			},
+			expectedPolicySetConfigInPolicy: [][]string{
+				{},
+				{"policyset-default"},
+			},
+			expectedPolicySetConfigs: []types.PolicySetConfig{
+				{
+					Name: "policyset-default",
+					Policies: []string{
+						"policy-app-config2",
+					},
+					PolicySetOptions: types.PolicySetOptions{
+						GeneratePolicySetPlacement: true,
 					},
 				},
-				Path: path.Join(tmpDir, "configmap.yaml"),
 			},
-			// Test that it does not get an inherited value when it is explicitly set to empty in the YAML below.
-			{
-				Path: path.Join(tmpDir, "configmap.yaml"),
+		},
+		{
+			name: "Use p.Policies[0].PolicySets and p.PolicySets, should merge",
+			setupFunc: func(p *Plugin) {
+				// p.Policies[0].PolicySets and p.PolicySets should merge
+				p.PolicySets = []types.PolicySetConfig{
+					{
+						Name:        "policyset-default",
+						Description: "This is a default policyset.",
+						Policies: []string{
+							"policy-app-config",
+							"policy-app-config2",
+							"pre-exists-policy",
+						},
+					},
+				}
+			},
+			expectedPolicySetConfigInPolicy: [][]string{
+				{"policyset-default"},
+				{"policyset-default"},
+			},
+			expectedPolicySetConfigs: []types.PolicySetConfig{
+				{
+					Name:        "policyset-default",
+					Description: "This is a default policyset.",
+					Policies: []string{
+						"policy-app-config",
+						"policy-app-config2",
+						"pre-exists-policy",
+					},
+					PolicySetOptions: types.PolicySetOptions{
+						GeneratePolicySetPlacement: true,
+					},
+				},
 			},
 		},
 	}
-	// Test that the policy defaults get inherited.
-	policyConf2 := types.PolicyConfig{
-		Name: "policy-app-config2",
-		Manifests: []types.Manifest{
-			{Path: path.Join(tmpDir, "configmap.yaml")},
-		},
+	for _, tc := range testCases {
+		tc := tc // capture range variable
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			p := Plugin{}
+			var err error
+			p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			p.PlacementBindingDefaults.Name = "my-placement-binding"
+			p.PolicyDefaults.Placement.Name = "my-placement-rule"
+			p.PolicyDefaults.Namespace = "my-policies"
+			policyConf := types.PolicyConfig{
+				Name: "policy-app-config",
+				Manifests: []types.Manifest{
+					{
+						Path: path.Join(tmpDir, "configmap.yaml"),
+					},
+				},
+			}
+			policyConf2 := types.PolicyConfig{
+				Name: "policy-app-config2",
+				Manifests: []types.Manifest{
+					{Path: path.Join(tmpDir, "configmap.yaml")},
+				},
+			}
+			p.Policies = append(p.Policies, policyConf, policyConf2)
+			tc.setupFunc(&p)
+			p.applyDefaults(map[string]interface{}{})
+			assertReflectEqual(t, p.Policies[0].PolicySets, tc.expectedPolicySetConfigInPolicy[0])
+			assertReflectEqual(t, p.Policies[1].PolicySets, tc.expectedPolicySetConfigInPolicy[1])
+			assertReflectEqual(t, p.PolicySets, tc.expectedPolicySetConfigs)
+		})
 	}
-	// Test that explicitly setting evaluationInterval to an empty value overrides the policy default.
-	policyConf3 := types.PolicyConfig{
-		ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
-			EvaluationInterval: types.EvaluationInterval{},
-		},
-		Name: "policy-app-config3",
+}
+
+func TestGeneratePolicySetBindingName(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	p := Plugin{}
+	var err error
+
+	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p.PolicyDefaults.Namespace = "my-policies"
+	policyConf := types.PolicyConfig{
+		Name: "policy-app-config",
 		Manifests: []types.Manifest{
 			{Path: path.Join(tmpDir, "configmap.yaml")},
 		},
-	}
-	// Test that the policy defaults get inherited with object-templates-raw.
-	policyConf4 := types.PolicyConfig{
-		Name: "policy-app-config4",
-		Manifests: []types.Manifest{
-			{Path: path.Join(tmpDir, "object-templates-raw.yaml")},
+		PolicyOptions: types.PolicyOptions{
+			PolicySets: []string{"my-policyset"},
 		},
 	}
-	p.Policies = append(p.Policies, policyConf, policyConf2, policyConf3, policyConf4)
-	p.applyDefaults(
-		map[string]interface{}{
-			"policies": []interface{}{
-				map[string]interface{}{
-					"consolidateManifests": false,
-					"manifests": []interface{}{
-						map[string]interface{}{},
-						map[string]interface{}{},
-						map[string]interface{}{
-							"evaluationInterval": map[string]interface{}{
-								"compliant":    "",
-								"noncompliant": "",
-							},
-						},
-					},
-				},
-				map[string]interface{}{},
-				map[string]interface{}{
-					"evaluationInterval": map[string]interface{}{
-						"compliant":    "",
-						"noncompliant": "",
-					},
-				},
-			},
-		},
-	)
+	p.Policies = append(p.Policies, policyConf)
+	p.applyDefaults(map[string]interface{}{})
 
 	if err := p.assertValidConfig(); err != nil {
 		t.Fatal(err.Error())
@@ -3857,1164 +4482,4416 @@ func TestGenerateEvaluationInterval(t *testing.T) {
 		t.Fatal(err.Error())
 	}
 
-	generatedManifests, err := unmarshalManifestBytes(output)
+	manifests, err := unmarshalManifestBytes(output)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	assertEqual(t, len(generatedManifests), 12)
+	bindingNames := []string{}
 
-	for _, manifest := range generatedManifests {
-		kind, _ := manifest["kind"].(string)
-		if kind != "Policy" {
+	for _, manifest := range manifests {
+		if manifest["kind"] != placementBindingKind {
 			continue
 		}
 
-		metadata, _ := manifest["metadata"].(map[string]interface{})
-
-		name, _ := metadata["name"].(string)
-
-		spec, _ := manifest["spec"].(map[string]interface{})
-		policyTemplates, _ := spec["policy-templates"].([]interface{})
-
-		if name == "policy-app-config" {
-			assertEqual(t, len(policyTemplates), 3)
-			evaluationInterval := getYAMLEvaluationInterval(t, policyTemplates[0], false)
-			assertEqual(t, evaluationInterval["compliant"], "30m")
-			assertEqual(t, evaluationInterval["noncompliant"], "30s")
-
-			evaluationInterval = getYAMLEvaluationInterval(t, policyTemplates[1], false)
-			assertEqual(t, evaluationInterval["compliant"], "25m")
-			assertEqual(t, evaluationInterval["noncompliant"], "5m")
+		name, _, _ := unstructured.NestedString(manifest, "metadata", "name")
+		bindingNames = append(bindingNames, name)
 
-			evaluationInterval = getYAMLEvaluationInterval(t, policyTemplates[2], true)
-			assertEqual(t, len(evaluationInterval), 0)
-		} else if name == "policy-app-config2" {
-			assertEqual(t, len(policyTemplates), 1)
-			evaluationInterval := getYAMLEvaluationInterval(t, policyTemplates[0], false)
-			assertEqual(t, evaluationInterval["compliant"], "never")
-			assertEqual(t, evaluationInterval["noncompliant"], "15s")
-		} else if name == "policy-app-config3" {
-			assertEqual(t, len(policyTemplates), 1)
-			evaluationInterval := getYAMLEvaluationInterval(t, policyTemplates[0], true)
-			assertEqual(t, len(evaluationInterval), 0)
-		} else if name == "policy-app-config4" {
-			assertEqual(t, len(policyTemplates), 1)
-			evaluationInterval := getYAMLEvaluationInterval(t, policyTemplates[0], false)
-			assertEqual(t, evaluationInterval["compliant"], "never")
-			assertEqual(t, evaluationInterval["noncompliant"], "15s")
-		}
+		subjects, _, _ := unstructured.NestedSlice(manifest, "subjects")
+		assertEqual(t, len(subjects), 1)
+		//nolint:forcetypeassert
+		assertEqual(t, subjects[0].(map[string]interface{})["kind"], policySetKind)
 	}
+
+	assertEqual(t, bindingNames, []string{"binding-my-policyset"})
 }
 
-func TestCreatePolicyWithConfigPolicyAnnotations(t *testing.T) {
+func TestGenerateBindingPlacementRef(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
 	createConfigMap(t, tmpDir, "configmap.yaml")
 
-	tests := []struct {
-		name        string
-		annotations map[string]string
-	}{
-		{name: "no-override", annotations: nil},
-		{
-			name: "override",
-			annotations: map[string]string{
-				"policy.open-cluster-management.io/disable-templates": "true",
-			},
-		},
-		{
-			name:        "override-empty",
-			annotations: map[string]string{},
-		},
+	p := Plugin{}
+	var err error
+
+	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
 	}
 
-	for _, test := range tests {
-		test := test
-		t.Run(test.name, func(t *testing.T) {
-			t.Parallel()
+	p.PolicyDefaults.Namespace = "my-policies"
+	p.PolicyDefaults.Placement.PlacementName = "my-placement"
+	p.PolicyDefaults.Placement.BindingPlacementRef = &types.PlacementRef{
+		APIGroup: "cluster.example.com",
+		Kind:     "CustomPlacement",
+		Name:     "my-placement",
+	}
+	policyConf := types.PolicyConfig{
+		Name: "policy-app-config",
+		Manifests: []types.Manifest{
+			{Path: path.Join(tmpDir, "configmap.yaml")},
+		},
+	}
+	p.Policies = append(p.Policies, policyConf)
+	p.applyDefaults(map[string]interface{}{})
 
-			p := Plugin{}
-			p.PolicyDefaults.Namespace = "my-policies"
-			p.PolicyDefaults.ConfigurationPolicyAnnotations = map[string]string{"test-default-annotation": "default"}
-			policyConf := types.PolicyConfig{
-				Name: "policy-app-config", Manifests: []types.Manifest{
-					{Path: path.Join(tmpDir, "configmap.yaml")},
-				},
-			}
+	if err := p.assertValidConfig(); err != nil {
+		t.Fatal(err.Error())
+	}
 
-			if test.annotations != nil {
-				policyConf.ConfigurationPolicyAnnotations = test.annotations
-			}
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
 
-			p.Policies = append(p.Policies, policyConf)
-			p.applyDefaults(map[string]interface{}{})
+	manifests, err := unmarshalManifestBytes(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
 
-			err := p.createPolicy(&p.Policies[0])
-			if err != nil {
-				t.Fatal(err.Error())
-			}
+	placementRefs := []map[string]interface{}{}
 
-			output := p.outputBuffer.Bytes()
-			policyManifests, err := unmarshalManifestBytes(output)
-			if err != nil {
-				t.Fatal(err.Error())
-			}
-			//nolint:forcetypeassert
-			spec := policyManifests[0]["spec"].(map[string]interface{})
-			policyTemplates := spec["policy-templates"].([]interface{})
-			//nolint:forcetypeassert
-			configPolicy := policyTemplates[0].(map[string]interface{})["objectDefinition"].(map[string]interface{})
-			//nolint:forcetypeassert
-			metadata := configPolicy["metadata"].(map[string]interface{})
+	for _, manifest := range manifests {
+		if manifest["kind"] != placementBindingKind {
+			continue
+		}
 
-			if test.annotations != nil && len(test.annotations) == 0 {
-				assertEqual(t, metadata["annotations"], nil)
-			} else {
-				annotations := map[string]string{}
-				for key, val := range metadata["annotations"].(map[string]interface{}) {
-					//nolint:forcetypeassert
-					annotations[key] = val.(string)
-				}
+		placementRef, _, _ := unstructured.NestedMap(manifest, "placementRef")
+		placementRefs = append(placementRefs, placementRef)
+	}
 
-				if test.annotations == nil {
-					assertReflectEqual(t, annotations, map[string]string{"test-default-annotation": "default"})
-				} else {
-					assertReflectEqual(t, annotations, test.annotations)
-				}
-			}
-		})
+	expected := []map[string]interface{}{
+		{"apiGroup": "cluster.example.com", "kind": "CustomPlacement", "name": "my-placement"},
 	}
+	assertReflectEqual(t, placementRefs, expected)
 }
 
-func TestCreatePolicyWithNamespaceSelector(t *testing.T) {
+func TestGeneratePlacementBindingNamespace(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
 	createConfigMap(t, tmpDir, "configmap.yaml")
 
-	tests := map[string]struct {
-		name              string
-		namespaceSelector types.NamespaceSelector
-	}{
-		"nil-selector": {namespaceSelector: types.NamespaceSelector{}},
-		"empty-selector-values": {
-			namespaceSelector: types.NamespaceSelector{
-				Include:          []string{},
-				Exclude:          []string{},
-				MatchLabels:      &map[string]string{},
-				MatchExpressions: &[]metav1.LabelSelectorRequirement{},
-			},
-		},
-		"completely-filled-values": {
-			namespaceSelector: types.NamespaceSelector{
-				Include: []string{"test-ns-1", "test-ns-2"},
-				Exclude: []string{"*-ns-[1]"},
-				MatchLabels: &map[string]string{
-					"testing": "is awesome",
-				},
-				MatchExpressions: &[]metav1.LabelSelectorRequirement{{
-					Key:      "door",
-					Operator: "Exists",
-				}},
-			},
-		},
-		"include-exclude-only": {
-			namespaceSelector: types.NamespaceSelector{
-				Include: []string{"test-ns-1", "test-ns-2"},
-				Exclude: []string{"*-ns-[1]"},
-			},
-		},
-		"label-selectors-only": {
-			namespaceSelector: types.NamespaceSelector{
-				MatchLabels: &map[string]string{
-					"testing": "is awesome",
-				},
-				MatchExpressions: &[]metav1.LabelSelectorRequirement{{
-					Key:      "door",
-					Operator: "Exists",
-				}},
-			},
-		},
+	p := Plugin{}
+	var err error
+
+	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
 	}
 
-	for name, test := range tests {
-		test := test
+	p.PlacementBindingDefaults.Namespace = "global-hub"
+	p.PolicyDefaults.Namespace = "my-policies"
+	policyConf := types.PolicyConfig{
+		Name: "policy-app-config",
+		Manifests: []types.Manifest{
+			{Path: path.Join(tmpDir, "configmap.yaml")},
+		},
+	}
+	p.Policies = append(p.Policies, policyConf)
+	p.applyDefaults(map[string]interface{}{})
 
-		t.Run(name, func(t *testing.T) {
-			t.Parallel()
+	if err := p.assertValidConfig(); err != nil {
+		t.Fatal(err.Error())
+	}
 
-			p := Plugin{}
-			p.PolicyDefaults.Namespace = "my-policies"
-			p.PolicyDefaults.NamespaceSelector = types.NamespaceSelector{
-				MatchLabels: &map[string]string{},
-			}
-			policyConf := types.PolicyConfig{
-				Name: "policy-app-config", Manifests: []types.Manifest{
-					{Path: path.Join(tmpDir, "configmap.yaml")},
-				},
-			}
-			policyConf.NamespaceSelector = test.namespaceSelector
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
 
-			p.Policies = append(p.Policies, policyConf)
-			p.applyDefaults(map[string]interface{}{})
+	manifests, err := unmarshalManifestBytes(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
 
-			err := p.createPolicy(&p.Policies[0])
-			if err != nil {
-				t.Fatal(err.Error())
-			}
+	namespaces := map[string]string{}
 
-			output := p.outputBuffer.Bytes()
-			policyManifests, err := unmarshalManifestBytes(output)
-			if err != nil {
-				t.Fatal(err.Error())
-			}
-			//nolint:forcetypeassert
-			spec := policyManifests[0]["spec"].(map[string]interface{})
-			policyTemplates := spec["policy-templates"].([]interface{})
-			//nolint:forcetypeassert
-			configPolicy := policyTemplates[0].(map[string]interface{})["objectDefinition"].(map[string]interface{})
-			//nolint:forcetypeassert
-			configPolicyOptions := configPolicy["spec"].(map[string]interface{})
-			//nolint:forcetypeassert
-			configPolicySelector := configPolicyOptions["namespaceSelector"].(map[string]interface{})
+	for _, manifest := range manifests {
+		kind, _, _ := unstructured.NestedString(manifest, "kind")
+		namespace, _, _ := unstructured.NestedString(manifest, "metadata", "namespace")
+		namespaces[kind] = namespace
+	}
 
-			if reflect.DeepEqual(test.namespaceSelector, types.NamespaceSelector{}) {
-				assertSelectorEqual(t, configPolicySelector, p.PolicyDefaults.NamespaceSelector)
-			} else {
-				assertSelectorEqual(t, configPolicySelector, test.namespaceSelector)
-			}
-		})
+	expected := map[string]string{
+		policyKind:           "my-policies",
+		placementKind:        "global-hub",
+		placementBindingKind: "global-hub",
 	}
+	assertReflectEqual(t, namespaces, expected)
 }
 
-func TestGenerateNonDNSPolicyName(t *testing.T) {
+func TestGenerateMaxSubjectsPerBinding(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
 	createConfigMap(t, tmpDir, "configmap.yaml")
 
-	tests := []struct {
-		name       string
-		policyName string
-	}{
-		{
-			name:       "capitalized",
-			policyName: "policy-APP-CONFIG",
-		},
-		{
-			name:       "invalid character",
-			policyName: "policy_app_config",
-		},
-	}
-
-	for _, test := range tests {
-		test := test
-		t.Run(test.name, func(t *testing.T) {
-			t.Parallel()
+	p := Plugin{}
+	var err error
 
-			p := Plugin{}
-			var err error
+	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
 
-			p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
-			if err != nil {
-				t.Fatal(err.Error())
-			}
+	p.PlacementBindingDefaults.Name = "my-placement-binding"
+	p.PlacementBindingDefaults.MaxSubjectsPerBinding = 2
+	p.PolicyDefaults.Placement.Name = "my-placement"
+	p.PolicyDefaults.Namespace = "my-policies"
 
-			p.PlacementBindingDefaults.Name = "my-placement-binding"
-			p.PolicyDefaults.Placement.Name = "my-placement-rule"
-			p.PolicyDefaults.Namespace = "my-policies"
-			policyConf := types.PolicyConfig{
-				Name: test.policyName,
-				Manifests: []types.Manifest{
-					{Path: path.Join(tmpDir, "configmap.yaml")},
-				},
-			}
-			basePolicies := p.Policies
-			p.Policies = append(basePolicies, policyConf)
-			p.applyDefaults(map[string]interface{}{})
+	for _, name := range []string{"policy-app-config", "policy-app-config2", "policy-app-config3"} {
+		p.Policies = append(p.Policies, types.PolicyConfig{
+			Name: name,
+			Manifests: []types.Manifest{
+				{Path: path.Join(tmpDir, "configmap.yaml")},
+			},
+		})
+	}
 
-			err = p.assertValidConfig()
-			if err == nil {
-				t.Fatal("Expected an error but did not get one")
-			}
+	p.applyDefaults(map[string]interface{}{})
 
-			expected := fmt.Sprintf(
-				"policy name `%s` is not DNS compliant. See "+
-					"https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#dns-subdomain-names",
-				test.policyName,
-			)
-			assertEqual(t, err.Error(), expected)
-		})
+	if err := p.assertValidConfig(); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	manifests, err := unmarshalManifestBytes(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	bindingSubjects := map[string][]string{}
+
+	for _, manifest := range manifests {
+		if manifest["kind"] != placementBindingKind {
+			continue
+		}
+
+		name, _, _ := unstructured.NestedString(manifest, "metadata", "name")
+		placementName, _, _ := unstructured.NestedString(manifest, "placementRef", "name")
+		assertEqual(t, placementName, "my-placement")
+
+		subjects, _, _ := unstructured.NestedSlice(manifest, "subjects")
+		for _, subject := range subjects {
+			//nolint:forcetypeassert
+			bindingSubjects[name] = append(bindingSubjects[name], subject.(map[string]interface{})["name"].(string))
+		}
+	}
+
+	expected := map[string][]string{
+		"my-placement-binding":  {"policy-app-config", "policy-app-config2"},
+		"my-placement-binding2": {"policy-app-config3"},
 	}
+	assertReflectEqual(t, bindingSubjects, expected)
 }
 
-func TestGenerateNonDNSPlacementName(t *testing.T) {
+func TestGenerateBindingOverridesPolicyLabel(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
 	createConfigMap(t, tmpDir, "configmap.yaml")
 
-	tests := []struct {
-		name          string
-		placementName string
-	}{
-		{
-			name:          "capitalized",
-			placementName: "my-placement-RULE",
-		},
+	p := Plugin{}
+	var err error
+
+	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p.PlacementBindingDefaults.Name = "my-placement-binding"
+	p.PlacementBindingDefaults.BindingOverrides = &types.BindingOverrides{
+		PolicyLabel:       "remediation=enforce",
+		RemediationAction: "enforce",
+		SubFilter:         "restricted",
+	}
+	p.PolicyDefaults.Placement.Name = "my-placement"
+	p.PolicyDefaults.Namespace = "my-policies"
+	p.Policies = []types.PolicyConfig{
 		{
-			name:          "invalid character",
-			placementName: "my-placement?rule",
+			Name:      "policy-app-config",
+			Manifests: []types.Manifest{{Path: path.Join(tmpDir, "configmap.yaml")}},
 		},
 		{
-			name: "too many characters",
-			placementName: "placementplacementplacementplacementplacementplacementplacementplacementplacement" +
-				"placementplacementplacementplacementplacementplacementplacementplacementplacementplacement" +
-				"placementplacementplacementplacementplacementplacementplacementplacementplacementrule",
+			Name:      "policy-app-config2",
+			Manifests: []types.Manifest{{Path: path.Join(tmpDir, "configmap.yaml")}},
+			PolicyOptions: types.PolicyOptions{
+				PolicyLabels: map[string]string{"remediation": "enforce"},
+			},
 		},
 	}
 
-	for _, test := range tests {
-		test := test
-		t.Run(test.name, func(t *testing.T) {
-			t.Parallel()
+	p.applyDefaults(map[string]interface{}{})
 
-			p := Plugin{}
-			var err error
+	if err := p.assertValidConfig(); err != nil {
+		t.Fatal(err.Error())
+	}
 
-			p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
-			if err != nil {
-				t.Fatal(err.Error())
-			}
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
 
-			p.PlacementBindingDefaults.Name = "my-placement-binding"
-			p.PolicyDefaults.Placement.Name = test.placementName
-			p.PolicyDefaults.Namespace = "my-policies"
-			policyConf := types.PolicyConfig{
-				Name: "policy-app-config",
-				Manifests: []types.Manifest{
-					{Path: path.Join(tmpDir, "configmap.yaml")},
-				},
-			}
-			p.Policies = append(p.Policies, policyConf)
-			p.applyDefaults(map[string]interface{}{})
+	manifests, err := unmarshalManifestBytes(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
 
-			err = p.assertValidConfig()
-			if err == nil {
-				t.Fatal("Expected an error but did not get one")
-			}
+	bindings := map[string]map[string]interface{}{}
 
-			expected := fmt.Sprintf(
-				"policyDefaults placement.name `%s` is not DNS compliant. See "+
-					"https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#dns-subdomain-names",
-				test.placementName,
-			)
-			assertEqual(t, err.Error(), expected)
-		})
+	for _, manifest := range manifests {
+		if manifest["kind"] != placementBindingKind {
+			continue
+		}
+
+		subjects, _, _ := unstructured.NestedSlice(manifest, "subjects")
+		assertEqual(t, len(subjects), 1)
+
+		name, _, _ := unstructured.NestedString(manifest, "metadata", "name")
+		//nolint:forcetypeassert
+		bindings[name+"/"+subjects[0].(map[string]interface{})["name"].(string)] = manifest
 	}
+
+	assertEqual(t, len(bindings), 3)
+
+	// The policy with the label is still propagated by a placement binding without the subFilter
+	labeledBinding := bindings["my-placement-binding/policy-app-config2"]
+	assertEqual(t, labeledBinding["bindingOverrides"], nil)
+	assertEqual(t, labeledBinding["subFilter"], nil)
+
+	// Only the additional restricted placement binding of the policy with the label gets the overrides
+	restrictedBinding := bindings["my-placement-binding-restricted/policy-app-config2"]
+	remediationAction, _, _ := unstructured.NestedString(restrictedBinding, "bindingOverrides", "remediationAction")
+	assertEqual(t, remediationAction, "enforce")
+	assertEqual(t, restrictedBinding["subFilter"], "restricted")
+
+	otherBinding := bindings["my-placement-binding2/policy-app-config"]
+	assertEqual(t, otherBinding["bindingOverrides"], nil)
+	assertEqual(t, otherBinding["subFilter"], nil)
 }
 
-func TestGenerateNonDNSBindingName(t *testing.T) {
+func TestGenerateBindingOverridesDerivedPolicyLabel(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
 	createConfigMap(t, tmpDir, "configmap.yaml")
+	manifestPath := path.Join(tmpDir, "enforced-configmap.yaml")
+	manifestYAML := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-configmap
+  labels:
+    remediation: enforce
+`
 
-	tests := []struct {
-		name        string
-		bindingName string
-	}{
-		{
-			name:        "capitalized",
-			bindingName: "my-placement-BINDING",
-		},
+	err := os.WriteFile(manifestPath, []byte(manifestYAML), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write %s", manifestPath)
+	}
+
+	p := Plugin{}
+
+	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p.PlacementBindingDefaults.Name = "my-placement-binding"
+	p.PlacementBindingDefaults.BindingOverrides = &types.BindingOverrides{
+		PolicyLabel:       "remediation=enforce",
+		RemediationAction: "enforce",
+		SubFilter:         "restricted",
+	}
+	p.PolicyDefaults.Placement.Name = "my-placement"
+	p.PolicyDefaults.Namespace = "my-policies"
+	p.PolicyDefaults.DeriveLabelsFrom = []string{"metadata.labels.remediation"}
+	p.Policies = []types.PolicyConfig{
 		{
-			name:        "invalid character",
-			bindingName: "my-placement?binding",
+			Name:      "policy-app-config",
+			Manifests: []types.Manifest{{Path: path.Join(tmpDir, "configmap.yaml")}},
 		},
 		{
-			name: "too many characters",
-			bindingName: "placementplacementplacementplacementplacementplacementplacementplacementplacement" +
-				"placementplacementplacementplacementplacementplacementplacementplacementplacementplacement" +
-				"placementplacementplacementplacementplacementplacementplacementplacementplacementbinding",
+			Name:      "policy-app-config2",
+			Manifests: []types.Manifest{{Path: manifestPath}},
 		},
 	}
 
-	for _, test := range tests {
-		test := test
-		t.Run(test.name, func(t *testing.T) {
-			t.Parallel()
+	p.applyDefaults(map[string]interface{}{})
 
-			p := Plugin{}
-			var err error
+	if err := p.assertValidConfig(); err != nil {
+		t.Fatal(err.Error())
+	}
 
-			p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
-			if err != nil {
-				t.Fatal(err.Error())
-			}
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
 
-			p.PlacementBindingDefaults.Name = test.bindingName
-			p.PolicyDefaults.Placement.Name = "my-placement-rule"
-			p.PolicyDefaults.Namespace = "my-policies"
-			policyConf := types.PolicyConfig{
-				Name: "policy-app-config",
-				Manifests: []types.Manifest{
-					{Path: path.Join(tmpDir, "configmap.yaml")},
-				},
-			}
-			policyConf2 := types.PolicyConfig{
-				Name: "policy-app-config2",
-				Manifests: []types.Manifest{
-					{Path: path.Join(tmpDir, "configmap.yaml")},
-				},
-			}
-			p.Policies = append(p.Policies, policyConf, policyConf2)
-			p.applyDefaults(map[string]interface{}{})
+	manifests, err := unmarshalManifestBytes(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
 
-			err = p.assertValidConfig()
-			if err == nil {
-				t.Fatal("Expected an error but did not get one")
-			}
+	bindingSubjects := map[string][]string{}
 
-			expected := fmt.Sprintf(
-				"PlacementBindingDefaults.Name `%s` is not DNS compliant. See "+
-					"https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#dns-subdomain-names",
-				test.bindingName,
-			)
-			assertEqual(t, err.Error(), expected)
-		})
+	for _, manifest := range manifests {
+		if manifest["kind"] != placementBindingKind {
+			continue
+		}
+
+		name, _, _ := unstructured.NestedString(manifest, "metadata", "name")
+		subjects, _, _ := unstructured.NestedSlice(manifest, "subjects")
+
+		for _, subject := range subjects {
+			//nolint:forcetypeassert
+			bindingSubjects[name] = append(bindingSubjects[name], subject.(map[string]interface{})["name"].(string))
+		}
+	}
+
+	// The policy label derived from the manifest determines which policy gets the binding overrides
+	expected := map[string][]string{
+		"my-placement-binding":            {"policy-app-config2"},
+		"my-placement-binding-restricted": {"policy-app-config2"},
+		"my-placement-binding2":           {"policy-app-config"},
+	}
+	assertReflectEqual(t, bindingSubjects, expected)
+}
+
+func TestGenerateExtractPlacement(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	manifestPath := path.Join(tmpDir, "manifests.yaml")
+	yamlContent := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-configmap
+data:
+  game.properties: enemies=potato
+---
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
+metadata:
+  name: my-placement
+  namespace: my-policies
+spec:
+  predicates:
+  - requiredClusterSelector:
+      labelSelector:
+        matchLabels:
+          env: dev
+`
+
+	err := os.WriteFile(manifestPath, []byte(yamlContent), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write %s", manifestPath)
+	}
+
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  placement:
+    labelSelector:
+      env: prod
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+      extractPlacement: true
+`,
+		manifestPath,
+	)
+
+	p := Plugin{}
+
+	err = p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	manifests, err := unmarshalManifestBytes(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, len(manifests), 3)
+
+	// Only the ConfigMap is embedded in the policy
+	policyTemplates, _, _ := unstructured.NestedSlice(manifests[0], "spec", "policy-templates")
+	assertEqual(t, len(policyTemplates), 1)
+
+	//nolint:forcetypeassert
+	objTemplates, _, _ := unstructured.NestedSlice(
+		policyTemplates[0].(map[string]interface{}), "objectDefinition", "spec", "object-templates",
+	)
+	assertEqual(t, len(objTemplates), 1)
+
+	//nolint:forcetypeassert
+	kind, _, _ := unstructured.NestedString(objTemplates[0].(map[string]interface{}), "objectDefinition", "kind")
+	assertEqual(t, kind, "ConfigMap")
+
+	// The placement from the manifest is used instead of the default placement
+	assertEqual(t, manifests[1]["kind"], placementKind)
+	placementName, _, _ := unstructured.NestedString(manifests[1], "metadata", "name")
+	assertEqual(t, placementName, "my-placement")
+
+	bindingPlacement, _, _ := unstructured.NestedString(manifests[2], "placementRef", "name")
+	assertEqual(t, bindingPlacement, "my-placement")
+}
+
+func TestGenerateOmitNamespace(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	p := Plugin{}
+	var err error
+
+	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p.PolicyDefaults.Namespace = "my-policies"
+	p.PolicyDefaults.OmitNamespace = true
+	policyConf := types.PolicyConfig{
+		Name: "policy-app-config",
+		Manifests: []types.Manifest{
+			{Path: path.Join(tmpDir, "configmap.yaml")},
+		},
+		PolicyOptions: types.PolicyOptions{
+			PolicySets: []string{"my-policyset"},
+		},
+	}
+	p.Policies = append(p.Policies, policyConf)
+	p.applyDefaults(map[string]interface{}{})
+
+	if err := p.assertValidConfig(); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	manifests, err := unmarshalManifestBytes(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	kinds := []string{}
+
+	for _, manifest := range manifests {
+		kind, _, _ := unstructured.NestedString(manifest, "kind")
+		kinds = append(kinds, kind)
+
+		_, found, _ := unstructured.NestedString(manifest, "metadata", "namespace")
+		if found {
+			t.Fatalf("Expected the %s to not have a namespace", kind)
+		}
+	}
+
+	assertReflectEqual(
+		t, kinds, []string{policyKind, policySetKind, placementKind, placementBindingKind},
+	)
+}
+
+func TestGenerateFieldManagerAnnotation(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		fieldManager *types.FieldManagerAnnotation
+		expected     interface{}
+	}{
+		"set": {
+			&types.FieldManagerAnnotation{Key: "example.com/field-manager", Value: "policy-pipeline"},
+			"policy-pipeline",
+		},
+		"unset": {nil, nil},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p := Plugin{}
+			var err error
+
+			p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			p.PolicyDefaults.Namespace = "my-policies"
+			p.PolicyDefaults.FieldManagerAnnotation = test.fieldManager
+			policyConf := types.PolicyConfig{
+				Name: "policy-app-config",
+				Manifests: []types.Manifest{
+					{Path: path.Join(tmpDir, "configmap.yaml")},
+				},
+				PolicyOptions: types.PolicyOptions{
+					PolicySets: []string{"my-policyset"},
+				},
+			}
+			p.Policies = append(p.Policies, policyConf)
+			p.applyDefaults(map[string]interface{}{})
+
+			if err := p.assertValidConfig(); err != nil {
+				t.Fatal(err.Error())
+			}
+
+			output, err := p.Generate()
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			manifests, err := unmarshalManifestBytes(output)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			kinds := []string{}
+
+			for _, manifest := range manifests {
+				kind, _, _ := unstructured.NestedString(manifest, "kind")
+				kinds = append(kinds, kind)
+
+				annotations, _, _ := unstructured.NestedMap(manifest, "metadata", "annotations")
+				assertEqual(t, annotations["example.com/field-manager"], test.expected)
+			}
+
+			assertReflectEqual(
+				t, kinds, []string{policyKind, policySetKind, placementKind, placementBindingKind},
+			)
+		})
+	}
+}
+
+func TestGenerateObjectSelectorWarnings(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	clusterRole := `
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: my-clusterrole
+`
+
+	err := os.WriteFile(path.Join(tmpDir, "clusterrole.yaml"), []byte(clusterRole), 0o666)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-cluster-scoped
+  objectSelector:
+    matchLabels:
+      app: my-app
+  manifests:
+    - path: %s
+- name: policy-mixed
+  objectSelector:
+    matchLabels:
+      app: my-app
+  manifests:
+    - path: %s
+    - path: %s
+- name: policy-no-selector
+  manifests:
+    - path: %s
+`,
+		path.Join(tmpDir, "clusterrole.yaml"),
+		path.Join(tmpDir, "clusterrole.yaml"),
+		path.Join(tmpDir, "configmap.yaml"),
+		path.Join(tmpDir, "clusterrole.yaml"),
+	)
+
+	p := Plugin{}
+
+	err = p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, err = p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := []string{
+		"the policy policy-cluster-scoped sets objectSelector on the ConfigurationPolicy policy-cluster-scoped, but " +
+			"it only has the cluster scoped kinds ClusterRole, so the objectSelector has no effect",
+	}
+	assertReflectEqual(t, p.Warnings(), expected)
+}
+
+func TestGenerateConsolidatedRecordDiff(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	createConfigMap(t, tmpDir, "configmap2.yaml")
+
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  allowDuplicateObjects: true
+  namespace: my-policies
+  recordDiff: InStatus
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+      recordDiff: Log
+    - path: %s
+`,
+		path.Join(tmpDir, "configmap.yaml"), path.Join(tmpDir, "configmap2.yaml"),
+	)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	manifests, err := unmarshalManifestBytes(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	policyTemplates, _, _ := unstructured.NestedSlice(manifests[0], "spec", "policy-templates")
+	assertEqual(t, len(policyTemplates), 1)
+
+	configPolicySpec, _, _ := unstructured.NestedMap(
+		policyTemplates[0].(map[string]interface{}), "objectDefinition", "spec",
+	)
+	assertEqual(t, configPolicySpec["recordDiff"], nil)
+
+	objTemplates, _ := configPolicySpec["object-templates"].([]interface{})
+	assertEqual(t, len(objTemplates), 2)
+	assertEqual(t, objTemplates[0].(map[string]interface{})["recordDiff"], "Log")
+	assertEqual(t, objTemplates[1].(map[string]interface{})["recordDiff"], "InStatus")
+}
+
+func TestGenerateDisableFromManifestAnnotation(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		annotation     string
+		policyDisabled string
+		expected       bool
+	}{
+		"annotation true":          {annotation: `"true"`, expected: true},
+		"annotation false":         {annotation: `"false"`, expected: false},
+		"annotation unset":         {expected: true},
+		"explicit policy disabled": {annotation: `"true"`, policyDisabled: "false", expected: false},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir := t.TempDir()
+
+			manifest := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: my-configmap\n"
+			if test.annotation != "" {
+				manifest += "  annotations:\n    policy.acme.com/disabled: " + test.annotation + "\n"
+			}
+
+			err := os.WriteFile(path.Join(tmpDir, "configmap.yaml"), []byte(manifest), 0o666)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			policyDisabled := ""
+			if test.policyDisabled != "" {
+				policyDisabled = "disabled: " + test.policyDisabled
+			}
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  disabled: true
+  disableFromManifestAnnotation: policy.acme.com/disabled
+policies:
+- name: policy-app-config
+  %s
+  manifests:
+    - path: %s
+`,
+				policyDisabled, path.Join(tmpDir, "configmap.yaml"),
+			)
+
+			p := Plugin{}
+
+			err = p.Config([]byte(config), tmpDir)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			output, err := p.Generate()
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			manifests, err := unmarshalManifestBytes(output)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			disabled, _, _ := unstructured.NestedBool(manifests[0], "spec", "disabled")
+			assertEqual(t, disabled, test.expected)
+		})
+	}
+}
+
+func TestGenerateDisableFromManifestAnnotationInvalid(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	manifest := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-configmap
+  annotations:
+    policy.acme.com/disabled: "maybe"
+`
+
+	err := os.WriteFile(path.Join(tmpDir, "configmap.yaml"), []byte(manifest), 0o666)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p := Plugin{}
+
+	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p.PolicyDefaults.Namespace = "my-policies"
+	p.PolicyDefaults.DisableFromManifestAnnotation = "policy.acme.com/disabled"
+	p.Policies = append(p.Policies, types.PolicyConfig{
+		Name:      "policy-app-config",
+		Manifests: []types.Manifest{{Path: path.Join(tmpDir, "configmap.yaml")}},
+	})
+	p.applyDefaults(map[string]interface{}{})
+
+	if err := p.assertValidConfig(); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, err = p.Generate()
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := "failed to determine if the policy policy-app-config is disabled: the policy.acme.com/disabled " +
+		"annotation of the first embedded object must be true or false but got maybe"
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestGeneratePolicySetsWithPlacement(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	p := Plugin{}
+	var err error
+
+	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p.PlacementBindingDefaults.Name = "my-placement-binding"
+	p.PolicyDefaults.Placement.Name = "my-placement"
+	p.PolicyDefaults.Namespace = "my-policies"
+
+	policyConf := types.PolicyConfig{
+		Name: "policy-app-config",
+		Manifests: []types.Manifest{
+			{
+				Path: path.Join(tmpDir, "configmap.yaml"),
+			},
+		},
+		PolicyOptions: types.PolicyOptions{
+			PolicySets: []string{"policyset"},
+		},
+	}
+	p.Policies = append(p.Policies, policyConf)
+
+	p.applyDefaults(map[string]interface{}{})
+
+	if err := p.assertValidConfig(); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := `
+---
+apiVersion: policy.open-cluster-management.io/v1
+kind: Policy
+metadata:
+    annotations:
+        policy.open-cluster-management.io/categories: CM Configuration Management
+        policy.open-cluster-management.io/controls: CM-2 Baseline Configuration
+        policy.open-cluster-management.io/description: ""
+        policy.open-cluster-management.io/standards: NIST SP 800-53
+    name: policy-app-config
+    namespace: my-policies
+spec:
+    disabled: false
+    policy-templates:
+        - objectDefinition:
+            apiVersion: policy.open-cluster-management.io/v1
+            kind: ConfigurationPolicy
+            metadata:
+                name: policy-app-config
+            spec:
+                object-templates:
+                    - complianceType: musthave
+                      objectDefinition:
+                        apiVersion: v1
+                        data:
+                            game.properties: enemies=potato
+                        kind: ConfigMap
+                        metadata:
+                            name: my-configmap
+                remediationAction: inform
+                severity: low
+    remediationAction: inform
+---
+apiVersion: policy.open-cluster-management.io/v1beta1
+kind: PolicySet
+metadata:
+    name: policyset
+    namespace: my-policies
+spec:
+    description: ""
+    policies:
+        - policy-app-config
+---
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
+metadata:
+    name: my-placement
+    namespace: my-policies
+spec:
+    predicates:
+        - requiredClusterSelector:
+            labelSelector:
+                matchExpressions: []
+    tolerations:
+        - key: cluster.open-cluster-management.io/unavailable
+          operator: Exists
+        - key: cluster.open-cluster-management.io/unreachable
+          operator: Exists
+---
+apiVersion: policy.open-cluster-management.io/v1
+kind: PlacementBinding
+metadata:
+    name: my-placement-binding
+    namespace: my-policies
+placementRef:
+    apiGroup: cluster.open-cluster-management.io
+    kind: Placement
+    name: my-placement
+subjects:
+    - apiGroup: policy.open-cluster-management.io
+      kind: PolicySet
+      name: policyset
+`
+	expected = strings.TrimPrefix(expected, "\n")
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, string(output), expected)
+}
+
+func TestGeneratePolicySetsOverridePlacement(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	p := Plugin{}
+	var err error
+
+	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p.PlacementBindingDefaults.Name = "my-placement-binding"
+	p.PolicyDefaults.Placement.Name = "my-placement"
+	p.PolicyDefaults.Namespace = "my-policies"
+	p.PolicySetDefaults.Placement.Name = "other-placement"
+
+	policyConf := types.PolicyConfig{
+		Name: "policy-app-config",
+		Manifests: []types.Manifest{
+			{
+				Path: path.Join(tmpDir, "configmap.yaml"),
+			},
+		},
+		PolicyOptions: types.PolicyOptions{
+			PolicySets: []string{"policyset-overrides"},
+		},
+	}
+	p.Policies = append(p.Policies, policyConf)
+
+	policySetConf := types.PolicySetConfig{
+		Name: "policyset-overrides",
+		PolicySetOptions: types.PolicySetOptions{
+			Placement: types.PlacementConfig{
+				LabelSelector: map[string]interface{}{
+					"my-label": "my-cluster",
+				},
+			},
+		},
+	}
+	p.PolicySets = append(p.PolicySets, policySetConf)
+
+	p.applyDefaults(map[string]interface{}{})
+
+	if err := p.assertValidConfig(); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := `
+---
+apiVersion: policy.open-cluster-management.io/v1
+kind: Policy
+metadata:
+    annotations:
+        policy.open-cluster-management.io/categories: CM Configuration Management
+        policy.open-cluster-management.io/controls: CM-2 Baseline Configuration
+        policy.open-cluster-management.io/description: ""
+        policy.open-cluster-management.io/standards: NIST SP 800-53
+    name: policy-app-config
+    namespace: my-policies
+spec:
+    disabled: false
+    policy-templates:
+        - objectDefinition:
+            apiVersion: policy.open-cluster-management.io/v1
+            kind: ConfigurationPolicy
+            metadata:
+                name: policy-app-config
+            spec:
+                object-templates:
+                    - complianceType: musthave
+                      objectDefinition:
+                        apiVersion: v1
+                        data:
+                            game.properties: enemies=potato
+                        kind: ConfigMap
+                        metadata:
+                            name: my-configmap
+                remediationAction: inform
+                severity: low
+    remediationAction: inform
+---
+apiVersion: policy.open-cluster-management.io/v1beta1
+kind: PolicySet
+metadata:
+    name: policyset-overrides
+    namespace: my-policies
+spec:
+    description: ""
+    policies:
+        - policy-app-config
+---
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
+metadata:
+    name: other-placement
+    namespace: my-policies
+spec:
+    predicates:
+        - requiredClusterSelector:
+            labelSelector:
+                matchExpressions:
+                    - key: my-label
+                      operator: In
+                      values:
+                        - my-cluster
+    tolerations:
+        - key: cluster.open-cluster-management.io/unavailable
+          operator: Exists
+        - key: cluster.open-cluster-management.io/unreachable
+          operator: Exists
+---
+apiVersion: policy.open-cluster-management.io/v1
+kind: PlacementBinding
+metadata:
+    name: my-placement-binding
+    namespace: my-policies
+placementRef:
+    apiGroup: cluster.open-cluster-management.io
+    kind: Placement
+    name: other-placement
+subjects:
+    - apiGroup: policy.open-cluster-management.io
+      kind: PolicySet
+      name: policyset-overrides
+`
+	expected = strings.TrimPrefix(expected, "\n")
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, string(output), expected)
+}
+
+func TestGeneratePolicySetsWithoutPlacement(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	p := Plugin{}
+	var err error
+
+	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p.PlacementBindingDefaults.Name = "my-placement-binding"
+	p.PolicyDefaults.Placement.Name = "my-placement-rule"
+	p.PolicyDefaults.Namespace = "my-policies"
+
+	policyConf := types.PolicyConfig{
+		Name: "policy-app-config",
+		Manifests: []types.Manifest{
+			{
+				Path: path.Join(tmpDir, "configmap.yaml"),
+			},
+		},
+		PolicyOptions: types.PolicyOptions{
+			PolicySets: []string{"policyset"},
+		},
+	}
+	p.Policies = append(p.Policies, policyConf)
+
+	p.applyDefaults(map[string]interface{}{
+		"policySetDefaults": map[string]interface{}{
+			"generatePolicySetPlacement": false,
+		},
+	})
+
+	if err := p.assertValidConfig(); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := `
+---
+apiVersion: policy.open-cluster-management.io/v1
+kind: Policy
+metadata:
+    annotations:
+        policy.open-cluster-management.io/categories: CM Configuration Management
+        policy.open-cluster-management.io/controls: CM-2 Baseline Configuration
+        policy.open-cluster-management.io/description: ""
+        policy.open-cluster-management.io/standards: NIST SP 800-53
+    name: policy-app-config
+    namespace: my-policies
+spec:
+    disabled: false
+    policy-templates:
+        - objectDefinition:
+            apiVersion: policy.open-cluster-management.io/v1
+            kind: ConfigurationPolicy
+            metadata:
+                name: policy-app-config
+            spec:
+                object-templates:
+                    - complianceType: musthave
+                      objectDefinition:
+                        apiVersion: v1
+                        data:
+                            game.properties: enemies=potato
+                        kind: ConfigMap
+                        metadata:
+                            name: my-configmap
+                remediationAction: inform
+                severity: low
+    remediationAction: inform
+---
+apiVersion: policy.open-cluster-management.io/v1beta1
+kind: PolicySet
+metadata:
+    name: policyset
+    namespace: my-policies
+spec:
+    description: ""
+    policies:
+        - policy-app-config
+`
+	expected = strings.TrimPrefix(expected, "\n")
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, string(output), expected)
+}
+
+func TestGeneratePolicySetsWithPolicyPlacement(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	p := Plugin{}
+	var err error
+
+	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p.PlacementBindingDefaults.Name = "my-placement-binding"
+	p.PolicyDefaults.Placement.Name = "my-placement"
+	p.PolicyDefaults.Namespace = "my-policies"
+
+	policyConf := types.PolicyConfig{
+		Name: "policy-app-config",
+		Manifests: []types.Manifest{
+			{
+				Path: path.Join(tmpDir, "configmap.yaml"),
+			},
+		},
+		PolicyOptions: types.PolicyOptions{
+			PolicySets: []string{"my-policyset"},
+		},
+	}
+	p.Policies = append(p.Policies, policyConf)
+	p.PolicySets = []types.PolicySetConfig{
+		{
+			Name: "my-policyset",
+			PolicySetOptions: types.PolicySetOptions{
+				Placement: types.PlacementConfig{
+					Name:             "policyset-placement",
+					ClusterSelectors: map[string]interface{}{"my": "app"},
+				},
+			},
+		},
+	}
+
+	p.applyDefaults(map[string]interface{}{})
+
+	if err := p.assertValidConfig(); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p.Policies[0].GeneratePlacementWhenInSet = true
+
+	expected := `
+---
+apiVersion: policy.open-cluster-management.io/v1
+kind: Policy
+metadata:
+    annotations:
+        policy.open-cluster-management.io/categories: CM Configuration Management
+        policy.open-cluster-management.io/controls: CM-2 Baseline Configuration
+        policy.open-cluster-management.io/description: ""
+        policy.open-cluster-management.io/standards: NIST SP 800-53
+    name: policy-app-config
+    namespace: my-policies
+spec:
+    disabled: false
+    policy-templates:
+        - objectDefinition:
+            apiVersion: policy.open-cluster-management.io/v1
+            kind: ConfigurationPolicy
+            metadata:
+                name: policy-app-config
+            spec:
+                object-templates:
+                    - complianceType: musthave
+                      objectDefinition:
+                        apiVersion: v1
+                        data:
+                            game.properties: enemies=potato
+                        kind: ConfigMap
+                        metadata:
+                            name: my-configmap
+                remediationAction: inform
+                severity: low
+    remediationAction: inform
+---
+apiVersion: policy.open-cluster-management.io/v1beta1
+kind: PolicySet
+metadata:
+    name: my-policyset
+    namespace: my-policies
+spec:
+    description: ""
+    policies:
+        - policy-app-config
+---
+apiVersion: apps.open-cluster-management.io/v1
+kind: PlacementRule
+metadata:
+    name: my-placement
+    namespace: my-policies
+spec:
+    clusterSelector:
+        matchExpressions: []
+---
+apiVersion: apps.open-cluster-management.io/v1
+kind: PlacementRule
+metadata:
+    name: policyset-placement
+    namespace: my-policies
+spec:
+    clusterSelector:
+        matchExpressions:
+            - key: my
+              operator: In
+              values:
+                - app
+---
+apiVersion: policy.open-cluster-management.io/v1
+kind: PlacementBinding
+metadata:
+    name: binding-policy-app-config
+    namespace: my-policies
+placementRef:
+    apiGroup: apps.open-cluster-management.io
+    kind: PlacementRule
+    name: my-placement
+subjects:
+    - apiGroup: policy.open-cluster-management.io
+      kind: Policy
+      name: policy-app-config
+---
+apiVersion: policy.open-cluster-management.io/v1
+kind: PlacementBinding
+metadata:
+    name: my-placement-binding
+    namespace: my-policies
+placementRef:
+    apiGroup: apps.open-cluster-management.io
+    kind: PlacementRule
+    name: policyset-placement
+subjects:
+    - apiGroup: policy.open-cluster-management.io
+      kind: PolicySet
+      name: my-policyset
+`
+	expected = strings.TrimPrefix(expected, "\n")
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, string(output), expected)
+}
+
+func TestGenerateBindPoliciesIndividually(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		generatePlacementWhenInSet string
+		bindPoliciesIndividually   string
+		policyOverride             string
+		expectedSubjects           []string
+	}{
+		"true": {
+			bindPoliciesIndividually: "true",
+			expectedSubjects:         []string{"Policy/policy-app-config", "PolicySet/my-policyset"},
+		},
+		"false": {
+			bindPoliciesIndividually: "false",
+			expectedSubjects:         []string{"PolicySet/my-policyset"},
+		},
+		"false overrides policyDefaults": {
+			generatePlacementWhenInSet: "true",
+			bindPoliciesIndividually:   "false",
+			expectedSubjects:           []string{"PolicySet/my-policyset"},
+		},
+		"unset uses policyDefaults": {
+			generatePlacementWhenInSet: "true",
+			expectedSubjects:           []string{"Policy/policy-app-config", "PolicySet/my-policyset"},
+		},
+		"policy value takes precedence": {
+			bindPoliciesIndividually: "false",
+			policyOverride:           "true",
+			expectedSubjects:         []string{"Policy/policy-app-config", "PolicySet/my-policyset"},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			policyDefaults := ""
+			if test.generatePlacementWhenInSet != "" {
+				policyDefaults = "\n  generatePlacementWhenInSet: " + test.generatePlacementWhenInSet
+			}
+
+			policySetDefaults := ""
+			if test.bindPoliciesIndividually != "" {
+				policySetDefaults = "\npolicySetDefaults:\n  bindPoliciesIndividually: " + test.bindPoliciesIndividually
+			}
+
+			policyOverride := ""
+			if test.policyOverride != "" {
+				policyOverride = "\n  generatePlacementWhenInSet: " + test.policyOverride
+			}
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  placement:
+    labelSelector:
+      app: policy%s%s
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+  policySets:
+    - my-policyset%s
+policySets:
+- name: my-policyset
+  placement:
+    labelSelector:
+      app: policyset
+`,
+				policyDefaults, policySetDefaults, path.Join(tmpDir, "configmap.yaml"), policyOverride,
+			)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			output, err := p.Generate()
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			manifests, err := unmarshalManifestBytes(output)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			subjects := []string{}
+
+			for _, manifest := range manifests {
+				if manifest["kind"] != "PlacementBinding" {
+					continue
+				}
+
+				for _, subject := range manifest["subjects"].([]interface{}) {
+					subject := subject.(map[string]interface{})
+					subjects = append(subjects, fmt.Sprintf("%s/%s", subject["kind"], subject["name"]))
+				}
+			}
+
+			sort.Strings(subjects)
+
+			assertReflectEqual(t, subjects, test.expectedSubjects)
+		})
+	}
+}
+
+func TestCreatePolicySet(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	p := Plugin{}
+	var err error
+
+	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p.PlacementBindingDefaults.Name = "my-placement-binding"
+	p.PolicyDefaults.Placement.Name = "my-placement-rule"
+	p.PolicyDefaults.Namespace = "my-policies"
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]string{
+				"chandler": "bing",
+			},
+		},
+	}
+	policyConf := types.PolicyConfig{
+		Name: "policy-app-config",
+		Manifests: []types.Manifest{
+			{
+				Path:    path.Join(tmpDir, "configmap.yaml"),
+				Patches: []map[string]interface{}{patch},
+			},
+		},
+	}
+	policyConf2 := types.PolicyConfig{
+		Name: "policy-app-config2",
+		Manifests: []types.Manifest{
+			{Path: path.Join(tmpDir, "configmap.yaml")},
+		},
+	}
+	p.Policies = append(p.Policies, policyConf, policyConf2)
+
+	p.PolicyDefaults.PolicySets = []string{"policyset-default"}
+	p.PolicySets = []types.PolicySetConfig{
+		{
+			Name:        "policyset-default",
+			Description: "This is a default policyset.",
+			Policies: []string{
+				"policy-app-config",
+				"policy-app-config2",
+				"pre-exists-policy",
+			},
+		},
+	}
+	p.applyDefaults(map[string]interface{}{})
+
+	err = p.createPolicySet(&p.PolicySets[0])
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output := p.outputBuffer.String()
+	expected := `
+---
+apiVersion: policy.open-cluster-management.io/v1beta1
+kind: PolicySet
+metadata:
+    name: policyset-default
+    namespace: my-policies
+spec:
+    description: This is a default policyset.
+    policies:
+        - policy-app-config
+        - policy-app-config2
+        - pre-exists-policy
+`
+	expected = strings.TrimPrefix(expected, "\n")
+	assertEqual(t, output, expected)
+}
+
+func getYAMLEvaluationInterval(
+	t *testing.T, policyTemplate interface{}, skipFinalValidation bool,
+) map[string]interface{} {
+	t.Helper()
+
+	plcTemplate, ok := policyTemplate.(map[string]interface{})
+	assertEqual(t, ok, true)
+
+	configPolicy, ok := plcTemplate["objectDefinition"].(map[string]interface{})
+	assertEqual(t, ok, true)
+
+	configPolicyOptions, ok := configPolicy["spec"].(map[string]interface{})
+	assertEqual(t, ok, true)
+
+	evaluationInterval, ok := configPolicyOptions["evaluationInterval"].(map[string]interface{})
+
+	if !skipFinalValidation {
+		assertEqual(t, ok, true)
+	}
+
+	return evaluationInterval
+}
+
+func TestGenerateEvaluationInterval(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	createObjectTemplatesRawManifest(t, tmpDir, "object-templates-raw.yaml")
+
+	p := Plugin{}
+	var err error
+
+	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p.PolicyDefaults.Namespace = "my-policies"
+	p.PolicyDefaults.EvaluationInterval = types.EvaluationInterval{
+		Compliant:    "never",
+		NonCompliant: "15s",
+	}
+
+	// Test that the policy evaluation interval gets inherited when not set on a manifest.
+	policyConf := types.PolicyConfig{
+		PolicyOptions: types.PolicyOptions{
+			ConsolidateManifests: false,
+		},
+		ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
+			EvaluationInterval: types.EvaluationInterval{
+				Compliant:    "30m",
+				NonCompliant: "30s",
+			},
+		},
+		Name: "policy-app-config",
+		Manifests: []types.Manifest{
+			{Path: path.Join(tmpDir, "configmap.yaml")},
+			{
+				ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
+					EvaluationInterval: types.EvaluationInterval{
+						Compliant:    "25m",
+						NonCompliant: "5m",
+					},
+				},
+				Path: path.Join(tmpDir, "configmap.yaml"),
+			},
+			// Test that it does not get an inherited value when it is explicitly set to empty in the YAML below.
+			{
+				Path: path.Join(tmpDir, "configmap.yaml"),
+			},
+		},
+	}
+	// Test that the policy defaults get inherited.
+	policyConf2 := types.PolicyConfig{
+		Name: "policy-app-config2",
+		Manifests: []types.Manifest{
+			{Path: path.Join(tmpDir, "configmap.yaml")},
+		},
+	}
+	// Test that explicitly setting evaluationInterval to an empty value overrides the policy default.
+	policyConf3 := types.PolicyConfig{
+		ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
+			EvaluationInterval: types.EvaluationInterval{},
+		},
+		Name: "policy-app-config3",
+		Manifests: []types.Manifest{
+			{Path: path.Join(tmpDir, "configmap.yaml")},
+		},
+	}
+	// Test that the policy defaults get inherited with object-templates-raw.
+	policyConf4 := types.PolicyConfig{
+		Name: "policy-app-config4",
+		Manifests: []types.Manifest{
+			{Path: path.Join(tmpDir, "object-templates-raw.yaml")},
+		},
+	}
+	p.Policies = append(p.Policies, policyConf, policyConf2, policyConf3, policyConf4)
+	p.applyDefaults(
+		map[string]interface{}{
+			"policies": []interface{}{
+				map[string]interface{}{
+					"consolidateManifests": false,
+					"manifests": []interface{}{
+						map[string]interface{}{},
+						map[string]interface{}{},
+						map[string]interface{}{
+							"evaluationInterval": map[string]interface{}{
+								"compliant":    "",
+								"noncompliant": "",
+							},
+						},
+					},
+				},
+				map[string]interface{}{},
+				map[string]interface{}{
+					"evaluationInterval": map[string]interface{}{
+						"compliant":    "",
+						"noncompliant": "",
+					},
+				},
+			},
+		},
+	)
+
+	if err := p.assertValidConfig(); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	generatedManifests, err := unmarshalManifestBytes(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, len(generatedManifests), 12)
+
+	for _, manifest := range generatedManifests {
+		kind, _ := manifest["kind"].(string)
+		if kind != "Policy" {
+			continue
+		}
+
+		metadata, _ := manifest["metadata"].(map[string]interface{})
+
+		name, _ := metadata["name"].(string)
+
+		spec, _ := manifest["spec"].(map[string]interface{})
+		policyTemplates, _ := spec["policy-templates"].([]interface{})
+
+		if name == "policy-app-config" {
+			assertEqual(t, len(policyTemplates), 3)
+			evaluationInterval := getYAMLEvaluationInterval(t, policyTemplates[0], false)
+			assertEqual(t, evaluationInterval["compliant"], "30m")
+			assertEqual(t, evaluationInterval["noncompliant"], "30s")
+
+			evaluationInterval = getYAMLEvaluationInterval(t, policyTemplates[1], false)
+			assertEqual(t, evaluationInterval["compliant"], "25m")
+			assertEqual(t, evaluationInterval["noncompliant"], "5m")
+
+			evaluationInterval = getYAMLEvaluationInterval(t, policyTemplates[2], true)
+			assertEqual(t, len(evaluationInterval), 0)
+		} else if name == "policy-app-config2" {
+			assertEqual(t, len(policyTemplates), 1)
+			evaluationInterval := getYAMLEvaluationInterval(t, policyTemplates[0], false)
+			assertEqual(t, evaluationInterval["compliant"], "never")
+			assertEqual(t, evaluationInterval["noncompliant"], "15s")
+		} else if name == "policy-app-config3" {
+			assertEqual(t, len(policyTemplates), 1)
+			evaluationInterval := getYAMLEvaluationInterval(t, policyTemplates[0], true)
+			assertEqual(t, len(evaluationInterval), 0)
+		} else if name == "policy-app-config4" {
+			assertEqual(t, len(policyTemplates), 1)
+			evaluationInterval := getYAMLEvaluationInterval(t, policyTemplates[0], false)
+			assertEqual(t, evaluationInterval["compliant"], "never")
+			assertEqual(t, evaluationInterval["noncompliant"], "15s")
+		}
+	}
+}
+
+func TestCreatePolicyWithConfigPolicyAnnotations(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+	}{
+		{name: "no-override", annotations: nil},
+		{
+			name: "override",
+			annotations: map[string]string{
+				"policy.open-cluster-management.io/disable-templates": "true",
+			},
+		},
+		{
+			name:        "override-empty",
+			annotations: map[string]string{},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := Plugin{}
+			p.PolicyDefaults.Namespace = "my-policies"
+			p.PolicyDefaults.ConfigurationPolicyAnnotations = map[string]string{"test-default-annotation": "default"}
+			policyConf := types.PolicyConfig{
+				Name: "policy-app-config", Manifests: []types.Manifest{
+					{Path: path.Join(tmpDir, "configmap.yaml")},
+				},
+			}
+
+			if test.annotations != nil {
+				policyConf.ConfigurationPolicyAnnotations = test.annotations
+			}
+
+			p.Policies = append(p.Policies, policyConf)
+			p.applyDefaults(map[string]interface{}{})
+
+			err := p.createPolicy(&p.Policies[0])
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			output := p.outputBuffer.Bytes()
+			policyManifests, err := unmarshalManifestBytes(output)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			//nolint:forcetypeassert
+			spec := policyManifests[0]["spec"].(map[string]interface{})
+			policyTemplates := spec["policy-templates"].([]interface{})
+			//nolint:forcetypeassert
+			configPolicy := policyTemplates[0].(map[string]interface{})["objectDefinition"].(map[string]interface{})
+			//nolint:forcetypeassert
+			metadata := configPolicy["metadata"].(map[string]interface{})
+
+			if test.annotations != nil && len(test.annotations) == 0 {
+				assertEqual(t, metadata["annotations"], nil)
+			} else {
+				annotations := map[string]string{}
+				for key, val := range metadata["annotations"].(map[string]interface{}) {
+					//nolint:forcetypeassert
+					annotations[key] = val.(string)
+				}
+
+				if test.annotations == nil {
+					assertReflectEqual(t, annotations, map[string]string{"test-default-annotation": "default"})
+				} else {
+					assertReflectEqual(t, annotations, test.annotations)
+				}
+			}
+		})
+	}
+}
+
+func TestCreatePolicyWithConfigPolicyLabels(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	manifestPath := path.Join(tmpDir, "configmap.yaml")
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  consolidateManifests: false
+  configurationPolicyLabels:
+    team: default
+policies:
+- name: policy-app-config
+  manifests:
+  - path: %[1]s
+  - path: %[1]s
+    configurationPolicyLabels:
+      team: manifest
+  - path: %[1]s
+    configurationPolicyLabels: {}
+- name: policy-app-config2
+  configurationPolicyLabels: {}
+  manifests:
+  - path: %[1]s
+`,
+		manifestPath,
+	)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := map[string][]map[string]string{
+		"policy-app-config":  {{"team": "default"}, {"team": "manifest"}, nil},
+		"policy-app-config2": {nil},
+	}
+
+	for i := range p.Policies {
+		policyTemplates, err := getPolicyTemplates(&p.Policies[i], nil)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		labels := []map[string]string{}
+
+		for _, policyTemplate := range policyTemplates {
+			//nolint:forcetypeassert
+			metadata := policyTemplate["objectDefinition"].(map[string]interface{})["metadata"].(map[string]interface{})
+			templateLabels, _ := metadata["labels"].(map[string]string)
+			labels = append(labels, templateLabels)
+		}
+
+		assertReflectEqual(t, labels, expected[p.Policies[i].Name])
+	}
+}
+
+func TestConfigInvalidConfigPolicyLabels(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	manifestPath := path.Join(tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		policy      string
+		expectedErr string
+	}{
+		"invalid policy label": {
+			policy: `
+  configurationPolicyLabels:
+    team: not a label
+  manifests:
+  - path: %s`,
+			expectedErr: "the policy policy-app-config has an invalid configurationPolicyLabels entry `team: not a label`; " +
+				"it must be a valid label",
+		},
+		"invalid manifest label": {
+			policy: `
+  consolidateManifests: false
+  manifests:
+  - path: %s
+    configurationPolicyLabels:
+      team/: manifest`,
+			expectedErr: "the policy policy-app-config has an invalid manifest[0].configurationPolicyLabels entry " +
+				"`team/: manifest`; it must be a valid label",
+		},
+		"consolidated manifest": {
+			policy: `
+  manifests:
+  - path: %s
+    configurationPolicyLabels:
+      team: manifest`,
+			expectedErr: "the policy policy-app-config has the configurationPolicyLabels value set on manifest[0] but " +
+				"consolidateManifests is true",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config`+test.policy+"\n",
+				manifestPath,
+			)
+
+			p := Plugin{}
+
+			err := p.Config([]byte(config), tmpDir)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+func TestCreatePolicyWithNamespaceSelector(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		name              string
+		namespaceSelector types.NamespaceSelector
+	}{
+		"nil-selector": {namespaceSelector: types.NamespaceSelector{}},
+		"empty-selector-values": {
+			namespaceSelector: types.NamespaceSelector{
+				Include:          []string{},
+				Exclude:          []string{},
+				MatchLabels:      &map[string]string{},
+				MatchExpressions: &[]metav1.LabelSelectorRequirement{},
+			},
+		},
+		"completely-filled-values": {
+			namespaceSelector: types.NamespaceSelector{
+				Include: []string{"test-ns-1", "test-ns-2"},
+				Exclude: []string{"*-ns-[1]"},
+				MatchLabels: &map[string]string{
+					"testing": "is awesome",
+				},
+				MatchExpressions: &[]metav1.LabelSelectorRequirement{{
+					Key:      "door",
+					Operator: "Exists",
+				}},
+			},
+		},
+		"include-exclude-only": {
+			namespaceSelector: types.NamespaceSelector{
+				Include: []string{"test-ns-1", "test-ns-2"},
+				Exclude: []string{"*-ns-[1]"},
+			},
+		},
+		"label-selectors-only": {
+			namespaceSelector: types.NamespaceSelector{
+				MatchLabels: &map[string]string{
+					"testing": "is awesome",
+				},
+				MatchExpressions: &[]metav1.LabelSelectorRequirement{{
+					Key:      "door",
+					Operator: "Exists",
+				}},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p := Plugin{}
+			p.PolicyDefaults.Namespace = "my-policies"
+			p.PolicyDefaults.NamespaceSelector = types.NamespaceSelector{
+				MatchLabels: &map[string]string{},
+			}
+			policyConf := types.PolicyConfig{
+				Name: "policy-app-config", Manifests: []types.Manifest{
+					{Path: path.Join(tmpDir, "configmap.yaml")},
+				},
+			}
+			policyConf.NamespaceSelector = test.namespaceSelector
+
+			p.Policies = append(p.Policies, policyConf)
+			p.applyDefaults(map[string]interface{}{})
+
+			err := p.createPolicy(&p.Policies[0])
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			output := p.outputBuffer.Bytes()
+			policyManifests, err := unmarshalManifestBytes(output)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			//nolint:forcetypeassert
+			spec := policyManifests[0]["spec"].(map[string]interface{})
+			policyTemplates := spec["policy-templates"].([]interface{})
+			//nolint:forcetypeassert
+			configPolicy := policyTemplates[0].(map[string]interface{})["objectDefinition"].(map[string]interface{})
+			//nolint:forcetypeassert
+			configPolicyOptions := configPolicy["spec"].(map[string]interface{})
+			//nolint:forcetypeassert
+			configPolicySelector := configPolicyOptions["namespaceSelector"].(map[string]interface{})
+
+			if reflect.DeepEqual(test.namespaceSelector, types.NamespaceSelector{}) {
+				assertSelectorEqual(t, configPolicySelector, p.PolicyDefaults.NamespaceSelector)
+			} else {
+				assertSelectorEqual(t, configPolicySelector, test.namespaceSelector)
+			}
+		})
+	}
+}
+
+func TestCreatePolicyWithObjectSelector(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	defaultSelector := types.LabelSelector{
+		MatchLabels: &map[string]string{"app": "default"},
+	}
+
+	tests := map[string]struct {
+		policySelector   types.LabelSelector
+		manifestSelector types.LabelSelector
+		expected         *types.LabelSelector
+	}{
+		"nil-selector": {expected: &defaultSelector},
+		"empty-selector-values": {
+			policySelector: types.LabelSelector{
+				MatchLabels:      &map[string]string{},
+				MatchExpressions: &[]metav1.LabelSelectorRequirement{},
+			},
+			expected: &types.LabelSelector{
+				MatchLabels:      &map[string]string{},
+				MatchExpressions: &[]metav1.LabelSelectorRequirement{},
+			},
+		},
+		"completely-filled-values": {
+			policySelector: types.LabelSelector{
+				MatchLabels: &map[string]string{
+					"testing": "is awesome",
+				},
+				MatchExpressions: &[]metav1.LabelSelectorRequirement{{
+					Key:      "door",
+					Operator: "Exists",
+				}},
+			},
+			expected: &types.LabelSelector{
+				MatchLabels: &map[string]string{
+					"testing": "is awesome",
+				},
+				MatchExpressions: &[]metav1.LabelSelectorRequirement{{
+					Key:      "door",
+					Operator: "Exists",
+				}},
+			},
+		},
+		"manifest-override": {
+			policySelector: types.LabelSelector{
+				MatchLabels: &map[string]string{"app": "policy"},
+			},
+			manifestSelector: types.LabelSelector{
+				MatchLabels: &map[string]string{"app": "manifest"},
+			},
+			expected: &types.LabelSelector{
+				MatchLabels: &map[string]string{"app": "manifest"},
+			},
+		},
+		"manifest-empty-match-labels": {
+			manifestSelector: types.LabelSelector{
+				MatchLabels: &map[string]string{},
+			},
+			expected: &types.LabelSelector{
+				MatchLabels: &map[string]string{},
+			},
+		},
+		"manifest-empty-match-expressions": {
+			manifestSelector: types.LabelSelector{
+				MatchExpressions: &[]metav1.LabelSelectorRequirement{},
+			},
+			expected: &types.LabelSelector{
+				MatchExpressions: &[]metav1.LabelSelectorRequirement{},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p := Plugin{}
+			p.PolicyDefaults.Namespace = "my-policies"
+			p.PolicyDefaults.ObjectSelector = defaultSelector
+			policyConf := types.PolicyConfig{
+				Name: "policy-app-config", Manifests: []types.Manifest{
+					{Path: path.Join(tmpDir, "configmap.yaml")},
+				},
+			}
+			policyConf.ObjectSelector = test.policySelector
+			policyConf.Manifests[0].ObjectSelector = test.manifestSelector
+
+			p.Policies = append(p.Policies, policyConf)
+			// Disable consolidateManifests so that the manifest level objectSelector is used
+			p.applyDefaults(map[string]interface{}{
+				"policyDefaults": map[string]interface{}{"consolidateManifests": false},
+			})
+
+			err := p.createPolicy(&p.Policies[0])
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			output := p.outputBuffer.Bytes()
+			policyManifests, err := unmarshalManifestBytes(output)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			//nolint:forcetypeassert
+			spec := policyManifests[0]["spec"].(map[string]interface{})
+			policyTemplates := spec["policy-templates"].([]interface{})
+			//nolint:forcetypeassert
+			configPolicy := policyTemplates[0].(map[string]interface{})["objectDefinition"].(map[string]interface{})
+			//nolint:forcetypeassert
+			configPolicyOptions := configPolicy["spec"].(map[string]interface{})
+			assertEqual(t, configPolicyOptions["objectSelector"], nil)
+
+			// The objectSelector is set on the object templates rather than the ConfigurationPolicy spec
+			//nolint:forcetypeassert
+			objTemplate := configPolicyOptions["object-templates"].([]interface{})[0].(map[string]interface{})
+
+			actual, err := yaml.Marshal(objTemplate["objectSelector"])
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			expected, err := yaml.Marshal(test.expected)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			assertEqualYaml(t, actual, expected)
+		})
+	}
+}
+
+func TestGenerateNonDNSPolicyName(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := []struct {
+		name       string
+		policyName string
+	}{
+		{
+			name:       "capitalized",
+			policyName: "policy-APP-CONFIG",
+		},
+		{
+			name:       "invalid character",
+			policyName: "policy_app_config",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := Plugin{}
+			var err error
+
+			p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			p.PlacementBindingDefaults.Name = "my-placement-binding"
+			p.PolicyDefaults.Placement.Name = "my-placement-rule"
+			p.PolicyDefaults.Namespace = "my-policies"
+			policyConf := types.PolicyConfig{
+				Name: test.policyName,
+				Manifests: []types.Manifest{
+					{Path: path.Join(tmpDir, "configmap.yaml")},
+				},
+			}
+			basePolicies := p.Policies
+			p.Policies = append(basePolicies, policyConf)
+			p.applyDefaults(map[string]interface{}{})
+
+			err = p.assertValidConfig()
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			expected := fmt.Sprintf(
+				"policy name `%s` is not DNS compliant. See "+
+					"https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#dns-subdomain-names",
+				test.policyName,
+			)
+			assertEqual(t, err.Error(), expected)
+		})
+	}
+}
+
+func TestGenerateNonDNSPlacementName(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := []struct {
+		name          string
+		placementName string
+	}{
+		{
+			name:          "capitalized",
+			placementName: "my-placement-RULE",
+		},
+		{
+			name:          "invalid character",
+			placementName: "my-placement?rule",
+		},
+		{
+			name: "too many characters",
+			placementName: "placementplacementplacementplacementplacementplacementplacementplacementplacement" +
+				"placementplacementplacementplacementplacementplacementplacementplacementplacementplacement" +
+				"placementplacementplacementplacementplacementplacementplacementplacementplacementrule",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := Plugin{}
+			var err error
+
+			p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			p.PlacementBindingDefaults.Name = "my-placement-binding"
+			p.PolicyDefaults.Placement.Name = test.placementName
+			p.PolicyDefaults.Namespace = "my-policies"
+			policyConf := types.PolicyConfig{
+				Name: "policy-app-config",
+				Manifests: []types.Manifest{
+					{Path: path.Join(tmpDir, "configmap.yaml")},
+				},
+			}
+			p.Policies = append(p.Policies, policyConf)
+			p.applyDefaults(map[string]interface{}{})
+
+			err = p.assertValidConfig()
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			expected := fmt.Sprintf(
+				"policyDefaults placement.name `%s` is not DNS compliant. See "+
+					"https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#dns-subdomain-names",
+				test.placementName,
+			)
+			assertEqual(t, err.Error(), expected)
+		})
+	}
+}
+
+func TestGenerateNonDNSBindingName(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := []struct {
+		name        string
+		bindingName string
+	}{
+		{
+			name:        "capitalized",
+			bindingName: "my-placement-BINDING",
+		},
+		{
+			name:        "invalid character",
+			bindingName: "my-placement?binding",
+		},
+		{
+			name: "too many characters",
+			bindingName: "placementplacementplacementplacementplacementplacementplacementplacementplacement" +
+				"placementplacementplacementplacementplacementplacementplacementplacementplacementplacement" +
+				"placementplacementplacementplacementplacementplacementplacementplacementplacementbinding",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := Plugin{}
+			var err error
+
+			p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			p.PlacementBindingDefaults.Name = test.bindingName
+			p.PolicyDefaults.Placement.Name = "my-placement-rule"
+			p.PolicyDefaults.Namespace = "my-policies"
+			policyConf := types.PolicyConfig{
+				Name: "policy-app-config",
+				Manifests: []types.Manifest{
+					{Path: path.Join(tmpDir, "configmap.yaml")},
+				},
+			}
+			policyConf2 := types.PolicyConfig{
+				Name: "policy-app-config2",
+				Manifests: []types.Manifest{
+					{Path: path.Join(tmpDir, "configmap.yaml")},
+				},
+			}
+			p.Policies = append(p.Policies, policyConf, policyConf2)
+			p.applyDefaults(map[string]interface{}{})
+
+			err = p.assertValidConfig()
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			expected := fmt.Sprintf(
+				"PlacementBindingDefaults.Name `%s` is not DNS compliant. See "+
+					"https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#dns-subdomain-names",
+				test.bindingName,
+			)
+			assertEqual(t, err.Error(), expected)
+		})
+	}
+}
+
+func TestCreatePlacementRuleFromMatchExpressions(t *testing.T) {
+	t.Parallel()
+
+	p := Plugin{}
+	p.usingPlR = true
+	p.allPlcs = map[string]bool{}
+	p.csToPlc = map[string]string{}
+	p.PolicyDefaults.Namespace = "my-policies"
+	policyConf := types.PolicyConfig{Name: "policy-app-config"}
+	me := map[string]interface{}{
+		"key":      "cloud",
+		"operator": "In",
+		"values": []string{
+			"red hat",
+			"test",
+		},
+	}
+	policyConf.Placement.ClusterSelectors = map[string]interface{}{
+		"matchExpressions": []interface{}{me},
+	}
+
+	name, err := p.createPolicyPlacement(policyConf.Placement, policyConf.Name)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, name, "placement-policy-app-config")
+
+	output := p.outputBuffer.String()
+	expected := `
+---
+apiVersion: apps.open-cluster-management.io/v1
+kind: PlacementRule
+metadata:
+    name: placement-policy-app-config
+    namespace: my-policies
+spec:
+    clusterSelector:
+        matchExpressions:
+            - key: cloud
+              operator: In
+              values:
+                - red hat
+                - test
+`
+	expected = strings.TrimPrefix(expected, "\n")
+	assertEqual(t, output, expected)
+}
+
+func TestCreatePlacementRuleWithClusterSelector(t *testing.T) {
+	t.Parallel()
+
+	p := Plugin{}
+	p.usingPlR = true
+	p.allPlcs = map[string]bool{}
+	p.csToPlc = map[string]string{}
+	p.PolicyDefaults.Namespace = "my-policies"
+	policyConf := types.PolicyConfig{Name: "policy-app-config"}
+	me := map[string]interface{}{
+		"key":      "cloud",
+		"operator": "In",
+		"values": []string{
+			"red hat",
+			"test",
+		},
+	}
+	policyConf.Placement.ClusterSelector = map[string]interface{}{
+		"matchExpressions": []interface{}{me},
+	}
+
+	name, err := p.createPolicyPlacement(policyConf.Placement, policyConf.Name)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, name, "placement-policy-app-config")
+
+	output := p.outputBuffer.String()
+	expected := `
+---
+apiVersion: apps.open-cluster-management.io/v1
+kind: PlacementRule
+metadata:
+    name: placement-policy-app-config
+    namespace: my-policies
+spec:
+    clusterSelector:
+        matchExpressions:
+            - key: cloud
+              operator: In
+              values:
+                - red hat
+                - test
+`
+	expected = strings.TrimPrefix(expected, "\n")
+	assertEqual(t, output, expected)
+}
+
+func TestCreatePlacementFromMatchLabels(t *testing.T) {
+	t.Parallel()
+
+	p := Plugin{}
+	p.allPlcs = map[string]bool{}
+	p.csToPlc = map[string]string{}
+	p.PolicyDefaults.Namespace = "my-policies"
+	policyConf := types.PolicyConfig{Name: "policy-app-config"}
+	ml := map[string]interface{}{
+		"cloud": "red hat",
+	}
+	policyConf.Placement.ClusterSelectors = map[string]interface{}{
+		"matchLabels": ml,
+	}
+
+	name, err := p.createPolicyPlacement(policyConf.Placement, policyConf.Name)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, name, "placement-policy-app-config")
+
+	output := p.outputBuffer.String()
+	expected := `
+---
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
+metadata:
+    name: placement-policy-app-config
+    namespace: my-policies
+spec:
+    predicates:
+        - requiredClusterSelector:
+            labelSelector:
+                matchLabels:
+                    cloud: red hat
+    tolerations:
+        - key: cluster.open-cluster-management.io/unavailable
+          operator: Exists
+        - key: cluster.open-cluster-management.io/unreachable
+          operator: Exists
+`
+	expected = strings.TrimPrefix(expected, "\n")
+	assertEqual(t, output, expected)
+}
+
+func TestCreatePlacementFromMatchExpressions(t *testing.T) {
+	t.Parallel()
+
+	p := Plugin{}
+	p.allPlcs = map[string]bool{}
+	p.csToPlc = map[string]string{}
+	p.PolicyDefaults.Namespace = "my-policies"
+	policyConf := types.PolicyConfig{Name: "policy-app-config"}
+	me := map[string]interface{}{
+		"key":      "cloud",
+		"operator": "In",
+		"values": []string{
+			"red hat",
+			"test",
+		},
+	}
+	policyConf.Placement.LabelSelector = map[string]interface{}{
+		"matchExpressions": []interface{}{me},
+	}
+
+	name, err := p.createPolicyPlacement(policyConf.Placement, policyConf.Name)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, name, "placement-policy-app-config")
+
+	output := p.outputBuffer.String()
+	expected := `
+---
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
+metadata:
+    name: placement-policy-app-config
+    namespace: my-policies
+spec:
+    predicates:
+        - requiredClusterSelector:
+            labelSelector:
+                matchExpressions:
+                    - key: cloud
+                      operator: In
+                      values:
+                        - red hat
+                        - test
+    tolerations:
+        - key: cluster.open-cluster-management.io/unavailable
+          operator: Exists
+        - key: cluster.open-cluster-management.io/unreachable
+          operator: Exists
+`
+	expected = strings.TrimPrefix(expected, "\n")
+	assertEqual(t, output, expected)
+}
+
+func TestCreatePlacementInvalidMatchExpressions(t *testing.T) {
+	t.Parallel()
+
+	p := Plugin{}
+	p.allPlcs = map[string]bool{}
+	p.csToPlc = map[string]string{}
+	p.PolicyDefaults.Namespace = "my-policies"
+	policyConf := types.PolicyConfig{Name: "policy-app-config"}
+	nestedMap := map[string]interface{}{
+		"test": "invalid",
+	}
+	me := map[string]interface{}{
+		"key":      "cloud",
+		"operator": "In",
+		"values": []interface{}{
+			"red hat",
+			nestedMap,
+		},
+	}
+	policyConf.Placement.LabelSelector = map[string]interface{}{
+		"matchExpressions": []interface{}{me},
+	}
+
+	_, err := p.createPolicyPlacement(policyConf.Placement, policyConf.Name)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := "the input is not a valid label selector or key-value label matching map"
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestCreatePlacementMultipleSelectors(t *testing.T) {
+	t.Parallel()
+
+	p := Plugin{}
+	p.allPlcs = map[string]bool{}
+	p.csToPlc = map[string]string{}
+	p.PolicyDefaults.Namespace = "my-policies"
+	policyConf := types.PolicyConfig{Name: "policy-app-config"}
+	me := map[string]interface{}{
+		"key":      "cloud",
+		"operator": "In",
+		"values": []string{
+			"red hat",
+		},
+	}
+	ml := map[string]interface{}{
+		"cloud": "red hat",
+	}
+	policyConf.Placement.LabelSelector = map[string]interface{}{
+		"matchExpressions": []interface{}{me},
+		"matchLabels":      ml,
+	}
+
+	_, err := p.createPolicyPlacement(policyConf.Placement, policyConf.Name)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output := p.outputBuffer.String()
+	expected := `
+---
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
+metadata:
+    name: placement-policy-app-config
+    namespace: my-policies
+spec:
+    predicates:
+        - requiredClusterSelector:
+            labelSelector:
+                matchExpressions:
+                    - key: cloud
+                      operator: In
+                      values:
+                        - red hat
+                matchLabels:
+                    cloud: red hat
+    tolerations:
+        - key: cluster.open-cluster-management.io/unavailable
+          operator: Exists
+        - key: cluster.open-cluster-management.io/unreachable
+          operator: Exists
+`
+	expected = strings.TrimPrefix(expected, "\n")
+	assertEqual(t, output, expected)
+}
+
+func TestCreatePolicyWithCopyPolicyMetadata(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	bTrue := true
+	bFalse := false
+
+	tests := []struct {
+		name               string
+		copyPolicyMetadata *bool
+		expected           *bool
+	}{
+		{name: "unset", copyPolicyMetadata: nil, expected: nil},
+		{name: "true", copyPolicyMetadata: &bTrue, expected: nil},
+		{name: "false", copyPolicyMetadata: &bFalse, expected: &bFalse},
+	}
+
+	for _, mode := range []string{"policyDefault", "policy"} {
+		mode := mode
+
+		for _, test := range tests {
+			test := test
+			t.Run(mode+" "+test.name, func(t *testing.T) {
+				t.Parallel()
+
+				p := Plugin{}
+				p.PolicyDefaults.Namespace = "my-policies"
+				policyConf := types.PolicyConfig{
+					Name: "policy-app-config", Manifests: []types.Manifest{
+						{Path: path.Join(tmpDir, "configmap.yaml")},
+					},
+				}
+
+				policyDefaultsUnmarshaled := map[string]interface{}{}
+				policyUnmarshaled := map[string]interface{}{}
+
+				if test.copyPolicyMetadata != nil {
+					if mode == "policyDefault" {
+						policyDefaultsUnmarshaled["copyPolicyMetadata"] = *test.copyPolicyMetadata
+					} else if mode == "policy" {
+						policyUnmarshaled["copyPolicyMetadata"] = *test.copyPolicyMetadata
+					}
+				}
+
+				p.Policies = append(p.Policies, policyConf)
+				p.applyDefaults(
+					map[string]interface{}{
+						"policyDefaults": policyDefaultsUnmarshaled,
+						"policies":       []interface{}{policyUnmarshaled},
+					},
+				)
+
+				err := p.createPolicy(&p.Policies[0])
+				if err != nil {
+					t.Fatal(err.Error())
+				}
+
+				output := p.outputBuffer.Bytes()
+				policyManifests, err := unmarshalManifestBytes(output)
+				if err != nil {
+					t.Fatal(err.Error())
+				}
+
+				//nolint:forcetypeassert
+				spec := policyManifests[0]["spec"].(map[string]interface{})
+
+				if test.expected == nil {
+					if _, set := spec["copyPolicyMetadata"]; set {
+						t.Fatal("Expected the policy's spec.copyPolicyMetadata to be unset")
+					}
+				} else {
+					assertEqual(t, spec["copyPolicyMetadata"], *test.expected)
+				}
+			})
+		}
+	}
+}
+
+func TestCreatePolicyWithContentChecksumAnnotation(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	getContentHash := func(patches []map[string]interface{}) string {
+		t.Helper()
+
+		p := Plugin{}
+		p.PolicyDefaults.Namespace = "my-policies"
+		p.PolicyDefaults.ContentChecksumAnnotation = true
+		policyConf := types.PolicyConfig{
+			Name: "policy-app-config",
+			Manifests: []types.Manifest{
+				{Path: path.Join(tmpDir, "configmap.yaml"), Patches: patches},
+			},
+		}
+		p.Policies = append(p.Policies, policyConf)
+		p.applyDefaults(map[string]interface{}{})
+
+		err := p.createPolicy(&p.Policies[0])
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		policyManifests, err := unmarshalManifestBytes(p.outputBuffer.Bytes())
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		hash, _, _ := unstructured.NestedString(
+			policyManifests[0], "metadata", "annotations", contentHashAnnotation,
+		)
+		if hash == "" {
+			t.Fatalf("Expected the %s annotation to be set", contentHashAnnotation)
+		}
+
+		return hash
+	}
+
+	hash1 := getContentHash(nil)
+	hash2 := getContentHash(nil)
+	assertEqual(t, hash1, hash2)
+
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{"chandler": "bing"},
+		},
+	}
+
+	patchedHash := getContentHash([]map[string]interface{}{patch})
+	if patchedHash == hash1 {
+		t.Fatal("Expected the content hash to change when a patch is applied")
+	}
+}
+
+func TestCreatePolicyWithoutContentChecksumAnnotation(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	p := Plugin{}
+	p.PolicyDefaults.Namespace = "my-policies"
+	policyConf := types.PolicyConfig{
+		Name: "policy-app-config",
+		Manifests: []types.Manifest{
+			{Path: path.Join(tmpDir, "configmap.yaml")},
+		},
+	}
+	p.Policies = append(p.Policies, policyConf)
+	p.applyDefaults(map[string]interface{}{})
+
+	err := p.createPolicy(&p.Policies[0])
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if strings.Contains(p.outputBuffer.String(), contentHashAnnotation) {
+		t.Fatalf("Expected the %s annotation to not be set", contentHashAnnotation)
+	}
+}
+
+func TestGenerateWithTimestampAnnotation(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	p := Plugin{}
+	p.PolicyDefaults.Namespace = "my-policies"
+	p.PolicyDefaults.TimestampAnnotation = true
+
+	for _, name := range []string{"policy-app-config", "policy-app-config2"} {
+		p.Policies = append(p.Policies, types.PolicyConfig{
+			Name: name,
+			Manifests: []types.Manifest{
+				{Path: path.Join(tmpDir, "configmap.yaml")},
+			},
+		})
+	}
+
+	p.applyDefaults(map[string]interface{}{})
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	manifests, err := unmarshalManifestBytes(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	timestamps := []string{}
+
+	for _, manifest := range manifests {
+		if manifest["kind"] != policyKind {
+			continue
+		}
+
+		timestamp, _, _ := unstructured.NestedString(manifest, "metadata", "annotations", generatedAtAnnotation)
+
+		_, err := time.Parse(time.RFC3339, timestamp)
+		if err != nil {
+			t.Fatalf("Expected the %s annotation to be an RFC3339 timestamp, got: %v", generatedAtAnnotation, err)
+		}
+
+		timestamps = append(timestamps, timestamp)
+	}
+
+	assertEqual(t, len(timestamps), 2)
+	assertEqual(t, timestamps[0], timestamps[1])
+}
+
+func TestCreatePolicyWithoutTimestampAnnotation(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	p := Plugin{}
+	p.PolicyDefaults.Namespace = "my-policies"
+	policyConf := types.PolicyConfig{
+		Name: "policy-app-config",
+		Manifests: []types.Manifest{
+			{Path: path.Join(tmpDir, "configmap.yaml")},
+		},
+	}
+	p.Policies = append(p.Policies, policyConf)
+	p.applyDefaults(map[string]interface{}{})
+
+	err := p.createPolicy(&p.Policies[0])
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if strings.Contains(p.outputBuffer.String(), generatedAtAnnotation) {
+		t.Fatalf("Expected the %s annotation to not be set", generatedAtAnnotation)
+	}
+}
+
+func TestGenerateWithDescriptionTemplate(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	p := Plugin{}
+	var err error
+
+	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p.PolicyDefaults.Namespace = "my-policies"
+	p.PolicyDefaults.DescriptionTemplate = `{{ .Name }} enforces {{ join .Controls ", " }} of ` +
+		`{{ join .Standards ", " }} ({{ index .Categories 0 }})`
+
+	p.Policies = append(p.Policies,
+		types.PolicyConfig{
+			Name: "policy-app-config",
+			PolicyOptions: types.PolicyOptions{
+				Controls:  []string{"CM-2 Baseline Configuration", "CM-6 Configuration Settings"},
+				Standards: []string{"NIST SP 800-53"},
+			},
+			Manifests: []types.Manifest{
+				{Path: path.Join(tmpDir, "configmap.yaml")},
+			},
+		},
+		types.PolicyConfig{
+			Name:          "policy-app-config2",
+			PolicyOptions: types.PolicyOptions{Description: "An explicit description"},
+			Manifests: []types.Manifest{
+				{Path: path.Join(tmpDir, "configmap.yaml")},
+			},
+		},
+	)
+
+	p.applyDefaults(map[string]interface{}{})
+
+	err = p.assertValidConfig()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	manifests, err := unmarshalManifestBytes(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	descriptions := []string{}
+
+	for _, manifest := range manifests {
+		if manifest["kind"] != policyKind {
+			continue
+		}
+
+		description, _, _ := unstructured.NestedString(
+			manifest, "metadata", "annotations", "policy.open-cluster-management.io/description",
+		)
+		descriptions = append(descriptions, description)
+	}
+
+	expected := []string{
+		"policy-app-config enforces CM-2 Baseline Configuration, CM-6 Configuration Settings of NIST SP 800-53 " +
+			"(CM Configuration Management)",
+		"An explicit description",
+	}
+	assertReflectEqual(t, descriptions, expected)
+}
+
+func TestConfigInvalidDescriptionTemplate(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	p := Plugin{}
+	p.PolicyDefaults.Namespace = "my-policies"
+	p.PolicyDefaults.DescriptionTemplate = "{{ .Name "
+	p.Policies = append(p.Policies, types.PolicyConfig{
+		Name: "policy-app-config",
+		Manifests: []types.Manifest{
+			{Path: path.Join(tmpDir, "configmap.yaml")},
+		},
+	})
+	p.applyDefaults(map[string]interface{}{})
+
+	err := p.assertValidConfig()
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := "failed to parse the policyDefaults.descriptionTemplate: template: description:1: unclosed action"
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestCreatePolicyAlwaysEmitPruneBehavior(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		alwaysEmit          bool
+		pruneObjectBehavior string
+		expected            string
+	}{
+		"unset":                         {expected: ""},
+		"unset and always emit":         {alwaysEmit: true, expected: "None"},
+		"set":                           {pruneObjectBehavior: "DeleteAll", expected: "DeleteAll"},
+		"set and always emit":           {alwaysEmit: true, pruneObjectBehavior: "DeleteAll", expected: "DeleteAll"},
+		"set to None and always emit":   {alwaysEmit: true, pruneObjectBehavior: "None", expected: "None"},
+		"set to None without emit flag": {pruneObjectBehavior: "None", expected: "None"},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p := Plugin{}
+			p.PolicyDefaults.Namespace = "my-policies"
+			p.PolicyDefaults.AlwaysEmitPruneBehavior = test.alwaysEmit
+			p.PolicyDefaults.PruneObjectBehavior = test.pruneObjectBehavior
+			policyConf := types.PolicyConfig{
+				Name: "policy-app-config",
+				Manifests: []types.Manifest{
+					{Path: path.Join(tmpDir, "configmap.yaml")},
+				},
+			}
+			p.Policies = append(p.Policies, policyConf)
+			p.applyDefaults(map[string]interface{}{})
+
+			err := p.createPolicy(&p.Policies[0])
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			policyManifests, err := unmarshalManifestBytes(p.outputBuffer.Bytes())
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			policyTemplates, _, _ := unstructured.NestedSlice(policyManifests[0], "spec", "policy-templates")
+			//nolint:forcetypeassert
+			pruneObjectBehavior, _, _ := unstructured.NestedString(
+				policyTemplates[0].(map[string]interface{}), "objectDefinition", "spec", "pruneObjectBehavior",
+			)
+
+			assertEqual(t, pruneObjectBehavior, test.expected)
+		})
+	}
+}
+
+func TestCreatePolicyRootRemediationAction(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		rootRemediationAction string
+		clearTemplates        bool
+		expectedRoot          string
+		expectedTemplates     []string
+	}{
+		"unset with mixed templates": {
+			expectedRoot:      "",
+			expectedTemplates: []string{"inform", "enforce"},
+		},
+		"forced with mixed templates": {
+			rootRemediationAction: "enforce",
+			expectedRoot:          "enforce",
+			expectedTemplates:     []string{"inform", "enforce"},
+		},
+		"forced with cleared templates": {
+			rootRemediationAction: "inform",
+			clearTemplates:        true,
+			expectedRoot:          "inform",
+			expectedTemplates:     []string{"", ""},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p := Plugin{}
+			p.PolicyDefaults.Namespace = "my-policies"
+			policyConf := types.PolicyConfig{
+				Name:                           "policy-app-config",
+				RootRemediationAction:          test.rootRemediationAction,
+				ClearTemplateRemediationAction: test.clearTemplates,
+				Manifests: []types.Manifest{
+					{
+						Path: path.Join(tmpDir, "configmap.yaml"),
+						ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
+							RemediationAction: "inform",
+						},
+					},
+					{
+						Path: path.Join(tmpDir, "configmap.yaml"),
+						ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
+							RemediationAction: "enforce",
+						},
+					},
+				},
+			}
+			p.Policies = append(p.Policies, policyConf)
+			p.applyDefaults(map[string]interface{}{})
+			// Generate a ConfigurationPolicy per manifest so that the remediation actions differ
+			p.Policies[0].ConsolidateManifests = false
+
+			err := p.createPolicy(&p.Policies[0])
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			policyManifests, err := unmarshalManifestBytes(p.outputBuffer.Bytes())
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			rootRemediationAction, _, _ := unstructured.NestedString(
+				policyManifests[0], "spec", "remediationAction",
+			)
+			assertEqual(t, rootRemediationAction, test.expectedRoot)
+
+			policyTemplates, _, _ := unstructured.NestedSlice(policyManifests[0], "spec", "policy-templates")
+			templateRemediationActions := []string{}
+
+			for _, policyTemplate := range policyTemplates {
+				//nolint:forcetypeassert
+				remediationAction, _, _ := unstructured.NestedString(
+					policyTemplate.(map[string]interface{}), "objectDefinition", "spec", "remediationAction",
+				)
+				templateRemediationActions = append(templateRemediationActions, remediationAction)
+			}
+
+			assertReflectEqual(t, templateRemediationActions, test.expectedTemplates)
+		})
+	}
+}
+
+func TestGenerateWithCommonMetadata(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	p := Plugin{}
+	p.PolicyDefaults.Namespace = "my-policies"
+	p.PolicyDefaults.CommonLabels = map[string]string{"app": "common", "team": "platform"}
+	p.PolicyDefaults.CommonAnnotations = map[string]string{
+		"argocd.argoproj.io/sync-wave":                "1",
+		"policy.open-cluster-management.io/standards": "common",
+	}
+	p.PolicyDefaults.Placement.LabelSelector = map[string]interface{}{"cloud": "red hat"}
+	policyConf := types.PolicyConfig{
+		Name: "policy-app-config",
+		Manifests: []types.Manifest{
+			{Path: path.Join(tmpDir, "configmap.yaml")},
+		},
+		PolicyOptions: types.PolicyOptions{
+			PolicyLabels: map[string]string{"app": "policy"},
+			PolicySets:   []string{"my-policyset"},
+		},
+	}
+	p.Policies = append(p.Policies, policyConf)
+	p.applyDefaults(map[string]interface{}{})
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	manifests, err := unmarshalManifestBytes(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	kinds := map[string]bool{}
+
+	for _, manifest := range manifests {
+		kind, _, _ := unstructured.NestedString(manifest, "kind")
+		kinds[kind] = true
+
+		labels, _, _ := unstructured.NestedStringMap(manifest, "metadata", "labels")
+		annotations, _, _ := unstructured.NestedStringMap(manifest, "metadata", "annotations")
+
+		assertEqual(t, labels["team"], "platform")
+		assertEqual(t, annotations["argocd.argoproj.io/sync-wave"], "1")
+
+		if kind == policyKind {
+			// Object specific and generator required metadata take precedence
+			assertEqual(t, labels["app"], "policy")
+			assertEqual(t, annotations["policy.open-cluster-management.io/standards"], "NIST SP 800-53")
+		} else {
+			assertEqual(t, labels["app"], "common")
+		}
+	}
+
+	assertEqual(t, kinds, map[string]bool{
+		policyKind: true, policySetKind: true, placementKind: true, placementBindingKind: true,
+	})
+}
+
+func TestCreatePolicyWithCustomMessage(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	createConfigMap(t, tmpDir, "configmap2.yaml")
+
+	p := Plugin{}
+	var err error
+
+	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	p.PolicyDefaults.Namespace = "my-policies"
+	p.PolicyDefaults.CustomMessage = types.CustomMessage{
+		Compliant:    "{{ default }}",
+		NonCompliant: "{{ default }}",
+	}
+
+	policyConf := types.PolicyConfig{
+		Name: "policy-app-config",
+		PolicyOptions: types.PolicyOptions{
+			ConsolidateManifests: false,
+		},
+		Manifests: []types.Manifest{
+			{
+				Path: path.Join(tmpDir, "configmap.yaml"),
+			},
+			{
+				Path: path.Join(tmpDir, "configmap2.yaml"),
+			},
+		},
+	}
+	p.Policies = append(p.Policies, policyConf)
+
+	// Ensure values are correctly propagated/overridden
+	p.applyDefaults(map[string]interface{}{})
+	assertEqual(t, policyConf.Manifests[0].ConfigurationPolicyOptions.CustomMessage.Compliant, "{{ default }}")
+	assertEqual(t, policyConf.Manifests[0].ConfigurationPolicyOptions.CustomMessage.NonCompliant, "{{ default }}")
+	assertEqual(t, policyConf.Manifests[1].ConfigurationPolicyOptions.CustomMessage.Compliant, "{{ default }}")
+	assertEqual(t, policyConf.Manifests[1].ConfigurationPolicyOptions.CustomMessage.NonCompliant, "{{ default }}")
+
+	// With consolidateManifest = false
+	policyConf.ConfigurationPolicyOptions = types.ConfigurationPolicyOptions{
+		CustomMessage: types.CustomMessage{
+			Compliant:    "{{ root }}",
+			NonCompliant: "{{ root }}",
+		},
+	}
+	policyConf.Manifests[0].ConfigurationPolicyOptions = types.ConfigurationPolicyOptions{
+		CustomMessage: types.CustomMessage{
+			Compliant:    "{{ manifest1 }}",
+			NonCompliant: "{{ manifest1 }}",
+		},
+	}
+	policyConf.Manifests[1].ConfigurationPolicyOptions = types.ConfigurationPolicyOptions{
+		CustomMessage: types.CustomMessage{
+			Compliant:    "{{ manifest2 }}",
+			NonCompliant: "{{ manifest2 }}",
+		},
+	}
+
+	p.applyDefaults(map[string]interface{}{})
+
+	err = p.createPolicy(&policyConf)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output := p.outputBuffer.String()
+	expected := `
+---
+apiVersion: policy.open-cluster-management.io/v1
+kind: Policy
+metadata:
+    annotations:
+        policy.open-cluster-management.io/categories: ""
+        policy.open-cluster-management.io/controls: ""
+        policy.open-cluster-management.io/description: ""
+        policy.open-cluster-management.io/standards: ""
+    name: policy-app-config
+    namespace: my-policies
+spec:
+    copyPolicyMetadata: false
+    disabled: false
+    policy-templates:
+        - objectDefinition:
+            apiVersion: policy.open-cluster-management.io/v1
+            kind: ConfigurationPolicy
+            metadata:
+                name: policy-app-config
+            spec:
+                customMessage:
+                    compliant: '{{ manifest1 }}'
+                    noncompliant: '{{ manifest1 }}'
+                object-templates:
+                    - complianceType: musthave
+                      objectDefinition:
+                        apiVersion: v1
+                        data:
+                            game.properties: enemies=potato
+                        kind: ConfigMap
+                        metadata:
+                            name: my-configmap
+                remediationAction: inform
+                severity: low
+        - objectDefinition:
+            apiVersion: policy.open-cluster-management.io/v1
+            kind: ConfigurationPolicy
+            metadata:
+                name: policy-app-config2
+            spec:
+                customMessage:
+                    compliant: '{{ manifest2 }}'
+                    noncompliant: '{{ manifest2 }}'
+                object-templates:
+                    - complianceType: musthave
+                      objectDefinition:
+                        apiVersion: v1
+                        data:
+                            game.properties: enemies=potato
+                        kind: ConfigMap
+                        metadata:
+                            name: my-configmap
+                remediationAction: inform
+                severity: low
+    remediationAction: inform
+`
+
+	expected = strings.TrimPrefix(expected, "\n")
+	assertEqual(t, output, expected)
+	p.outputBuffer.Reset()
+
+	// With consolidateManifest = true
+	policyConf.PolicyOptions.ConsolidateManifests = true
+	policyConf.PolicyOptions.AllowDuplicateObjects = true
+	err = p.assertValidConfig()
+	expectedErr := "the policy policy-app-config has the customMessage " +
+		"value set on manifest[0] but consolidateManifests is true"
+	assertEqual(t, err.Error(), expectedErr)
+
+	// Note: customMessage field at the manifest level must be set to
+	// the same value as in the policy level when consolidateManifest = true
+	// to successfully generate a policy. If customMessage field is unset
+	// at the manifest level, applyDefaults() can be used to populate this field
+	// if it's set at the policyDefaults or policy level.
+	policyConf.Manifests[0].ConfigurationPolicyOptions.CustomMessage.Compliant = "{{ root }}"
+	policyConf.Manifests[0].ConfigurationPolicyOptions.CustomMessage.NonCompliant = "{{ root }}"
+	policyConf.Manifests[1].ConfigurationPolicyOptions.CustomMessage.Compliant = "{{ root }}"
+	policyConf.Manifests[1].ConfigurationPolicyOptions.CustomMessage.NonCompliant = "{{ root }}"
+
+	err = p.createPolicy(&policyConf)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output = p.outputBuffer.String()
+	expected = `
+---
+apiVersion: policy.open-cluster-management.io/v1
+kind: Policy
+metadata:
+    annotations:
+        policy.open-cluster-management.io/categories: ""
+        policy.open-cluster-management.io/controls: ""
+        policy.open-cluster-management.io/description: ""
+        policy.open-cluster-management.io/standards: ""
+    name: policy-app-config
+    namespace: my-policies
+spec:
+    copyPolicyMetadata: false
+    disabled: false
+    policy-templates:
+        - objectDefinition:
+            apiVersion: policy.open-cluster-management.io/v1
+            kind: ConfigurationPolicy
+            metadata:
+                name: policy-app-config
+            spec:
+                customMessage:
+                    compliant: '{{ root }}'
+                    noncompliant: '{{ root }}'
+                object-templates:
+                    - complianceType: musthave
+                      objectDefinition:
+                        apiVersion: v1
+                        data:
+                            game.properties: enemies=potato
+                        kind: ConfigMap
+                        metadata:
+                            name: my-configmap
+                    - complianceType: musthave
+                      objectDefinition:
+                        apiVersion: v1
+                        data:
+                            game.properties: enemies=potato
+                        kind: ConfigMap
+                        metadata:
+                            name: my-configmap
+                remediationAction: ""
+                severity: ""
+`
+
+	expected = strings.TrimPrefix(expected, "\n")
+	assertEqual(t, output, expected)
+}
+
+// Test Patching a CR object, "MyCr", containing a list of profile objects.
+// Patching profile interface name and (not profile) recommend
+// - metadata:
+// name: "profile1"
+// spec:
+// recommend:
+// - match:
+//   - nodeLabel: node-role.kubernetes.io/master
+//     priority: 4
+//     listOfStuff: profile1
+//
+// listOfStuff:
+//   - name: "profile1"
+//     interface: "ens5f1"
+//
+// The profile name is used to locate the right profile entry in the patch
+func TestOpenAPIListPatch(t *testing.T) {
+	const (
+		crFilename      = "cr1.yaml"
+		openAPIFilename = "openapi-schema.json"
+	)
+
+	t.Parallel()
+	tmpDir := t.TempDir()
+	// relative path to the CR file
+	crRelativePath := filepath.Join(tmpDir, crFilename)
+	// Relative path to the openAPI schema
+	openAPIRelativePath := filepath.Join(tmpDir, openAPIFilename)
+	// Loading plugin file, CR file, and shema file to memory
+	pluginFileContent, err := os.ReadFile("testdata/OpenAPI/policy-generator1.yaml")
+	assertEqual(t, err, nil)
+	crFileContent, err := os.ReadFile("testdata/OpenAPI/cr-files/" + crFilename)
+	assertEqual(t, err, nil)
+	openAPIFileContent, err := os.ReadFile("testdata/OpenAPI/openapi-schema.json")
+	assertEqual(t, err, nil)
+
+	// Writing CR file and shema file to temporary directory
+	err = os.WriteFile(crRelativePath, crFileContent, 0o666)
+	assertEqual(t, err, nil)
+	err = os.WriteFile(openAPIRelativePath, openAPIFileContent, 0o666)
+	assertEqual(t, err, nil)
+
+	p := Plugin{}
+
+	// Load plugin object from file
+	err = yaml.Unmarshal(pluginFileContent, &p)
+	assertEqual(t, err, nil)
+
+	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// Set CR file path and OpenAPI path with temporary directory
+	p.Policies[0].Manifests[0].Path = crRelativePath
+	p.Policies[0].Manifests[0].OpenAPI.Path = openAPIRelativePath
+
+	// Check configuration
+	if err := p.assertValidConfig(); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := `
+---
+apiVersion: policy.open-cluster-management.io/v1
+kind: Policy
+metadata:
+    annotations:
+        policy.open-cluster-management.io/categories: ""
+        policy.open-cluster-management.io/controls: ""
+        policy.open-cluster-management.io/description: ""
+        policy.open-cluster-management.io/standards: ""
+    name: group-du-sno-latest-config-policy
+    namespace: ztp-group
+spec:
+    copyPolicyMetadata: false
+    disabled: false
+    policy-templates:
+        - objectDefinition:
+            apiVersion: policy.open-cluster-management.io/v1
+            kind: ConfigurationPolicy
+            metadata:
+                name: group-du-sno-latest-config-policy
+            spec:
+                object-templates:
+                    - complianceType: ""
+                      objectDefinition:
+                        apiVersion: myapp.myorg.io/v1
+                        kind: MyCr
+                        metadata:
+                            name: profile1
+                            namespace: mynamespace
+                        spec:
+                            listOfStuff:
+                                - myattribute: ens5f1
+                                  name: profile1
+                remediationAction: ""
+                severity: ""
+`
+	expected = strings.TrimPrefix(expected, "\n")
+	// Generated Policies
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, string(output), expected)
+}
+
+// Test patching unknown fields in the MyCr CR.
+// The "plugins" object is a generic object (part of the profile list) not defined by the schema
+// Patching :
+// LocalHoldoverTimeout: 14400
+// to
+// LocalHoldoverTimeout: 14401
+func TestOpenAPIListUnkownFieldsPatch(t *testing.T) {
+	const (
+		crFilename      = "cr2.yaml"
+		openAPIFilename = "openapi-schema.json"
+	)
+
+	t.Parallel()
+	tmpDir := t.TempDir()
+	// relative path to the CR file
+	crRelativePath := filepath.Join(tmpDir, crFilename)
+	// Relative path to the openAPI schema
+	openAPIRelativePath := filepath.Join(tmpDir, openAPIFilename)
+	// Loading plugin file, CR file, and shema file to memory
+	pluginFileContent, err := os.ReadFile("testdata/OpenAPI/policy-generator2.yaml")
+	assertEqual(t, err, nil)
+	crFileContent, err := os.ReadFile("testdata/OpenAPI/cr-files/" + crFilename)
+	assertEqual(t, err, nil)
+	openAPIFileContent, err := os.ReadFile("testdata/OpenAPI/openapi-schema.json")
+	assertEqual(t, err, nil)
+
+	// Writing CR file and shema file to temporary directory
+	err = os.WriteFile(crRelativePath, crFileContent, 0o666)
+	assertEqual(t, err, nil)
+	err = os.WriteFile(openAPIRelativePath, openAPIFileContent, 0o666)
+	assertEqual(t, err, nil)
+
+	p := Plugin{}
+
+	// Load plugin object from file
+	err = yaml.Unmarshal(pluginFileContent, &p)
+	assertEqual(t, err, nil)
+
+	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// Set CR file path and OpenAPI path with temporary directory
+	p.Policies[0].Manifests[0].Path = crRelativePath
+	p.Policies[0].Manifests[0].OpenAPI.Path = openAPIRelativePath
+
+	// Check configuration
+	if err := p.assertValidConfig(); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := `
+---
+apiVersion: policy.open-cluster-management.io/v1
+kind: Policy
+metadata:
+    annotations:
+        policy.open-cluster-management.io/categories: ""
+        policy.open-cluster-management.io/controls: ""
+        policy.open-cluster-management.io/description: ""
+        policy.open-cluster-management.io/standards: ""
+    name: group-du-sno-v4.14-config-policy
+    namespace: ztp-group
+spec:
+    copyPolicyMetadata: false
+    disabled: false
+    policy-templates:
+        - objectDefinition:
+            apiVersion: policy.open-cluster-management.io/v1
+            kind: ConfigurationPolicy
+            metadata:
+                name: group-du-sno-v4.14-config-policy
+            spec:
+                object-templates:
+                    - complianceType: ""
+                      objectDefinition:
+                        apiVersion: myapp.myorg.io/v1
+                        kind: MyCr
+                        metadata:
+                            name: profile1
+                            namespace: mynamespace
+                        spec:
+                            listOfStuff:
+                                - name: profile1
+                                  plugins:
+                                    e810:
+                                        enableDefaultConfig: false
+                                        pins: $e810_pins
+                                        settings:
+                                            LocalHoldoverTimeout: 14401
+                                            LocalMaxHoldoverOffSet: 1500
+                                            MaxInSpecOffset: 100
+                remediationAction: ""
+                severity: ""
+`
+	expected = strings.TrimPrefix(expected, "\n")
+	// Generated Policies
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
 	}
+
+	assertEqual(t, string(output), expected)
 }
 
-func TestCreatePlacementRuleFromMatchExpressions(t *testing.T) {
+func TestGeneratePerClusterManifest(t *testing.T) {
 	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	clustersPath := path.Join(tmpDir, "clusters.yaml")
+	clustersYAML := `
+cluster1:
+  data:
+    region: east
+cluster2:
+  data:
+    region: west
+`
+
+	err := os.WriteFile(clustersPath, []byte(clustersYAML), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write %s", clustersPath)
+	}
+
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  generatePlacementWhenInSet: true
+policies:
+- name: policy-app-config
+  policySets:
+    - my-policyset
+  manifests:
+    - path: %s
+      perCluster:
+        path: %s
+        generatePlacement: true
+policySetDefaults:
+  generatePolicySetPlacement: false
+`,
+		path.Join(tmpDir, "configmap.yaml"), clustersPath,
+	)
 
 	p := Plugin{}
-	p.usingPlR = true
-	p.allPlcs = map[string]bool{}
-	p.csToPlc = map[string]string{}
-	p.PolicyDefaults.Namespace = "my-policies"
-	policyConf := types.PolicyConfig{Name: "policy-app-config"}
-	me := map[string]interface{}{
-		"key":      "cloud",
-		"operator": "In",
-		"values": []string{
-			"red hat",
-			"test",
-		},
+
+	err = p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
 	}
-	policyConf.Placement.ClusterSelectors = map[string]interface{}{
-		"matchExpressions": []interface{}{me},
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
 	}
 
-	name, err := p.createPolicyPlacement(policyConf.Placement, policyConf.Name)
+	manifests, err := unmarshalManifestBytes(output)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	assertEqual(t, name, "placement-policy-app-config")
+	regions := map[string]string{}
+	placementClusters := map[string]string{}
+	setPolicies := []interface{}{}
 
-	output := p.outputBuffer.String()
-	expected := `
----
-apiVersion: apps.open-cluster-management.io/v1
-kind: PlacementRule
-metadata:
-    name: placement-policy-app-config
-    namespace: my-policies
-spec:
-    clusterSelector:
-        matchExpressions:
-            - key: cloud
-              operator: In
-              values:
-                - red hat
-                - test
-`
-	expected = strings.TrimPrefix(expected, "\n")
-	assertEqual(t, output, expected)
+	for _, manifest := range manifests {
+		name, _, _ := unstructured.NestedString(manifest, "metadata", "name")
+
+		switch manifest["kind"] {
+		case policyKind:
+			templates, _, _ := unstructured.NestedSlice(manifest, "spec", "policy-templates")
+			assertEqual(t, len(templates), 1)
+
+			//nolint:forcetypeassert
+			objTemplates, _, _ := unstructured.NestedSlice(
+				templates[0].(map[string]interface{}), "objectDefinition", "spec", "object-templates",
+			)
+			assertEqual(t, len(objTemplates), 1)
+
+			//nolint:forcetypeassert
+			region, _, _ := unstructured.NestedString(
+				objTemplates[0].(map[string]interface{}), "objectDefinition", "data", "region",
+			)
+			regions[name] = region
+		case placementKind:
+			predicates, _, _ := unstructured.NestedSlice(manifest, "spec", "predicates")
+			//nolint:forcetypeassert
+			expressions, _, _ := unstructured.NestedSlice(
+				predicates[0].(map[string]interface{}), "requiredClusterSelector", "labelSelector", "matchExpressions",
+			)
+			//nolint:forcetypeassert
+			values, _, _ := unstructured.NestedStringSlice(expressions[0].(map[string]interface{}), "values")
+			placementClusters[name] = strings.Join(values, ",")
+		case policySetKind:
+			setPolicies, _, _ = unstructured.NestedSlice(manifest, "spec", "policies")
+		}
+	}
+
+	assertReflectEqual(
+		t, regions, map[string]string{"policy-app-config-cluster1": "east", "policy-app-config-cluster2": "west"},
+	)
+	assertReflectEqual(
+		t,
+		placementClusters,
+		map[string]string{
+			"placement-policy-app-config-cluster1": "cluster1",
+			"placement-policy-app-config-cluster2": "cluster2",
+		},
+	)
+	assertReflectEqual(t, setPolicies, []interface{}{"policy-app-config-cluster1", "policy-app-config-cluster2"})
 }
 
-func TestCreatePlacementRuleWithClusterSelector(t *testing.T) {
+func TestConfigPerClusterManifestInvalidCluster(t *testing.T) {
 	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
 
-	p := Plugin{}
-	p.usingPlR = true
-	p.allPlcs = map[string]bool{}
-	p.csToPlc = map[string]string{}
-	p.PolicyDefaults.Namespace = "my-policies"
-	policyConf := types.PolicyConfig{Name: "policy-app-config"}
-	me := map[string]interface{}{
-		"key":      "cloud",
-		"operator": "In",
-		"values": []string{
-			"red hat",
-			"test",
-		},
-	}
-	policyConf.Placement.ClusterSelector = map[string]interface{}{
-		"matchExpressions": []interface{}{me},
-	}
+	clustersPath := path.Join(tmpDir, "clusters.yaml")
 
-	name, err := p.createPolicyPlacement(policyConf.Placement, policyConf.Name)
+	err := os.WriteFile(clustersPath, []byte("Cluster_1:\n  data:\n    region: east\n"), 0o666)
 	if err != nil {
-		t.Fatal(err.Error())
+		t.Fatalf("Failed to write %s", clustersPath)
 	}
 
-	assertEqual(t, name, "placement-policy-app-config")
-
-	output := p.outputBuffer.String()
-	expected := `
----
-apiVersion: apps.open-cluster-management.io/v1
-kind: PlacementRule
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
 metadata:
-    name: placement-policy-app-config
-    namespace: my-policies
-spec:
-    clusterSelector:
-        matchExpressions:
-            - key: cloud
-              operator: In
-              values:
-                - red hat
-                - test
-`
-	expected = strings.TrimPrefix(expected, "\n")
-	assertEqual(t, output, expected)
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+      perCluster:
+        path: %s
+`,
+		path.Join(tmpDir, "configmap.yaml"), clustersPath,
+	)
+
+	p := Plugin{}
+
+	err = p.Config([]byte(config), tmpDir)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := fmt.Sprintf(
+		"the policy policy-app-config has an invalid manifest[0].perCluster: the cluster name Cluster_1 in the "+
+			"path %s is not a valid cluster name",
+		clustersPath,
+	)
+	assertEqual(t, err.Error(), expected)
 }
 
-func TestCreatePlacementFromMatchLabels(t *testing.T) {
+func TestGeneratePlacementRef(t *testing.T) {
 	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	configMapPath := path.Join(tmpDir, "configmap.yaml")
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+placementBindingDefaults:
+  name: my-placement-binding
+placements:
+  prod:
+    labelSelector:
+      env: prod
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  placement:
+    ref: prod
+  manifests:
+    - path: %s
+- name: policy-app-config2
+  placement:
+    ref: prod
+  manifests:
+    - path: %s
+- name: policy-app-config3
+  manifests:
+    - path: %s
+`,
+		configMapPath, configMapPath, configMapPath,
+	)
 
 	p := Plugin{}
-	p.allPlcs = map[string]bool{}
-	p.csToPlc = map[string]string{}
-	p.PolicyDefaults.Namespace = "my-policies"
-	policyConf := types.PolicyConfig{Name: "policy-app-config"}
-	ml := map[string]interface{}{
-		"cloud": "red hat",
+
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
 	}
-	policyConf.Placement.ClusterSelectors = map[string]interface{}{
-		"matchLabels": ml,
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
 	}
 
-	name, err := p.createPolicyPlacement(policyConf.Placement, policyConf.Name)
+	manifests, err := unmarshalManifestBytes(output)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	assertEqual(t, name, "placement-policy-app-config")
+	placementSelectors := map[string]interface{}{}
+	bindingSubjects := map[string][]string{}
 
-	output := p.outputBuffer.String()
-	expected := `
----
-apiVersion: cluster.open-cluster-management.io/v1beta1
-kind: Placement
-metadata:
-    name: placement-policy-app-config
-    namespace: my-policies
-spec:
-    predicates:
-        - requiredClusterSelector:
-            labelSelector:
-                matchLabels:
-                    cloud: red hat
-    tolerations:
-        - key: cluster.open-cluster-management.io/unavailable
-          operator: Exists
-        - key: cluster.open-cluster-management.io/unreachable
-          operator: Exists
-`
-	expected = strings.TrimPrefix(expected, "\n")
-	assertEqual(t, output, expected)
+	for _, manifest := range manifests {
+		name, _, _ := unstructured.NestedString(manifest, "metadata", "name")
+
+		switch manifest["kind"] {
+		case placementKind:
+			predicates, _, _ := unstructured.NestedSlice(manifest, "spec", "predicates")
+			//nolint:forcetypeassert
+			placementSelectors[name], _, _ = unstructured.NestedFieldCopy(
+				predicates[0].(map[string]interface{}), "requiredClusterSelector", "labelSelector", "matchExpressions",
+			)
+		case placementBindingKind:
+			placementName, _, _ := unstructured.NestedString(manifest, "placementRef", "name")
+			subjects, _, _ := unstructured.NestedSlice(manifest, "subjects")
+
+			for _, subject := range subjects {
+				//nolint:forcetypeassert
+				bindingSubjects[placementName] = append(
+					bindingSubjects[placementName], subject.(map[string]interface{})["name"].(string),
+				)
+			}
+		}
+	}
+
+	assertReflectEqual(t, placementSelectors, map[string]interface{}{
+		"placement-prod": []interface{}{
+			map[string]interface{}{"key": "env", "operator": "In", "values": []interface{}{"prod"}},
+		},
+		"placement-policy-app-config3": []interface{}{},
+	})
+	assertReflectEqual(t, bindingSubjects, map[string][]string{
+		"placement-prod":               {"policy-app-config", "policy-app-config2"},
+		"placement-policy-app-config3": {"policy-app-config3"},
+	})
 }
 
-func TestCreatePlacementFromMatchExpressions(t *testing.T) {
+func TestGenerateDeriveLabelsFrom(t *testing.T) {
 	t.Parallel()
+	tmpDir := t.TempDir()
+	manifestPath := path.Join(tmpDir, "configmap.yaml")
+	manifestYAML := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-configmap
+  namespace: my-app
+  labels:
+    team: my-team
+    app.kubernetes.io/name: my-app
+data:
+  game.properties: enemies=potato
+`
+
+	err := os.WriteFile(manifestPath, []byte(manifestYAML), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write %s", manifestPath)
+	}
+
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  deriveLabelsFrom:
+    - metadata.labels.team
+    - metadata.namespace
+    - metadata.labels.missing
+    - metadata.labels[app.kubernetes.io/name]
+    - data
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+- name: policy-app-config2
+  policyLabels:
+    team: other-team
+  manifests:
+    - path: %s
+`,
+		manifestPath, manifestPath,
+	)
 
 	p := Plugin{}
-	p.allPlcs = map[string]bool{}
-	p.csToPlc = map[string]string{}
-	p.PolicyDefaults.Namespace = "my-policies"
-	policyConf := types.PolicyConfig{Name: "policy-app-config"}
-	me := map[string]interface{}{
-		"key":      "cloud",
-		"operator": "In",
-		"values": []string{
-			"red hat",
-			"test",
-		},
-	}
-	policyConf.Placement.LabelSelector = map[string]interface{}{
-		"matchExpressions": []interface{}{me},
+
+	err = p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
 	}
 
-	name, err := p.createPolicyPlacement(policyConf.Placement, policyConf.Name)
+	output, err := p.Generate()
 	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	assertEqual(t, name, "placement-policy-app-config")
+	manifests, err := unmarshalManifestBytes(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
 
-	output := p.outputBuffer.String()
-	expected := `
----
-apiVersion: cluster.open-cluster-management.io/v1beta1
-kind: Placement
-metadata:
-    name: placement-policy-app-config
-    namespace: my-policies
-spec:
-    predicates:
-        - requiredClusterSelector:
-            labelSelector:
-                matchExpressions:
-                    - key: cloud
-                      operator: In
-                      values:
-                        - red hat
-                        - test
-    tolerations:
-        - key: cluster.open-cluster-management.io/unavailable
-          operator: Exists
-        - key: cluster.open-cluster-management.io/unreachable
-          operator: Exists
-`
-	expected = strings.TrimPrefix(expected, "\n")
-	assertEqual(t, output, expected)
-}
+	policyLabels := map[string]map[string]string{}
 
-func TestCreatePlacementInvalidMatchExpressions(t *testing.T) {
-	t.Parallel()
+	for _, manifest := range manifests {
+		if manifest["kind"] != policyKind {
+			continue
+		}
 
-	p := Plugin{}
-	p.allPlcs = map[string]bool{}
-	p.csToPlc = map[string]string{}
-	p.PolicyDefaults.Namespace = "my-policies"
-	policyConf := types.PolicyConfig{Name: "policy-app-config"}
-	nestedMap := map[string]interface{}{
-		"test": "invalid",
-	}
-	me := map[string]interface{}{
-		"key":      "cloud",
-		"operator": "In",
-		"values": []interface{}{
-			"red hat",
-			nestedMap,
-		},
-	}
-	policyConf.Placement.LabelSelector = map[string]interface{}{
-		"matchExpressions": []interface{}{me},
+		name, _, _ := unstructured.NestedString(manifest, "metadata", "name")
+		labels, _, _ := unstructured.NestedStringMap(manifest, "metadata", "labels")
+		policyLabels[name] = labels
 	}
 
-	_, err := p.createPolicyPlacement(policyConf.Placement, policyConf.Name)
-	if err == nil {
-		t.Fatal("Expected an error but did not get one")
+	expected := map[string]map[string]string{
+		"policy-app-config":  {"team": "my-team", "namespace": "my-app", "app.kubernetes.io/name": "my-app"},
+		"policy-app-config2": {"team": "other-team", "namespace": "my-app", "app.kubernetes.io/name": "my-app"},
 	}
-
-	expected := "the input is not a valid label selector or key-value label matching map"
-	assertEqual(t, err.Error(), expected)
+	assertReflectEqual(t, policyLabels, expected)
 }
 
-func TestCreatePlacementMultipleSelectors(t *testing.T) {
+func TestGeneratePolicyAutomation(t *testing.T) {
 	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
 
 	p := Plugin{}
-	p.allPlcs = map[string]bool{}
-	p.csToPlc = map[string]string{}
+	var err error
+
+	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
 	p.PolicyDefaults.Namespace = "my-policies"
-	policyConf := types.PolicyConfig{Name: "policy-app-config"}
-	me := map[string]interface{}{
-		"key":      "cloud",
-		"operator": "In",
-		"values": []string{
-			"red hat",
+	policyConf := types.PolicyConfig{
+		Name: "policy-app-config",
+		Manifests: []types.Manifest{
+			{Path: path.Join(tmpDir, "configmap.yaml")},
+		},
+		Automation: &types.PolicyAutomationConfig{
+			ExtraVars:       map[string]interface{}{"target": "prod"},
+			JobTemplateName: "remediate-configmap",
+			Mode:            "everyEvent",
+			Secret:          "ansible-tower",
 		},
 	}
-	ml := map[string]interface{}{
-		"cloud": "red hat",
+	p.Policies = append(p.Policies, policyConf)
+	p.applyDefaults(map[string]interface{}{})
+
+	if err := p.assertValidConfig(); err != nil {
+		t.Fatal(err.Error())
 	}
-	policyConf.Placement.LabelSelector = map[string]interface{}{
-		"matchExpressions": []interface{}{me},
-		"matchLabels":      ml,
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
 	}
 
-	_, err := p.createPolicyPlacement(policyConf.Placement, policyConf.Name)
+	manifests, err := unmarshalManifestBytes(output)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	output := p.outputBuffer.String()
+	assertEqual(t, len(manifests), 4)
+	assertEqual(t, manifests[0]["kind"], policyKind)
+
 	expected := `
----
-apiVersion: cluster.open-cluster-management.io/v1beta1
-kind: Placement
+apiVersion: policy.open-cluster-management.io/v1beta1
+kind: PolicyAutomation
 metadata:
-    name: placement-policy-app-config
+    name: policy-app-config-policy-automation
     namespace: my-policies
 spec:
-    predicates:
-        - requiredClusterSelector:
-            labelSelector:
-                matchExpressions:
-                    - key: cloud
-                      operator: In
-                      values:
-                        - red hat
-                matchLabels:
-                    cloud: red hat
-    tolerations:
-        - key: cluster.open-cluster-management.io/unavailable
-          operator: Exists
-        - key: cluster.open-cluster-management.io/unreachable
-          operator: Exists
+    automationDef:
+        extra_vars:
+            target: prod
+        name: remediate-configmap
+        secret: ansible-tower
+        type: AnsibleJob
+    eventHook: noncompliant
+    mode: everyEvent
+    policyRef: policy-app-config
 `
-	expected = strings.TrimPrefix(expected, "\n")
-	assertEqual(t, output, expected)
+	automationYAML, err := yaml.Marshal(manifests[1])
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, "\n"+string(automationYAML), expected)
 }
 
-func TestCreatePolicyWithCopyPolicyMetadata(t *testing.T) {
+func TestFilterPolicies(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
 	createConfigMap(t, tmpDir, "configmap.yaml")
 
-	bTrue := true
-	bFalse := false
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  policySets:
+    - my-policyset
+  placement:
+    labelSelector:
+      app: app-config
+  manifests:
+    - path: %s
+- name: policy-app-config2
+  policySets:
+    - my-policyset
+    - my-other-policyset
+  generatePlacementWhenInSet: true
+  placement:
+    labelSelector:
+      app: app-config2
+  manifests:
+    - path: %s
+- name: policy-app-config3
+  placement:
+    labelSelector:
+      app: app-config3
+  manifests:
+    - path: %s
+policySets:
+- name: my-policyset
+  placement:
+    labelSelector:
+      app: my-policyset
+- name: my-other-policyset
+  placement:
+    labelSelector:
+      app: my-other-policyset
+`,
+		path.Join(tmpDir, "configmap.yaml"), path.Join(tmpDir, "configmap.yaml"), path.Join(tmpDir, "configmap.yaml"),
+	)
 
-	tests := []struct {
-		name               string
-		copyPolicyMetadata *bool
-		expected           *bool
+	tests := map[string]struct {
+		names    []string
+		expected []string
 	}{
-		{name: "unset", copyPolicyMetadata: nil, expected: nil},
-		{name: "true", copyPolicyMetadata: &bTrue, expected: nil},
-		{name: "false", copyPolicyMetadata: &bFalse, expected: &bFalse},
+		"policy not in a set": {
+			names: []string{"policy-app-config3"},
+			expected: []string{
+				"Policy/policy-app-config3",
+				"Placement/placement-policy-app-config3",
+				"PlacementBinding/binding-policy-app-config3",
+			},
+		},
+		"policy in sets": {
+			names: []string{"policy-app-config"},
+			expected: []string{
+				"Policy/policy-app-config",
+				"PolicySet/my-policyset:policy-app-config",
+				"Placement/placement-my-policyset",
+				"PlacementBinding/binding-my-policyset",
+			},
+		},
+		"multiple policies": {
+			names: []string{"policy-app-config2", "policy-app-config3"},
+			expected: []string{
+				"Policy/policy-app-config2",
+				"Policy/policy-app-config3",
+				"PolicySet/my-policyset:policy-app-config2",
+				"PolicySet/my-other-policyset:policy-app-config2",
+				"Placement/placement-policy-app-config2",
+				"Placement/placement-policy-app-config3",
+				"Placement/placement-my-policyset",
+				"Placement/placement-my-other-policyset",
+				"PlacementBinding/binding-my-other-policyset",
+				"PlacementBinding/binding-my-policyset",
+				"PlacementBinding/binding-policy-app-config2",
+				"PlacementBinding/binding-policy-app-config3",
+			},
+		},
 	}
 
-	for _, mode := range []string{"policyDefault", "policy"} {
-		mode := mode
+	for name, test := range tests {
+		test := test
 
-		for _, test := range tests {
-			test := test
-			t.Run(mode+" "+test.name, func(t *testing.T) {
-				t.Parallel()
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
 
-				p := Plugin{}
-				p.PolicyDefaults.Namespace = "my-policies"
-				policyConf := types.PolicyConfig{
-					Name: "policy-app-config", Manifests: []types.Manifest{
-						{Path: path.Join(tmpDir, "configmap.yaml")},
-					},
-				}
+			p := Plugin{}
 
-				policyDefaultsUnmarshaled := map[string]interface{}{}
-				policyUnmarshaled := map[string]interface{}{}
+			err := p.Config([]byte(config), tmpDir)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
 
-				if test.copyPolicyMetadata != nil {
-					if mode == "policyDefault" {
-						policyDefaultsUnmarshaled["copyPolicyMetadata"] = *test.copyPolicyMetadata
-					} else if mode == "policy" {
-						policyUnmarshaled["copyPolicyMetadata"] = *test.copyPolicyMetadata
-					}
-				}
+			err = p.FilterPolicies(test.names)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
 
-				p.Policies = append(p.Policies, policyConf)
-				p.applyDefaults(
-					map[string]interface{}{
-						"policyDefaults": policyDefaultsUnmarshaled,
-						"policies":       []interface{}{policyUnmarshaled},
-					},
-				)
+			output, err := p.Generate()
+			if err != nil {
+				t.Fatal(err.Error())
+			}
 
-				err := p.createPolicy(&p.Policies[0])
-				if err != nil {
-					t.Fatal(err.Error())
-				}
+			manifests, err := unmarshalManifestBytes(output)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
 
-				output := p.outputBuffer.Bytes()
-				policyManifests, err := unmarshalManifestBytes(output)
-				if err != nil {
-					t.Fatal(err.Error())
-				}
+			objects := []string{}
 
-				//nolint:forcetypeassert
-				spec := policyManifests[0]["spec"].(map[string]interface{})
+			for _, manifest := range manifests {
+				object := fmt.Sprintf("%s/%s", manifest["kind"], manifest["metadata"].(map[string]interface{})["name"])
 
-				if test.expected == nil {
-					if _, set := spec["copyPolicyMetadata"]; set {
-						t.Fatal("Expected the policy's spec.copyPolicyMetadata to be unset")
+				if manifest["kind"] == "PolicySet" {
+					policies := []string{}
+
+					for _, policy := range manifest["spec"].(map[string]interface{})["policies"].([]interface{}) {
+						policies = append(policies, policy.(string))
 					}
-				} else {
-					assertEqual(t, spec["copyPolicyMetadata"], *test.expected)
+
+					object += ":" + strings.Join(policies, ",")
 				}
-			})
-		}
+
+				objects = append(objects, object)
+			}
+
+			assertReflectEqual(t, objects, test.expected)
+		})
 	}
 }
 
-func TestCreatePolicyWithCustomMessage(t *testing.T) {
+func TestFilterPoliciesUnknown(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
 	createConfigMap(t, tmpDir, "configmap.yaml")
-	createConfigMap(t, tmpDir, "configmap2.yaml")
+
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+`,
+		path.Join(tmpDir, "configmap.yaml"),
+	)
 
 	p := Plugin{}
-	var err error
 
-	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	err := p.Config([]byte(config), tmpDir)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	p.PolicyDefaults.Namespace = "my-policies"
-	p.PolicyDefaults.CustomMessage = types.CustomMessage{
-		Compliant:    "{{ default }}",
-		NonCompliant: "{{ default }}",
-	}
-
-	policyConf := types.PolicyConfig{
-		Name: "policy-app-config",
-		PolicyOptions: types.PolicyOptions{
-			ConsolidateManifests: false,
-		},
-		Manifests: []types.Manifest{
-			{
-				Path: path.Join(tmpDir, "configmap.yaml"),
-			},
-			{
-				Path: path.Join(tmpDir, "configmap2.yaml"),
-			},
-		},
+	err = p.FilterPolicies([]string{"policy-app-config", "policy-typo"})
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
 	}
-	p.Policies = append(p.Policies, policyConf)
 
-	// Ensure values are correctly propagated/overridden
-	p.applyDefaults(map[string]interface{}{})
-	assertEqual(t, policyConf.Manifests[0].ConfigurationPolicyOptions.CustomMessage.Compliant, "{{ default }}")
-	assertEqual(t, policyConf.Manifests[0].ConfigurationPolicyOptions.CustomMessage.NonCompliant, "{{ default }}")
-	assertEqual(t, policyConf.Manifests[1].ConfigurationPolicyOptions.CustomMessage.Compliant, "{{ default }}")
-	assertEqual(t, policyConf.Manifests[1].ConfigurationPolicyOptions.CustomMessage.NonCompliant, "{{ default }}")
+	expected := "the policy policy-typo in the policy filter is not defined in the configuration"
+	assertEqual(t, err.Error(), expected)
 
-	// With consolidateManifest = false
-	policyConf.ConfigurationPolicyOptions = types.ConfigurationPolicyOptions{
-		CustomMessage: types.CustomMessage{
-			Compliant:    "{{ root }}",
-			NonCompliant: "{{ root }}",
-		},
-	}
-	policyConf.Manifests[0].ConfigurationPolicyOptions = types.ConfigurationPolicyOptions{
-		CustomMessage: types.CustomMessage{
-			Compliant:    "{{ manifest1 }}",
-			NonCompliant: "{{ manifest1 }}",
-		},
-	}
-	policyConf.Manifests[1].ConfigurationPolicyOptions = types.ConfigurationPolicyOptions{
-		CustomMessage: types.CustomMessage{
-			Compliant:    "{{ manifest2 }}",
-			NonCompliant: "{{ manifest2 }}",
-		},
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected the error to have the ErrInvalidConfig class: %v", err)
 	}
 
-	p.applyDefaults(map[string]interface{}{})
+	assertEqual(t, len(p.Policies), 1)
+}
 
-	err = p.createPolicy(&policyConf)
-	if err != nil {
-		t.Fatal(err.Error())
-	}
+func TestFilterPoliciesAfter(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
 
-	output := p.outputBuffer.String()
-	expected := `
----
+	config := fmt.Sprintf(`
 apiVersion: policy.open-cluster-management.io/v1
-kind: Policy
+kind: PolicyGenerator
 metadata:
-    annotations:
-        policy.open-cluster-management.io/categories: ""
-        policy.open-cluster-management.io/controls: ""
-        policy.open-cluster-management.io/description: ""
-        policy.open-cluster-management.io/standards: ""
-    name: policy-app-config
-    namespace: my-policies
-spec:
-    copyPolicyMetadata: false
-    disabled: false
-    policy-templates:
-        - objectDefinition:
-            apiVersion: policy.open-cluster-management.io/v1
-            kind: ConfigurationPolicy
-            metadata:
-                name: policy-app-config
-            spec:
-                customMessage:
-                    compliant: '{{ manifest1 }}'
-                    noncompliant: '{{ manifest1 }}'
-                object-templates:
-                    - complianceType: musthave
-                      objectDefinition:
-                        apiVersion: v1
-                        data:
-                            game.properties: enemies=potato
-                        kind: ConfigMap
-                        metadata:
-                            name: my-configmap
-                remediationAction: inform
-                severity: low
-        - objectDefinition:
-            apiVersion: policy.open-cluster-management.io/v1
-            kind: ConfigurationPolicy
-            metadata:
-                name: policy-app-config2
-            spec:
-                customMessage:
-                    compliant: '{{ manifest2 }}'
-                    noncompliant: '{{ manifest2 }}'
-                object-templates:
-                    - complianceType: musthave
-                      objectDefinition:
-                        apiVersion: v1
-                        data:
-                            game.properties: enemies=potato
-                        kind: ConfigMap
-                        metadata:
-                            name: my-configmap
-                remediationAction: inform
-                severity: low
-    remediationAction: inform
-`
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: policy-a
+  manifests:
+    - path: %[1]s
+- name: policy-b
+  after:
+    - policy-a
+  manifests:
+    - path: %[1]s
+`,
+		path.Join(tmpDir, "configmap.yaml"),
+	)
 
-	expected = strings.TrimPrefix(expected, "\n")
-	assertEqual(t, output, expected)
-	p.outputBuffer.Reset()
+	p := Plugin{}
 
-	// With consolidateManifest = true
-	policyConf.PolicyOptions.ConsolidateManifests = true
-	err = p.assertValidConfig()
-	expectedErr := "the policy policy-app-config has the customMessage " +
-		"value set on manifest[0] but consolidateManifests is true"
-	assertEqual(t, err.Error(), expectedErr)
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
 
-	// Note: customMessage field at the manifest level must be set to
-	// the same value as in the policy level when consolidateManifest = true
-	// to successfully generate a policy. If customMessage field is unset
-	// at the manifest level, applyDefaults() can be used to populate this field
-	// if it's set at the policyDefaults or policy level.
-	policyConf.Manifests[0].ConfigurationPolicyOptions.CustomMessage.Compliant = "{{ root }}"
-	policyConf.Manifests[0].ConfigurationPolicyOptions.CustomMessage.NonCompliant = "{{ root }}"
-	policyConf.Manifests[1].ConfigurationPolicyOptions.CustomMessage.Compliant = "{{ root }}"
-	policyConf.Manifests[1].ConfigurationPolicyOptions.CustomMessage.NonCompliant = "{{ root }}"
+	err = p.FilterPolicies([]string{"policy-b"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
 
-	err = p.createPolicy(&policyConf)
+	output, err := p.Generate()
 	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	output = p.outputBuffer.String()
-	expected = `
----
-apiVersion: policy.open-cluster-management.io/v1
-kind: Policy
-metadata:
-    annotations:
-        policy.open-cluster-management.io/categories: ""
-        policy.open-cluster-management.io/controls: ""
-        policy.open-cluster-management.io/description: ""
-        policy.open-cluster-management.io/standards: ""
-    name: policy-app-config
-    namespace: my-policies
-spec:
-    copyPolicyMetadata: false
-    disabled: false
-    policy-templates:
-        - objectDefinition:
-            apiVersion: policy.open-cluster-management.io/v1
-            kind: ConfigurationPolicy
-            metadata:
-                name: policy-app-config
-            spec:
-                customMessage:
-                    compliant: '{{ root }}'
-                    noncompliant: '{{ root }}'
-                object-templates:
-                    - complianceType: musthave
-                      objectDefinition:
-                        apiVersion: v1
-                        data:
-                            game.properties: enemies=potato
-                        kind: ConfigMap
-                        metadata:
-                            name: my-configmap
-                    - complianceType: musthave
-                      objectDefinition:
-                        apiVersion: v1
-                        data:
-                            game.properties: enemies=potato
-                        kind: ConfigMap
-                        metadata:
-                            name: my-configmap
-                remediationAction: ""
-                severity: ""
-`
+	manifests, err := unmarshalManifestBytes(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
 
-	expected = strings.TrimPrefix(expected, "\n")
-	assertEqual(t, output, expected)
-}
+	for _, manifest := range manifests {
+		if manifest["kind"] != "Policy" {
+			continue
+		}
 
-// Test Patching a CR object, "MyCr", containing a list of profile objects.
-// Patching profile interface name and (not profile) recommend
-// - metadata:
-// name: "profile1"
-// spec:
-// recommend:
-// - match:
-//   - nodeLabel: node-role.kubernetes.io/master
-//     priority: 4
-//     listOfStuff: profile1
-//
-// listOfStuff:
-//   - name: "profile1"
-//     interface: "ens5f1"
-//
-// The profile name is used to locate the right profile entry in the patch
-func TestOpenAPIListPatch(t *testing.T) {
-	const (
-		crFilename      = "cr1.yaml"
-		openAPIFilename = "openapi-schema.json"
-	)
+		metadata, _ := manifest["metadata"].(map[string]interface{})
+		assertEqual(t, metadata["name"], "policy-b")
+
+		spec, _ := manifest["spec"].(map[string]interface{})
+		if _, ok := spec["dependencies"]; ok {
+			t.Fatalf("Expected no dependencies but got: %v", spec["dependencies"])
+		}
+	}
+}
 
+func TestGenerateTemplateLibrary(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
-	// relative path to the CR file
-	crRelativePath := filepath.Join(tmpDir, crFilename)
-	// Relative path to the openAPI schema
-	openAPIRelativePath := filepath.Join(tmpDir, openAPIFilename)
-	// Loading plugin file, CR file, and shema file to memory
-	pluginFileContent, err := os.ReadFile("testdata/OpenAPI/policy-generator1.yaml")
-	assertEqual(t, err, nil)
-	crFileContent, err := os.ReadFile("testdata/OpenAPI/cr-files/" + crFilename)
-	assertEqual(t, err, nil)
-	openAPIFileContent, err := os.ReadFile("testdata/OpenAPI/openapi-schema.json")
-	assertEqual(t, err, nil)
+	createConfigMap(t, tmpDir, "configmap.yaml")
 
-	// Writing CR file and shema file to temporary directory
-	err = os.WriteFile(crRelativePath, crFileContent, 0o666)
-	assertEqual(t, err, nil)
-	err = os.WriteFile(openAPIRelativePath, openAPIFileContent, 0o666)
-	assertEqual(t, err, nil)
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+templateLibrary:
+  scc-check:
+    - complianceType: mustnothave
+      objectDefinition:
+        apiVersion: security.openshift.io/v1
+        kind: SecurityContextConstraints
+        allowPrivilegedContainer: true
+  namespace-check:
+    - objectDefinition:
+        apiVersion: v1
+        kind: Namespace
+        metadata:
+          name: my-namespace
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+      useTemplates:
+        - scc-check
+    - useTemplates:
+        - namespace-check
+      recordDiff: Log
+`,
+		path.Join(tmpDir, "configmap.yaml"),
+	)
 
 	p := Plugin{}
 
-	// Load plugin object from file
-	err = yaml.Unmarshal(pluginFileContent, &p)
-	assertEqual(t, err, nil)
-
-	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	err := p.Config([]byte(config), tmpDir)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	// Set CR file path and OpenAPI path with temporary directory
-	p.Policies[0].Manifests[0].Path = crRelativePath
-	p.Policies[0].Manifests[0].OpenAPI.Path = openAPIRelativePath
-
-	// Check configuration
-	if err := p.assertValidConfig(); err != nil {
+	output, err := p.Generate()
+	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	expected := `
----
-apiVersion: policy.open-cluster-management.io/v1
-kind: Policy
-metadata:
-    annotations:
-        policy.open-cluster-management.io/categories: ""
-        policy.open-cluster-management.io/controls: ""
-        policy.open-cluster-management.io/description: ""
-        policy.open-cluster-management.io/standards: ""
-    name: group-du-sno-latest-config-policy
-    namespace: ztp-group
-spec:
-    copyPolicyMetadata: false
-    disabled: false
-    policy-templates:
-        - objectDefinition:
-            apiVersion: policy.open-cluster-management.io/v1
-            kind: ConfigurationPolicy
-            metadata:
-                name: group-du-sno-latest-config-policy
-            spec:
-                object-templates:
-                    - complianceType: ""
-                      objectDefinition:
-                        apiVersion: myapp.myorg.io/v1
-                        kind: MyCr
-                        metadata:
-                            name: profile1
-                            namespace: mynamespace
-                        spec:
-                            listOfStuff:
-                                - myattribute: ens5f1
-                                  name: profile1
-                remediationAction: ""
-                severity: ""
-`
-	expected = strings.TrimPrefix(expected, "\n")
-	// Generated Policies
-	output, err := p.Generate()
+	manifests, err := unmarshalManifestBytes(output)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	assertEqual(t, string(output), expected)
-}
+	policyTemplates, _, _ := unstructured.NestedSlice(manifests[0], "spec", "policy-templates")
+	assertEqual(t, len(policyTemplates), 1)
 
-// Test patching unknown fields in the MyCr CR.
-// The "plugins" object is a generic object (part of the profile list) not defined by the schema
-// Patching :
-// LocalHoldoverTimeout: 14400
-// to
-// LocalHoldoverTimeout: 14401
-func TestOpenAPIListUnkownFieldsPatch(t *testing.T) {
-	const (
-		crFilename      = "cr2.yaml"
-		openAPIFilename = "openapi-schema.json"
+	objTemplates, _, _ := unstructured.NestedSlice(
+		policyTemplates[0].(map[string]interface{}), "objectDefinition", "spec", "object-templates",
 	)
 
+	expected := []interface{}{
+		map[string]interface{}{
+			"complianceType": "musthave",
+			"objectDefinition": map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "my-configmap"},
+				"data":       map[string]interface{}{"game.properties": "enemies=potato"},
+			},
+		},
+		map[string]interface{}{
+			"complianceType": "mustnothave",
+			"objectDefinition": map[string]interface{}{
+				"apiVersion":               "security.openshift.io/v1",
+				"kind":                     "SecurityContextConstraints",
+				"allowPrivilegedContainer": true,
+			},
+		},
+		map[string]interface{}{
+			"complianceType": "musthave",
+			"recordDiff":     "Log",
+			"objectDefinition": map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Namespace",
+				"metadata":   map[string]interface{}{"name": "my-namespace"},
+			},
+		},
+	}
+
+	assertReflectEqual(t, objTemplates, expected)
+
+	// The library templates must not be modified by the manifest options
+	_, hasRecordDiff := p.TemplateLibrary["namespace-check"][0]["recordDiff"]
+	assertEqual(t, hasRecordDiff, false)
+}
+
+func TestGenerateYAMLIndent(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
-	// relative path to the CR file
-	crRelativePath := filepath.Join(tmpDir, crFilename)
-	// Relative path to the openAPI schema
-	openAPIRelativePath := filepath.Join(tmpDir, openAPIFilename)
-	// Loading plugin file, CR file, and shema file to memory
-	pluginFileContent, err := os.ReadFile("testdata/OpenAPI/policy-generator2.yaml")
-	assertEqual(t, err, nil)
-	crFileContent, err := os.ReadFile("testdata/OpenAPI/cr-files/" + crFilename)
-	assertEqual(t, err, nil)
-	openAPIFileContent, err := os.ReadFile("testdata/OpenAPI/openapi-schema.json")
-	assertEqual(t, err, nil)
-
-	// Writing CR file and shema file to temporary directory
-	err = os.WriteFile(crRelativePath, crFileContent, 0o666)
-	assertEqual(t, err, nil)
-	err = os.WriteFile(openAPIRelativePath, openAPIFileContent, 0o666)
-	assertEqual(t, err, nil)
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  placement:
+    name: my-placement
+  yamlIndent: 2
+policies:
+- name: policy-app-config
+  manifests:
+  - path: %s
+`,
+		path.Join(tmpDir, "configmap.yaml"),
+	)
 
 	p := Plugin{}
 
-	// Load plugin object from file
-	err = yaml.Unmarshal(pluginFileContent, &p)
-	assertEqual(t, err, nil)
-
-	p.baseDirectory, err = filepath.EvalSymlinks(tmpDir)
+	err := p.Config([]byte(config), tmpDir)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	// Set CR file path and OpenAPI path with temporary directory
-	p.Policies[0].Manifests[0].Path = crRelativePath
-	p.Policies[0].Manifests[0].OpenAPI.Path = openAPIRelativePath
-
-	// Check configuration
-	if err := p.assertValidConfig(); err != nil {
+	output, err := p.Generate()
+	if err != nil {
 		t.Fatal(err.Error())
 	}
 
@@ -5023,51 +8900,186 @@ func TestOpenAPIListUnkownFieldsPatch(t *testing.T) {
 apiVersion: policy.open-cluster-management.io/v1
 kind: Policy
 metadata:
-    annotations:
-        policy.open-cluster-management.io/categories: ""
-        policy.open-cluster-management.io/controls: ""
-        policy.open-cluster-management.io/description: ""
-        policy.open-cluster-management.io/standards: ""
-    name: group-du-sno-v4.14-config-policy
-    namespace: ztp-group
+  annotations:
+    policy.open-cluster-management.io/categories: CM Configuration Management
+    policy.open-cluster-management.io/controls: CM-2 Baseline Configuration
+    policy.open-cluster-management.io/description: ""
+    policy.open-cluster-management.io/standards: NIST SP 800-53
+  name: policy-app-config
+  namespace: my-policies
 spec:
-    copyPolicyMetadata: false
-    disabled: false
-    policy-templates:
-        - objectDefinition:
-            apiVersion: policy.open-cluster-management.io/v1
-            kind: ConfigurationPolicy
-            metadata:
-                name: group-du-sno-v4.14-config-policy
-            spec:
-                object-templates:
-                    - complianceType: ""
-                      objectDefinition:
-                        apiVersion: myapp.myorg.io/v1
-                        kind: MyCr
-                        metadata:
-                            name: profile1
-                            namespace: mynamespace
-                        spec:
-                            listOfStuff:
-                                - name: profile1
-                                  plugins:
-                                    e810:
-                                        enableDefaultConfig: false
-                                        pins: $e810_pins
-                                        settings:
-                                            LocalHoldoverTimeout: 14401
-                                            LocalMaxHoldoverOffSet: 1500
-                                            MaxInSpecOffset: 100
-                remediationAction: ""
-                severity: ""
+  disabled: false
+  policy-templates:
+    - objectDefinition:
+        apiVersion: policy.open-cluster-management.io/v1
+        kind: ConfigurationPolicy
+        metadata:
+          name: policy-app-config
+        spec:
+          object-templates:
+            - complianceType: musthave
+              objectDefinition:
+                apiVersion: v1
+                data:
+                  game.properties: enemies=potato
+                kind: ConfigMap
+                metadata:
+                  name: my-configmap
+          remediationAction: inform
+          severity: low
+  remediationAction: inform
+---
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
+metadata:
+  name: my-placement
+  namespace: my-policies
+spec:
+  predicates:
+    - requiredClusterSelector:
+        labelSelector:
+          matchExpressions: []
+  tolerations:
+    - key: cluster.open-cluster-management.io/unavailable
+      operator: Exists
+    - key: cluster.open-cluster-management.io/unreachable
+      operator: Exists
+---
+apiVersion: policy.open-cluster-management.io/v1
+kind: PlacementBinding
+metadata:
+  name: binding-policy-app-config
+  namespace: my-policies
+placementRef:
+  apiGroup: cluster.open-cluster-management.io
+  kind: Placement
+  name: my-placement
+subjects:
+  - apiGroup: policy.open-cluster-management.io
+    kind: Policy
+    name: policy-app-config
 `
 	expected = strings.TrimPrefix(expected, "\n")
-	// Generated Policies
+	assertEqual(t, string(output), expected)
+}
+
+func TestConfigInvalidYAMLIndent(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	p := Plugin{}
+	p.PolicyDefaults.Namespace = "my-policies"
+	p.PolicyDefaults.YAMLIndent = 3
+	p.Policies = append(p.Policies, types.PolicyConfig{
+		Name: "policy-app-config",
+		Manifests: []types.Manifest{
+			{Path: path.Join(tmpDir, "configmap.yaml")},
+		},
+	})
+	p.applyDefaults(map[string]interface{}{})
+
+	err := p.assertValidConfig()
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	assertEqual(t, err.Error(), "policyDefaults.yamlIndent must be 2 or 4 but got 3")
+}
+
+func TestGenerateScaffoldOnly(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+placementBindingDefaults:
+  name: my-placement-binding
+policyDefaults:
+  namespace: my-policies
+  placement:
+    name: my-placement
+policies:
+- name: policy-app-config
+  manifests:
+  - path: %[1]s
+- name: policy-app-config2
+  manifests:
+  - path: %[1]s
+- name: policy-app-config3
+  policySets:
+  - my-policyset
+  manifests:
+  - path: %[1]s
+policySets:
+- name: my-policyset
+  placement:
+    name: my-policyset-placement
+`,
+		path.Join(tmpDir, "configmap.yaml"),
+	)
+
+	p := Plugin{}
+	p.SetScaffoldOnly(true)
+
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
 	output, err := p.Generate()
 	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	assertEqual(t, string(output), expected)
+	manifests, err := unmarshalManifestBytes(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	kinds := []string{}
+	subjects := []string{}
+
+	for _, manifest := range manifests {
+		kind, _, _ := unstructured.NestedString(manifest, "kind")
+		kinds = append(kinds, kind)
+
+		bindingSubjects, _, _ := unstructured.NestedSlice(manifest, "subjects")
+		for _, subject := range bindingSubjects {
+			name, _, _ := unstructured.NestedString(subject.(map[string]interface{}), "name")
+			subjects = append(subjects, name)
+		}
+	}
+
+	assertReflectEqual(t, kinds, []string{placementKind, placementKind, placementBindingKind, placementBindingKind})
+	assertReflectEqual(t, subjects, []string{"policy-app-config", "policy-app-config2", "my-policyset"})
+}
+
+func TestConfigInvalidConfigurationPolicyName(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	p := Plugin{}
+	p.PolicyDefaults.Namespace = "my-policies"
+	p.Policies = append(p.Policies, types.PolicyConfig{
+		Name:                    "policy-app-config",
+		ConfigurationPolicyName: "My_ConfigPolicy",
+		Manifests: []types.Manifest{
+			{Path: path.Join(tmpDir, "configmap.yaml")},
+		},
+	})
+	p.applyDefaults(map[string]interface{}{})
+
+	err := p.assertValidConfig()
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := "the policy policy-app-config has a configurationPolicyName `My_ConfigPolicy` that is not DNS " +
+		"compliant. See " + dnsReference
+	assertEqual(t, err.Error(), expected)
 }
@@ -0,0 +1,128 @@
+// Copyright Contributors to the Open Cluster Management project
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"open-cluster-management.io/policy-generator-plugin/internal/types"
+)
+
+// sortPoliciesByAfter returns the indexes of the input policies in a topological order where each
+// policy comes after the policies in its after list. The order is stable, so policies that don't
+// depend on each other keep their declaration order. An error is returned if a policy in an after
+// list doesn't exist or if the after lists have a cycle, in which case the policies in the cycle are
+// named.
+func sortPoliciesByAfter(policies []types.PolicyConfig) ([]int, error) {
+	nameToIdx := make(map[string]int, len(policies))
+
+	for i := range policies {
+		nameToIdx[policies[i].Name] = i
+	}
+
+	for i := range policies {
+		for _, name := range policies[i].After {
+			if _, found := nameToIdx[name]; !found {
+				return nil, fmt.Errorf(
+					"the policy %s has the policy %s in after but it isn't a policy in the configuration",
+					policies[i].Name, name,
+				)
+			}
+		}
+	}
+
+	if cycle := findAfterCycle(policies, nameToIdx); cycle != nil {
+		return nil, fmt.Errorf("the policies have a cycle in after: %s", strings.Join(cycle, " -> "))
+	}
+
+	order := make([]int, 0, len(policies))
+	added := make([]bool, len(policies))
+
+	// Repeatedly add the first policy in declaration order whose after policies were all added. Since
+	// there is no cycle, at least one policy is added in each pass.
+	for len(order) < len(policies) {
+		for i := range policies {
+			if added[i] {
+				continue
+			}
+
+			ready := true
+
+			for _, name := range policies[i].After {
+				if !added[nameToIdx[name]] {
+					ready = false
+
+					break
+				}
+			}
+
+			if ready {
+				added[i] = true
+				order = append(order, i)
+
+				break
+			}
+		}
+	}
+
+	return order, nil
+}
+
+// findAfterCycle returns the names of the policies in a cycle of the after lists of the input
+// policies, with the first policy repeated at the end, or nil if there is no cycle.
+func findAfterCycle(policies []types.PolicyConfig, nameToIdx map[string]int) []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	states := make([]int, len(policies))
+	path := []int{}
+
+	var visit func(i int) []string
+
+	visit = func(i int) []string {
+		states[i] = visiting
+		path = append(path, i)
+
+		for _, name := range policies[i].After {
+			next := nameToIdx[name]
+
+			switch states[next] {
+			case visiting:
+				cycle := []string{}
+				start := len(path) - 1
+
+				for path[start] != next {
+					start--
+				}
+
+				for _, idx := range path[start:] {
+					cycle = append(cycle, policies[idx].Name)
+				}
+
+				return append(cycle, policies[next].Name)
+			case unvisited:
+				if cycle := visit(next); cycle != nil {
+					return cycle
+				}
+			}
+		}
+
+		states[i] = visited
+		path = path[:len(path)-1]
+
+		return nil
+	}
+
+	for i := range policies {
+		if states[i] == unvisited {
+			if cycle := visit(i); cycle != nil {
+				return cycle
+			}
+		}
+	}
+
+	return nil
+}
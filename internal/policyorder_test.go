@@ -0,0 +1,167 @@
+// Copyright Contributors to the Open Cluster Management project
+package internal
+
+import (
+	"fmt"
+	"path"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"open-cluster-management.io/policy-generator-plugin/internal/types"
+)
+
+func TestSortPoliciesByAfter(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		after         map[string][]string
+		names         []string
+		expectedOrder []string
+		expectedErr   string
+	}{
+		"no after": {
+			names:         []string{"one", "two", "three"},
+			expectedOrder: []string{"one", "two", "three"},
+		},
+		"diamond": {
+			names: []string{"four", "three", "two", "one"},
+			after: map[string][]string{
+				"four":  {"two", "three"},
+				"three": {"one"},
+				"two":   {"one"},
+			},
+			expectedOrder: []string{"one", "three", "two", "four"},
+		},
+		"cycle": {
+			names: []string{"zero", "one", "two", "three"},
+			after: map[string][]string{
+				"zero":  {"one"},
+				"one":   {"two"},
+				"two":   {"three"},
+				"three": {"one"},
+			},
+			expectedErr: "the policies have a cycle in after: one -> two -> three -> one",
+		},
+		"self": {
+			names:       []string{"one"},
+			after:       map[string][]string{"one": {"one"}},
+			expectedErr: "the policies have a cycle in after: one -> one",
+		},
+		"missing policy": {
+			names:       []string{"one"},
+			after:       map[string][]string{"one": {"two"}},
+			expectedErr: "the policy one has the policy two in after but it isn't a policy in the configuration",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			policies := []types.PolicyConfig{}
+			for _, name := range test.names {
+				policies = append(policies, types.PolicyConfig{Name: name, After: test.after[name]})
+			}
+
+			order, err := sortPoliciesByAfter(policies)
+			if test.expectedErr != "" {
+				if err == nil {
+					t.Fatal("Expected an error but did not get one")
+				}
+
+				assertEqual(t, err.Error(), test.expectedErr)
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			names := []string{}
+			for _, i := range order {
+				names = append(names, policies[i].Name)
+			}
+
+			assertReflectEqual(t, names, test.expectedOrder)
+		})
+	}
+}
+
+func TestGenerateAfter(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+policies:
+- name: four
+  after: [two, three]
+  manifests: &manifests
+  - path: %s
+- name: three
+  after: [one]
+  manifests: *manifests
+- name: two
+  after: [one]
+  manifests: *manifests
+- name: one
+  manifests: *manifests
+`,
+		path.Join(tmpDir, "configmap.yaml"),
+	)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	manifests, err := unmarshalManifestBytes(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	names := []string{}
+	dependencies := map[string][]string{}
+
+	for _, manifest := range manifests {
+		if manifest["kind"] != policyKind {
+			continue
+		}
+
+		name, _, _ := unstructured.NestedString(manifest, "metadata", "name")
+		names = append(names, name)
+
+		deps, _, _ := unstructured.NestedSlice(manifest, "spec", "dependencies")
+		for _, dep := range deps {
+			//nolint:forcetypeassert
+			depMap := dep.(map[string]interface{})
+			assertEqual(t, depMap["compliance"], "Compliant")
+			assertEqual(t, depMap["namespace"], "my-policies")
+			//nolint:forcetypeassert
+			dependencies[name] = append(dependencies[name], depMap["name"].(string))
+		}
+	}
+
+	assertReflectEqual(t, names, []string{"one", "three", "two", "four"})
+	assertReflectEqual(t, dependencies, map[string][]string{
+		"four":  {"two", "three"},
+		"three": {"one"},
+		"two":   {"one"},
+	})
+}
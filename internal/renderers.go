@@ -0,0 +1,134 @@
+// Copyright Contributors to the Open Cluster Management project
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+const (
+	rawRenderer       = "raw"
+	kustomizeRenderer = "kustomize"
+	jsonnetRenderer   = "jsonnet"
+	cueRenderer       = "cue"
+)
+
+// ManifestRenderer is the interface for all manifest renderers, which turn a manifest path into the
+// objects to wrap in a policy.
+type ManifestRenderer interface {
+	// Render returns the objects defined at the input path.
+	Render(path string) ([]map[string]interface{}, error)
+}
+
+// getRenderers returns the available renderers keyed by the value of the manifest renderer field.
+func getRenderers() map[string]ManifestRenderer {
+	return map[string]ManifestRenderer{
+		rawRenderer:       rawManifestRenderer{},
+		kustomizeRenderer: kustomizeManifestRenderer{},
+		jsonnetRenderer: commandManifestRenderer{
+			command: "jsonnet",
+			args:    func(path string) []string { return []string{path} },
+		},
+		cueRenderer: commandManifestRenderer{
+			command: "cue",
+			args:    func(path string) []string { return []string{"export", "--out", "json", path} },
+		},
+	}
+}
+
+// getRendererNames returns the sorted names of the available renderers.
+func getRendererNames() []string {
+	names := []string{}
+	for name := range getRenderers() {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// rawManifestRenderer renders a YAML file of one or more Kubernetes objects.
+type rawManifestRenderer struct{}
+
+// Render unmarshals the YAML documents in the file at the input path.
+func (r rawManifestRenderer) Render(path string) ([]map[string]interface{}, error) {
+	return unmarshalManifestFile(path)
+}
+
+// kustomizeManifestRenderer renders a Kustomize directory.
+type kustomizeManifestRenderer struct{}
+
+// Render runs the Kustomize directory at the input path through Kustomize.
+func (r kustomizeManifestRenderer) Render(path string) ([]map[string]interface{}, error) {
+	return processKustomizeDir(path)
+}
+
+// commandManifestRenderer renders a manifest by running an external command that prints a JSON or
+// YAML object, or list of objects, to stdout.
+type commandManifestRenderer struct {
+	command string
+	args    func(path string) []string
+}
+
+// Render runs the renderer's command on the input path and unmarshals its output.
+func (r commandManifestRenderer) Render(path string) ([]map[string]interface{}, error) {
+	var stdout, stderr bytes.Buffer
+
+	// #nosec G204 -- the command is one of the built-in renderers and the path is verified to be
+	// in the kustomization directory tree.
+	cmd := exec.Command(r.command, r.args(path)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		var execErr *exec.Error
+		if errors.As(err, &execErr) {
+			return nil, fmt.Errorf(
+				"failed to render the manifest %s (is '%s' installed?): %w", path, r.command, err,
+			)
+		}
+
+		return nil, fmt.Errorf(
+			"failed to render the manifest %s with %s: %w: %s", path, r.command, err, strings.TrimSpace(stderr.String()),
+		)
+	}
+
+	var rendered interface{}
+
+	err = yaml.Unmarshal(stdout.Bytes(), &rendered)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the %s output of the manifest %s: %w", r.command, path, err)
+	}
+
+	const formatErr = "the %s output of the manifest %s must be an object or a list of objects"
+
+	switch typedRendered := rendered.(type) {
+	case nil:
+		return []map[string]interface{}{}, nil
+	case map[string]interface{}:
+		return []map[string]interface{}{typedRendered}, nil
+	case []interface{}:
+		manifests := make([]map[string]interface{}, 0, len(typedRendered))
+
+		for _, obj := range typedRendered {
+			manifest, ok := obj.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf(formatErr, r.command, path)
+			}
+
+			manifests = append(manifests, manifest)
+		}
+
+		return manifests, nil
+	default:
+		return nil, fmt.Errorf(formatErr, r.command, path)
+	}
+}
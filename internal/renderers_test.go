@@ -0,0 +1,98 @@
+// Copyright Contributors to the Open Cluster Management project
+package internal
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestCommandManifestRenderer(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	tests := map[string]struct {
+		output        string
+		expectedNames []string
+		expectedErr   string
+	}{
+		"object": {
+			output:        `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "cm1"}}`,
+			expectedNames: []string{"cm1"},
+		},
+		"list": {
+			output: `[{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "cm1"}},` +
+				`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "cm2"}}]`,
+			expectedNames: []string{"cm1", "cm2"},
+		},
+		"empty": {
+			output:        "",
+			expectedNames: []string{},
+		},
+		"not an object": {
+			output:      `["cm1"]`,
+			expectedErr: "the cat output of the manifest %s must be an object or a list of objects",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		manifestPath := path.Join(tmpDir, strings.ReplaceAll(name, " ", "-")+".json")
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := os.WriteFile(manifestPath, []byte(test.output), 0o666)
+			if err != nil {
+				t.Fatalf("Failed to write %s", manifestPath)
+			}
+
+			renderer := commandManifestRenderer{
+				command: "cat",
+				args:    func(path string) []string { return []string{path} },
+			}
+
+			manifests, err := renderer.Render(manifestPath)
+			if test.expectedErr != "" {
+				if err == nil {
+					t.Fatal("Expected an error but did not get one")
+				}
+
+				assertEqual(t, err.Error(), strings.ReplaceAll(test.expectedErr, "%s", manifestPath))
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Failed to render the manifest, got: %v", err)
+			}
+
+			names := []string{}
+			for _, manifest := range manifests {
+				names = append(names, manifest["metadata"].(map[string]interface{})["name"].(string))
+			}
+
+			assertEqual(t, names, test.expectedNames)
+		})
+	}
+}
+
+func TestCommandManifestRendererNotInstalled(t *testing.T) {
+	t.Parallel()
+
+	renderer := commandManifestRenderer{
+		command: "not-a-real-renderer-command",
+		args:    func(path string) []string { return []string{path} },
+	}
+
+	_, err := renderer.Render("manifest.jsonnet")
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := "failed to render the manifest manifest.jsonnet (is 'not-a-real-renderer-command' installed?)"
+	if !strings.HasPrefix(err.Error(), expected) {
+		t.Fatalf("Expected the error to start with %q, got: %s", expected, err)
+	}
+}
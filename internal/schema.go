@@ -0,0 +1,297 @@
+// Copyright Contributors to the Open Cluster Management project
+package internal
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The embedded schemas are structural subsets of the openAPIV3Schema of the CRDs of the generated
+// kinds. They are used to validate the generated output without access to a cluster.
+//
+//go:embed schemas/*.yaml
+var schemaFS embed.FS
+
+// schemaFiles maps the apiVersion and kind of an object to the file name of its embedded schema.
+var schemaFiles = map[string]string{
+	policyAPIVersion + "/" + policyKind:                     "policy.yaml",
+	policyV1beta1APIVersion + "/" + policyKind:              "policy-v1beta1.yaml",
+	policyAPIVersion + "/" + configPolicyKind:               "configurationpolicy.yaml",
+	policySetAPIVersion + "/" + policySetKind:               "policyset.yaml",
+	placementAPIVersion + "/" + placementKind:               "placement.yaml",
+	placementBindingAPIVersion + "/" + placementBindingKind: "placementbinding.yaml",
+}
+
+// openAPISchema is the subset of an OpenAPI v3 schema supported by the validation of the generated
+// output. Unlike OpenAPI, $ref is the name of a schema in the definitions of the root schema.
+type openAPISchema struct {
+	Type                  string                    `yaml:"type"`
+	Required              []string                  `yaml:"required"`
+	Properties            map[string]*openAPISchema `yaml:"properties"`
+	AdditionalProperties  *openAPISchema            `yaml:"additionalProperties"`
+	Items                 *openAPISchema            `yaml:"items"`
+	Enum                  []string                  `yaml:"enum"`
+	MinItems              int                       `yaml:"minItems"`
+	Ref                   string                    `yaml:"$ref"`
+	PreserveUnknownFields bool                      `yaml:"x-kubernetes-preserve-unknown-fields"`
+	IntOrString           bool                      `yaml:"x-kubernetes-int-or-string"`
+	Definitions           map[string]*openAPISchema `yaml:"definitions"`
+}
+
+// loadSchemas returns the embedded schemas keyed by the apiVersion and kind of the objects they
+// validate.
+func loadSchemas() (map[string]*openAPISchema, error) {
+	schemas := make(map[string]*openAPISchema, len(schemaFiles))
+
+	for key, fileName := range schemaFiles {
+		schemaBytes, err := schemaFS.ReadFile(path.Join("schemas", fileName))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the embedded schema %s: %w", fileName, err)
+		}
+
+		schema := &openAPISchema{}
+
+		err = yaml.Unmarshal(schemaBytes, schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the embedded schema %s: %w", fileName, err)
+		}
+
+		schemas[key] = schema
+	}
+
+	return schemas, nil
+}
+
+// ValidateOutput validates the objects in the generated output against the embedded schemas of the
+// Policy, ConfigurationPolicy, PolicySet, Placement, and PlacementBinding kinds. The
+// ConfigurationPolicy objects in the policy templates of a Policy are also validated. Objects of
+// other kinds are not validated. The returned error lists the schema violations of each invalid
+// object and matches ErrInvalidOutput.
+func (p *Plugin) ValidateOutput(output []byte) error {
+	schemas, err := loadSchemas()
+	if err != nil {
+		return withErrorClass(err, ErrGeneration)
+	}
+
+	objects, err := unmarshalManifestBytes(output)
+	if err != nil {
+		return withErrorClass(fmt.Errorf("failed to parse the generated output: %w", err), ErrGeneration)
+	}
+
+	violations := []string{}
+
+	for _, obj := range objects {
+		kind, _ := obj["kind"].(string)
+		displayName := kind + " " + getObjectDisplayName(obj)
+
+		violations = append(violations, validateObjectSchema(schemas, obj, displayName)...)
+
+		if kind != policyKind {
+			continue
+		}
+
+		spec, _ := obj["spec"].(map[string]interface{})
+
+		policyTemplatesField := "policy-templates"
+		if obj["apiVersion"] == policyV1beta1APIVersion {
+			policyTemplatesField = v1beta1PolicySpecFields[policyTemplatesField]
+		}
+
+		policyTemplates, _ := spec[policyTemplatesField].([]interface{})
+
+		for _, policyTemplate := range policyTemplates {
+			policyTemplate, _ := policyTemplate.(map[string]interface{})
+
+			objDef, _ := policyTemplate["objectDefinition"].(map[string]interface{})
+			if objDef == nil {
+				continue
+			}
+
+			templateKind, _ := objDef["kind"].(string)
+			templateName := fmt.Sprintf(
+				"%s %s in the %s", templateKind, getObjectDisplayName(objDef), displayName,
+			)
+
+			violations = append(violations, validateObjectSchema(schemas, objDef, templateName)...)
+		}
+	}
+
+	if len(violations) != 0 {
+		return wrapSentinel(errors.New(strings.Join(violations, "\n")), ErrInvalidOutput)
+	}
+
+	return nil
+}
+
+// getObjectDisplayName returns the name of the object prefixed with its namespace, if it has one.
+func getObjectDisplayName(obj map[string]interface{}) string {
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+
+	if namespace, _ := metadata["namespace"].(string); namespace != "" {
+		return namespace + "/" + name
+	}
+
+	return name
+}
+
+// validateObjectSchema returns the schema violations of the input object in the format of
+// "the <displayName> is invalid: <violation>". An object without an embedded schema for its
+// apiVersion and kind has no violations.
+func validateObjectSchema(
+	schemas map[string]*openAPISchema, obj map[string]interface{}, displayName string,
+) []string {
+	apiVersion, _ := obj["apiVersion"].(string)
+	kind, _ := obj["kind"].(string)
+
+	schema := schemas[apiVersion+"/"+kind]
+	if schema == nil {
+		return nil
+	}
+
+	violations := validateSchema(schema, schema.Definitions, obj, "")
+
+	// The API server requires a name even though the CRD schemas don't
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	if name, _ := metadata["name"].(string); name == "" {
+		violations = append(violations, "metadata.name: required value")
+	}
+
+	formatted := make([]string, 0, len(violations))
+
+	for _, violation := range violations {
+		formatted = append(formatted, fmt.Sprintf("the %s is invalid: %s", displayName, violation))
+	}
+
+	return formatted
+}
+
+// validateSchema returns the violations of the input value against the schema in the format of
+// "<field path>: <message>". A nil value is treated as unset.
+func validateSchema(
+	schema *openAPISchema, definitions map[string]*openAPISchema, value interface{}, fieldPath string,
+) []string {
+	if value == nil {
+		return nil
+	}
+
+	if schema.Ref != "" {
+		refSchema, ok := definitions[schema.Ref]
+		if !ok {
+			return []string{fmt.Sprintf("%s: the schema reference %s is not defined", fieldPath, schema.Ref)}
+		}
+
+		schema = refSchema
+	}
+
+	if schema.IntOrString {
+		switch value.(type) {
+		case int, string:
+			return nil
+		default:
+			return []string{fmt.Sprintf("%s: expected an integer or string but got %s", fieldPath, schemaType(value))}
+		}
+	}
+
+	if schema.Type != "" && schemaType(value) != schema.Type &&
+		!(schema.Type == "number" && schemaType(value) == "integer") {
+		return []string{fmt.Sprintf("%s: expected %s but got %s", fieldPath, schema.Type, schemaType(value))}
+	}
+
+	violations := []string{}
+
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		for _, required := range schema.Required {
+			if _, ok := typedValue[required]; !ok {
+				violations = append(violations, joinFieldPath(fieldPath, required)+": required value")
+			}
+		}
+
+		keys := make([]string, 0, len(typedValue))
+		for key := range typedValue {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			propSchema := schema.Properties[key]
+			if propSchema == nil {
+				propSchema = schema.AdditionalProperties
+			}
+
+			if propSchema == nil {
+				if !schema.PreserveUnknownFields && schema.Properties != nil {
+					violations = append(violations, joinFieldPath(fieldPath, key)+": unknown field")
+				}
+
+				continue
+			}
+
+			violations = append(
+				violations,
+				validateSchema(propSchema, definitions, typedValue[key], joinFieldPath(fieldPath, key))...,
+			)
+		}
+	case []interface{}:
+		if len(typedValue) < schema.MinItems {
+			violations = append(
+				violations, fmt.Sprintf("%s: must have at least %d items", fieldPath, schema.MinItems),
+			)
+		}
+
+		if schema.Items != nil {
+			for i, item := range typedValue {
+				violations = append(
+					violations,
+					validateSchema(schema.Items, definitions, item, fmt.Sprintf("%s[%d]", fieldPath, i))...,
+				)
+			}
+		}
+	case string:
+		if len(schema.Enum) != 0 && !slices.Contains(schema.Enum, typedValue) {
+			violations = append(violations, fmt.Sprintf(
+				"%s: unsupported value %q, must be one of: %s", fieldPath, typedValue, strings.Join(schema.Enum, ", "),
+			))
+		}
+	}
+
+	return violations
+}
+
+// schemaType returns the OpenAPI type of the input value decoded from YAML.
+func schemaType(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case int:
+		return "integer"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// joinFieldPath returns the field path of the key in the object at the input field path.
+func joinFieldPath(fieldPath string, key string) string {
+	if fieldPath == "" {
+		return key
+	}
+
+	return fieldPath + "." + key
+}
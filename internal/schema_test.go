@@ -0,0 +1,354 @@
+// Copyright Contributors to the Open Cluster Management project
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"testing"
+)
+
+func TestValidateOutput(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  orderPolicies: true
+  placement:
+    labelSelector:
+      app: my-app
+    decisionStrategy:
+      groupStrategy:
+        clustersPerDecisionGroup: 25%%
+policies:
+- name: policy-app-config
+  policySets:
+    - my-policyset
+  manifests:
+    - path: %s
+      recordDiff: Log
+- name: policy-app-config2
+  consolidateManifests: false
+  manifests:
+    - path: %s
+      namespaceSelector:
+        include:
+          - default
+policySets:
+- name: my-policyset
+  description: My policy set
+`,
+		path.Join(tmpDir, "configmap.yaml"), path.Join(tmpDir, "configmap.yaml"),
+	)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = p.ValidateOutput(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+}
+
+func TestValidateOutputInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		output      string
+		expectedErr string
+	}{
+		"invalid enum": {
+			output: `
+apiVersion: policy.open-cluster-management.io/v1
+kind: Policy
+metadata:
+  name: policy-app-config
+  namespace: my-policies
+spec:
+  disabled: false
+  remediationAction: audit
+  policy-templates: []
+`,
+			expectedErr: `the Policy my-policies/policy-app-config is invalid: spec.remediationAction: unsupported ` +
+				`value "audit", must be one of: Inform, inform, Enforce, enforce`,
+		},
+		"policy template": {
+			output: `
+apiVersion: policy.open-cluster-management.io/v1
+kind: Policy
+metadata:
+  name: policy-app-config
+  namespace: my-policies
+spec:
+  disabled: false
+  policy-templates:
+    - objectDefinition:
+        apiVersion: policy.open-cluster-management.io/v1
+        kind: ConfigurationPolicy
+        metadata:
+          name: policy-app-config
+        spec:
+          severity: extreme
+          object-templates:
+            - objectDefinition:
+                apiVersion: v1
+                kind: ConfigMap
+`,
+			expectedErr: "the ConfigurationPolicy policy-app-config in the Policy my-policies/policy-app-config is " +
+				"invalid: spec.object-templates[0].complianceType: required value\n" +
+				"the ConfigurationPolicy policy-app-config in the Policy my-policies/policy-app-config is invalid: " +
+				`spec.severity: unsupported value "extreme", must be one of: low, Low, medium, Medium, high, High, ` +
+				"critical, Critical",
+		},
+		"v1beta1 policy template": {
+			output: `
+apiVersion: policy.open-cluster-management.io/v1beta1
+kind: Policy
+metadata:
+  name: policy-app-config
+  namespace: my-policies
+spec:
+  disabled: false
+  policyTemplates:
+    - objectDefinition:
+        apiVersion: policy.open-cluster-management.io/v1
+        kind: ConfigurationPolicy
+        metadata:
+          name: policy-app-config
+        spec:
+          object-templates:
+            - objectDefinition:
+                apiVersion: v1
+                kind: ConfigMap
+`,
+			expectedErr: "the ConfigurationPolicy policy-app-config in the Policy my-policies/policy-app-config is " +
+				"invalid: spec.object-templates[0].complianceType: required value",
+		},
+		"unknown field and wrong type": {
+			output: `
+apiVersion: cluster.open-cluster-management.io/v1beta1
+kind: Placement
+metadata:
+  name: my-placement
+  namespace: my-policies
+spec:
+  numberOfCluster: 2
+  tolerations:
+    - key: cluster.open-cluster-management.io/unreachable
+      tolerationSeconds: "60"
+`,
+			expectedErr: "the Placement my-policies/my-placement is invalid: spec.numberOfCluster: unknown field\n" +
+				"the Placement my-policies/my-placement is invalid: spec.tolerations[0].tolerationSeconds: expected " +
+				"integer but got string",
+		},
+		"missing fields": {
+			output: `
+apiVersion: policy.open-cluster-management.io/v1
+kind: PlacementBinding
+metadata:
+  namespace: my-policies
+subjects: []
+`,
+			expectedErr: "the PlacementBinding my-policies/ is invalid: placementRef: required value\n" +
+				"the PlacementBinding my-policies/ is invalid: subjects: must have at least 1 items\n" +
+				"the PlacementBinding my-policies/ is invalid: metadata.name: required value",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p := Plugin{}
+
+			err := p.ValidateOutput([]byte(test.output))
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+
+			if !errors.Is(err, ErrInvalidOutput) || !errors.Is(err, ErrGeneration) {
+				t.Fatalf("Expected the error to match ErrInvalidOutput and ErrGeneration: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateOutputSkipsOtherKinds(t *testing.T) {
+	t.Parallel()
+
+	output := `
+apiVersion: apps.open-cluster-management.io/v1
+kind: PlacementRule
+metadata:
+  name: my-placement
+spec:
+  unknownField: true
+---
+apiVersion: policy.open-cluster-management.io/v1beta1
+kind: PolicySet
+metadata:
+  name: my-policyset
+spec:
+  policies:
+    - policy-app-config
+`
+
+	p := Plugin{}
+
+	err := p.ValidateOutput([]byte(output))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+}
+
+func TestGenerateValidateOutputSpecOverrides(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  placement:
+    name: my-placement
+    specOverrides:
+      spreadPolicy:
+        spreadConstraints:
+          - topologyKey: region
+            topologyKeyType: Annotation
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+`,
+		path.Join(tmpDir, "configmap.yaml"),
+	)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = p.ValidateOutput(output)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := "the Placement my-policies/my-placement is invalid: " +
+		`spec.spreadPolicy.spreadConstraints[0].topologyKeyType: unsupported value "Annotation", must be one of: ` +
+		"Label, Claim"
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestGenerateValidateOutputObjectSelector(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  objectSelector:
+    matchLabels:
+      app: my-app
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+`,
+		path.Join(tmpDir, "configmap.yaml"),
+	)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// The generated objectSelector must match the ConfigurationPolicy schema
+	err = p.ValidateOutput(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+}
+
+func TestGenerateValidateOutputV1beta1(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	config := fmt.Sprintf(`
+apiVersion: policy.open-cluster-management.io/v1
+kind: PolicyGenerator
+metadata:
+  name: policy-generator-name
+policyDefaults:
+  namespace: my-policies
+  policyApiVersion: policy.open-cluster-management.io/v1beta1
+policies:
+- name: policy-app-config
+  manifests:
+    - path: %s
+`,
+		path.Join(tmpDir, "configmap.yaml"),
+	)
+
+	p := Plugin{}
+
+	err := p.Config([]byte(config), tmpDir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output, err := p.Generate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// The renamed policyTemplates field must match the v1beta1 Policy schema
+	err = p.ValidateOutput(output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+}
@@ -7,6 +7,13 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// FieldManagerAnnotation is the annotation key and the field manager name value to set on the
+// generated objects for the tooling that applies them with server-side apply.
+type FieldManagerAnnotation struct {
+	Key   string `json:"key,omitempty" yaml:"key,omitempty"`
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
 type HubTemplateOptions struct {
 	ServiceAccountName string `json:"serviceAccountName,omitempty" yaml:"serviceAccountName,omitempty"`
 }
@@ -16,28 +23,46 @@ type CustomMessage struct {
 	NonCompliant string `json:"noncompliant,omitempty" yaml:"noncompliant,omitempty"`
 }
 
+type KyvernoExpanderOptions struct {
+	IncludeWarn bool   `json:"includeWarn,omitempty" yaml:"includeWarn,omitempty"`
+	Scope       string `json:"scope,omitempty" yaml:"scope,omitempty"`
+}
+
 type PolicyOptions struct {
-	Categories                     []string           `json:"categories,omitempty" yaml:"categories,omitempty"`
-	Controls                       []string           `json:"controls,omitempty" yaml:"controls,omitempty"`
-	CopyPolicyMetadata             bool               `json:"copyPolicyMetadata,omitempty" yaml:"copyPolicyMetadata,omitempty"`
-	Dependencies                   []PolicyDependency `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
-	Description                    string             `json:"description,omitempty" yaml:"description,omitempty"`
-	ExtraDependencies              []PolicyDependency `json:"extraDependencies,omitempty" yaml:"extraDependencies,omitempty"`
-	Placement                      PlacementConfig    `json:"placement,omitempty" yaml:"placement,omitempty"`
-	Standards                      []string           `json:"standards,omitempty" yaml:"standards,omitempty"`
-	ConsolidateManifests           bool               `json:"consolidateManifests,omitempty" yaml:"consolidateManifests,omitempty"`
-	OrderManifests                 bool               `json:"orderManifests" yaml:"orderManifests"`
-	Disabled                       bool               `json:"disabled,omitempty" yaml:"disabled,omitempty"`
-	IgnorePending                  bool               `json:"ignorePending,omitempty" yaml:"ignorePending,omitempty"`
-	InformGatekeeperPolicies       bool               `json:"informGatekeeperPolicies,omitempty" yaml:"informGatekeeperPolicies,omitempty"`
-	InformKyvernoPolicies          bool               `json:"informKyvernoPolicies,omitempty" yaml:"informKyvernoPolicies,omitempty"`
-	GeneratePolicyPlacement        bool               `json:"generatePolicyPlacement,omitempty" yaml:"generatePolicyPlacement,omitempty"`
-	GeneratePlacementWhenInSet     bool               `json:"generatePlacementWhenInSet,omitempty" yaml:"generatePlacementWhenInSet,omitempty"`
-	PolicySets                     []string           `json:"policySets,omitempty" yaml:"policySets,omitempty"`
-	PolicyAnnotations              map[string]string  `json:"policyAnnotations,omitempty" yaml:"policyAnnotations,omitempty"`
-	PolicyLabels                   map[string]string  `json:"policyLabels,omitempty" yaml:"policyLabels,omitempty"`
-	ConfigurationPolicyAnnotations map[string]string  `json:"configurationPolicyAnnotations,omitempty" yaml:"configurationPolicyAnnotations,omitempty"`
-	HubTemplateOptions             HubTemplateOptions `json:"hubTemplateOptions,omitempty" yaml:"hubTemplateOptions,omitempty"`
+	AllowDuplicateObjects          bool                   `json:"allowDuplicateObjects,omitempty" yaml:"allowDuplicateObjects,omitempty"`
+	AllowedKinds                   []string               `json:"allowedKinds,omitempty" yaml:"allowedKinds,omitempty"`
+	AlwaysEmitPruneBehavior        bool                   `json:"alwaysEmitPruneBehavior,omitempty" yaml:"alwaysEmitPruneBehavior,omitempty"`
+	CanonicalKeyOrder              bool                   `json:"canonicalKeyOrder,omitempty" yaml:"canonicalKeyOrder,omitempty"`
+	Categories                     []string               `json:"categories,omitempty" yaml:"categories,omitempty"`
+	ContentChecksumAnnotation      bool                   `json:"contentChecksumAnnotation,omitempty" yaml:"contentChecksumAnnotation,omitempty"`
+	Controls                       []string               `json:"controls,omitempty" yaml:"controls,omitempty"`
+	CopyPolicyMetadata             bool                   `json:"copyPolicyMetadata,omitempty" yaml:"copyPolicyMetadata,omitempty"`
+	DeduplicateObjects             bool                   `json:"deduplicateObjects,omitempty" yaml:"deduplicateObjects,omitempty"`
+	Dependencies                   []PolicyDependency     `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	DeriveLabelsFrom               []string               `json:"deriveLabelsFrom,omitempty" yaml:"deriveLabelsFrom,omitempty"`
+	Description                    string                 `json:"description,omitempty" yaml:"description,omitempty"`
+	ExtraDependencies              []PolicyDependency     `json:"extraDependencies,omitempty" yaml:"extraDependencies,omitempty"`
+	Finalizers                     []string               `json:"finalizers,omitempty" yaml:"finalizers,omitempty"`
+	Placement                      PlacementConfig        `json:"placement,omitempty" yaml:"placement,omitempty"`
+	Standards                      []string               `json:"standards,omitempty" yaml:"standards,omitempty"`
+	StrictPatches                  bool                   `json:"strictPatches,omitempty" yaml:"strictPatches,omitempty"`
+	ConsolidateManifests           bool                   `json:"consolidateManifests,omitempty" yaml:"consolidateManifests,omitempty"`
+	OrderManifests                 bool                   `json:"orderManifests" yaml:"orderManifests"`
+	Disabled                       bool                   `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+	DisableFromManifestAnnotation  string                 `json:"disableFromManifestAnnotation,omitempty" yaml:"disableFromManifestAnnotation,omitempty"`
+	DisallowedKinds                []string               `json:"disallowedKinds,omitempty" yaml:"disallowedKinds,omitempty"`
+	IgnorePending                  bool                   `json:"ignorePending,omitempty" yaml:"ignorePending,omitempty"`
+	InformGatekeeperPolicies       bool                   `json:"informGatekeeperPolicies,omitempty" yaml:"informGatekeeperPolicies,omitempty"`
+	InformKyvernoPolicies          bool                   `json:"informKyvernoPolicies,omitempty" yaml:"informKyvernoPolicies,omitempty"`
+	KyvernoExpanderOptions         KyvernoExpanderOptions `json:"kyvernoExpanderOptions,omitempty" yaml:"kyvernoExpanderOptions,omitempty"`
+	GeneratePolicyPlacement        bool                   `json:"generatePolicyPlacement,omitempty" yaml:"generatePolicyPlacement,omitempty"`
+	GeneratePlacementWhenInSet     bool                   `json:"generatePlacementWhenInSet,omitempty" yaml:"generatePlacementWhenInSet,omitempty"`
+	PolicySets                     []string               `json:"policySets,omitempty" yaml:"policySets,omitempty"`
+	PreserveComments               bool                   `json:"preserveComments,omitempty" yaml:"preserveComments,omitempty"`
+	PolicyAnnotations              map[string]string      `json:"policyAnnotations,omitempty" yaml:"policyAnnotations,omitempty"`
+	PolicyLabels                   map[string]string      `json:"policyLabels,omitempty" yaml:"policyLabels,omitempty"`
+	ConfigurationPolicyAnnotations map[string]string      `json:"configurationPolicyAnnotations,omitempty" yaml:"configurationPolicyAnnotations,omitempty"`
+	HubTemplateOptions             HubTemplateOptions     `json:"hubTemplateOptions,omitempty" yaml:"hubTemplateOptions,omitempty"`
 }
 
 type PolicySetOptions struct {
@@ -52,10 +77,13 @@ type ConfigurationPolicyOptions struct {
 	MetadataComplianceType string             `json:"metadataComplianceType,omitempty" yaml:"metadataComplianceType,omitempty"`
 	EvaluationInterval     EvaluationInterval `json:"evaluationInterval,omitempty" yaml:"evaluationInterval,omitempty"`
 	NamespaceSelector      NamespaceSelector  `json:"namespaceSelector,omitempty" yaml:"namespaceSelector,omitempty"`
+	ObjectSelector         LabelSelector      `json:"objectSelector,omitempty" yaml:"objectSelector,omitempty"`
 	PruneObjectBehavior    string             `json:"pruneObjectBehavior,omitempty" yaml:"pruneObjectBehavior,omitempty"`
 	RecordDiff             string             `json:"recordDiff,omitempty" yaml:"recordDiff,omitempty"`
 	RecreateOption         string             `json:"recreateOption,omitempty" yaml:"recreateOption,omitempty"`
 	CustomMessage          CustomMessage      `json:"customMessage,omitempty" yaml:"customMessage,omitempty"`
+	// The labels to set on the generated configuration policies. An empty map doesn't inherit any labels.
+	ConfigurationPolicyLabels map[string]string `json:"configurationPolicyLabels,omitempty" yaml:"configurationPolicyLabels,omitempty"`
 }
 
 type GatekeeperOptions struct {
@@ -65,12 +93,68 @@ type GatekeeperOptions struct {
 type Manifest struct {
 	ConfigurationPolicyOptions `json:",inline" yaml:",inline"`
 	GatekeeperOptions          `json:",inline" yaml:",inline"`
-	Patches                    []map[string]interface{} `json:"patches,omitempty" yaml:"patches,omitempty"`
-	Path                       string                   `json:"path,omitempty" yaml:"path,omitempty"`
-	ExtraDependencies          []PolicyDependency       `json:"extraDependencies,omitempty" yaml:"extraDependencies,omitempty"`
-	IgnorePending              bool                     `json:"ignorePending,omitempty" yaml:"ignorePending,omitempty"`
-	OpenAPI                    Filepath                 `json:"openapi,omitempty" yaml:"openapi,omitempty"`
-	Name                       string                   `json:"name,omitempty" yaml:"name,omitempty"`
+	Patches                    []map[string]interface{}   `json:"patches,omitempty" yaml:"patches,omitempty"`
+	AllowEmpty                 bool                       `json:"allowEmpty,omitempty" yaml:"allowEmpty,omitempty"`
+	Path                       string                     `json:"path,omitempty" yaml:"path,omitempty"`
+	ClusterScopedKinds         []string                   `json:"clusterScopedKinds,omitempty" yaml:"clusterScopedKinds,omitempty"`
+	ComplianceTypeByIndex      map[int]string             `json:"complianceTypeByIndex,omitempty" yaml:"complianceTypeByIndex,omitempty"`
+	ConfigMapGenerator         *ConfigMapGeneratorOptions `json:"configMapGenerator,omitempty" yaml:"configMapGenerator,omitempty"`
+	Consolidate                *bool                      `json:"consolidate,omitempty" yaml:"consolidate,omitempty"`
+	ExtraDependencies          []PolicyDependency         `json:"extraDependencies,omitempty" yaml:"extraDependencies,omitempty"`
+	ExtractPlacement           bool                       `json:"extractPlacement,omitempty" yaml:"extractPlacement,omitempty"`
+	FromFiles                  map[string]string          `json:"fromFiles,omitempty" yaml:"fromFiles,omitempty"`
+	HubTemplate                *HubTemplateManifest       `json:"hubTemplate,omitempty" yaml:"hubTemplate,omitempty"`
+	IgnorePending              bool                       `json:"ignorePending,omitempty" yaml:"ignorePending,omitempty"`
+	IncludeAPIVersions         []string                   `json:"includeApiVersions,omitempty" yaml:"includeApiVersions,omitempty"`
+	IncludeKinds               []string                   `json:"includeKinds,omitempty" yaml:"includeKinds,omitempty"`
+	IncludeWhen                string                     `json:"includeWhen,omitempty" yaml:"includeWhen,omitempty"`
+	OpenAPI                    Filepath                   `json:"openapi,omitempty" yaml:"openapi,omitempty"`
+	Order                      *int                       `json:"order,omitempty" yaml:"order,omitempty"`
+	Name                       string                     `json:"name,omitempty" yaml:"name,omitempty"`
+	OCIRef                     string                     `json:"ociRef,omitempty" yaml:"ociRef,omitempty"`
+	Object                     map[string]interface{}     `json:"object,omitempty" yaml:"object,omitempty"`
+	ObjectAnnotations          map[string]string          `json:"objectAnnotations,omitempty" yaml:"objectAnnotations,omitempty"`
+	ObjectLabels               map[string]string          `json:"objectLabels,omitempty" yaml:"objectLabels,omitempty"`
+	ObjectNamespace            string                     `json:"objectNamespace,omitempty" yaml:"objectNamespace,omitempty"`
+	OverrideObjectMetadata     bool                       `json:"overrideObjectMetadata,omitempty" yaml:"overrideObjectMetadata,omitempty"`
+	OverrideObjectNamespace    bool                       `json:"overrideObjectNamespace,omitempty" yaml:"overrideObjectNamespace,omitempty"`
+	PerCluster                 *PerClusterOptions         `json:"perCluster,omitempty" yaml:"perCluster,omitempty"`
+	PerNamespace               bool                       `json:"perNamespace,omitempty" yaml:"perNamespace,omitempty"`
+	PreRender                  map[string]interface{}     `json:"preRender,omitempty" yaml:"preRender,omitempty"`
+	Renderer                   string                     `json:"renderer,omitempty" yaml:"renderer,omitempty"`
+	UseTemplates               []string                   `json:"useTemplates,omitempty" yaml:"useTemplates,omitempty"`
+	// The object templates of the templateLibrary entries in UseTemplates, which are set by the generator
+	LibraryTemplates []map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// ConfigMapGeneratorOptions configures a ConfigMap that is generated instead of read from a manifest
+// path, in the same way as the Kustomize configMapGenerator.
+type ConfigMapGeneratorOptions struct {
+	DisableNameSuffixHash bool     `json:"disableNameSuffixHash,omitempty" yaml:"disableNameSuffixHash,omitempty"`
+	Files                 []string `json:"files,omitempty" yaml:"files,omitempty"`
+	Literals              []string `json:"literals,omitempty" yaml:"literals,omitempty"`
+	Name                  string   `json:"name,omitempty" yaml:"name,omitempty"`
+	Namespace             string   `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+}
+
+// HubTemplateManifest configures an object-templates-raw manifest that is generated to copy data from
+// an object on the hub with a hub template function instead of being read from a manifest path.
+type HubTemplateManifest struct {
+	APIVersion      string `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+	Function        string `json:"function,omitempty" yaml:"function,omitempty"`
+	Key             string `json:"key,omitempty" yaml:"key,omitempty"`
+	Kind            string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Name            string `json:"name,omitempty" yaml:"name,omitempty"`
+	Namespace       string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	TargetName      string `json:"targetName,omitempty" yaml:"targetName,omitempty"`
+	TargetNamespace string `json:"targetNamespace,omitempty" yaml:"targetNamespace,omitempty"`
+}
+
+// PerClusterOptions configures a manifest that generates a variant of its policy for each cluster in
+// a table of per-cluster patches.
+type PerClusterOptions struct {
+	GeneratePlacement bool   `json:"generatePlacement,omitempty" yaml:"generatePlacement,omitempty"`
+	Path              string `json:"path,omitempty" yaml:"path,omitempty"`
 }
 
 type Filepath struct {
@@ -102,15 +186,72 @@ func (t NamespaceSelector) String() string {
 	return fmt.Sprintf(fmtSelectorStr, t.Include, t.Exclude, *t.MatchLabels, *t.MatchExpressions)
 }
 
+type LabelSelector struct {
+	MatchLabels      *map[string]string                 `json:"matchLabels,omitempty" yaml:"matchLabels,omitempty"`
+	MatchExpressions *[]metav1.LabelSelectorRequirement `json:"matchExpressions,omitempty" yaml:"matchExpressions,omitempty"`
+}
+
+// IsUnset returns true when neither matchLabels nor matchExpressions is set. An empty matchLabels or
+// matchExpressions is considered set.
+func (t LabelSelector) IsUnset() bool {
+	return t.MatchLabels == nil && t.MatchExpressions == nil
+}
+
+// Define String() so that the LabelSelector is dereferenced in the logs
+func (t LabelSelector) String() string {
+	fmtSelectorStr := "{matchLabels:%+v,matchExpressions:%+v}"
+	if t.MatchLabels == nil && t.MatchExpressions == nil {
+		return fmt.Sprintf(fmtSelectorStr, nil, nil)
+	}
+
+	if t.MatchLabels == nil {
+		return fmt.Sprintf(fmtSelectorStr, nil, *t.MatchExpressions)
+	}
+
+	if t.MatchExpressions == nil {
+		return fmt.Sprintf(fmtSelectorStr, *t.MatchLabels, nil)
+	}
+
+	return fmt.Sprintf(fmtSelectorStr, *t.MatchLabels, *t.MatchExpressions)
+}
+
+// PlacementRef is a reference to a placement for the placementRef field of a PlacementBinding.
+type PlacementRef struct {
+	APIGroup string `json:"apiGroup,omitempty" yaml:"apiGroup,omitempty"`
+	Kind     string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Name     string `json:"name,omitempty" yaml:"name,omitempty"`
+}
+
+// BindingOverrides configures the bindingOverrides and subFilter fields of the generated
+// PlacementBindings. When PolicyLabel is set, they are only set on the placement bindings of policies
+// with the label, which is in the format of key or key=value.
+type BindingOverrides struct {
+	PolicyLabel       string `json:"policyLabel,omitempty" yaml:"policyLabel,omitempty"`
+	RemediationAction string `json:"remediationAction,omitempty" yaml:"remediationAction,omitempty"`
+	SubFilter         string `json:"subFilter,omitempty" yaml:"subFilter,omitempty"`
+}
+
 type PlacementConfig struct {
-	ClusterSelectors  map[string]interface{} `json:"clusterSelectors,omitempty" yaml:"clusterSelectors,omitempty"`
-	ClusterSelector   map[string]interface{} `json:"clusterSelector,omitempty" yaml:"clusterSelector,omitempty"`
-	LabelSelector     map[string]interface{} `json:"labelSelector,omitempty" yaml:"labelSelector,omitempty"`
-	Name              string                 `json:"name,omitempty" yaml:"name,omitempty"`
-	PlacementPath     string                 `json:"placementPath,omitempty" yaml:"placementPath,omitempty"`
-	PlacementRulePath string                 `json:"placementRulePath,omitempty" yaml:"placementRulePath,omitempty"`
-	PlacementName     string                 `json:"placementName,omitempty" yaml:"placementName,omitempty"`
-	PlacementRuleName string                 `json:"placementRuleName,omitempty" yaml:"placementRuleName,omitempty"`
+	AllClusters         bool                     `json:"allClusters,omitempty" yaml:"allClusters,omitempty"`
+	BindingPlacementRef *PlacementRef            `json:"bindingPlacementRef,omitempty" yaml:"bindingPlacementRef,omitempty"`
+	CelExpressions      []string                 `json:"celExpressions,omitempty" yaml:"celExpressions,omitempty"`
+	ClusterSelectors    map[string]interface{}   `json:"clusterSelectors,omitempty" yaml:"clusterSelectors,omitempty"`
+	ClusterVersion      string                   `json:"clusterVersion,omitempty" yaml:"clusterVersion,omitempty"`
+	ClusterSelector     map[string]interface{}   `json:"clusterSelector,omitempty" yaml:"clusterSelector,omitempty"`
+	DecisionStrategy    map[string]interface{}   `json:"decisionStrategy,omitempty" yaml:"decisionStrategy,omitempty"`
+	LabelSelector       map[string]interface{}   `json:"labelSelector,omitempty" yaml:"labelSelector,omitempty"`
+	Name                string                   `json:"name,omitempty" yaml:"name,omitempty"`
+	PlacementPath       string                   `json:"placementPath,omitempty" yaml:"placementPath,omitempty"`
+	PlacementRulePath   string                   `json:"placementRulePath,omitempty" yaml:"placementRulePath,omitempty"`
+	PlacementName       string                   `json:"placementName,omitempty" yaml:"placementName,omitempty"`
+	PlacementSelector   map[string]string        `json:"placementSelector,omitempty" yaml:"placementSelector,omitempty"`
+	PlacementsDir       string                   `json:"placementsDir,omitempty" yaml:"placementsDir,omitempty"`
+	PlacementRuleName   string                   `json:"placementRuleName,omitempty" yaml:"placementRuleName,omitempty"`
+	Ref                 string                   `json:"ref,omitempty" yaml:"ref,omitempty"`
+	RequireSelector     bool                     `json:"requireSelector,omitempty" yaml:"requireSelector,omitempty"`
+	Predicates          []map[string]interface{} `json:"predicates,omitempty" yaml:"predicates,omitempty"`
+	SpecOverrides       map[string]interface{}   `json:"specOverrides,omitempty" yaml:"specOverrides,omitempty"`
+	SpreadConstraints   []map[string]interface{} `json:"spreadConstraints,omitempty" yaml:"spreadConstraints,omitempty"`
 }
 
 type EvaluationInterval struct {
@@ -118,12 +259,29 @@ type EvaluationInterval struct {
 	NonCompliant string `json:"noncompliant,omitempty" yaml:"noncompliant,omitempty"`
 }
 
+// PolicyAutomationConfig configures the PolicyAutomation that runs an Ansible job when a policy is
+// noncompliant.
+type PolicyAutomationConfig struct {
+	ExtraVars       map[string]interface{} `json:"extraVars,omitempty" yaml:"extraVars,omitempty"`
+	JobTemplateName string                 `json:"jobTemplateName,omitempty" yaml:"jobTemplateName,omitempty"`
+	Mode            string                 `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Secret          string                 `json:"secret,omitempty" yaml:"secret,omitempty"`
+}
+
 // PolicyConfig represents a policy entry in the PolicyGenerator configuration.
 type PolicyConfig struct {
 	PolicyOptions              `json:",inline" yaml:",inline"`
 	ConfigurationPolicyOptions `json:",inline" yaml:",inline"`
 	GatekeeperOptions          `json:",inline" yaml:",inline"`
-	Name                       string `json:"name,omitempty" yaml:"name,omitempty"`
+	After                      []string                `json:"after,omitempty" yaml:"after,omitempty"`
+	Automation                 *PolicyAutomationConfig `json:"automation,omitempty" yaml:"automation,omitempty"`
+	Name                       string                  `json:"name,omitempty" yaml:"name,omitempty"`
+	// The name of the generated ConfigurationPolicy when it should differ from the policy name
+	ConfigurationPolicyName string `json:"configurationPolicyName,omitempty" yaml:"configurationPolicyName,omitempty"`
+	// The remediationAction to set on the root policy regardless of the policy templates
+	RootRemediationAction string `json:"rootRemediationAction,omitempty" yaml:"rootRemediationAction,omitempty"`
+	// Whether to remove the remediationAction from the policy templates so that the root policy governs
+	ClearTemplateRemediationAction bool `json:"clearTemplateRemediationAction,omitempty" yaml:"clearTemplateRemediationAction,omitempty"`
 	// This a slice of structs to allow additional configuration related to a manifest such as
 	// accepting patches.
 	Manifests []Manifest `json:"manifests,omitempty" yaml:"manifests,omitempty"`
@@ -133,19 +291,36 @@ type PolicyDefaults struct {
 	PolicyOptions              `json:",inline" yaml:",inline"`
 	ConfigurationPolicyOptions `json:",inline" yaml:",inline"`
 	GatekeeperOptions          `json:",inline" yaml:",inline"`
-	Namespace                  string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
-	OrderPolicies              bool   `json:"orderPolicies,omitempty" yaml:"orderPolicies,omitempty"`
+	CommonAnnotations          map[string]string       `json:"commonAnnotations,omitempty" yaml:"commonAnnotations,omitempty"`
+	CommonLabels               map[string]string       `json:"commonLabels,omitempty" yaml:"commonLabels,omitempty"`
+	DescriptionTemplate        string                  `json:"descriptionTemplate,omitempty" yaml:"descriptionTemplate,omitempty"`
+	FieldManagerAnnotation     *FieldManagerAnnotation `json:"fieldManagerAnnotation,omitempty" yaml:"fieldManagerAnnotation,omitempty"`
+	Namespace                  string                  `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	OmitNamespace              bool                    `json:"omitNamespace,omitempty" yaml:"omitNamespace,omitempty"`
+	OrderPolicies              bool                    `json:"orderPolicies,omitempty" yaml:"orderPolicies,omitempty"`
+	PolicyAPIVersion           string                  `json:"policyApiVersion,omitempty" yaml:"policyApiVersion,omitempty"`
+	SeverityByControl          map[string]string       `json:"severityByControl,omitempty" yaml:"severityByControl,omitempty"`
+	// Whether to error on remediationAction values that aren't in their canonical casing rather than
+	// normalizing them
+	StrictRemediationAction bool `json:"strictRemediationAction,omitempty" yaml:"strictRemediationAction,omitempty"`
+	TimestampAnnotation     bool `json:"timestampAnnotation,omitempty" yaml:"timestampAnnotation,omitempty"`
+	YAMLIndent              int  `json:"yamlIndent,omitempty" yaml:"yamlIndent,omitempty"`
 }
 
 type PolicySetConfig struct {
 	Name             string   `json:"name,omitempty" yaml:"name,omitempty"`
 	Description      string   `json:"description,omitempty" yaml:"description,omitempty"`
 	Policies         []string `json:"policies,omitempty" yaml:"policies,omitempty"`
+	OrderedPolicies  []string `json:"orderedPolicies,omitempty" yaml:"orderedPolicies,omitempty"`
 	PolicySetOptions `json:",inline" yaml:",inline"`
 }
 
 type PolicySetDefaults struct {
-	PolicySetOptions `json:",inline" yaml:",inline"`
+	PolicySetOptions         `json:",inline" yaml:",inline"`
+	PreservePolicyOrder      bool          `json:"preservePolicyOrder,omitempty" yaml:"preservePolicyOrder,omitempty"`
+	RecordDiff               string        `json:"recordDiff,omitempty" yaml:"recordDiff,omitempty"`
+	CustomMessage            CustomMessage `json:"customMessage,omitempty" yaml:"customMessage,omitempty"`
+	BindPoliciesIndividually bool          `json:"bindPoliciesIndividually,omitempty" yaml:"bindPoliciesIndividually,omitempty"`
 }
 
 type PolicyDependency struct {
@@ -3,16 +3,29 @@ package internal
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"path"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"text/template"
+	"unicode/utf8"
 
 	yaml "gopkg.in/yaml.v3"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/kustomize/api/krusty"
 	kustomizetypes "sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
@@ -21,26 +34,130 @@ import (
 	"open-cluster-management.io/policy-generator-plugin/internal/types"
 )
 
+// marshalYAML marshals the input object to YAML with the input number of spaces of indentation. An
+// indent of 0 uses the yaml.Marshal default of 4 spaces.
+func marshalYAML(obj interface{}, indent int) ([]byte, error) {
+	if indent == 0 {
+		return yaml.Marshal(obj)
+	}
+
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(indent)
+
+	err := encoder.Encode(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	err = encoder.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
 // getManifests will get all of the manifest files associated with the input policy configuration
 // separated by policyConf.Manifests entries. An error is returned if a manifest path cannot
 // be read.
-func getManifests(policyConf *types.PolicyConfig) ([][]map[string]interface{}, error) {
+func getManifests(policyConf *types.PolicyConfig, values map[string]string) ([][]map[string]interface{}, error) {
 	manifests := [][]map[string]interface{}{}
 	hasKustomize := map[string]bool{}
+	renderers := getRenderers()
 
 	for _, manifest := range policyConf.Manifests {
+		if manifest.IncludeWhen != "" {
+			include, err := evaluateIncludeWhen(manifest.IncludeWhen, values)
+			if err != nil {
+				return nil, fmt.Errorf("failed to evaluate includeWhen of the manifest %s: %w", manifest.Path, err)
+			}
+
+			// Keep an empty entry for the skipped manifest so that the manifest indexes still line up
+			if !include {
+				manifests = append(manifests, []map[string]interface{}{})
+
+				continue
+			}
+		}
+
+		// A manifest with only library templates doesn't have any objects
+		if manifest.Path == "" && manifest.OCIRef == "" && manifest.ConfigMapGenerator == nil &&
+			manifest.HubTemplate == nil && manifest.Object == nil {
+			manifests = append(manifests, []map[string]interface{}{})
+
+			continue
+		}
+
 		manifestPaths := []string{}
 		manifestFiles := []map[string]interface{}{}
-		readErr := fmt.Errorf("failed to read the manifest path %s", manifest.Path)
+		readErr := withErrorClass(fmt.Errorf("failed to read the manifest path %s", manifest.Path), ErrManifestRead)
 
-		manifestPathInfo, err := os.Stat(manifest.Path)
-		if err != nil {
-			return nil, readErr
+		var manifestPathInfo os.FileInfo
+		var err error
+
+		// A manifest from an OCI artifact, a ConfigMap generator, a hub template, or an inline object doesn't have a
+		// local path
+		if manifest.OCIRef == "" && manifest.ConfigMapGenerator == nil && manifest.HubTemplate == nil &&
+			manifest.Object == nil {
+			manifestPathInfo, err = os.Stat(manifest.Path)
+			if err != nil {
+				return nil, wrapSentinel(
+					fmt.Errorf("failed to read the manifest path %s", manifest.Path), ErrManifestNotFound,
+				)
+			}
 		}
 
 		resolvedFiles := []string{}
+		// Whether the single patch was used to replace the metadata of the single manifest object
+		metadataReplaced := false
+
+		if manifest.ConfigMapGenerator != nil {
+			configMap, err := generateConfigMap(manifest.ConfigMapGenerator)
+			if err != nil {
+				return nil, err
+			}
+
+			manifestFiles = append(manifestFiles, configMap)
+		} else if manifest.HubTemplate != nil {
+			hubTemplateManifest, err := generateHubTemplateManifest(manifest.HubTemplate, manifest.ComplianceType)
+			if err != nil {
+				return nil, err
+			}
+
+			manifestFiles = append(manifestFiles, hubTemplateManifest)
+		} else if manifest.Object != nil {
+			// Copy the inline object so that patching it doesn't modify the configuration
+			objectYAML, err := yaml.Marshal(manifest.Object)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal the inline object of policy %s: %w", policyConf.Name, err)
+			}
+
+			object := map[string]interface{}{}
+
+			err = yaml.Unmarshal(objectYAML, &object)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal the inline object of policy %s: %w", policyConf.Name, err)
+			}
+
+			manifestFiles = append(manifestFiles, object)
+		} else if manifest.OCIRef != "" {
+			manifestFiles, err = ociManifestRenderer{}.Render(manifest.OCIRef)
+			if err != nil {
+				return nil, err
+			}
+		} else if manifest.Renderer != "" && manifest.Renderer != rawRenderer {
+			renderer, ok := renderers[manifest.Renderer]
+			if !ok {
+				return nil, fmt.Errorf("the manifest %s has an unknown renderer %s", manifest.Path, manifest.Renderer)
+			}
 
-		if manifestPathInfo.IsDir() {
+			manifestFiles, err = renderer.Render(manifest.Path)
+			if err != nil {
+				return nil, err
+			}
+		} else if manifestPathInfo.IsDir() {
 			files, err := os.ReadDir(manifest.Path)
 			if err != nil {
 				return nil, readErr
@@ -57,9 +174,9 @@ func getManifests(policyConf *types.PolicyConfig) ([][]map[string]interface{}, e
 				if ext != ".yaml" && ext != ".yml" {
 					continue
 				}
-				// Handle when a Kustomization directory is specified
+				// Handle when a Kustomization directory is specified and the renderer isn't explicitly raw
 				_, filename := path.Split(filepath)
-				if filename == "kustomization.yml" || filename == "kustomization.yaml" {
+				if manifest.Renderer == "" && (filename == "kustomization.yml" || filename == "kustomization.yaml") {
 					hasKustomize[manifest.Path] = true
 					resolvedFiles = []string{manifest.Path}
 
@@ -73,13 +190,15 @@ func getManifests(policyConf *types.PolicyConfig) ([][]map[string]interface{}, e
 			manifestPaths = append(manifestPaths, resolvedFiles...)
 		} else {
 			// Unmarshal the manifest in order to check for metadata patch replacement
-			manifestFile, err := unmarshalManifestFile(manifest.Path)
+			manifestFile, err := renderers[rawRenderer].Render(manifest.Path)
 			if err != nil {
 				return nil, err
 			}
 
-			if len(manifestFile) == 0 {
-				return nil, fmt.Errorf("found empty YAML in the manifest at %s", manifest.Path)
+			if len(manifestFile) == 0 && !manifest.AllowEmpty {
+				return nil, wrapSentinel(
+					fmt.Errorf("found empty YAML in the manifest at %s", manifest.Path), ErrEmptyManifest,
+				)
 			}
 			// Allowing replace the original manifest metadata.name and/or metadata.namespace if it is a single
 			// yaml structure in the manifest path
@@ -87,12 +206,14 @@ func getManifests(policyConf *types.PolicyConfig) ([][]map[string]interface{}, e
 				if patchMetadata, ok := manifest.Patches[0]["metadata"].(map[string]interface{}); ok {
 					if metadata, ok := manifestFile[0]["metadata"].(map[string]interface{}); ok {
 						name, ok := patchMetadata["name"].(string)
-						if ok && name != "" {
+						if ok && name != "" && name != metadata["name"] {
 							metadata["name"] = name
+							metadataReplaced = true
 						}
 						namespace, ok := patchMetadata["namespace"].(string)
-						if ok && namespace != "" {
+						if ok && namespace != "" && namespace != metadata["namespace"] {
 							metadata["namespace"] = namespace
+							metadataReplaced = true
 						}
 						manifestFile[0]["metadata"] = metadata
 					}
@@ -107,9 +228,9 @@ func getManifests(policyConf *types.PolicyConfig) ([][]map[string]interface{}, e
 			var err error
 
 			if hasKustomize[manifestPath] {
-				manifestFile, err = processKustomizeDir(manifestPath)
+				manifestFile, err = renderers[kustomizeRenderer].Render(manifestPath)
 			} else {
-				manifestFile, err = unmarshalManifestFile(manifestPath)
+				manifestFile, err = renderers[rawRenderer].Render(manifestPath)
 			}
 
 			if err != nil {
@@ -123,37 +244,225 @@ func getManifests(policyConf *types.PolicyConfig) ([][]map[string]interface{}, e
 			manifestFiles = append(manifestFiles, manifestFile...)
 		}
 
+		// Catch a manifest without any objects, such as a directory of files with only document separators, even
+		// if the other manifests of the policy have objects
+		if len(manifestFiles) == 0 && !manifest.AllowEmpty {
+			return nil, wrapSentinel(fmt.Errorf(
+				"the manifest %s in policy %s doesn't have any objects; set allowEmpty to true to allow it",
+				getManifestSource(manifest), policyConf.Name,
+			), ErrEmptyManifest)
+		}
+
+		manifestFiles = filterManifestObjects(manifestFiles, manifest.IncludeKinds, manifest.IncludeAPIVersions)
+
+		// The placement is used for the placement binding of the policy rather than wrapped in the policy
+		if manifest.ExtractPlacement {
+			manifestFiles = slices.DeleteFunc(manifestFiles, func(object map[string]interface{}) bool {
+				kind, _, _ := unstructured.NestedString(object, "kind")
+
+				return kind == placementKind || kind == placementRuleKind
+			})
+		}
+
 		if len(manifest.Patches) > 0 {
-			patcher := manifestPatcher{manifests: manifestFiles, patches: manifest.Patches, openAPI: manifest.OpenAPI}
+			patcher := manifestPatcher{
+				manifests: manifestFiles,
+				patches:   manifest.Patches,
+				openAPI:   manifest.OpenAPI,
+				// A patch that replaced the metadata of the manifest has already changed it
+				strict: policyConf.StrictPatches && !metadataReplaced,
+			}
 			const errTemplate = `failed to process the manifest at "%s": %w`
 
 			err = patcher.Validate()
 			if err != nil {
-				return nil, fmt.Errorf(errTemplate, manifest.Path, err)
+				return nil, fmt.Errorf(errTemplate, getManifestSource(manifest), err)
 			}
 
 			patchedFiles, err := patcher.ApplyPatches()
 			if err != nil {
-				return nil, fmt.Errorf(errTemplate, manifest.Path, err)
+				return nil, fmt.Errorf(errTemplate, getManifestSource(manifest), err)
 			}
 
 			manifestFiles = patchedFiles
 		}
 
+		if len(manifest.FromFiles) > 0 {
+			err = injectFromFiles(manifestFiles, manifest.FromFiles)
+			if err != nil {
+				return nil, fmt.Errorf(`failed to process the manifest at "%s": %w`, getManifestSource(manifest), err)
+			}
+		}
+
 		manifests = append(manifests, manifestFiles)
 	}
 
 	return manifests, nil
 }
 
+// getManifestSource returns the manifest.ociRef value of the input manifest if it is set, the
+// configMapGenerator name if it is set, the kind and name of the inline object if it is set, and
+// otherwise the manifest path, which identifies the manifest in error messages.
+func getManifestSource(manifest types.Manifest) string {
+	if manifest.OCIRef != "" {
+		return manifest.OCIRef
+	}
+
+	if manifest.ConfigMapGenerator != nil {
+		return "configMapGenerator " + manifest.ConfigMapGenerator.Name
+	}
+
+	if manifest.HubTemplate != nil {
+		return "hubTemplate " + manifest.HubTemplate.Function + " " + manifest.HubTemplate.Name
+	}
+
+	if manifest.Object != nil {
+		kind, _, _ := unstructured.NestedString(manifest.Object, "kind")
+		name, _, _ := unstructured.NestedString(manifest.Object, "metadata", "name")
+
+		return "object " + kind + " " + name
+	}
+
+	return manifest.Path
+}
+
+// injectFromFiles sets the contents of the fromFiles file paths in the data of the single ConfigMap
+// or Secret in the input manifest objects, keyed by the fromFiles keys. Secret data is base64
+// encoded. ConfigMap data is set as is, unless the file isn't valid UTF-8, in which case it is base64
+// encoded in binaryData instead. An error is returned if there isn't a single ConfigMap or Secret
+// or a file can't be read.
+func injectFromFiles(manifests []map[string]interface{}, fromFiles map[string]string) error {
+	var target map[string]interface{}
+
+	targets := 0
+
+	for _, manifest := range manifests {
+		apiVersion, _, _ := unstructured.NestedString(manifest, "apiVersion")
+		kind, _, _ := unstructured.NestedString(manifest, "kind")
+
+		if apiVersion == "v1" && (kind == "ConfigMap" || kind == "Secret") {
+			target = manifest
+			targets++
+		}
+	}
+
+	if targets != 1 {
+		return fmt.Errorf("fromFiles requires a single ConfigMap or Secret in the manifest but found %d", targets)
+	}
+
+	keys := make([]string, 0, len(fromFiles))
+	for key := range fromFiles {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		filePath := fromFiles[key]
+
+		content, err := os.ReadFile(filepath.Clean(filePath))
+		if err != nil {
+			return withErrorClass(fmt.Errorf("failed to read the fromFiles path %s", filePath), ErrManifestRead)
+		}
+
+		dataField := "data"
+		value := base64.StdEncoding.EncodeToString(content)
+
+		if target["kind"] == "ConfigMap" {
+			if utf8.Valid(content) {
+				value = string(content)
+				unstructured.RemoveNestedField(target, "binaryData", key)
+			} else {
+				dataField = "binaryData"
+				unstructured.RemoveNestedField(target, "data", key)
+			}
+		}
+
+		data, _ := target[dataField].(map[string]interface{})
+		if data == nil {
+			data = map[string]interface{}{}
+			target[dataField] = data
+		}
+
+		data[key] = value
+	}
+
+	return nil
+}
+
+// rewritePathPrefix returns the input path with its leading path segments replaced using the first
+// mapping whose Old value matches them. Only whole path segments match, so a mapping of `manifests`
+// doesn't apply to `manifests-v2/configmap.yaml`. The path is returned unmodified if no mapping matches.
+func rewritePathPrefix(filePath string, mappings []PathPrefixMapping) string {
+	for _, mapping := range mappings {
+		oldPrefix := strings.TrimSuffix(mapping.Old, "/")
+
+		if filePath == oldPrefix {
+			return mapping.New
+		}
+
+		if rest, found := strings.CutPrefix(filePath, oldPrefix+"/"); found {
+			return path.Join(mapping.New, rest)
+		}
+	}
+
+	return filePath
+}
+
+// filterManifestObjects returns the input manifest objects whose kind is in includeKinds and whose
+// apiVersion is in includeAPIVersions. An empty filter includes all the objects.
+func filterManifestObjects(
+	manifests []map[string]interface{}, includeKinds []string, includeAPIVersions []string,
+) []map[string]interface{} {
+	if len(includeKinds) == 0 && len(includeAPIVersions) == 0 {
+		return manifests
+	}
+
+	filtered := make([]map[string]interface{}, 0, len(manifests))
+
+	for _, manifest := range manifests {
+		kind, _, _ := unstructured.NestedString(manifest, "kind")
+		if len(includeKinds) != 0 && !slices.Contains(includeKinds, kind) {
+			continue
+		}
+
+		apiVersion, _, _ := unstructured.NestedString(manifest, "apiVersion")
+		if len(includeAPIVersions) != 0 && !slices.Contains(includeAPIVersions, apiVersion) {
+			continue
+		}
+
+		filtered = append(filtered, manifest)
+	}
+
+	return filtered
+}
+
+// isManifestConsolidated returns whether the manifest at the input index is added to the consolidated
+// ConfigurationPolicy of the policy. The manifest consolidate value takes precedence over
+// policyConf.ConsolidateManifests.
+func isManifestConsolidated(policyConf *types.PolicyConfig, index int) bool {
+	if consolidate := policyConf.Manifests[index].Consolidate; consolidate != nil {
+		return *consolidate
+	}
+
+	return policyConf.ConsolidateManifests
+}
+
 // getPolicyTemplates generates the policy templates for the ConfigurationPolicy manifests
 // policyConf.ConsolidateManifests = true (default value) will generate a policy templates slice
 // that just has one template which includes all the manifests specified in policyConf.
 // policyConf.ConsolidateManifests = false will generate a policy templates slice
 // that each template includes a single manifest specified in policyConf.
+// A manifest with consolidate set is added to the consolidated template or to its own template
+// regardless of policyConf.ConsolidateManifests. The consolidated template is added after the
+// templates of the manifests that aren't consolidated. The object-templates-raw of consolidated
+// manifests are concatenated in order into a single policy template at the position of the first one
+// since they can't be combined with object-templates.
 // An error is returned if one or more manifests cannot be read or are invalid.
-func getPolicyTemplates(policyConf *types.PolicyConfig) ([]map[string]interface{}, error) {
-	manifestGroups, err := getManifests(policyConf)
+func getPolicyTemplates(
+	policyConf *types.PolicyConfig, values map[string]string,
+) ([]map[string]interface{}, error) {
+	manifestGroups, err := getManifests(policyConf, values)
 	if err != nil {
 		return nil, err
 	}
@@ -169,10 +478,32 @@ func getPolicyTemplates(policyConf *types.PolicyConfig) ([]map[string]interface{
 	objectTemplates := make([]map[string]interface{}, 0, objectTemplatesLength)
 	policyTemplates := make([]map[string]interface{}, 0, policyTemplatesLength)
 
-	var consolidatedPolicyName string
+	// The configurationPolicyName of the policy takes precedence over the manifest names for the
+	// consolidated ConfigurationPolicy
+	consolidatedPolicyName := policyConf.ConfigurationPolicyName
+
+	defaultConfigPolicyName := policyConf.Name
+	if policyConf.ConfigurationPolicyName != "" {
+		defaultConfigPolicyName = policyConf.ConfigurationPolicyName
+	}
+
+	// The policy template, content, and first manifest of the concatenated object-templates-raw of
+	// consolidated manifests
+	var consolidatedRawTemplate map[string]interface{}
+	var consolidatedRaw string
+	var consolidatedRawManifest *types.Manifest
 
 	policyNameCounter := map[string]int{}
 
+	// The order values of the manifests that the policy templates were generated from, in the same order
+	// as policyTemplates, which are used to sort the policy templates when a manifest sets order
+	templateOrders := make([]*int, 0, policyTemplatesLength)
+
+	appendPolicyTemplate := func(policyTemplate map[string]interface{}, order *int) {
+		policyTemplates = append(policyTemplates, policyTemplate)
+		templateOrders = append(templateOrders, order)
+	}
+
 	for i, manifestGroup := range manifestGroups {
 		complianceType := policyConf.Manifests[i].ComplianceType
 		metadataComplianceType := policyConf.Manifests[i].MetadataComplianceType
@@ -183,10 +514,74 @@ func getPolicyTemplates(policyConf *types.PolicyConfig) ([]map[string]interface{
 
 		policyName := policyConf.Manifests[i].Name
 		if policyName == "" {
-			policyName = policyConf.Name
+			policyName = defaultConfigPolicyName
+		}
+
+		// addObjectTemplate adds the object template to the consolidated ConfigurationPolicy or wraps it in
+		// its own ConfigurationPolicy
+		consolidated := isManifestConsolidated(policyConf, i)
+
+		addObjectTemplate := func(objTemplate map[string]interface{}) {
+			if consolidated {
+				if consolidatedPolicyName == "" {
+					consolidatedPolicyName = policyConf.Manifests[i].Name
+				}
+				// put all objTemplate with manifest into single consolidated objectTemplates
+				objectTemplates = append(objectTemplates, objTemplate)
+			} else {
+				// casting each objTemplate with manifest to objectTemplates type
+				// build policyTemplate for each objectTemplates
+				for _, configPolicyOptions := range getPerNamespaceOptions(&policyConf.Manifests[i]) {
+					policyNameCounter[policyName]++
+					policyTemplate := buildPolicyTemplate(
+						policyConf,
+						[]map[string]interface{}{objTemplate},
+						configPolicyOptions,
+						getConfigurationPolicyName(policyName, policyNameCounter[policyName]),
+					)
+
+					setTemplateOptions(policyTemplate, ignorePending, extraDeps)
+
+					appendPolicyTemplate(policyTemplate, policyConf.Manifests[i].Order)
+				}
+			}
+		}
+
+		included := true
+
+		if policyConf.Manifests[i].IncludeWhen != "" {
+			included, err = evaluateIncludeWhen(policyConf.Manifests[i].IncludeWhen, values)
+			if err != nil {
+				return nil, err
+			}
 		}
 
-		for _, manifest := range manifestGroup {
+		// The indexes count the documents after the includeKinds and includeApiVersions filters. A manifest
+		// skipped by includeWhen has no documents, so its indexes aren't checked.
+		for idx := range policyConf.Manifests[i].ComplianceTypeByIndex {
+			if included && idx >= len(manifestGroup) {
+				return nil, fmt.Errorf(
+					"the complianceTypeByIndex index %d is out of range since there are %d documents in manifest "+
+						"path: %s",
+					idx, len(manifestGroup), getManifestSource(policyConf.Manifests[i]),
+				)
+			}
+		}
+
+		for j, manifest := range manifestGroup {
+			// Catch YAML objects that aren't Kubernetes objects before they fail later with a less clear error
+			_, hasAPIVersion := manifest["apiVersion"]
+			_, hasKind := manifest["kind"]
+			_, hasRaw := manifest["object-templates-raw"]
+
+			if !hasAPIVersion && !hasKind && !hasRaw {
+				return nil, fmt.Errorf(
+					"the manifest in manifest path: %s is not a Kubernetes object since it is missing the apiVersion "+
+						"and kind fields",
+					getManifestSource(policyConf.Manifests[i]),
+				)
+			}
+
 			err := setGatekeeperEnforcementAction(manifest,
 				policyConf.Manifests[i].GatekeeperEnforcementAction)
 			if err != nil {
@@ -200,22 +595,82 @@ func getPolicyTemplates(policyConf *types.PolicyConfig) ([]map[string]interface{
 				return nil, fmt.Errorf(
 					"%w in manifest path: %s",
 					err,
-					policyConf.Manifests[i].Path,
+					getManifestSource(policyConf.Manifests[i]),
+				)
+			}
+
+			// The kind of a policy type manifest is the wrapper of the embedded objects, so only the kinds of
+			// the embedded objects are checked
+			embeddedKinds := getObjectTemplateKinds(manifest)
+			if kind, _, _ := unstructured.NestedString(manifest, "kind"); kind != "" && !isPolicyTypeManifest {
+				embeddedKinds = append(embeddedKinds, kind)
+			}
+
+			err = assertAllowedKinds(policyConf, embeddedKinds...)
+			if err != nil {
+				return nil, fmt.Errorf("%w in manifest path: %s", err, getManifestSource(policyConf.Manifests[i]))
+			}
+
+			if isPolicyTypeManifest && policyConf.Manifests[i].PerNamespace {
+				return nil, fmt.Errorf(
+					"perNamespace can't be set on manifest path: %s since it contains a policy or object-templates-raw",
+					getManifestSource(policyConf.Manifests[i]),
 				)
 			}
 
 			if isPolicyTypeManifest {
 				var policyTemplate map[string]interface{}
 
-				_, found, _ := unstructured.NestedString(manifest, "object-templates-raw")
+				objectTemplatesRaw, found, _ := unstructured.NestedString(manifest, "object-templates-raw")
 				if found {
+					if policyConf.Manifests[i].PreRender != nil {
+						objectTemplatesRaw, err = preRenderObjectTemplatesRaw(
+							objectTemplatesRaw, policyConf.Manifests[i].PreRender,
+						)
+						if err != nil {
+							return nil, fmt.Errorf("%w in manifest path: %s", err, getManifestSource(policyConf.Manifests[i]))
+						}
+					}
+
+					if consolidated && consolidatedRawTemplate != nil {
+						// The ConfigurationPolicy options are set from the first manifest, so the others must match
+						err := assertSameConfigPolicyOptions(consolidatedRawManifest, &policyConf.Manifests[i])
+						if err != nil {
+							return nil, err
+						}
+
+						if !strings.HasSuffix(consolidatedRaw, "\n") {
+							consolidatedRaw += "\n"
+						}
+
+						consolidatedRaw += objectTemplatesRaw
+
+						err = unstructured.SetNestedField(
+							consolidatedRawTemplate, consolidatedRaw, "objectDefinition", "spec", "object-templates-raw",
+						)
+						if err != nil {
+							return nil, fmt.Errorf(
+								"failed to consolidate the object-templates-raw in manifest path: %s: %w",
+								getManifestSource(policyConf.Manifests[i]), err,
+							)
+						}
+
+						continue
+					}
+
 					policyNameCounter[policyName]++
 					policyTemplate = buildPolicyTemplate(
 						policyConf,
-						manifest["object-templates-raw"],
+						objectTemplatesRaw,
 						&policyConf.Manifests[i].ConfigurationPolicyOptions,
 						getConfigurationPolicyName(policyName, policyNameCounter[policyName]),
 					)
+
+					if consolidated {
+						consolidatedRawTemplate = policyTemplate
+						consolidatedRaw = objectTemplatesRaw
+						consolidatedRawManifest = &policyConf.Manifests[i]
+					}
 				} else {
 					policyTemplate = map[string]interface{}{"objectDefinition": manifest}
 				}
@@ -234,18 +689,45 @@ func getPolicyTemplates(policyConf *types.PolicyConfig) ([]map[string]interface{
 						annotations = make(map[string]string, 1)
 					}
 
-					annotations[severityAnnotation] = policyConf.Severity
+					// The manifest severity inherits the policy severity, but fall back in case it's not set
+					severity := policyConf.Manifests[i].Severity
+					if severity == "" {
+						severity = policyConf.Severity
+					}
+
+					annotations[severityAnnotation] = severity
 
 					policyTemplateUnstructured.SetAnnotations(annotations)
 				}
 
-				policyTemplates = append(policyTemplates, policyTemplate)
+				appendPolicyTemplate(policyTemplate, policyConf.Manifests[i].Order)
 
 				continue
 			}
 
+			if policyConf.Manifests[i].ObjectNamespace != "" {
+				setObjectNamespace(
+					manifest,
+					policyConf.Manifests[i].ObjectNamespace,
+					policyConf.Manifests[i].ClusterScopedKinds,
+					policyConf.Manifests[i].OverrideObjectNamespace,
+				)
+			}
+
+			setObjectMetadata(
+				manifest,
+				policyConf.Manifests[i].ObjectLabels,
+				policyConf.Manifests[i].ObjectAnnotations,
+				policyConf.Manifests[i].OverrideObjectMetadata,
+			)
+
+			objComplianceType := complianceType
+			if indexComplianceType, ok := policyConf.Manifests[i].ComplianceTypeByIndex[j]; ok {
+				objComplianceType = indexComplianceType
+			}
+
 			objTemplate := map[string]interface{}{
-				"complianceType":   complianceType,
+				"complianceType":   objComplianceType,
 				"objectDefinition": manifest,
 			}
 
@@ -261,39 +743,80 @@ func getPolicyTemplates(policyConf *types.PolicyConfig) ([]map[string]interface{
 				objTemplate["recordDiff"] = recordDiff
 			}
 
-			if policyConf.ConsolidateManifests {
-				if consolidatedPolicyName == "" {
-					consolidatedPolicyName = policyConf.Manifests[i].Name
-				}
-				// put all objTemplate with manifest into single consolidated objectTemplates
-				objectTemplates = append(objectTemplates, objTemplate)
-			} else {
-				policyNameCounter[policyName]++
-				// casting each objTemplate with manifest to objectTemplates type
-				// build policyTemplate for each objectTemplates
-				policyTemplate := buildPolicyTemplate(
-					policyConf,
-					[]map[string]interface{}{objTemplate},
-					&policyConf.Manifests[i].ConfigurationPolicyOptions,
-					getConfigurationPolicyName(policyName, policyNameCounter[policyName]),
-				)
+			addObjectTemplate(objTemplate)
+		}
+
+		if len(policyConf.Manifests[i].LibraryTemplates) == 0 || !included {
+			continue
+		}
+
+		// The library templates are added after the objects in the manifest path, if any. The manifest
+		// options are only set on the library templates that don't set them.
+		for _, libraryTemplate := range policyConf.Manifests[i].LibraryTemplates {
+			objTemplate, _ := copyYAMLValue(libraryTemplate).(map[string]interface{})
+			objDef, _ := objTemplate["objectDefinition"].(map[string]interface{})
+
+			kind, _, _ := unstructured.NestedString(objDef, "kind")
+
+			err := assertAllowedKinds(policyConf, kind)
+			if err != nil {
+				return nil, fmt.Errorf("%w in the templates of manifest[%d].useTemplates", err, i)
+			}
 
-				setTemplateOptions(policyTemplate, ignorePending, extraDeps)
+			if policyConf.Manifests[i].ObjectNamespace != "" {
+				setObjectNamespace(
+					objDef,
+					policyConf.Manifests[i].ObjectNamespace,
+					policyConf.Manifests[i].ClusterScopedKinds,
+					policyConf.Manifests[i].OverrideObjectNamespace,
+				)
+			}
 
-				policyTemplates = append(policyTemplates, policyTemplate)
+			setObjectMetadata(
+				objDef,
+				policyConf.Manifests[i].ObjectLabels,
+				policyConf.Manifests[i].ObjectAnnotations,
+				policyConf.Manifests[i].OverrideObjectMetadata,
+			)
+
+			for key, value := range map[string]string{
+				"complianceType":         complianceType,
+				"metadataComplianceType": metadataComplianceType,
+				"recreateOption":         recreateOption,
+				"recordDiff":             recordDiff,
+			} {
+				if _, ok := objTemplate[key]; !ok && value != "" {
+					objTemplate[key] = value
+				}
 			}
+
+			addObjectTemplate(objTemplate)
 		}
 	}
 
 	if len(policyTemplates) == 0 && len(objectTemplates) == 0 {
-		return nil, fmt.Errorf(
+		return nil, wrapSentinel(fmt.Errorf(
 			"the policy %s must specify at least one non-empty manifest file", policyConf.Name,
-		)
+		), ErrEmptyManifest)
 	}
 
 	// just build one policyTemplate by using the above non-empty consolidated objectTemplates
-	// ConsolidateManifests = true or there is non-policy-type manifest
-	if policyConf.ConsolidateManifests && len(objectTemplates) > 0 {
+	// ConsolidateManifests = true or a manifest sets consolidate and there is non-policy-type manifest
+	if len(objectTemplates) > 0 {
+		if policyConf.DeduplicateObjects {
+			objectTemplates = deduplicateObjectTemplates(objectTemplates)
+		}
+
+		if !policyConf.AllowDuplicateObjects {
+			err := assertNoDuplicateObjects(objectTemplates)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"the policy %s has consolidated manifests with %w; set allowDuplicateObjects to true to allow it",
+					policyConf.Name, err,
+				)
+			}
+		}
+
 		if consolidatedPolicyName == "" {
 			consolidatedPolicyName = policyConf.Name
 		}
@@ -308,7 +831,7 @@ func getPolicyTemplates(policyConf *types.PolicyConfig) ([]map[string]interface{
 			getConfigurationPolicyName(consolidatedPolicyName, policyNameCounter[consolidatedPolicyName]),
 		)
 		setTemplateOptions(policyTemplate, policyConf.IgnorePending, policyConf.ExtraDependencies)
-		policyTemplates = append(policyTemplates, policyTemplate)
+		appendPolicyTemplate(policyTemplate, nil)
 	}
 
 	// check the enabled expanders and add additional policy templates
@@ -317,8 +840,29 @@ func getPolicyTemplates(policyConf *types.PolicyConfig) ([]map[string]interface{
 		extraDeps := policyConf.Manifests[i].ExtraDependencies
 
 		for _, additionalTemplate := range handleExpanders(manifestGroup, *policyConf) {
+			objDef, _ := additionalTemplate["objectDefinition"].(map[string]interface{})
+
+			err := assertAllowedKinds(policyConf, getObjectTemplateKinds(objDef)...)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"%w in the policy template expanded from manifest path: %s", err, getManifestSource(policyConf.Manifests[i]),
+				)
+			}
+
 			setTemplateOptions(additionalTemplate, ignorePending, extraDeps)
-			policyTemplates = append(policyTemplates, additionalTemplate)
+			appendPolicyTemplate(additionalTemplate, policyConf.Manifests[i].Order)
+		}
+	}
+
+	policyTemplates = sortPolicyTemplatesByOrder(policyTemplates, templateOrders)
+
+	// An explicit configurationPolicyName could be the same as a manifest name
+	if policyConf.ConfigurationPolicyName != "" {
+		err = assertUniqueConfigPolicyNames(policyTemplates)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"the policy %s has %w; set a unique configurationPolicyName or manifest name", policyConf.Name, err,
+			)
 		}
 	}
 
@@ -341,21 +885,145 @@ func getPolicyTemplates(policyConf *types.PolicyConfig) ([]map[string]interface{
 	return policyTemplates, nil
 }
 
-func getConfigurationPolicyName(name string, count int) string {
-	if count > 1 {
-		return fmt.Sprintf("%s%d", name, count)
-	}
+// deduplicateObjectTemplates returns the input object templates without the object templates that
+// have the same complianceType and objectDefinition as a previous one. The order is preserved.
+func deduplicateObjectTemplates(objectTemplates []map[string]interface{}) []map[string]interface{} {
+	deduplicated := make([]map[string]interface{}, 0, len(objectTemplates))
 
-	return name
-}
+	for _, objTemplate := range objectTemplates {
+		duplicate := slices.ContainsFunc(deduplicated, func(prevTemplate map[string]interface{}) bool {
+			return prevTemplate["complianceType"] == objTemplate["complianceType"] &&
+				reflect.DeepEqual(prevTemplate["objectDefinition"], objTemplate["objectDefinition"])
+		})
 
-// setGatekeeperEnforcementAction function override gatekeeper.constraint.enforcementAction
-func setGatekeeperEnforcementAction(manifest map[string]interface{}, enforcementAction string) error {
-	if enforcementAction == "" {
-		return nil
+		if !duplicate {
+			deduplicated = append(deduplicated, objTemplate)
+		}
 	}
 
-	apiVersion, _, _ := unstructured.NestedString(manifest, "apiVersion")
+	return deduplicated
+}
+
+// assertUniqueConfigPolicyNames returns an error naming the first ConfigurationPolicy in the input
+// policy templates with the same name as a previous one.
+func assertUniqueConfigPolicyNames(policyTemplates []map[string]interface{}) error {
+	seen := map[string]bool{}
+
+	for _, policyTemplate := range policyTemplates {
+		kind, _, _ := unstructured.NestedString(policyTemplate, "objectDefinition", "kind")
+		if kind != configPolicyKind {
+			continue
+		}
+
+		name, _, _ := unstructured.NestedString(policyTemplate, "objectDefinition", "metadata", "name")
+		if seen[name] {
+			return fmt.Errorf("multiple ConfigurationPolicy policy templates with the name %s", name)
+		}
+
+		seen[name] = true
+	}
+
+	return nil
+}
+
+// assertNoDuplicateObjects returns an error naming the first object in the input object templates
+// with the same apiVersion, kind, namespace, and name as a previous one. Objects without a name are
+// skipped since they can match multiple objects.
+func assertNoDuplicateObjects(objectTemplates []map[string]interface{}) error {
+	seen := map[string]bool{}
+
+	for _, objTemplate := range objectTemplates {
+		objDef, _ := objTemplate["objectDefinition"].(map[string]interface{})
+
+		name, _, _ := unstructured.NestedString(objDef, "metadata", "name")
+		if name == "" {
+			continue
+		}
+
+		apiVersion, _, _ := unstructured.NestedString(objDef, "apiVersion")
+		kind, _, _ := unstructured.NestedString(objDef, "kind")
+		namespace, _, _ := unstructured.NestedString(objDef, "metadata", "namespace")
+
+		identity := fmt.Sprintf("apiVersion %s, kind %s, namespace %s, and name %s", apiVersion, kind, namespace, name)
+		if namespace == "" {
+			identity = fmt.Sprintf("apiVersion %s, kind %s, and name %s", apiVersion, kind, name)
+		}
+
+		if seen[identity] {
+			return fmt.Errorf("the duplicate object with the %s", identity)
+		}
+
+		seen[identity] = true
+	}
+
+	return nil
+}
+
+// sortPolicyTemplatesByOrder sorts the input policy templates by the order values of the manifests
+// they were generated from when at least one is set. The policy templates of manifests without an
+// order are placed after the others. Ties keep the declaration order.
+func sortPolicyTemplatesByOrder(policyTemplates []map[string]interface{}, orders []*int) []map[string]interface{} {
+	if !slices.ContainsFunc(orders, func(order *int) bool { return order != nil }) {
+		return policyTemplates
+	}
+
+	indexes := make([]int, len(policyTemplates))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	sort.SliceStable(indexes, func(i, j int) bool {
+		orderI, orderJ := orders[indexes[i]], orders[indexes[j]]
+		if orderI == nil || orderJ == nil {
+			return orderI != nil && orderJ == nil
+		}
+
+		return *orderI < *orderJ
+	})
+
+	sorted := make([]map[string]interface{}, 0, len(policyTemplates))
+	for _, i := range indexes {
+		sorted = append(sorted, policyTemplates[i])
+	}
+
+	return sorted
+}
+
+// getPerNamespaceOptions returns the ConfigurationPolicy options of each ConfigurationPolicy to
+// generate for an object in the input manifest. When perNamespace is set, there is one per namespace
+// in namespaceSelector.include with the namespaceSelector pinned to that namespace. Otherwise, the
+// manifest options are returned as is.
+func getPerNamespaceOptions(manifest *types.Manifest) []*types.ConfigurationPolicyOptions {
+	if !manifest.PerNamespace {
+		return []*types.ConfigurationPolicyOptions{&manifest.ConfigurationPolicyOptions}
+	}
+
+	options := make([]*types.ConfigurationPolicyOptions, 0, len(manifest.NamespaceSelector.Include))
+
+	for _, namespace := range manifest.NamespaceSelector.Include {
+		namespaceOptions := manifest.ConfigurationPolicyOptions
+		namespaceOptions.NamespaceSelector = types.NamespaceSelector{Include: []string{namespace}}
+		options = append(options, &namespaceOptions)
+	}
+
+	return options
+}
+
+func getConfigurationPolicyName(name string, count int) string {
+	if count > 1 {
+		return fmt.Sprintf("%s%d", name, count)
+	}
+
+	return name
+}
+
+// setGatekeeperEnforcementAction function override gatekeeper.constraint.enforcementAction
+func setGatekeeperEnforcementAction(manifest map[string]interface{}, enforcementAction string) error {
+	if enforcementAction == "" {
+		return nil
+	}
+
+	apiVersion, _, _ := unstructured.NestedString(manifest, "apiVersion")
 
 	if strings.HasPrefix(apiVersion, "constraints.gatekeeper.sh") {
 		err := unstructured.SetNestedField(manifest, enforcementAction, "spec", "enforcementAction")
@@ -367,6 +1035,189 @@ func setGatekeeperEnforcementAction(manifest map[string]interface{}, enforcement
 	return nil
 }
 
+// getObjectTemplateKinds returns the kinds of the objects embedded in the spec.object-templates of the
+// input ConfigurationPolicy-like manifest. An empty slice is returned if there are none.
+func getObjectTemplateKinds(manifest map[string]interface{}) []string {
+	kinds := []string{}
+
+	spec, _ := manifest["spec"].(map[string]interface{})
+
+	var objTemplates []map[string]interface{}
+
+	// Generated policy templates use []map[string]interface{} while decoded manifests use []interface{}
+	switch typedObjTemplates := spec["object-templates"].(type) {
+	case []map[string]interface{}:
+		objTemplates = typedObjTemplates
+	case []interface{}:
+		for _, objTemplate := range typedObjTemplates {
+			if objTemplateMap, ok := objTemplate.(map[string]interface{}); ok {
+				objTemplates = append(objTemplates, objTemplateMap)
+			}
+		}
+	}
+
+	for _, objTemplate := range objTemplates {
+		if kind, _, _ := unstructured.NestedString(objTemplate, "objectDefinition", "kind"); kind != "" {
+			kinds = append(kinds, kind)
+		}
+	}
+
+	return kinds
+}
+
+// assertAllowedKinds verifies that the input kinds are permitted by the policy's allowedKinds and
+// disallowedKinds. An error naming the offending kind is returned otherwise.
+func assertAllowedKinds(policyConf *types.PolicyConfig, kinds ...string) error {
+	for _, kind := range kinds {
+		if len(policyConf.AllowedKinds) != 0 && !slices.Contains(policyConf.AllowedKinds, kind) {
+			return fmt.Errorf("the kind %s is not in the allowedKinds of the policy %s", kind, policyConf.Name)
+		}
+
+		if slices.Contains(policyConf.DisallowedKinds, kind) {
+			return fmt.Errorf("the kind %s is in the disallowedKinds of the policy %s", kind, policyConf.Name)
+		}
+	}
+
+	return nil
+}
+
+// clusterScopedKinds is the set of well-known cluster scoped kinds that objectNamespace must not be
+// set on. Kinds not in this set or in the clusterScopedKinds of the manifest are assumed to be
+// namespaced.
+var clusterScopedKinds = map[string]bool{
+	"APIService":                     true,
+	"CSIDriver":                      true,
+	"CSINode":                        true,
+	"CertificateSigningRequest":      true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"CustomResourceDefinition":       true,
+	"IngressClass":                   true,
+	"ManagedCluster":                 true,
+	"ManagedClusterSet":              true,
+	"MutatingWebhookConfiguration":   true,
+	"Namespace":                      true,
+	"Node":                           true,
+	"PersistentVolume":               true,
+	"PriorityClass":                  true,
+	"Project":                        true,
+	"RuntimeClass":                   true,
+	"SecurityContextConstraints":     true,
+	"StorageClass":                   true,
+	"ValidatingWebhookConfiguration": true,
+	"VolumeAttachment":               true,
+	"VolumeSnapshotClass":            true,
+}
+
+// getObjectSelectorWarnings returns a warning for each ConfigurationPolicy in the input policy
+// templates that sets an objectSelector but only has well-known cluster scoped kinds in its object
+// templates, since the objectSelector likely was meant to be a namespaceSelector.
+func getObjectSelectorWarnings(policyName string, policyTemplates []map[string]interface{}) []string {
+	warnings := []string{}
+
+	for _, policyTemplate := range policyTemplates {
+		objDef, _ := policyTemplate["objectDefinition"].(map[string]interface{})
+		if objDef["kind"] != configPolicyKind {
+			continue
+		}
+
+		spec, _ := objDef["spec"].(map[string]interface{})
+		objTemplates, _ := spec["object-templates"].([]map[string]interface{})
+
+		hasObjectSelector := slices.ContainsFunc(objTemplates, func(objTemplate map[string]interface{}) bool {
+			_, ok := objTemplate["objectSelector"]
+
+			return ok
+		})
+		if !hasObjectSelector {
+			continue
+		}
+
+		kinds := []string{}
+
+		for _, objTemplate := range objTemplates {
+			obj, _ := objTemplate["objectDefinition"].(map[string]interface{})
+			kind, _ := obj["kind"].(string)
+
+			if !clusterScopedKinds[kind] {
+				kinds = nil
+
+				break
+			}
+
+			if !slices.Contains(kinds, kind) {
+				kinds = append(kinds, kind)
+			}
+		}
+
+		if len(kinds) == 0 {
+			continue
+		}
+
+		name, _, _ := unstructured.NestedString(objDef, "metadata", "name")
+
+		warnings = append(warnings, fmt.Sprintf(
+			"the policy %s sets objectSelector on the ConfigurationPolicy %s, but it only has the cluster scoped "+
+				"kinds %s, so the objectSelector has no effect",
+			policyName, name, strings.Join(kinds, ", "),
+		))
+	}
+
+	return warnings
+}
+
+// setObjectNamespace sets the metadata.namespace of the input manifest to the input namespace if the
+// manifest is not a well-known cluster scoped kind or one of the input extra cluster scoped kinds,
+// such as the kinds of cluster scoped custom resources. An existing namespace is only replaced when
+// override is true.
+func setObjectNamespace(manifest map[string]interface{}, namespace string, extraKinds []string, override bool) {
+	kind, _, _ := unstructured.NestedString(manifest, "kind")
+	if clusterScopedKinds[kind] || slices.Contains(extraKinds, kind) {
+		return
+	}
+
+	existing, _, _ := unstructured.NestedString(manifest, "metadata", "namespace")
+	if existing != "" && !override {
+		return
+	}
+
+	_ = unstructured.SetNestedField(manifest, namespace, "metadata", "namespace")
+}
+
+// setObjectMetadata merges the input labels and annotations into the metadata of the input object.
+// The existing labels and annotations of the object are kept on conflict unless override is true.
+func setObjectMetadata(manifest map[string]interface{}, labels, annotations map[string]string, override bool) {
+	if len(labels) == 0 && len(annotations) == 0 {
+		return
+	}
+
+	metadata, _ := manifest["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+		manifest["metadata"] = metadata
+	}
+
+	for field, values := range map[string]map[string]string{"labels": labels, "annotations": annotations} {
+		if len(values) == 0 {
+			continue
+		}
+
+		existing, _ := metadata[field].(map[string]interface{})
+		if existing == nil {
+			existing = make(map[string]interface{}, len(values))
+			metadata[field] = existing
+		}
+
+		for key, value := range values {
+			if _, found := existing[key]; found && !override {
+				continue
+			}
+
+			existing[key] = value
+		}
+	}
+}
+
 func setTemplateOptions(tmpl map[string]interface{}, ignorePending bool, extraDeps []types.PolicyDependency) {
 	if ignorePending {
 		tmpl["ignorePending"] = ignorePending
@@ -445,6 +1296,30 @@ func setNamespaceSelector(
 	}
 }
 
+// setObjectSelector sets the object selector, if set, on each object template of the input policy
+// template, since objectSelector is a field of the object templates rather than of the
+// ConfigurationPolicy spec. It isn't set on object-templates-raw.
+func setObjectSelector(
+	policyConf *types.ConfigurationPolicyOptions,
+	policyTemplate map[string]interface{},
+) {
+	selector := policyConf.ObjectSelector
+	if selector.IsUnset() {
+		return
+	}
+
+	objDef := policyTemplate["objectDefinition"].(map[string]interface{})
+	spec := objDef["spec"].(map[string]interface{})
+	objTemplates, _ := spec["object-templates"].([]map[string]interface{})
+
+	for i := range objTemplates {
+		// Copy the object template since it may be shared, such as with a template library entry
+		objTemplate := maps.Clone(objTemplates[i])
+		objTemplate["objectSelector"] = selector
+		objTemplates[i] = objTemplate
+	}
+}
+
 // processKustomizeDir runs a provided directory through Kustomize in order to generate the manifests within it.
 func processKustomizeDir(path string) ([]map[string]interface{}, error) {
 	kustomizeOpts := krusty.MakeDefaultOptions()
@@ -478,6 +1353,36 @@ func processKustomizeDir(path string) ([]map[string]interface{}, error) {
 	return manifests, nil
 }
 
+// assertSameConfigPolicyOptions returns an error if the ConfigurationPolicy options that
+// buildPolicyTemplate sets from the input manifests differ, since the object-templates-raw of the
+// manifests are concatenated into the ConfigurationPolicy of the first manifest.
+func assertSameConfigPolicyOptions(first *types.Manifest, manifest *types.Manifest) error {
+	options := []struct {
+		name  string
+		equal bool
+	}{
+		{"remediationAction", first.RemediationAction == manifest.RemediationAction},
+		{"severity", first.Severity == manifest.Severity},
+		{"evaluationInterval", first.EvaluationInterval == manifest.EvaluationInterval},
+		{"customMessage", first.CustomMessage == manifest.CustomMessage},
+		{"namespaceSelector", reflect.DeepEqual(first.NamespaceSelector, manifest.NamespaceSelector)},
+		{"pruneObjectBehavior", first.PruneObjectBehavior == manifest.PruneObjectBehavior},
+		{"configurationPolicyLabels", maps.Equal(first.ConfigurationPolicyLabels, manifest.ConfigurationPolicyLabels)},
+	}
+
+	for _, option := range options {
+		if !option.equal {
+			return fmt.Errorf(
+				"the %s value of the object-templates-raw in manifest path: %s must match the consolidated "+
+					"object-templates-raw in manifest path: %s",
+				option.name, getManifestSource(*manifest), getManifestSource(*first),
+			)
+		}
+	}
+
+	return nil
+}
+
 // buildPolicyTemplate generates single policy template by using objectTemplates with manifests.
 // policyNum defines which number the configuration policy is in the policy. If it is greater than
 // one then the configuration policy name will have policyNum appended to it.
@@ -510,8 +1415,9 @@ func buildPolicyTemplate(
 		},
 	}
 
-	// Set NamespaceSelector with policy configuration
+	// Set NamespaceSelector and ObjectSelector with policy configuration
 	setNamespaceSelector(&policyConf.ConfigurationPolicyOptions, policyTemplate)
+	setObjectSelector(&policyConf.ConfigurationPolicyOptions, policyTemplate)
 
 	if len(policyConf.ConfigurationPolicyAnnotations) > 0 {
 		objDef := policyTemplate["objectDefinition"].(map[string]interface{})
@@ -519,6 +1425,18 @@ func buildPolicyTemplate(
 		metadata["annotations"] = policyConf.ConfigurationPolicyAnnotations
 	}
 
+	// Set the labels with manifest overrides, where an empty map means that no labels are set
+	labels := configPolicyOptionsOverrides.ConfigurationPolicyLabels
+	if labels == nil {
+		labels = policyConf.ConfigurationPolicyLabels
+	}
+
+	if len(labels) > 0 {
+		objDef := policyTemplate["objectDefinition"].(map[string]interface{})
+		metadata := objDef["metadata"].(map[string]interface{})
+		metadata["labels"] = labels
+	}
+
 	objDef := policyTemplate["objectDefinition"].(map[string]interface{})
 	configSpec := objDef["spec"].(map[string]interface{})
 
@@ -554,12 +1472,16 @@ func buildPolicyTemplate(
 		configSpec["customMessage"] = customMessageJSON
 	}
 
-	// Set NamespaceSelector with manifest overrides
+	// Set NamespaceSelector and ObjectSelector with manifest overrides
 	setNamespaceSelector(configPolicyOptionsOverrides, policyTemplate)
+	setObjectSelector(configPolicyOptionsOverrides, policyTemplate)
 
-	// Set PruneObjectBehavior with manifest overrides
+	// Set PruneObjectBehavior with manifest overrides, and explicitly set the default of None if
+	// configured to always emit it
 	if configPolicyOptionsOverrides.PruneObjectBehavior != "" {
 		configSpec["pruneObjectBehavior"] = configPolicyOptionsOverrides.PruneObjectBehavior
+	} else if policyConf.AlwaysEmitPruneBehavior {
+		configSpec["pruneObjectBehavior"] = "None"
 	}
 
 	// Set RemediationAction with manifest overrides
@@ -583,7 +1505,7 @@ func handleExpanders(manifests []map[string]interface{}, policyConf types.Policy
 	for _, expander := range expanders.GetExpanders() {
 		for _, m := range manifests {
 			if expander.Enabled(&policyConf) && expander.CanHandle(m) {
-				expandedPolicyTemplates := expander.Expand(m, policyConf.Severity)
+				expandedPolicyTemplates := expander.Expand(m, &policyConf)
 				policyTemplates = append(policyTemplates, expandedPolicyTemplates...)
 			}
 		}
@@ -600,7 +1522,7 @@ func unmarshalManifestFile(manifestPath string) ([]map[string]interface{}, error
 	// #nosec G304
 	manifestBytes, err := os.ReadFile(manifestPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read the manifest file %s", manifestPath)
+		return nil, withErrorClass(fmt.Errorf("failed to read the manifest file %s", manifestPath), ErrManifestRead)
 	}
 
 	rv, err := unmarshalManifestBytes(manifestBytes)
@@ -646,8 +1568,10 @@ func unmarshalManifestBytes(manifestBytes []byte) ([]map[string]interface{}, err
 }
 
 // verifyFilePath verifies that the file path is in the directory tree under baseDirectory.
-// An error is returned if it is not or the paths couldn't be properly resolved.
-func verifyFilePath(baseDirectory string, filePath, fileType string) error {
+// An error is returned if it is not or the paths couldn't be properly resolved. The error
+// messages refer to the base directory when standalone is set and otherwise to the
+// kustomization.yaml file, which defines the base directory when run as a Kustomize plugin.
+func verifyFilePath(baseDirectory string, filePath, fileType string, standalone bool) error {
 	absPath, err := filepath.Abs(filePath)
 	if err != nil {
 		return fmt.Errorf("could not resolve the %s path %s to an absolute path", fileType, filePath)
@@ -658,32 +1582,235 @@ func verifyFilePath(baseDirectory string, filePath, fileType string) error {
 		return fmt.Errorf("could not resolve symlinks to the %s path %s", fileType, filePath)
 	}
 
+	baseDirDesc := "the kustomization.yaml file"
+	sameDirDesc := "the same directory as the kustomization.yaml file"
+	dirTreeDesc := "the same directory tree as the kustomization.yaml file"
+
+	if standalone {
+		baseDirDesc = "the base directory " + baseDirectory
+		sameDirDesc = baseDirDesc
+		dirTreeDesc = "the directory tree of " + baseDirDesc
+	}
+
 	relPath, err := filepath.Rel(baseDirectory, absPath)
 	if err != nil {
 		return fmt.Errorf(
-			"could not resolve the %s path %s to a relative path from the kustomization.yaml file",
-			fileType, filePath,
+			"could not resolve the %s path %s to a relative path from %s", fileType, filePath, baseDirDesc,
 		)
 	}
 
 	if relPath == "." {
-		return fmt.Errorf(
-			"the %s path %s may not refer to the same directory as the kustomization.yaml file",
-			fileType, filePath,
-		)
+		return fmt.Errorf("the %s path %s may not refer to %s", fileType, filePath, sameDirDesc)
 	}
 
 	parDir := ".." + string(filepath.Separator)
 	if strings.HasPrefix(relPath, parDir) || relPath == ".." {
-		return fmt.Errorf(
-			"the %s path %s is not in the same directory tree as the kustomization.yaml file",
-			fileType, filePath,
-		)
+		return fmt.Errorf("the %s path %s is not in %s", fileType, filePath, dirTreeDesc)
 	}
 
 	return nil
 }
 
+// evaluateIncludeWhen evaluates the includeWhen condition of a manifest. The condition is either a
+// single operand, which must resolve to a boolean, or two operands compared with == or !=. An
+// operand is an environment variable in the format of ${NAME}, an entry of the generator values in
+// the format of values.NAME, or otherwise a literal string. Operands may be wrapped in quotes. An
+// error is returned if the condition is invalid or references a value or environment variable that
+// isn't set.
+func evaluateIncludeWhen(condition string, values map[string]string) (bool, error) {
+	for _, operator := range []string{"==", "!="} {
+		left, right, found := strings.Cut(condition, operator)
+		if !found {
+			continue
+		}
+
+		leftValue, err := resolveIncludeWhenOperand(left, values)
+		if err != nil {
+			return false, err
+		}
+
+		rightValue, err := resolveIncludeWhenOperand(right, values)
+		if err != nil {
+			return false, err
+		}
+
+		return (leftValue == rightValue) == (operator == "=="), nil
+	}
+
+	value, err := resolveIncludeWhenOperand(condition, values)
+	if err != nil {
+		return false, err
+	}
+
+	include, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf(
+			"the condition %s must be a comparison with == or != or resolve to true or false, but got %s",
+			condition, value,
+		)
+	}
+
+	return include, nil
+}
+
+// resolveIncludeWhenOperand returns the value of an operand in an includeWhen condition. See
+// evaluateIncludeWhen for the supported operands.
+func resolveIncludeWhenOperand(operand string, values map[string]string) (string, error) {
+	operand = strings.TrimSpace(operand)
+	if operand == "" {
+		return "", errors.New("the condition has an empty operand")
+	}
+
+	if len(operand) >= 2 && (operand[0] == '"' || operand[0] == '\'') && operand[len(operand)-1] == operand[0] {
+		operand = operand[1 : len(operand)-1]
+	}
+
+	if strings.HasPrefix(operand, "${") && strings.HasSuffix(operand, "}") {
+		name := operand[2 : len(operand)-1]
+
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("the environment variable %s is not set", name)
+		}
+
+		return value, nil
+	}
+
+	if name, found := strings.CutPrefix(operand, "values."); found {
+		value, ok := values[name]
+		if !ok {
+			return "", fmt.Errorf("the value %s is not set in values", name)
+		}
+
+		return value, nil
+	}
+
+	return operand, nil
+}
+
+// substitutionVariableRegex matches ${NAME} variables. A variable escaped as $${NAME} is replaced
+// with the literal ${NAME}.
+var substitutionVariableRegex = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// substituteVariables replaces the ${NAME} variables in the input configuration with their values
+// based on the input options. An error is returned listing the undefined variables unless
+// options.AllowUndefined is set.
+func substituteVariables(config []byte, options SubstitutionOptions) ([]byte, error) {
+	undefined := []string{}
+
+	substituted := substitutionVariableRegex.ReplaceAllFunc(config, func(match []byte) []byte {
+		if bytes.HasPrefix(match, []byte("$$")) {
+			return match[1:]
+		}
+
+		name := string(match[2 : len(match)-1])
+
+		if value, ok := options.Values[name]; ok {
+			return []byte(value)
+		}
+
+		if options.UseEnv {
+			if value, ok := os.LookupEnv(name); ok {
+				return []byte(value)
+			}
+		}
+
+		if !options.AllowUndefined && !slices.Contains(undefined, name) {
+			undefined = append(undefined, name)
+		}
+
+		return []byte{}
+	})
+
+	if len(undefined) > 0 {
+		return nil, fmt.Errorf("the configuration references undefined variables: %s", strings.Join(undefined, ", "))
+	}
+
+	return substituted, nil
+}
+
+// copyYAMLValue returns a deep copy of the input value decoded from YAML so that modifying the copy
+// doesn't modify the input value.
+func copyYAMLValue(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(typedValue))
+		for key, val := range typedValue {
+			copied[key] = copyYAMLValue(val)
+		}
+
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(typedValue))
+		for i, val := range typedValue {
+			copied[i] = copyYAMLValue(val)
+		}
+
+		return copied
+	default:
+		return value
+	}
+}
+
+// getPolicyTemplatesHash returns a hex encoded SHA-256 hash of the input policy templates. The
+// templates are serialized to JSON first, which sorts map keys, so the hash is stable across runs
+// for identical input.
+func getPolicyTemplatesHash(policyTemplates []map[string]interface{}) (string, error) {
+	policyTemplatesJSON, err := json.Marshal(policyTemplates)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert the policy templates to JSON: %w", err)
+	}
+
+	hash := sha256.Sum256(policyTemplatesJSON)
+
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// mergePlacementSpec deep merges the user provided overrides into the spec of a generated placement.
+// The overrides take precedence except for the fields derived from the generator configuration
+// (the cluster selectors and the decision strategy), which are always kept. Lists are replaced
+// rather than merged.
+func mergePlacementSpec(spec map[string]interface{}, overrides map[string]interface{}) {
+	generatorFields := map[string]bool{
+		"clusterSelector": true, "decisionStrategy": true, "predicates": true, "spreadPolicy": true,
+	}
+
+	for key, value := range overrides {
+		if _, set := spec[key]; set && generatorFields[key] {
+			continue
+		}
+
+		spec[key] = mergeValues(spec[key], value)
+	}
+}
+
+// mergeValues returns the override value deep merged into the existing value. If both values are
+// maps, they are merged recursively with the override taking precedence. Otherwise, the override
+// value is returned.
+func mergeValues(existing interface{}, override interface{}) interface{} {
+	existingMap, ok := existing.(map[string]interface{})
+	if !ok {
+		return override
+	}
+
+	overrideMap, ok := override.(map[string]interface{})
+	if !ok {
+		return override
+	}
+
+	merged := make(map[string]interface{}, len(existingMap)+len(overrideMap))
+
+	for key, value := range existingMap {
+		merged[key] = value
+	}
+
+	for key, value := range overrideMap {
+		merged[key] = mergeValues(merged[key], value)
+	}
+
+	return merged
+}
+
 // Check policy-templates to see if all the remediation actions match, if so return the root policy remediation action
 func getRootRemediationAction(policyTemplates []map[string]interface{}) string {
 	var action string
@@ -708,3 +1835,383 @@ func getRootRemediationAction(policyTemplates []map[string]interface{}) string {
 
 	return action
 }
+
+// canonicalRemediationActions are the remediationAction values in their canonical casing.
+var canonicalRemediationActions = []string{"inform", "enforce", "InformOnly"}
+
+// normalizeRemediationAction returns the canonical casing of the input remediationAction, such as
+// inform for Inform. Unknown values are returned as is.
+func normalizeRemediationAction(action string) string {
+	for _, canonicalAction := range canonicalRemediationActions {
+		if strings.EqualFold(action, canonicalAction) {
+			return canonicalAction
+		}
+	}
+
+	return action
+}
+
+// clearTemplateRemediationActions removes the remediationAction from the spec of each of the input
+// policy templates so that the remediationAction of the root policy applies to them.
+func clearTemplateRemediationActions(policyTemplates []map[string]interface{}) {
+	for _, policyTemplate := range policyTemplates {
+		unstructured.RemoveNestedField(policyTemplate, "objectDefinition", "spec", "remediationAction")
+	}
+}
+
+// mergeConfigDocuments merges the PolicyGenerator documents in the input multi-document YAML into a
+// single document. The policies and policySets of the documents are combined and every other field
+// must have the same value in each document that sets it. If there is only one document, the input
+// is returned unmodified.
+func mergeConfigDocuments(config []byte) ([]byte, error) {
+	strictDec := yaml.NewDecoder(bytes.NewReader(config))
+	strictDec.KnownFields(true) // emit an error on unknown fields in the input
+
+	dec := yaml.NewDecoder(bytes.NewReader(config))
+	docs := []map[string]interface{}{}
+
+	for docNum := 1; ; docNum++ {
+		var doc map[string]interface{}
+
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		// Let the regular decoding of the first document report its errors
+		if err != nil && docNum == 1 {
+			return config, nil
+		}
+
+		if err == nil {
+			err = strictDec.Decode(&Plugin{})
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the PolicyGenerator document %d: %w", docNum, addFieldNotFoundHelp(err))
+		}
+
+		if doc != nil {
+			docs = append(docs, doc)
+		}
+	}
+
+	if len(docs) < 2 {
+		return config, nil
+	}
+
+	merged := docs[0]
+
+	for i, doc := range docs[1:] {
+		for key, value := range doc {
+			if key == "policies" || key == "policySets" {
+				items, _ := value.([]interface{})
+				existing, _ := merged[key].([]interface{})
+				merged[key] = append(existing, items...)
+
+				continue
+			}
+
+			if existing, ok := merged[key]; ok && !reflect.DeepEqual(existing, value) {
+				return nil, fmt.Errorf(
+					"the PolicyGenerator document %d has a %s value that conflicts with a previous document", i+2, key,
+				)
+			}
+
+			merged[key] = value
+		}
+	}
+
+	mergedConfig, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge the PolicyGenerator documents: %w", err)
+	}
+
+	return mergedConfig, nil
+}
+
+// preRenderObjectTemplatesRaw renders the input object-templates-raw content as a Go template with
+// the input values at generation time. The template uses the [[ ]] delimiters so that the {{ }}
+// delimiters of the OCM templates in the content are preserved for the policy framework to resolve.
+func preRenderObjectTemplatesRaw(objectTemplatesRaw string, values map[string]interface{}) (string, error) {
+	tmpl, err := template.New("object-templates-raw").Delims("[[", "]]").Option("missingkey=error").Parse(
+		objectTemplatesRaw,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the object-templates-raw for preRender: %w", err)
+	}
+
+	var rendered bytes.Buffer
+
+	err = tmpl.Execute(&rendered, values)
+	if err != nil {
+		return "", fmt.Errorf("failed to preRender the object-templates-raw: %w", err)
+	}
+
+	return rendered.String(), nil
+}
+
+// parseDescriptionTemplate parses the input policyDefaults.descriptionTemplate as a Go template.
+// The join function is available to join the lists, such as {{ join .Standards ", " }}.
+func parseDescriptionTemplate(descriptionTemplate string) (*template.Template, error) {
+	tmpl, err := template.New("description").Funcs(template.FuncMap{"join": strings.Join}).Parse(
+		descriptionTemplate,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the policyDefaults.descriptionTemplate: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// renderDescription renders the input policyDefaults.descriptionTemplate with the name,
+// categories, controls, and standards of the input policy to compute its description.
+func renderDescription(descriptionTemplate string, policyConf *types.PolicyConfig) (string, error) {
+	tmpl, err := parseDescriptionTemplate(descriptionTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	data := struct {
+		Name       string
+		Categories []string
+		Controls   []string
+		Standards  []string
+	}{
+		Name:       policyConf.Name,
+		Categories: policyConf.Categories,
+		Controls:   policyConf.Controls,
+		Standards:  policyConf.Standards,
+	}
+
+	var rendered bytes.Buffer
+
+	err = tmpl.Execute(&rendered, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to render the policyDefaults.descriptionTemplate: %w", err)
+	}
+
+	return rendered.String(), nil
+}
+
+// parseBindingNameTemplate parses the input placementBindingDefaults.nameTemplate and verifies that
+// it only references the fields available to it by rendering it with placeholder values.
+func parseBindingNameTemplate(nameTemplate string) (*template.Template, error) {
+	tmpl, err := template.New("bindingName").Parse(nameTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the placementBindingDefaults.nameTemplate: %w", err)
+	}
+
+	err = tmpl.Execute(io.Discard, bindingNameData{})
+	if err != nil {
+		return nil, fmt.Errorf("the placementBindingDefaults.nameTemplate is invalid: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// bindingNameData is the data that the placementBindingDefaults.nameTemplate is rendered with.
+type bindingNameData struct {
+	PlacementName string
+	PolicyName    string
+}
+
+// renderBindingName renders the input placementBindingDefaults.nameTemplate for the placement
+// binding to the input placement with the input subjects. The PolicyName is the name of the first
+// policy in the placement binding, or of the first policy set when there are no policies. An error is
+// returned if the rendered name isn't DNS compliant.
+func renderBindingName(nameTemplate string, placementName string, subjects bindingSubjects) (string, error) {
+	tmpl, err := parseBindingNameTemplate(nameTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	data := bindingNameData{PlacementName: placementName}
+
+	if len(subjects.policyConfs) > 0 {
+		data.PolicyName = subjects.policyConfs[0].Name
+	} else if len(subjects.policySetConfs) > 0 {
+		data.PolicyName = subjects.policySetConfs[0].Name
+	}
+
+	var rendered bytes.Buffer
+
+	err = tmpl.Execute(&rendered, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to render the placementBindingDefaults.nameTemplate: %w", err)
+	}
+
+	name := rendered.String()
+	if len(validation.IsDNS1123Subdomain(name)) > 0 {
+		return "", fmt.Errorf(
+			"the placement binding name `%s` rendered from placementBindingDefaults.nameTemplate is not DNS compliant. "+
+				"See %s",
+			name, dnsReference,
+		)
+	}
+
+	return name, nil
+}
+
+// getFirstEmbeddedObject returns the first object embedded in the input policy templates. For a
+// generated ConfigurationPolicy, this is the object of its first object template. Otherwise, it's
+// the policy template's object definition itself.
+func getFirstEmbeddedObject(policyTemplates []map[string]interface{}) map[string]interface{} {
+	if len(policyTemplates) == 0 {
+		return nil
+	}
+
+	objDef, _ := policyTemplates[0]["objectDefinition"].(map[string]interface{})
+	if objDef == nil || objDef["kind"] != configPolicyKind {
+		return objDef
+	}
+
+	spec, _ := objDef["spec"].(map[string]interface{})
+
+	switch objTemplates := spec["object-templates"].(type) {
+	case []map[string]interface{}:
+		if len(objTemplates) != 0 {
+			if obj, ok := objTemplates[0]["objectDefinition"].(map[string]interface{}); ok {
+				return obj
+			}
+		}
+	case []interface{}:
+		if len(objTemplates) != 0 {
+			if objTemplate, ok := objTemplates[0].(map[string]interface{}); ok {
+				if obj, ok := objTemplate["objectDefinition"].(map[string]interface{}); ok {
+					return obj
+				}
+			}
+		}
+	}
+
+	return objDef
+}
+
+// getDisabledFromAnnotation returns the boolean value of the input annotation on the first object
+// embedded in the input policy templates and whether the annotation is set. An error is returned if
+// the annotation value isn't a boolean.
+func getDisabledFromAnnotation(
+	policyTemplates []map[string]interface{}, annotation string,
+) (disabled bool, set bool, err error) {
+	obj := getFirstEmbeddedObject(policyTemplates)
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+
+	value, ok := annotations[annotation]
+	if !ok {
+		return false, false, nil
+	}
+
+	switch typedValue := value.(type) {
+	case bool:
+		return typedValue, true, nil
+	case string:
+		disabled, err = strconv.ParseBool(typedValue)
+		if err == nil {
+			return disabled, true, nil
+		}
+	}
+
+	return false, false, fmt.Errorf(
+		"the %s annotation of the first embedded object must be true or false but got %v", annotation, value,
+	)
+}
+
+// getDerivedLabels returns the labels derived from the input dot-separated field paths, such as
+// metadata.labels.team, of the first object embedded in the input policy templates. The label key
+// is the last segment of the field path. See parseFieldPath for the field path format. Fields that
+// are missing, aren't a string, boolean, or number, or aren't a valid label value are skipped.
+func getDerivedLabels(policyTemplates []map[string]interface{}, fieldPaths []string) map[string]string {
+	labels := map[string]string{}
+
+	if len(fieldPaths) == 0 {
+		return labels
+	}
+
+	obj := getFirstEmbeddedObject(policyTemplates)
+	if obj == nil {
+		return labels
+	}
+
+	for _, fieldPath := range fieldPaths {
+		fields, ok := parseFieldPath(fieldPath)
+		if !ok {
+			continue
+		}
+
+		value, found, err := unstructured.NestedFieldNoCopy(obj, fields...)
+		if err != nil || !found {
+			continue
+		}
+
+		var labelValue string
+
+		switch typedValue := value.(type) {
+		case string:
+			labelValue = typedValue
+		case bool, int, int64, float64:
+			labelValue = fmt.Sprint(typedValue)
+		default:
+			continue
+		}
+
+		if len(validation.IsValidLabelValue(labelValue)) != 0 {
+			continue
+		}
+
+		labels[fields[len(fields)-1]] = labelValue
+	}
+
+	return labels
+}
+
+// parseFieldPath returns the segments of the input dot-separated field path. A segment that
+// contains dots is wrapped in brackets, such as metadata.labels[app.kubernetes.io/name]. It returns
+// false if the field path is empty, has an empty segment, or has an unclosed bracket.
+func parseFieldPath(fieldPath string) ([]string, bool) {
+	fields := []string{}
+	rest := fieldPath
+
+	for rest != "" {
+		var field string
+
+		if strings.HasPrefix(rest, "[") {
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return nil, false
+			}
+
+			field, rest = rest[1:end], rest[end+1:]
+		} else {
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+
+			field, rest = rest[:end], rest[end:]
+		}
+
+		if field == "" {
+			return nil, false
+		}
+
+		fields = append(fields, field)
+
+		if strings.HasPrefix(rest, ".") {
+			rest = rest[1:]
+
+			if rest == "" {
+				return nil, false
+			}
+		} else if rest != "" && !strings.HasPrefix(rest, "[") {
+			return nil, false
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil, false
+	}
+
+	return fields, true
+}
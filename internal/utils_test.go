@@ -145,6 +145,43 @@ func compareSelectors(a map[string]interface{}, b types.NamespaceSelector) bool
 	return true
 }
 
+func TestMergePlacementSpec(t *testing.T) {
+	t.Parallel()
+
+	spec := map[string]interface{}{
+		"clusterSelector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{"cloud": "red hat"},
+		},
+		"spread": map[string]interface{}{
+			"spreadConstraints": []interface{}{"zone"},
+			"maxSkew":           1,
+		},
+	}
+
+	mergePlacementSpec(spec, map[string]interface{}{
+		"clusterSelector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{"cloud": "other"},
+		},
+		"spread": map[string]interface{}{
+			"spreadConstraints": []interface{}{"region"},
+		},
+		"custom": "value",
+	})
+
+	expected := map[string]interface{}{
+		"clusterSelector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{"cloud": "red hat"},
+		},
+		"spread": map[string]interface{}{
+			"spreadConstraints": []interface{}{"region"},
+			"maxSkew":           1,
+		},
+		"custom": "value",
+	}
+
+	assertReflectEqual(t, spec, expected)
+}
+
 func TestGetPolicyTemplate(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
@@ -244,7 +281,8 @@ data:
 			t.Parallel()
 			policyConf := types.PolicyConfig{
 				PolicyOptions: types.PolicyOptions{
-					ConsolidateManifests: true,
+					AllowDuplicateObjects: true,
+					ConsolidateManifests:  true,
 				},
 				ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
 					ComplianceType:    "musthave",
@@ -255,7 +293,7 @@ data:
 				Name:      "policy-app-config",
 			}
 
-			policyTemplates, err := getPolicyTemplates(&policyConf)
+			policyTemplates, err := getPolicyTemplates(&policyConf, nil)
 			if err != nil {
 				t.Fatalf("Failed to get the policy templates: %v", err)
 			}
@@ -430,7 +468,7 @@ resources:
 			Name: "policy-kustomize",
 		}
 
-		policyTemplates, err := getPolicyTemplates(&policyConf)
+		policyTemplates, err := getPolicyTemplates(&policyConf, nil)
 		if err != nil {
 			if test.ErrMsg != "" {
 				assertEqual(t, err.Error(), test.ErrMsg)
@@ -510,7 +548,7 @@ helmCharts:
 		Name: "policy-kustomize-helm",
 	}
 
-	_, err = getPolicyTemplates(&policyConf)
+	_, err = getPolicyTemplates(&policyConf, nil)
 	if err == nil {
 		t.Fatal("Expected this to fail without POLICY_GEN_ENABLE_HELM=true")
 	}
@@ -525,7 +563,7 @@ helmCharts:
 		_ = os.Unsetenv("POLICY_GEN_ENABLE_HELM")
 	}()
 
-	_, err = getPolicyTemplates(&policyConf)
+	_, err = getPolicyTemplates(&policyConf, nil)
 	if err == nil {
 		t.Fatal("Expected this to fail without POLICY_GEN_DISABLE_LOAD_RESTRICTORS=true")
 	}
@@ -547,7 +585,7 @@ helmCharts:
 		_ = os.Unsetenv("POLICY_GEN_DISABLE_LOAD_RESTRICTORS")
 	}()
 
-	policyTemplates, err := getPolicyTemplates(&policyConf)
+	policyTemplates, err := getPolicyTemplates(&policyConf, nil)
 	if err != nil {
 		t.Fatalf("Expected one manifest from rendering the Helm chart, but got: %v", err)
 	}
@@ -643,7 +681,7 @@ data:
 			Name:      "policy-app-config",
 		}
 
-		policyTemplates, err := getPolicyTemplates(&policyConf)
+		policyTemplates, err := getPolicyTemplates(&policyConf, nil)
 		if err != nil {
 			t.Fatalf("Failed to get the policy templates: %v", err)
 		}
@@ -909,7 +947,7 @@ func TestGetPolicyTemplateFromPolicyTypeManifest(t *testing.T) {
 			},
 		}
 
-		policyTemplates, err := getPolicyTemplates(&policyConf)
+		policyTemplates, err := getPolicyTemplates(&policyConf, nil)
 		if err != nil {
 			t.Fatalf("Failed to get the policy templates: %v", err)
 		}
@@ -984,7 +1022,7 @@ data:
 		Name:      "policy-app-config",
 	}
 
-	policyTemplates, err := getPolicyTemplates(&policyConf)
+	policyTemplates, err := getPolicyTemplates(&policyConf, nil)
 	if err != nil {
 		t.Fatalf("Failed to get the policy templates: %v", err)
 	}
@@ -1072,7 +1110,7 @@ data:
 		Name:      "policy-app-config",
 	}
 
-	policyTemplates, err := getPolicyTemplates(&policyConf)
+	policyTemplates, err := getPolicyTemplates(&policyConf, nil)
 	if err != nil {
 		t.Fatalf("Failed to get the policy templates: %v ", err)
 	}
@@ -1181,234 +1219,1490 @@ data:
 		Name:      "policy-app-config",
 	}
 
-	_, err = getPolicyTemplates(&policyConf)
+	_, err = getPolicyTemplates(&policyConf, nil)
 	assertEqual(t, err != nil, true)
 }
 
-func TestGetPolicyTemplateKyverno(t *testing.T) {
+func TestGetPolicyTemplateStrictPatches(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
-	manifestPath := path.Join(tmpDir, "kyverno.yaml")
+	manifestPath := path.Join(tmpDir, "patch-configmap.yaml")
 	manifestYAML := `
-apiVersion: kyverno.io/v1
-kind: ClusterPolicy
+---
+apiVersion: v1
+kind: configmap
 metadata:
-  name: my-awesome-policy`
+  name: test-configmap
+  namespace: test-namespace
+data:
+  image: "quay.io/potatos1"
+`
 
 	err := os.WriteFile(manifestPath, []byte(manifestYAML), 0o666)
 	if err != nil {
 		t.Fatalf("Failed to write %s", manifestPath)
 	}
 
-	policyConf := types.PolicyConfig{
-		PolicyOptions: types.PolicyOptions{
-			InformKyvernoPolicies: true,
+	tests := map[string]struct {
+		patches     []map[string]interface{}
+		expectedErr string
+	}{
+		"metadata patch": {
+			patches: []map[string]interface{}{
+				{"metadata": map[string]interface{}{"name": "patch-configmap"}},
+			},
 		},
-		ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
-			ComplianceType:    "musthave",
-			RemediationAction: "enforce",
-			Severity:          "low",
+		"data patch": {
+			patches: []map[string]interface{}{
+				{"data": map[string]interface{}{"image": "quay.io/potatos2"}},
+			},
+		},
+		"no-op patch": {
+			patches: []map[string]interface{}{
+				{"data": map[string]interface{}{"image": "quay.io/potatos1"}},
+			},
+			expectedErr: fmt.Sprintf(
+				`failed to process the manifest at "%s": the patch at index 0 did not change any manifest`,
+				manifestPath,
+			),
+		},
+		"stale name patch": {
+			patches: []map[string]interface{}{
+				{"data": map[string]interface{}{"image": "quay.io/potatos2"}},
+				{
+					"metadata": map[string]interface{}{"name": "old-configmap"},
+					"data":     map[string]interface{}{"image": "quay.io/potatos3"},
+				},
+			},
+			expectedErr: fmt.Sprintf(
+				`failed to process the manifest at "%s": the patch at index 1 targets the configmap `+
+					`"old-configmap" which is not in the manifests`,
+				manifestPath,
+			),
 		},
-		Manifests: []types.Manifest{{Path: manifestPath}},
-		Name:      "policy-kyverno-config",
-	}
-
-	policyTemplates, err := getPolicyTemplates(&policyConf)
-	if err != nil {
-		t.Fatalf("Failed to get the policy templates: %v", err)
 	}
 
-	assertEqual(t, len(policyTemplates), 2)
-
-	// This is not an in-depth test since the Kyverno expansion is tested elsewhere. This is
-	// to test that glue code is working as expected.
-	expandedPolicyTemplate := policyTemplates[1]
-	objdef := expandedPolicyTemplate["objectDefinition"].(map[string]interface{})
-
-	spec, ok := objdef["spec"].(map[string]interface{})
-	if !ok {
-		t.Fatal("The spec field is an invalid format")
-	}
+	for name, test := range tests {
+		test := test
 
-	objTemplates, ok := spec["object-templates"].([]map[string]interface{})
-	if !ok {
-		t.Fatal("The object-templates field is an invalid format")
-	}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
 
-	assertEqual(t, len(objTemplates), 2)
-	assertEqual(t, objTemplates[0]["complianceType"], "mustnothave")
-	assertEqual(t, objTemplates[0]["metadataComplianceType"], nil)
+			policyConf := types.PolicyConfig{
+				PolicyOptions: types.PolicyOptions{StrictPatches: true},
+				Manifests:     []types.Manifest{{Path: manifestPath, Patches: test.patches}},
+				Name:          "policy-app-config",
+			}
 
-	kind1, ok := objTemplates[0]["objectDefinition"].(map[string]interface{})["kind"]
-	if !ok {
-		t.Fatal("The objectDefinition field is an invalid format")
-	}
+			_, err := getPolicyTemplates(&policyConf, nil)
+			if test.expectedErr == "" {
+				assertEqual(t, err, nil)
 
-	assertEqual(t, kind1, "ClusterPolicyReport")
+				return
+			}
 
-	assertEqual(t, objTemplates[1]["complianceType"], "mustnothave")
-	assertEqual(t, objTemplates[1]["metadataComplianceType"], nil)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
 
-	kind2, ok := objTemplates[1]["objectDefinition"].(map[string]interface{})["kind"]
-	if !ok {
-		t.Fatal("The objectDefinition field is an invalid format")
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
 	}
-
-	assertEqual(t, kind2, "PolicyReport")
 }
 
-func TestGetPolicyTemplateNoManifests(t *testing.T) {
+func TestGetPolicyTemplateComplianceTypeByIndex(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
-	policyConf := types.PolicyConfig{
-		ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
-			ComplianceType:    "musthave",
-			RemediationAction: "inform",
-			Severity:          "low",
-		},
-		Manifests: []types.Manifest{{Path: tmpDir}},
-		Name:      "policy-app-config",
-	}
+	manifestPath := path.Join(tmpDir, "multi-configmaps.yaml")
+	manifestYAML := `
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: allowed-configmap
+  namespace: test-namespace
+data:
+  image: "quay.io/potatos1"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: forbidden-configmap
+  namespace: test-namespace
+`
 
-	_, err := getPolicyTemplates(&policyConf)
-	if err == nil {
-		t.Fatal("Expected an error but did not get one")
+	err := os.WriteFile(manifestPath, []byte(manifestYAML), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write %s", manifestPath)
 	}
 
-	expected := "the policy policy-app-config must specify at least one non-empty manifest file"
-	assertEqual(t, err.Error(), expected)
-}
+	manifest := types.Manifest{
+		Path:                  manifestPath,
+		ComplianceTypeByIndex: map[int]string{1: "mustnothave"},
+	}
+	manifest.ComplianceType = "musthave"
 
-func TestGetPolicyTemplateInvalidPath(t *testing.T) {
-	t.Parallel()
-	tmpDir := t.TempDir()
-	manifestPath := path.Join(tmpDir, "does-not-exist.yaml")
 	policyConf := types.PolicyConfig{
-		ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
-			ComplianceType:    "musthave",
-			RemediationAction: "inform",
-			Severity:          "low",
-		},
-		Manifests: []types.Manifest{{
-			ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
-				ComplianceType: "musthave",
-			},
-			Path: manifestPath,
-		}},
-		Name: "policy-app-config",
+		PolicyOptions: types.PolicyOptions{ConsolidateManifests: false},
+		Manifests:     []types.Manifest{manifest},
+		Name:          "policy-app-config",
 	}
 
-	_, err := getPolicyTemplates(&policyConf)
-	if err == nil {
-		t.Fatal("Expected an error but did not get one")
+	policyTemplates, err := getPolicyTemplates(&policyConf, nil)
+	if err != nil {
+		t.Fatalf("Failed to get the policy templates: %v ", err)
 	}
 
-	expected := fmt.Sprintf("failed to read the manifest path %s", manifestPath)
-	assertEqual(t, err.Error(), expected)
-}
+	assertEqual(t, len(policyTemplates), 2)
 
-func TestGetPolicyTemplateInvalidManifest(t *testing.T) {
-	t.Parallel()
-	tmpDir := t.TempDir()
-	manifestPath := path.Join(tmpDir, "configmap.yaml")
-	// Ensure an error is returned when there is an invalid manifest file
-	err := os.WriteFile(manifestPath, []byte("$i am not YAML!"), 0o666)
-	if err != nil {
-		t.Fatalf("Failed to write %s", manifestPath)
-	}
+	for i, expected := range []string{"musthave", "mustnothave"} {
+		objdef := policyTemplates[i]["objectDefinition"].(map[string]interface{})
 
-	policyConf := types.PolicyConfig{
-		ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
-			ComplianceType:    "musthave",
-			RemediationAction: "inform",
-			Severity:          "low",
-		},
-		Manifests: []types.Manifest{{Path: manifestPath}},
-		Name:      "policy-app-config",
+		spec, ok := objdef["spec"].(map[string]interface{})
+		if !ok {
+			t.Fatal("The spec field is an invalid format")
+		}
+
+		objTemplates, ok := spec["object-templates"].([]map[string]interface{})
+		if !ok {
+			t.Fatal("The object-templates field is an invalid format")
+		}
+
+		assertEqual(t, len(objTemplates), 1)
+		assertEqual(t, objTemplates[0]["complianceType"], expected)
 	}
 
-	_, err = getPolicyTemplates(&policyConf)
+	manifest.ComplianceTypeByIndex = map[int]string{2: "mustnothave"}
+	policyConf.Manifests = []types.Manifest{manifest}
+
+	_, err = getPolicyTemplates(&policyConf, nil)
 	if err == nil {
 		t.Fatal("Expected an error but did not get one")
 	}
 
 	expected := fmt.Sprintf(
-		"failed to decode the manifest file at %s: the input manifests must be in the format of "+
-			"YAML objects", manifestPath,
+		"the complianceTypeByIndex index 2 is out of range since there are 2 documents in manifest path: %s",
+		manifestPath,
 	)
 	assertEqual(t, err.Error(), expected)
+
+	// A manifest skipped by includeWhen doesn't have its indexes checked
+	skippedManifest := manifest
+	skippedManifest.IncludeWhen = "false"
+	policyConf.Manifests = []types.Manifest{{Path: manifestPath}, skippedManifest}
+
+	policyTemplates, err = getPolicyTemplates(&policyConf, nil)
+	if err != nil {
+		t.Fatalf("Failed to get the policy templates: %v ", err)
+	}
+
+	assertEqual(t, len(policyTemplates), 2)
 }
 
-func TestGetPolicyTemplateObjectTemplatesRaw(t *testing.T) {
+func TestGetPolicyTemplateManifestConsolidate(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
-	manifestPath := path.Join(tmpDir, "object-templates-raw.yaml")
-	manifestYAMLMultiple := `
-object-templates-raw: |
-  content1
----
-object-templates-raw: |
-  content2
-`
-	manifestYAMLContent1 := `content1
-`
-	manifestYAMLContent2 := `content2
-`
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	manifestPath := path.Join(tmpDir, "configmap.yaml")
 
-	err := os.WriteFile(manifestPath, []byte(manifestYAMLMultiple), 0o666)
-	if err != nil {
-		t.Fatalf("Failed to write %s", manifestPath)
-	}
+	consolidate := true
+	noConsolidate := false
 
-	policyConf := types.PolicyConfig{
-		PolicyOptions: types.PolicyOptions{
-			ConsolidateManifests: true,
+	tests := map[string]struct {
+		consolidateManifests bool
+		manifests            []types.Manifest
+		expectedNames        []string
+		expectedCounts       []int
+	}{
+		"standalone manifest in a consolidated policy": {
+			consolidateManifests: true,
+			manifests: []types.Manifest{
+				{Path: manifestPath},
+				{Path: manifestPath, Name: "large-manifest", Consolidate: &noConsolidate},
+				{Path: manifestPath},
+			},
+			expectedNames:  []string{"large-manifest", "policy-app-config"},
+			expectedCounts: []int{1, 2},
 		},
-		ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
-			ComplianceType:    "musthave",
-			RemediationAction: "enforce",
-			Severity:          "low",
+		"consolidated manifests in a policy without consolidation": {
+			consolidateManifests: false,
+			manifests: []types.Manifest{
+				{Path: manifestPath},
+				{Path: manifestPath, Consolidate: &consolidate},
+				{Path: manifestPath, Consolidate: &consolidate},
+			},
+			expectedNames:  []string{"policy-app-config", "policy-app-config2"},
+			expectedCounts: []int{1, 2},
 		},
-		Manifests: []types.Manifest{{Path: manifestPath}},
-		Name:      "configpolicy-object-templates-raw-config",
 	}
 
-	policyTemplates, err := getPolicyTemplates(&policyConf)
-	if err != nil {
-		t.Fatalf("Failed to get the policy templates: %v", err)
-	}
+	for name, test := range tests {
+		test := test
 
-	assertEqual(t, len(policyTemplates), 2)
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
 
-	policyTemplate1 := policyTemplates[0]
-	objdef := policyTemplate1["objectDefinition"].(map[string]interface{})
+			for i := range test.manifests {
+				test.manifests[i].ComplianceType = "musthave"
+			}
 
-	spec, ok := objdef["spec"].(map[string]interface{})
-	if !ok {
-		t.Fatal("The spec field is an invalid format")
-	}
+			policyConf := types.PolicyConfig{
+				PolicyOptions: types.PolicyOptions{
+					AllowDuplicateObjects: true,
+					ConsolidateManifests:  test.consolidateManifests,
+				},
+				Manifests: test.manifests,
+				Name:      "policy-app-config",
+			}
 
-	objectTemplatesRaw, ok := spec["object-templates-raw"].(string)
-	if !ok {
-		t.Fatal("The object-templates-raw field is an invalid format")
-	}
+			policyTemplates, err := getPolicyTemplates(&policyConf, nil)
+			if err != nil {
+				t.Fatalf("Failed to get the policy templates: %v ", err)
+			}
 
-	assertEqual(t, objectTemplatesRaw, manifestYAMLContent1)
+			assertEqual(t, len(policyTemplates), len(test.expectedNames))
 
-	policyTemplate2 := policyTemplates[1]
-	objdef = policyTemplate2["objectDefinition"].(map[string]interface{})
+			for i, policyTemplate := range policyTemplates {
+				name, _, _ := unstructured.NestedString(policyTemplate, "objectDefinition", "metadata", "name")
+				assertEqual(t, name, test.expectedNames[i])
 
-	spec, ok = objdef["spec"].(map[string]interface{})
-	if !ok {
-		t.Fatal("The spec field is an invalid format")
+				objDef, _ := policyTemplate["objectDefinition"].(map[string]interface{})
+				spec, _ := objDef["spec"].(map[string]interface{})
+				objTemplates, _ := spec["object-templates"].([]map[string]interface{})
+				assertEqual(t, len(objTemplates), test.expectedCounts[i])
+			}
+		})
 	}
+}
 
-	objectTemplatesRaw, ok = spec["object-templates-raw"].(string)
-	if !ok {
+func TestGetPolicyTemplateDuplicateObjects(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	createConfigMap(t, tmpDir, "configmap2.yaml")
+
+	for _, allowDuplicateObjects := range []bool{false, true} {
+		policyConf := types.PolicyConfig{
+			PolicyOptions: types.PolicyOptions{
+				AllowDuplicateObjects: allowDuplicateObjects,
+				ConsolidateManifests:  true,
+			},
+			Manifests: []types.Manifest{
+				{Path: path.Join(tmpDir, "configmap.yaml")},
+				{Path: path.Join(tmpDir, "configmap2.yaml")},
+			},
+			Name: "policy-app-config",
+		}
+
+		for i := range policyConf.Manifests {
+			policyConf.Manifests[i].ComplianceType = "musthave"
+		}
+
+		policyTemplates, err := getPolicyTemplates(&policyConf, nil)
+		if allowDuplicateObjects {
+			if err != nil {
+				t.Fatalf("Failed to get the policy templates: %v ", err)
+			}
+
+			assertEqual(t, len(policyTemplates), 1)
+
+			continue
+		}
+
+		if err == nil {
+			t.Fatal("Expected an error but did not get one")
+		}
+
+		expected := "the policy policy-app-config has consolidated manifests with the duplicate object with the " +
+			"apiVersion v1, kind ConfigMap, and name my-configmap; set allowDuplicateObjects to true to allow it"
+		assertEqual(t, err.Error(), expected)
+	}
+}
+
+func TestGetPolicyTemplateDeduplicateObjects(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	createConfigMap(t, tmpDir, "configmap2.yaml")
+
+	for _, deduplicateObjects := range []bool{false, true} {
+		policyConf := types.PolicyConfig{
+			PolicyOptions: types.PolicyOptions{
+				AllowDuplicateObjects: !deduplicateObjects,
+				ConsolidateManifests:  true,
+				DeduplicateObjects:    deduplicateObjects,
+			},
+			ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{ComplianceType: "musthave"},
+			Manifests:                  []types.Manifest{{Path: tmpDir}},
+			Name:                       "policy-app-config",
+		}
+
+		policyTemplates, err := getPolicyTemplates(&policyConf, nil)
+		if err != nil {
+			t.Fatalf("Failed to get the policy templates: %v ", err)
+		}
+
+		assertEqual(t, len(policyTemplates), 1)
+
+		objdef := policyTemplates[0]["objectDefinition"].(map[string]interface{})
+
+		spec, ok := objdef["spec"].(map[string]interface{})
+		if !ok {
+			t.Fatal("The spec field is an invalid format")
+		}
+
+		objTemplates, ok := spec["object-templates"].([]map[string]interface{})
+		if !ok {
+			t.Fatal("The object-templates field is an invalid format")
+		}
+
+		if deduplicateObjects {
+			assertEqual(t, len(objTemplates), 1)
+		} else {
+			assertEqual(t, len(objTemplates), 2)
+		}
+	}
+}
+
+func TestGetPolicyTemplateConfigurationPolicyName(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	manifestPath := path.Join(tmpDir, "configmap.yaml")
+
+	tests := map[string]struct {
+		consolidate   bool
+		manifests     []types.Manifest
+		expectedNames []string
+		expectedErr   string
+	}{
+		"consolidated": {
+			consolidate:   true,
+			manifests:     []types.Manifest{{Path: manifestPath, Name: "cm"}},
+			expectedNames: []string{"existing-config"},
+		},
+		"not consolidated": {
+			manifests: []types.Manifest{
+				{Path: manifestPath},
+				{Path: manifestPath, Name: "other"},
+			},
+			expectedNames: []string{"existing-config", "other"},
+		},
+		"duplicate name": {
+			manifests: []types.Manifest{
+				{Path: manifestPath},
+				{Path: manifestPath},
+				{Path: manifestPath, Name: "existing-config2"},
+			},
+			expectedErr: "the policy policy-app-config has multiple ConfigurationPolicy policy templates with the name " +
+				"existing-config2; set a unique configurationPolicyName or manifest name",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for i := range test.manifests {
+				test.manifests[i].ComplianceType = "musthave"
+			}
+
+			policyConf := types.PolicyConfig{
+				PolicyOptions: types.PolicyOptions{
+					AllowDuplicateObjects: true,
+					ConsolidateManifests:  test.consolidate,
+				},
+				ConfigurationPolicyName: "existing-config",
+				Manifests:               test.manifests,
+				Name:                    "policy-app-config",
+			}
+
+			policyTemplates, err := getPolicyTemplates(&policyConf, nil)
+			if test.expectedErr != "" {
+				if err == nil {
+					t.Fatal("Expected an error but did not get one")
+				}
+
+				assertEqual(t, err.Error(), test.expectedErr)
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Failed to get the policy templates: %v ", err)
+			}
+
+			names := []string{}
+
+			for _, policyTemplate := range policyTemplates {
+				name, _, _ := unstructured.NestedString(policyTemplate, "objectDefinition", "metadata", "name")
+				names = append(names, name)
+			}
+
+			assertReflectEqual(t, names, test.expectedNames)
+		})
+	}
+}
+
+func TestGetPolicyTemplateManifestOrder(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	manifestPath := path.Join(tmpDir, "configmap.yaml")
+
+	first := 1
+	second := 2
+
+	tests := map[string]struct {
+		manifests     []types.Manifest
+		expectedNames []string
+	}{
+		"no order": {
+			manifests: []types.Manifest{
+				{Path: manifestPath, Name: "cr"},
+				{Path: manifestPath, Name: "crd"},
+			},
+			expectedNames: []string{"cr", "crd"},
+		},
+		"order before unset": {
+			manifests: []types.Manifest{
+				{Path: manifestPath, Name: "other"},
+				{Path: manifestPath, Name: "cr", Order: &second},
+				{Path: manifestPath, Name: "crd", Order: &first},
+			},
+			expectedNames: []string{"crd", "cr", "other"},
+		},
+		"ties keep the declaration order": {
+			manifests: []types.Manifest{
+				{Path: manifestPath, Name: "cr", Order: &second},
+				{Path: manifestPath, Name: "cr2", Order: &second},
+				{Path: manifestPath, Name: "crd", Order: &first},
+			},
+			expectedNames: []string{"crd", "cr", "cr2"},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for i := range test.manifests {
+				test.manifests[i].ComplianceType = "musthave"
+			}
+
+			policyConf := types.PolicyConfig{
+				PolicyOptions: types.PolicyOptions{ConsolidateManifests: false},
+				Manifests:     test.manifests,
+				Name:          "policy-app-config",
+			}
+
+			policyTemplates, err := getPolicyTemplates(&policyConf, nil)
+			if err != nil {
+				t.Fatalf("Failed to get the policy templates: %v ", err)
+			}
+
+			names := []string{}
+
+			for _, policyTemplate := range policyTemplates {
+				name, _, _ := unstructured.NestedString(policyTemplate, "objectDefinition", "metadata", "name")
+				names = append(names, name)
+			}
+
+			assertReflectEqual(t, names, test.expectedNames)
+		})
+	}
+}
+
+func TestGetPolicyTemplatePerNamespace(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	policyConf := types.PolicyConfig{
+		PolicyOptions: types.PolicyOptions{ConsolidateManifests: false},
+		Manifests: []types.Manifest{{
+			ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
+				ComplianceType:    "musthave",
+				NamespaceSelector: types.NamespaceSelector{Include: []string{"app1", "app2"}},
+			},
+			Path:         path.Join(tmpDir, "configmap.yaml"),
+			PerNamespace: true,
+		}},
+		Name: "policy-app-config",
+	}
+
+	policyTemplates, err := getPolicyTemplates(&policyConf, nil)
+	if err != nil {
+		t.Fatalf("Failed to get the policy templates: %v ", err)
+	}
+
+	assertEqual(t, len(policyTemplates), 2)
+
+	for i, expected := range []struct{ name, namespace string }{
+		{"policy-app-config", "app1"}, {"policy-app-config2", "app2"},
+	} {
+		name, _, _ := unstructured.NestedString(policyTemplates[i], "objectDefinition", "metadata", "name")
+		assertEqual(t, name, expected.name)
+
+		selector, _, _ := unstructured.NestedFieldNoCopy(
+			policyTemplates[i], "objectDefinition", "spec", "namespaceSelector",
+		)
+		assertReflectEqual(t, selector, types.NamespaceSelector{Include: []string{expected.namespace}})
+	}
+}
+
+func TestGetPolicyTemplateFromFiles(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	files := map[string][]byte{
+		"app.properties": []byte("color=blue\nsize=large\n"),
+		"app.bin":        {0xff, 0xfe, 0x00, 0x01},
+	}
+
+	for fileName, content := range files {
+		err := os.WriteFile(path.Join(tmpDir, fileName), content, 0o666)
+		if err != nil {
+			t.Fatalf("Failed to write %s", fileName)
+		}
+	}
+
+	secretPath := path.Join(tmpDir, "secret.yaml")
+	secretYAML := `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: my-secret
+  namespace: my-namespace
+type: Opaque
+`
+
+	err := os.WriteFile(secretPath, []byte(secretYAML), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write %s", secretPath)
+	}
+
+	tests := map[string]struct {
+		manifestPath string
+		expected     map[string]interface{}
+	}{
+		"ConfigMap": {
+			manifestPath: path.Join(tmpDir, "configmap.yaml"),
+			expected: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "my-configmap"},
+				"data": map[string]interface{}{
+					"game.properties": "enemies=potato",
+					"app.properties":  "color=blue\nsize=large\n",
+				},
+				"binaryData": map[string]interface{}{"app.bin": "//4AAQ=="},
+			},
+		},
+		"Secret": {
+			manifestPath: secretPath,
+			expected: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Secret",
+				"metadata":   map[string]interface{}{"name": "my-secret", "namespace": "my-namespace"},
+				"type":       "Opaque",
+				"data": map[string]interface{}{
+					"app.properties": "Y29sb3I9Ymx1ZQpzaXplPWxhcmdlCg==",
+					"app.bin":        "//4AAQ==",
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			policyConf := types.PolicyConfig{
+				PolicyOptions: types.PolicyOptions{ConsolidateManifests: true},
+				Manifests: []types.Manifest{{
+					Path: test.manifestPath,
+					FromFiles: map[string]string{
+						"app.properties": path.Join(tmpDir, "app.properties"),
+						"app.bin":        path.Join(tmpDir, "app.bin"),
+					},
+				}},
+				Name: "policy-app-config",
+			}
+
+			policyTemplates, err := getPolicyTemplates(&policyConf, nil)
+			if err != nil {
+				t.Fatalf("Failed to get the policy templates: %v ", err)
+			}
+
+			assertEqual(t, len(policyTemplates), 1)
+
+			objdef := policyTemplates[0]["objectDefinition"].(map[string]interface{})
+
+			spec, ok := objdef["spec"].(map[string]interface{})
+			if !ok {
+				t.Fatal("The spec field is an invalid format")
+			}
+
+			objTemplates, ok := spec["object-templates"].([]map[string]interface{})
+			if !ok {
+				t.Fatal("The object-templates field is an invalid format")
+			}
+
+			assertEqual(t, len(objTemplates), 1)
+			assertReflectEqual(t, objTemplates[0]["objectDefinition"], test.expected)
+		})
+	}
+}
+
+func TestGetPolicyTemplateFromFilesNoTarget(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	createConfigMap(t, tmpDir, "configmap2.yaml")
+
+	err := os.WriteFile(path.Join(tmpDir, "app.properties"), []byte("color=blue"), 0o666)
+	if err != nil {
+		t.Fatal("Failed to write app.properties")
+	}
+
+	policyConf := types.PolicyConfig{
+		PolicyOptions: types.PolicyOptions{ConsolidateManifests: true},
+		Manifests: []types.Manifest{{
+			Path:      tmpDir,
+			FromFiles: map[string]string{"app.properties": path.Join(tmpDir, "app.properties")},
+		}},
+		Name: "policy-app-config",
+	}
+
+	_, err = getPolicyTemplates(&policyConf, nil)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := fmt.Sprintf(
+		`failed to process the manifest at "%s": fromFiles requires a single ConfigMap or Secret in the manifest `+
+			"but found 2",
+		tmpDir,
+	)
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestGetPolicyTemplateKyverno(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	manifestPath := path.Join(tmpDir, "kyverno.yaml")
+	manifestYAML := `
+apiVersion: kyverno.io/v1
+kind: ClusterPolicy
+metadata:
+  name: my-awesome-policy`
+
+	err := os.WriteFile(manifestPath, []byte(manifestYAML), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write %s", manifestPath)
+	}
+
+	policyConf := types.PolicyConfig{
+		PolicyOptions: types.PolicyOptions{
+			InformKyvernoPolicies: true,
+		},
+		ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
+			ComplianceType:    "musthave",
+			RemediationAction: "enforce",
+			Severity:          "low",
+		},
+		Manifests: []types.Manifest{{Path: manifestPath}},
+		Name:      "policy-kyverno-config",
+	}
+
+	policyTemplates, err := getPolicyTemplates(&policyConf, nil)
+	if err != nil {
+		t.Fatalf("Failed to get the policy templates: %v", err)
+	}
+
+	assertEqual(t, len(policyTemplates), 2)
+
+	// This is not an in-depth test since the Kyverno expansion is tested elsewhere. This is
+	// to test that glue code is working as expected.
+	expandedPolicyTemplate := policyTemplates[1]
+	objdef := expandedPolicyTemplate["objectDefinition"].(map[string]interface{})
+
+	spec, ok := objdef["spec"].(map[string]interface{})
+	if !ok {
+		t.Fatal("The spec field is an invalid format")
+	}
+
+	objTemplates, ok := spec["object-templates"].([]map[string]interface{})
+	if !ok {
+		t.Fatal("The object-templates field is an invalid format")
+	}
+
+	assertEqual(t, len(objTemplates), 2)
+	assertEqual(t, objTemplates[0]["complianceType"], "mustnothave")
+	assertEqual(t, objTemplates[0]["metadataComplianceType"], nil)
+
+	kind1, ok := objTemplates[0]["objectDefinition"].(map[string]interface{})["kind"]
+	if !ok {
+		t.Fatal("The objectDefinition field is an invalid format")
+	}
+
+	assertEqual(t, kind1, "ClusterPolicyReport")
+
+	assertEqual(t, objTemplates[1]["complianceType"], "mustnothave")
+	assertEqual(t, objTemplates[1]["metadataComplianceType"], nil)
+
+	kind2, ok := objTemplates[1]["objectDefinition"].(map[string]interface{})["kind"]
+	if !ok {
+		t.Fatal("The objectDefinition field is an invalid format")
+	}
+
+	assertEqual(t, kind2, "PolicyReport")
+}
+
+func TestGetPolicyTemplateKinds(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+	configMapPath := path.Join(tmpDir, "configmap.yaml")
+	kyvernoPath := path.Join(tmpDir, "kyverno.yaml")
+	kyvernoYAML := `
+apiVersion: kyverno.io/v1
+kind: ClusterPolicy
+metadata:
+  name: my-awesome-policy`
+
+	err := os.WriteFile(kyvernoPath, []byte(kyvernoYAML), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write %s", kyvernoPath)
+	}
+
+	configPolicyPath := path.Join(tmpDir, "configpolicy.yaml")
+	configPolicyYAML := `
+apiVersion: policy.open-cluster-management.io/v1
+kind: ConfigurationPolicy
+metadata:
+  name: my-configpolicy
+spec:
+  object-templates:
+    - complianceType: musthave
+      objectDefinition:
+        apiVersion: v1
+        kind: ConfigMap
+        metadata:
+          name: my-configmap
+          namespace: default
+`
+
+	err = os.WriteFile(configPolicyPath, []byte(configPolicyYAML), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write %s", configPolicyPath)
+	}
+
+	tests := []struct {
+		name            string
+		manifestPath    string
+		allowedKinds    []string
+		disallowedKinds []string
+		expectedErr     string
+	}{
+		{name: "no restrictions", manifestPath: configMapPath},
+		{name: "allowed", manifestPath: configMapPath, allowedKinds: []string{"ConfigMap"}},
+		{name: "not disallowed", manifestPath: configMapPath, disallowedKinds: []string{"Secret"}},
+		{
+			name:         "not allowed",
+			manifestPath: configMapPath,
+			allowedKinds: []string{"Secret"},
+			expectedErr: "the kind ConfigMap is not in the allowedKinds of the policy policy-app-config " +
+				"in manifest path: " + configMapPath,
+		},
+		{
+			name:            "disallowed",
+			manifestPath:    configMapPath,
+			disallowedKinds: []string{"ConfigMap"},
+			expectedErr: "the kind ConfigMap is in the disallowedKinds of the policy policy-app-config " +
+				"in manifest path: " + configMapPath,
+		},
+		{name: "allowed embedded", manifestPath: configPolicyPath, allowedKinds: []string{"ConfigMap"}},
+		{
+			name:            "disallowed embedded",
+			manifestPath:    configPolicyPath,
+			disallowedKinds: []string{"ConfigMap"},
+			expectedErr: "the kind ConfigMap is in the disallowedKinds of the policy policy-app-config " +
+				"in manifest path: " + configPolicyPath,
+		},
+		{
+			name:            "disallowed expanded",
+			manifestPath:    kyvernoPath,
+			disallowedKinds: []string{"PolicyReport"},
+			expectedErr: "the kind PolicyReport is in the disallowedKinds of the policy policy-app-config " +
+				"in the policy template expanded from manifest path: " + kyvernoPath,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			policyConf := types.PolicyConfig{
+				PolicyOptions: types.PolicyOptions{
+					AllowedKinds:          test.allowedKinds,
+					DisallowedKinds:       test.disallowedKinds,
+					InformKyvernoPolicies: true,
+				},
+				ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
+					ComplianceType:    "musthave",
+					RemediationAction: "inform",
+					Severity:          "low",
+				},
+				Manifests: []types.Manifest{{Path: test.manifestPath}},
+				Name:      "policy-app-config",
+			}
+
+			_, err := getPolicyTemplates(&policyConf, nil)
+			if test.expectedErr == "" {
+				if err != nil {
+					t.Fatalf("Failed to get the policy templates: %v", err)
+				}
+
+				return
+			}
+
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
+}
+
+func TestGetPolicyTemplateNoManifests(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	policyConf := types.PolicyConfig{
+		ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
+			ComplianceType:    "musthave",
+			RemediationAction: "inform",
+			Severity:          "low",
+		},
+		Manifests: []types.Manifest{{Path: tmpDir}},
+		Name:      "policy-app-config",
+	}
+
+	_, err := getPolicyTemplates(&policyConf, nil)
+	if !errors.Is(err, ErrEmptyManifest) {
+		t.Fatalf("Expected an ErrEmptyManifest error but got: %v", err)
+	}
+}
+
+func TestGetPolicyTemplateEmptyManifest(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	emptyDir := path.Join(tmpDir, "empty")
+
+	err := os.Mkdir(emptyDir, 0o777)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = os.WriteFile(path.Join(emptyDir, "separators.yaml"), []byte("---\n---\n"), 0o666)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	policyConf := types.PolicyConfig{
+		Manifests: []types.Manifest{
+			{Path: path.Join(tmpDir, "configmap.yaml")},
+			{Path: emptyDir},
+		},
+		Name: "policy-app-config",
+	}
+
+	_, err = getPolicyTemplates(&policyConf, nil)
+	if !errors.Is(err, ErrEmptyManifest) {
+		t.Fatalf("Expected an ErrEmptyManifest error but got: %v", err)
+	}
+
+	expected := fmt.Sprintf(
+		"the manifest %s in policy policy-app-config doesn't have any objects; set allowEmpty to true to allow it",
+		emptyDir,
+	)
+	assertEqual(t, err.Error(), expected)
+
+	policyConf.Manifests[1].AllowEmpty = true
+
+	policyTemplates, err := getPolicyTemplates(&policyConf, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assertEqual(t, len(policyTemplates), 1)
+}
+
+func TestGetPolicyTemplateInvalidPath(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	manifestPath := path.Join(tmpDir, "does-not-exist.yaml")
+	policyConf := types.PolicyConfig{
+		ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
+			ComplianceType:    "musthave",
+			RemediationAction: "inform",
+			Severity:          "low",
+		},
+		Manifests: []types.Manifest{{
+			ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
+				ComplianceType: "musthave",
+			},
+			Path: manifestPath,
+		}},
+		Name: "policy-app-config",
+	}
+
+	_, err := getPolicyTemplates(&policyConf, nil)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := fmt.Sprintf("failed to read the manifest path %s", manifestPath)
+	assertEqual(t, err.Error(), expected)
+	if !errors.Is(err, ErrManifestNotFound) || !errors.Is(err, ErrManifestRead) {
+		t.Fatalf("Expected an ErrManifestNotFound error but got: %v", err)
+	}
+}
+
+func TestGetPolicyTemplateInvalidManifest(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	manifestPath := path.Join(tmpDir, "configmap.yaml")
+	// Ensure an error is returned when there is an invalid manifest file
+	err := os.WriteFile(manifestPath, []byte("$i am not YAML!"), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write %s", manifestPath)
+	}
+
+	policyConf := types.PolicyConfig{
+		ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
+			ComplianceType:    "musthave",
+			RemediationAction: "inform",
+			Severity:          "low",
+		},
+		Manifests: []types.Manifest{{Path: manifestPath}},
+		Name:      "policy-app-config",
+	}
+
+	_, err = getPolicyTemplates(&policyConf, nil)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := fmt.Sprintf(
+		"failed to decode the manifest file at %s: the input manifests must be in the format of "+
+			"YAML objects", manifestPath,
+	)
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestGetPolicyTemplateNotKubernetesObject(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	manifestPath := path.Join(tmpDir, "configmap.yaml")
+	// Ensure a clear error is returned when the manifest is a YAML object but not a Kubernetes object
+	err := os.WriteFile(manifestPath, []byte("foo: bar"), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write %s", manifestPath)
+	}
+
+	policyConf := types.PolicyConfig{
+		ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
+			ComplianceType:    "musthave",
+			RemediationAction: "inform",
+			Severity:          "low",
+		},
+		Manifests: []types.Manifest{{Path: manifestPath}},
+		Name:      "policy-app-config",
+	}
+
+	_, err = getPolicyTemplates(&policyConf, nil)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := fmt.Sprintf(
+		"the manifest in manifest path: %s is not a Kubernetes object since it is missing the apiVersion and "+
+			"kind fields", manifestPath,
+	)
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestGetPolicyTemplateObjectNamespace(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	manifestPath := path.Join(tmpDir, "manifests.yaml")
+	yamlContent := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-configmap
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-configmap2
+  namespace: existing
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: my-namespace
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: my-clusterrole
+---
+apiVersion: example.com/v1
+kind: MyClusterResource
+metadata:
+  name: my-cluster-resource
+`
+
+	err := os.WriteFile(manifestPath, []byte(yamlContent), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write %s", manifestPath)
+	}
+
+	tests := map[string]struct {
+		override           bool
+		clusterScopedKinds []string
+		expected           []string
+	}{
+		"without override": {expected: []string{"my-ns", "existing", "", "", "my-ns"}},
+		"with override":    {override: true, expected: []string{"my-ns", "my-ns", "", "", "my-ns"}},
+		"with clusterScopedKinds": {
+			clusterScopedKinds: []string{"MyClusterResource"},
+			expected:           []string{"my-ns", "existing", "", "", ""},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			policyConf := types.PolicyConfig{
+				PolicyOptions: types.PolicyOptions{
+					ConsolidateManifests: true,
+				},
+				ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
+					ComplianceType:    "musthave",
+					RemediationAction: "inform",
+					Severity:          "low",
+				},
+				Manifests: []types.Manifest{{
+					Path:                    manifestPath,
+					ObjectNamespace:         "my-ns",
+					OverrideObjectNamespace: test.override,
+					ClusterScopedKinds:      test.clusterScopedKinds,
+				}},
+				Name: "policy-app-config",
+			}
+
+			policyTemplates, err := getPolicyTemplates(&policyConf, nil)
+			if err != nil {
+				t.Fatalf("Failed to get the policy templates: %v", err)
+			}
+
+			objdef := policyTemplates[0]["objectDefinition"].(map[string]interface{})
+
+			spec, ok := objdef["spec"].(map[string]interface{})
+			if !ok {
+				t.Fatal("The spec field is an invalid format")
+			}
+
+			objTemplates, ok := spec["object-templates"].([]map[string]interface{})
+			if !ok {
+				t.Fatal("The object-templates field is an invalid format")
+			}
+
+			namespaces := []string{}
+
+			for _, objTemplate := range objTemplates {
+				ns, _, _ := unstructured.NestedString(objTemplate, "objectDefinition", "metadata", "namespace")
+				namespaces = append(namespaces, ns)
+			}
+
+			assertEqual(t, namespaces, test.expected)
+		})
+	}
+}
+
+func TestGetPolicyTemplateObjectMetadata(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	manifestPath := path.Join(tmpDir, "manifests.yaml")
+	yamlContent := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-configmap
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-configmap2
+  labels:
+    team: existing
+    version: 2
+  annotations:
+    owner: existing
+`
+
+	err := os.WriteFile(manifestPath, []byte(yamlContent), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write %s", manifestPath)
+	}
+
+	tests := map[string]struct {
+		override            bool
+		expectedLabels      []map[string]interface{}
+		expectedAnnotations []map[string]interface{}
+	}{
+		"without override": {
+			expectedLabels: []map[string]interface{}{
+				{"team": "platform"},
+				{"team": "existing", "version": 2},
+			},
+			expectedAnnotations: []map[string]interface{}{
+				{"owner": "platform@example.com"},
+				{"owner": "existing"},
+			},
+		},
+		"with override": {
+			override: true,
+			expectedLabels: []map[string]interface{}{
+				{"team": "platform"},
+				{"team": "platform", "version": 2},
+			},
+			expectedAnnotations: []map[string]interface{}{
+				{"owner": "platform@example.com"},
+				{"owner": "platform@example.com"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			policyConf := types.PolicyConfig{
+				PolicyOptions: types.PolicyOptions{
+					ConsolidateManifests: true,
+				},
+				ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
+					ComplianceType:    "musthave",
+					RemediationAction: "inform",
+					Severity:          "low",
+				},
+				Manifests: []types.Manifest{{
+					Path:                   manifestPath,
+					ObjectLabels:           map[string]string{"team": "platform"},
+					ObjectAnnotations:      map[string]string{"owner": "platform@example.com"},
+					OverrideObjectMetadata: test.override,
+				}},
+				Name: "policy-app-config",
+			}
+
+			policyTemplates, err := getPolicyTemplates(&policyConf, nil)
+			if err != nil {
+				t.Fatalf("Failed to get the policy templates: %v", err)
+			}
+
+			assertEqual(t, len(policyTemplates), 1)
+
+			objTemplates, _, _ := unstructured.NestedFieldNoCopy(
+				policyTemplates[0], "objectDefinition", "spec", "object-templates",
+			)
+
+			labels := []map[string]interface{}{}
+			annotations := []map[string]interface{}{}
+
+			//nolint:forcetypeassert
+			for _, objTemplate := range objTemplates.([]map[string]interface{}) {
+				metadata := objTemplate["objectDefinition"].(map[string]interface{})["metadata"].(map[string]interface{})
+				labels = append(labels, metadata["labels"].(map[string]interface{}))
+				annotations = append(annotations, metadata["annotations"].(map[string]interface{}))
+			}
+
+			assertReflectEqual(t, labels, test.expectedLabels)
+			assertReflectEqual(t, annotations, test.expectedAnnotations)
+		})
+	}
+}
+
+func TestGetPolicyTemplateObjectTemplatesRaw(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	manifestPath := path.Join(tmpDir, "object-templates-raw.yaml")
+	manifestYAMLMultiple := `
+object-templates-raw: |
+  content1
+---
+object-templates-raw: |
+  content2
+`
+	manifestYAMLContent1 := `content1
+`
+	manifestYAMLContent2 := `content2
+`
+
+	err := os.WriteFile(manifestPath, []byte(manifestYAMLMultiple), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write %s", manifestPath)
+	}
+
+	policyConf := types.PolicyConfig{
+		PolicyOptions: types.PolicyOptions{
+			ConsolidateManifests: false,
+		},
+		ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
+			ComplianceType:    "musthave",
+			RemediationAction: "enforce",
+			Severity:          "low",
+		},
+		Manifests: []types.Manifest{{Path: manifestPath}},
+		Name:      "configpolicy-object-templates-raw-config",
+	}
+
+	policyTemplates, err := getPolicyTemplates(&policyConf, nil)
+	if err != nil {
+		t.Fatalf("Failed to get the policy templates: %v", err)
+	}
+
+	assertEqual(t, len(policyTemplates), 2)
+
+	policyTemplate1 := policyTemplates[0]
+	objdef := policyTemplate1["objectDefinition"].(map[string]interface{})
+
+	spec, ok := objdef["spec"].(map[string]interface{})
+	if !ok {
+		t.Fatal("The spec field is an invalid format")
+	}
+
+	objectTemplatesRaw, ok := spec["object-templates-raw"].(string)
+	if !ok {
+		t.Fatal("The object-templates-raw field is an invalid format")
+	}
+
+	assertEqual(t, objectTemplatesRaw, manifestYAMLContent1)
+
+	policyTemplate2 := policyTemplates[1]
+	objdef = policyTemplate2["objectDefinition"].(map[string]interface{})
+
+	spec, ok = objdef["spec"].(map[string]interface{})
+	if !ok {
+		t.Fatal("The spec field is an invalid format")
+	}
+
+	objectTemplatesRaw, ok = spec["object-templates-raw"].(string)
+	if !ok {
 		t.Fatal("The object-templates-raw field is an invalid format")
 	}
 
-	assertEqual(t, objectTemplatesRaw, manifestYAMLContent2)
+	assertEqual(t, objectTemplatesRaw, manifestYAMLContent2)
+}
+
+func TestGetPolicyTemplateObjectTemplatesRawConsolidated(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	manifestPath1 := path.Join(tmpDir, "object-templates-raw1.yaml")
+	manifestYAML1 := `
+object-templates-raw: |-
+  - complianceType: musthave
+    objectDefinition:
+      apiVersion: v1
+      kind: ConfigMap
+      metadata:
+        name: configmap1
+        namespace: default
+`
+	manifestPath2 := path.Join(tmpDir, "object-templates-raw2.yaml")
+	manifestYAML2 := `
+object-templates-raw: |
+  {{- range (lookup "v1" "Namespace" "" "").items }}
+  - complianceType: musthave
+    objectDefinition:
+      apiVersion: v1
+      kind: ConfigMap
+      metadata:
+        name: configmap2
+        namespace: {{ .metadata.name }}
+  {{- end }}
+`
+
+	for manifestPath, manifestYAML := range map[string]string{
+		manifestPath1: manifestYAML1, manifestPath2: manifestYAML2,
+	} {
+		err := os.WriteFile(manifestPath, []byte(manifestYAML), 0o666)
+		if err != nil {
+			t.Fatalf("Failed to write %s", manifestPath)
+		}
+	}
+
+	policyConf := types.PolicyConfig{
+		PolicyOptions: types.PolicyOptions{
+			ConsolidateManifests: true,
+		},
+		ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
+			ComplianceType:    "musthave",
+			RemediationAction: "enforce",
+			Severity:          "low",
+		},
+		Manifests: []types.Manifest{{Path: manifestPath1}, {Path: manifestPath2}},
+		Name:      "configpolicy-object-templates-raw-config",
+	}
+
+	policyTemplates, err := getPolicyTemplates(&policyConf, nil)
+	if err != nil {
+		t.Fatalf("Failed to get the policy templates: %v", err)
+	}
+
+	assertEqual(t, len(policyTemplates), 1)
+
+	name, _, _ := unstructured.NestedString(policyTemplates[0], "objectDefinition", "metadata", "name")
+	assertEqual(t, name, "configpolicy-object-templates-raw-config")
+
+	objectTemplatesRaw, _, _ := unstructured.NestedString(
+		policyTemplates[0], "objectDefinition", "spec", "object-templates-raw",
+	)
+
+	expected := `- complianceType: musthave
+  objectDefinition:
+    apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: configmap1
+      namespace: default
+{{- range (lookup "v1" "Namespace" "" "").items }}
+- complianceType: musthave
+  objectDefinition:
+    apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: configmap2
+      namespace: {{ .metadata.name }}
+{{- end }}
+`
+	assertEqual(t, objectTemplatesRaw, expected)
+}
+
+func TestGetPolicyTemplateObjectTemplatesRawConsolidatedMismatch(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	manifestPath1 := path.Join(tmpDir, "object-templates-raw1.yaml")
+	manifestPath2 := path.Join(tmpDir, "object-templates-raw2.yaml")
+	manifestYAML := `
+object-templates-raw: |-
+  - complianceType: musthave
+    objectDefinition:
+      apiVersion: v1
+      kind: ConfigMap
+      metadata:
+        name: configmap1
+        namespace: default
+`
+
+	for _, manifestPath := range []string{manifestPath1, manifestPath2} {
+		err := os.WriteFile(manifestPath, []byte(manifestYAML), 0o666)
+		if err != nil {
+			t.Fatalf("Failed to write %s", manifestPath)
+		}
+	}
+
+	tests := map[string]struct {
+		manifest    types.Manifest
+		expectedErr string
+	}{
+		"remediationAction": {
+			manifest: types.Manifest{
+				Path: manifestPath2,
+				ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
+					RemediationAction: "inform",
+					Severity:          "low",
+				},
+			},
+			expectedErr: fmt.Sprintf(
+				"the remediationAction value of the object-templates-raw in manifest path: %s must match the "+
+					"consolidated object-templates-raw in manifest path: %s",
+				manifestPath2, manifestPath1,
+			),
+		},
+		"severity": {
+			manifest: types.Manifest{
+				Path: manifestPath2,
+				ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
+					RemediationAction: "enforce",
+					Severity:          "high",
+				},
+			},
+			expectedErr: fmt.Sprintf(
+				"the severity value of the object-templates-raw in manifest path: %s must match the "+
+					"consolidated object-templates-raw in manifest path: %s",
+				manifestPath2, manifestPath1,
+			),
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			policyConf := types.PolicyConfig{
+				PolicyOptions: types.PolicyOptions{
+					ConsolidateManifests: true,
+				},
+				ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
+					ComplianceType:    "musthave",
+					RemediationAction: "enforce",
+					Severity:          "low",
+				},
+				Manifests: []types.Manifest{
+					{
+						Path: manifestPath1,
+						ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
+							RemediationAction: "enforce",
+							Severity:          "low",
+						},
+					},
+					test.manifest,
+				},
+				Name: "configpolicy-object-templates-raw-config",
+			}
+
+			_, err := getPolicyTemplates(&policyConf, nil)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+		})
+	}
 }
 
 func TestUnmarshalManifestFile(t *testing.T) {
@@ -1681,7 +2975,7 @@ func TestVerifyManifestPath(t *testing.T) {
 		t.Run(
 			"manifestPath="+test.ManifestPath,
 			func(t *testing.T) {
-				err := verifyFilePath(workingDir, test.ManifestPath, "manifest")
+				err := verifyFilePath(workingDir, test.ManifestPath, "manifest", false)
 				if err == nil {
 					assertEqual(t, "", test.ExpectedErrMsg)
 				} else {
@@ -1692,6 +2986,53 @@ func TestVerifyManifestPath(t *testing.T) {
 	}
 }
 
+func TestVerifyFilePathStandalone(t *testing.T) {
+	t.Parallel()
+
+	baseDirectory, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to evaluate symlinks for the base directory: %v", err)
+	}
+
+	workingDir := path.Join(baseDirectory, "workingdir")
+
+	err = os.Mkdir(workingDir, 0o777)
+	if err != nil {
+		t.Fatalf("Failed to create the directory structure %s: %v", workingDir, err)
+	}
+
+	tests := map[string]struct {
+		filePath       string
+		expectedErrMsg string
+	}{
+		"outside of the base directory": {
+			baseDirectory,
+			fmt.Sprintf(
+				"the manifest path %s is not in the directory tree of the base directory %s", baseDirectory, workingDir,
+			),
+		},
+		"the base directory": {
+			workingDir,
+			fmt.Sprintf("the manifest path %s may not refer to the base directory %s", workingDir, workingDir),
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := verifyFilePath(workingDir, test.filePath, "manifest", true)
+			if err == nil {
+				t.Fatal("Expected an error but did not get one")
+			}
+
+			assertEqual(t, err.Error(), test.expectedErrMsg)
+		})
+	}
+}
+
 func TestProcessKustomizeDir(t *testing.T) {
 	baseDirectory, err := filepath.EvalSymlinks(t.TempDir())
 	if err != nil {
@@ -1785,3 +3126,355 @@ func TestGetRootRemediationAction(t *testing.T) {
 	expected = getRootRemediationAction(policyTemplates)
 	assertEqual(t, "inform", expected)
 }
+
+func TestEvaluateIncludeWhen(t *testing.T) {
+	t.Setenv("POLICY_GEN_TEST_ENV", "prod")
+
+	values := map[string]string{"env": "prod", "enabled": "true"}
+
+	tests := map[string]struct {
+		condition   string
+		expected    bool
+		expectedErr string
+	}{
+		"env var equal":        {condition: "${POLICY_GEN_TEST_ENV} == prod", expected: true},
+		"quoted env var equal": {condition: `"${POLICY_GEN_TEST_ENV}" == "prod"`, expected: true},
+		"env var not equal":    {condition: "${POLICY_GEN_TEST_ENV} != prod", expected: false},
+		"value equal":          {condition: "values.env == ${POLICY_GEN_TEST_ENV}", expected: true},
+		"value not equal":      {condition: "values.env != dev", expected: true},
+		"boolean value":        {condition: "values.enabled", expected: true},
+		"boolean literal":      {condition: "false", expected: false},
+		"missing value":        {condition: "values.region == us", expectedErr: "the value region is not set in values"},
+		"empty operand":        {condition: "== prod", expectedErr: "the condition has an empty operand"},
+		"unset env var": {
+			condition:   "${POLICY_GEN_TEST_UNSET} == prod",
+			expectedErr: "the environment variable POLICY_GEN_TEST_UNSET is not set",
+		},
+		"non-boolean condition": {
+			condition: "values.env",
+			expectedErr: "the condition values.env must be a comparison with == or != or resolve to true or " +
+				"false, but got prod",
+		},
+	}
+
+	for name, test := range tests {
+		include, err := evaluateIncludeWhen(test.condition, values)
+		if test.expectedErr != "" {
+			if err == nil {
+				t.Fatalf("%s: Expected an error but did not get one", name)
+			}
+
+			assertEqual(t, err.Error(), test.expectedErr)
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%s: %s", name, err.Error())
+		}
+
+		assertEqual(t, include, test.expected)
+	}
+}
+
+func TestGetPolicyTemplateIncludeWhen(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	configMap2 := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-configmap2
+data:
+  game.properties: enemies=potato
+`
+
+	err := os.WriteFile(path.Join(tmpDir, "configmap2.yaml"), []byte(configMap2), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write configmap2.yaml: %v", err)
+	}
+
+	policyConf := types.PolicyConfig{
+		ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
+			ComplianceType:    "musthave",
+			RemediationAction: "inform",
+			Severity:          "low",
+		},
+		Manifests: []types.Manifest{
+			{Path: path.Join(tmpDir, "configmap.yaml"), IncludeWhen: "values.env == dev"},
+			{Path: path.Join(tmpDir, "configmap2.yaml"), IncludeWhen: "values.env == prod"},
+		},
+		Name: "policy-app-config",
+	}
+
+	policyTemplates, err := getPolicyTemplates(&policyConf, map[string]string{"env": "prod"})
+	if err != nil {
+		t.Fatalf("Failed to get the policy templates: %v", err)
+	}
+
+	assertEqual(t, len(policyTemplates), 1)
+
+	objdef := policyTemplates[0]["objectDefinition"].(map[string]interface{})
+
+	spec, ok := objdef["spec"].(map[string]interface{})
+	if !ok {
+		t.Fatal("The spec field is an invalid format")
+	}
+
+	objTemplates, ok := spec["object-templates"].([]map[string]interface{})
+	if !ok {
+		t.Fatal("The object-templates field is an invalid format")
+	}
+
+	assertEqual(t, len(objTemplates), 1)
+
+	name, _, _ := unstructured.NestedString(objTemplates[0], "objectDefinition", "metadata", "name")
+	assertEqual(t, name, "my-configmap2")
+
+	_, err = getPolicyTemplates(&policyConf, map[string]string{"env": "stage"})
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expected := "the policy policy-app-config must specify at least one non-empty manifest file"
+	assertEqual(t, err.Error(), expected)
+}
+
+func TestGetPolicyTemplateIncludeKinds(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	createConfigMap(t, tmpDir, "configmap.yaml")
+
+	objects := map[string]string{
+		"secret.yaml": `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: my-secret
+stringData:
+  password: potato
+`,
+		"deployment.yaml": `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: my-deployment
+`,
+	}
+
+	for fileName, object := range objects {
+		err := os.WriteFile(path.Join(tmpDir, fileName), []byte(object), 0o666)
+		if err != nil {
+			t.Fatalf("Failed to write %s: %v", fileName, err)
+		}
+	}
+
+	tests := map[string]struct {
+		includeKinds       []string
+		includeAPIVersions []string
+		expectedKinds      []string
+	}{
+		"no filters":          {nil, nil, []string{"ConfigMap", "Deployment", "Secret"}},
+		"includeKinds":        {[]string{"ConfigMap"}, nil, []string{"ConfigMap"}},
+		"includeApiVersions":  {nil, []string{"v1"}, []string{"ConfigMap", "Secret"}},
+		"both filters":        {[]string{"ConfigMap", "Deployment"}, []string{"apps/v1"}, []string{"Deployment"}},
+		"kinds without match": {[]string{"NetworkPolicy", "Secret"}, nil, []string{"Secret"}},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			policyConf := types.PolicyConfig{
+				PolicyOptions: types.PolicyOptions{
+					ConsolidateManifests: true,
+				},
+				ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
+					ComplianceType:    "musthave",
+					RemediationAction: "inform",
+					Severity:          "low",
+				},
+				Manifests: []types.Manifest{{
+					Path:               tmpDir,
+					IncludeKinds:       test.includeKinds,
+					IncludeAPIVersions: test.includeAPIVersions,
+				}},
+				Name: "policy-app-config",
+			}
+
+			policyTemplates, err := getPolicyTemplates(&policyConf, nil)
+			if err != nil {
+				t.Fatalf("Failed to get the policy templates: %v", err)
+			}
+
+			assertEqual(t, len(policyTemplates), 1)
+
+			objdef := policyTemplates[0]["objectDefinition"].(map[string]interface{})
+
+			spec, ok := objdef["spec"].(map[string]interface{})
+			if !ok {
+				t.Fatal("The spec field is an invalid format")
+			}
+
+			objTemplates, ok := spec["object-templates"].([]map[string]interface{})
+			if !ok {
+				t.Fatal("The object-templates field is an invalid format")
+			}
+
+			kinds := []string{}
+
+			for _, objTemplate := range objTemplates {
+				kind, _, _ := unstructured.NestedString(objTemplate, "objectDefinition", "kind")
+				kinds = append(kinds, kind)
+			}
+
+			assertReflectEqual(t, kinds, test.expectedKinds)
+		})
+	}
+}
+
+func TestGetPolicyTemplateObjectTemplatesRawPreRender(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	manifestPath := path.Join(tmpDir, "object-templates-raw.yaml")
+	manifestYAML := `
+object-templates-raw: |
+  - complianceType: musthave
+    objectDefinition:
+      apiVersion: v1
+      kind: ConfigMap
+      metadata:
+        name: [[ .name ]]
+        namespace: default
+      data:
+        region: '[[ .region ]]'
+        cluster: '{{ fromClusterClaim "name" }}'
+`
+
+	err := os.WriteFile(manifestPath, []byte(manifestYAML), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write %s", manifestPath)
+	}
+
+	policyConf := types.PolicyConfig{
+		PolicyOptions: types.PolicyOptions{
+			ConsolidateManifests: true,
+		},
+		ConfigurationPolicyOptions: types.ConfigurationPolicyOptions{
+			ComplianceType:    "musthave",
+			RemediationAction: "enforce",
+			Severity:          "low",
+		},
+		Manifests: []types.Manifest{{
+			Path:      manifestPath,
+			PreRender: map[string]interface{}{"name": "my-configmap", "region": "east"},
+		}},
+		Name: "configpolicy-object-templates-raw-config",
+	}
+
+	policyTemplates, err := getPolicyTemplates(&policyConf, nil)
+	if err != nil {
+		t.Fatalf("Failed to get the policy templates: %v", err)
+	}
+
+	assertEqual(t, len(policyTemplates), 1)
+
+	objectTemplatesRaw, _, _ := unstructured.NestedString(
+		policyTemplates[0], "objectDefinition", "spec", "object-templates-raw",
+	)
+
+	expected := `- complianceType: musthave
+  objectDefinition:
+    apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: my-configmap
+      namespace: default
+    data:
+      region: 'east'
+      cluster: '{{ fromClusterClaim "name" }}'
+`
+	assertEqual(t, objectTemplatesRaw, expected)
+
+	policyConf.Manifests[0].PreRender = map[string]interface{}{"name": "my-configmap"}
+
+	_, err = getPolicyTemplates(&policyConf, nil)
+	if err == nil {
+		t.Fatal("Expected an error but did not get one")
+	}
+
+	expectedErr := "failed to preRender the object-templates-raw: template: object-templates-raw:9:18: " +
+		`executing "object-templates-raw" at <.region>: map has no entry for key "region" in manifest path: ` +
+		manifestPath
+	assertEqual(t, err.Error(), expectedErr)
+}
+
+func TestRewritePathPrefix(t *testing.T) {
+	t.Parallel()
+
+	mappings := []PathPrefixMapping{
+		{Old: "manifests/base", New: "ci/base"},
+		{Old: "manifests/", New: "/checkout/manifests"},
+	}
+
+	tests := map[string]struct {
+		filePath string
+		expected string
+	}{
+		"first mapping":      {filePath: "manifests/base/configmap.yaml", expected: "ci/base/configmap.yaml"},
+		"whole path":         {filePath: "manifests", expected: "/checkout/manifests"},
+		"partial segment":    {filePath: "manifests-v2/configmap.yaml", expected: "manifests-v2/configmap.yaml"},
+		"no matching prefix": {filePath: "placements/placement.yaml", expected: "placements/placement.yaml"},
+		"second mapping": {
+			filePath: "manifests/other/configmap.yaml",
+			expected: "/checkout/manifests/other/configmap.yaml",
+		},
+	}
+
+	for name, test := range tests {
+		rewritten := rewritePathPrefix(test.filePath, mappings)
+		if rewritten != test.expected {
+			t.Fatalf("%s: expected %s but got %s", name, test.expected, rewritten)
+		}
+	}
+}
+
+func TestParseFieldPath(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		fieldPath string
+		expected  []string
+	}{
+		"dot-separated": {fieldPath: "metadata.labels.team", expected: []string{"metadata", "labels", "team"}},
+		"bracketed": {
+			fieldPath: "metadata.labels[app.kubernetes.io/name]",
+			expected:  []string{"metadata", "labels", "app.kubernetes.io/name"},
+		},
+		"dotted bracket": {
+			fieldPath: "metadata.annotations.[a.b/c]",
+			expected:  []string{"metadata", "annotations", "a.b/c"},
+		},
+		"middle bracket": {
+			fieldPath: "data[game.properties].value",
+			expected:  []string{"data", "game.properties", "value"},
+		},
+		"empty":              {fieldPath: "", expected: nil},
+		"empty segment":      {fieldPath: "metadata..team", expected: nil},
+		"trailing dot":       {fieldPath: "metadata.labels.", expected: nil},
+		"empty bracket":      {fieldPath: "metadata.labels[]", expected: nil},
+		"unclosed bracket":   {fieldPath: "metadata.labels[app.kubernetes.io/name", expected: nil},
+		"text after bracket": {fieldPath: "metadata.labels[a.b]c", expected: nil},
+	}
+
+	for name, test := range tests {
+		fields, ok := parseFieldPath(test.fieldPath)
+		if ok != (test.expected != nil) || !reflect.DeepEqual(fields, test.expected) {
+			t.Fatalf("%s: expected %v but got %v", name, test.expected, fields)
+		}
+	}
+}